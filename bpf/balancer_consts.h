@@ -26,7 +26,7 @@
 #define IPV4_PLUS_ICMP_HDR 28
 #define IPV6_PLUS_ICMP_HDR 48
 
-// consistent hashing ring size
+// consistent hashing ring size, of vips without their own ring size
 #ifndef RING_SIZE
 #define RING_SIZE 65537
 #endif
@@ -62,12 +62,23 @@
 
 #define CTL_MAP_SIZE 16
 
+// position in ctl_array with the number of active next hops in nexthop_macs.
+// if it's zero the default's mac at position 0 is used
+#define NEXTHOPS_CNT_POS 6
+
+// max number of next hops (e.g. ToRs of an ECMP default route)
+#ifndef MAX_NEXTHOPS
+#define MAX_NEXTHOPS 16
+#endif
+
 // size of internal prog array
 #define SUBPROGRAMS_ARRAY_SIZE 1
 // position where flomesh lb would register itself in prog array
 // for recirculation
 #define RECIRCULATION_INDEX 0
 
+// default size of ch_rings, the control plane could set a smaller one
+// when vips have rings of different sizes
 #define CH_RINGS_SIZE (MAX_VIPS * RING_SIZE)
 #define STATS_MAP_SIZE (MAX_VIPS * 2)
 
@@ -235,7 +246,8 @@
 #define ICMP_PTB_V4_STATS 13
 
 // indice for all stats maps defined above correspond to entries in the map
-// stats starting from the index MAX_VIPS. The max_entries of stats is
+// stats starting from the index max_vips (MAX_VIPS unless overridden on load,
+// see balancer_runtime_consts.h). The max_entries of stats is
 // STATS_MAP_SIZE defined as (MAX_VIPS * 2). So the index above should be always
 // less than MAX_VIPS. Otherwise, STATS_MAP_SIZE shall be updated.
 
@@ -324,6 +336,10 @@
  *
  * LOCAL_DELIVERY_OPTIMIZATION - allow to do optimization on local traffic,
  * where vip and real address are specified the same machine
+ *
+ * RECORD_FLOW_INFO - record outer addresses of inline decapsulated gue
+ * packets into flow_debug_maps (requires INLINE_DECAP_GUE). nothing is
+ * recorded until userspace inserts per cpu maps into flow_debug_maps
  */
 #ifdef LPM_SRC_LOOKUP
 #ifndef INLINE_DECAP
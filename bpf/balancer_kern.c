@@ -13,6 +13,7 @@
 #include "balancer_consts.h"
 #include "balancer_helpers.h"
 #include "balancer_maps.h"
+#include "balancer_runtime_consts.h"
 #include "balancer_structs.h"
 #include "handle_icmp.h"
 #include "pckt_encap.h"
@@ -28,9 +29,35 @@ get_packet_hash(struct packet_description *pckt, bool hash_16bytes) {
   }
 }
 
+__attribute__((__always_inline__)) static inline struct ctl_value *
+get_nexthop(struct packet_description *pckt, bool is_ipv6, __u16 pkt_bytes) {
+  struct ctl_value *cval;
+  struct lb_stats *nh_stats;
+  __u32 key = NEXTHOPS_CNT_POS;
+  __u32 nexthops_cnt;
+
+  cval = bpf_map_lookup_elem(&ctl_array, &key);
+  if (!cval || cval->value == 0) {
+    // no next hops configured. use default's mac
+    key = 0;
+    return bpf_map_lookup_elem(&ctl_array, &key);
+  }
+  nexthops_cnt = cval->value;
+  if (nexthops_cnt > MAX_NEXTHOPS) {
+    nexthops_cnt = MAX_NEXTHOPS;
+  }
+  key = get_packet_hash(pckt, is_ipv6) % nexthops_cnt;
+  nh_stats = bpf_map_lookup_elem(&nexthop_stats, &key);
+  if (nh_stats) {
+    nh_stats->v1 += 1;
+    nh_stats->v2 += pkt_bytes;
+  }
+  return bpf_map_lookup_elem(&nexthop_macs, &key);
+}
+
 __attribute__((__always_inline__)) static inline bool
 is_under_flood(__u64 *cur_time) {
-  __u32 conn_rate_key = MAX_VIPS + NEW_CONN_RATE_CNTR;
+  __u32 conn_rate_key = max_vips + NEW_CONN_RATE_CNTR;
   struct lb_stats *conn_rate_stats =
       bpf_map_lookup_elem(&stats, &conn_rate_key);
   if (!conn_rate_stats) {
@@ -57,7 +84,7 @@ is_under_flood(__u64 *cur_time) {
 
 __attribute__((__always_inline__)) static inline void
 increment_ch_drop_no_real() {
-  __u32 ch_drop_stats_key = MAX_VIPS + CH_DROP_STATS;
+  __u32 ch_drop_stats_key = max_vips + CH_DROP_STATS;
   struct lb_stats *ch_drop_stats =
       bpf_map_lookup_elem(&stats, &ch_drop_stats_key);
   if (!ch_drop_stats) {
@@ -68,7 +95,7 @@ increment_ch_drop_no_real() {
 
 __attribute__((__always_inline__)) static inline void
 increment_ch_drop_real_0() {
-  __u32 ch_drop_stats_key = MAX_VIPS + CH_DROP_STATS;
+  __u32 ch_drop_stats_key = max_vips + CH_DROP_STATS;
   struct lb_stats *ch_drop_stats =
       bpf_map_lookup_elem(&stats, &ch_drop_stats_key);
   if (!ch_drop_stats) {
@@ -109,7 +136,7 @@ get_packet_dst(struct real_definition **real, struct packet_description *pckt,
       src_found = true;
       key = *lpm_val;
     }
-    __u32 stats_key = MAX_VIPS + LPM_SRC_CNTRS;
+    __u32 stats_key = max_vips + LPM_SRC_CNTRS;
     struct lb_stats *data_stats = bpf_map_lookup_elem(&stats, &stats_key);
     if (data_stats) {
       if (src_found) {
@@ -130,8 +157,16 @@ get_packet_dst(struct real_definition **real, struct packet_description *pckt,
       pckt->flow.port16[0] = pckt->flow.port16[1];
       memset(pckt->flow.srcv6, 0, 16);
     }
-    hash = get_packet_hash(pckt, hash_16bytes) % RING_SIZE;
-    key = RING_SIZE * (vip_info->vip_num) + hash;
+    __u32 vip_num = vip_info->vip_num;
+    __u32 vip_ring_size = ring_size;
+    __u32 ring_offset = ring_size * vip_num;
+    struct vip_ring *ring = bpf_map_lookup_elem(&vip_rings, &vip_num);
+    if (ring && ring->size) {
+      vip_ring_size = ring->size;
+      ring_offset = ring->offset;
+    }
+    hash = get_packet_hash(pckt, hash_16bytes) % vip_ring_size;
+    key = ring_offset + hash;
 
     real_pos = bpf_map_lookup_elem(&ch_rings, &key);
     if (!real_pos) {
@@ -303,7 +338,7 @@ check_decap_dst(struct packet_description *pckt, bool is_ipv6, bool *pass) {
 
   if (decap_dst_flags) {
     *pass = false;
-    __u32 stats_key = MAX_VIPS + REMOTE_ENCAP_CNTRS;
+    __u32 stats_key = max_vips + REMOTE_ENCAP_CNTRS;
     data_stats = bpf_map_lookup_elem(&stats, &stats_key);
     if (!data_stats) {
       return XDP_DROP;
@@ -323,7 +358,7 @@ perform_global_lru_lookup(struct real_definition **dst,
                           struct vip_meta *vip_info, bool is_ipv6) {
   // lookup in the global cache
   void *g_lru_map = bpf_map_lookup_elem(&global_lru_maps, &cpu_num);
-  __u32 global_lru_stats_key = MAX_VIPS + GLOBAL_LRU_CNTR;
+  __u32 global_lru_stats_key = max_vips + GLOBAL_LRU_CNTR;
 
   struct lb_stats *global_lru_stats =
       bpf_map_lookup_elem(&stats, &global_lru_stats_key);
@@ -386,7 +421,7 @@ process_encaped_ipip_pckt(void **data, void **data_end, struct xdp_md *xdp,
     }
   }
 
-  __u32 stats_key = MAX_VIPS + DECAP_CNTR;
+  __u32 stats_key = max_vips + DECAP_CNTR;
   struct lb_stats *data_stats = bpf_map_lookup_elem(&stats, &stats_key);
   if (!data_stats) {
     return XDP_DROP;
@@ -450,7 +485,7 @@ process_encaped_gue_pckt(void **data, void **data_end, struct xdp_md *xdp,
     }
   }
 
-  __u32 stats_key = MAX_VIPS + DECAP_CNTR;
+  __u32 stats_key = max_vips + DECAP_CNTR;
   struct lb_stats *data_stats = bpf_map_lookup_elem(&stats, &stats_key);
   if (!data_stats) {
     return XDP_DROP;
@@ -499,7 +534,7 @@ update_vip_lru_miss_stats(struct vip_definition *vip,
                    lru_miss_stat_vip->vipv6[3] == vip->vipv6[3])) ||
       (!is_ipv6 && lru_miss_stat_vip->vip == vip->vip);
   bool port_match = lru_miss_stat_vip->port == vip->port;
-  bool proto_match = lru_miss_stat_vip->proto = vip->proto;
+  bool proto_match = lru_miss_stat_vip->proto == vip->proto;
   bool vip_match = address_match && port_match && proto_match;
   if (vip_match) {
     __u32 lru_stats_key = pckt->real_index;
@@ -528,7 +563,6 @@ process_packet(struct xdp_md *xdp, __u64 off, bool is_ipv6) {
 
   int action;
   __u32 vip_num;
-  __u32 mac_addr_pos = 0;
   __u16 pkt_bytes;
   action = process_l3_headers(&pckt, &protocol, off, &pkt_bytes, data, data_end,
                               is_ipv6);
@@ -632,7 +666,7 @@ process_packet(struct xdp_md *xdp, __u64 off, bool is_ipv6) {
   if (data_end - data > MAX_PCKT_SIZE) {
     REPORT_PACKET_TOOBIG(xdp, data, data_end - data, false);
 #ifdef ICMP_TOOBIG_GENERATION
-    __u32 stats_key = MAX_VIPS + ICMP_TOOBIG_CNTRS;
+    __u32 stats_key = max_vips + ICMP_TOOBIG_CNTRS;
     data_stats = bpf_map_lookup_elem(&stats, &stats_key);
     if (!data_stats) {
       return XDP_DROP;
@@ -648,7 +682,7 @@ process_packet(struct xdp_md *xdp, __u64 off, bool is_ipv6) {
 #endif
   }
 
-  __u32 stats_key = MAX_VIPS + LRU_CNTRS;
+  __u32 stats_key = max_vips + LRU_CNTRS;
   data_stats = bpf_map_lookup_elem(&stats, &stats_key);
   if (!data_stats) {
     return XDP_DROP;
@@ -667,7 +701,7 @@ process_packet(struct xdp_md *xdp, __u64 off, bool is_ipv6) {
       // the quic header from the original datagram is a short header, it has no
       // server generated connection id which can be used for routing.
       // fallback to CH to route quic icmp messages.
-      __u32 stats_key = MAX_VIPS + QUIC_ICMP_STATS;
+      __u32 stats_key = max_vips + QUIC_ICMP_STATS;
       struct lb_stats *data_stats = bpf_map_lookup_elem(&stats, &stats_key);
       if (!data_stats) {
         return XDP_DROP;
@@ -736,7 +770,7 @@ process_packet(struct xdp_md *xdp, __u64 off, bool is_ipv6) {
     void *lru_map = bpf_map_lookup_elem(&lru_mapping, &cpu_num);
     if (!lru_map) {
       lru_map = &fallback_cache;
-      __u32 lru_stats_key = MAX_VIPS + FALLBACK_LRU_CNTR;
+      __u32 lru_stats_key = max_vips + FALLBACK_LRU_CNTR;
       struct lb_stats *lru_stats = bpf_map_lookup_elem(&stats, &lru_stats_key);
       if (!lru_stats) {
         return XDP_DROP;
@@ -749,7 +783,7 @@ process_packet(struct xdp_md *xdp, __u64 off, bool is_ipv6) {
 #ifdef TCP_SERVER_ID_ROUTING
     // First try to lookup dst in the tcp_hdr_opt (if enabled)
     if (pckt.flow.proto == IPPROTO_TCP && !(pckt.flags & F_SYN_SET)) {
-      __u32 routing_stats_key = MAX_VIPS + TCP_SERVER_ID_ROUTE_STATS;
+      __u32 routing_stats_key = max_vips + TCP_SERVER_ID_ROUTE_STATS;
       struct lb_stats *routing_stats =
           bpf_map_lookup_elem(&stats, &routing_stats_key);
       if (!routing_stats) {
@@ -784,7 +818,7 @@ process_packet(struct xdp_md *xdp, __u64 off, bool is_ipv6) {
     // if dst is not found, route via consistent-hashing of the flow.
     if (!dst) {
       if (pckt.flow.proto == IPPROTO_TCP) {
-        __u32 lru_stats_key = MAX_VIPS + LRU_MISS_CNTR;
+        __u32 lru_stats_key = max_vips + LRU_MISS_CNTR;
         struct lb_stats *lru_stats =
             bpf_map_lookup_elem(&stats, &lru_stats_key);
         if (!lru_stats) {
@@ -815,12 +849,6 @@ process_packet(struct xdp_md *xdp, __u64 off, bool is_ipv6) {
     }
   }
 
-  cval = bpf_map_lookup_elem(&ctl_array, &mac_addr_pos);
-
-  if (!cval) {
-    return XDP_DROP;
-  }
-
   vip_num = vip_info->vip_num;
   data_stats = bpf_map_lookup_elem(&stats, &vip_num);
   if (!data_stats) {
@@ -843,6 +871,11 @@ process_packet(struct xdp_md *xdp, __u64 off, bool is_ipv6) {
 #endif
   // restore the original sport value to use it as a seed for the GUE sport
   pckt.flow.port16[0] = original_sport;
+  // pick next hop by flow's hash, so all packets of a flow go through the same router
+  cval = get_nexthop(&pckt, is_ipv6, pkt_bytes);
+  if (!cval) {
+    return XDP_DROP;
+  }
   if (dst->flags & F_IPV6) {
     if (!PCKT_ENCAP_V6(xdp, cval, is_ipv6, &pckt, dst, pkt_bytes)) {
       return XDP_DROP;
@@ -55,6 +55,16 @@ struct {
   __uint(map_flags, NO_FLAGS);
 } ch_rings SEC(".maps");
 
+// offset and size of each vip's ring in ch_rings, by vip_num. vips without
+// it have ring of ring_size at ring_size * vip_num
+struct {
+  __uint(type, BPF_MAP_TYPE_ARRAY);
+  __type(key, __u32);
+  __type(value, struct vip_ring);
+  __uint(max_entries, MAX_VIPS);
+  __uint(map_flags, NO_FLAGS);
+} vip_rings SEC(".maps");
+
 // map which contains opaque real's id to real mapping
 struct {
   __uint(type, BPF_MAP_TYPE_ARRAY);
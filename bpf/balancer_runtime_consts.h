@@ -0,0 +1,23 @@
+#ifndef __BALANCER_RUNTIME_CONSTS_H
+#define __BALANCER_RUNTIME_CONSTS_H
+
+/*
+ * This file contains sizes which are set by the control plane when the
+ * program is loaded (before verification), so they always agree with
+ * max_entries of the maps. compile time values are used as defaults
+ */
+
+#include "bpf.h"
+
+#include "balancer_consts.h"
+
+// number of vips; first stats index of global counters (see *_CNTR(S) offsets)
+volatile const __u32 max_vips = MAX_VIPS;
+
+// number of reals
+volatile const __u32 max_reals = MAX_REALS;
+
+// size of consistent hashing ring of each vip in ch_rings
+volatile const __u32 ring_size = RING_SIZE;
+
+#endif // of __BALANCER_RUNTIME_CONSTS_H
@@ -58,6 +58,13 @@ struct vip_meta {
   __u32 vip_num;
 };
 
+// where ring of the vip is placed in ch_rings, set by the control plane
+// when vips have rings of different sizes
+struct vip_ring {
+  __u32 offset;
+  __u32 size;
+};
+
 // where to send client's packet from LRU_MAP
 struct real_pos_lru {
   __u32 pos;
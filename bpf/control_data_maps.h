@@ -16,6 +16,7 @@
 // and/or interfaces ifindexes
 // indexes:
 // 0 - default's mac
+// 6 - number of active next hops in nexthop_macs
 struct {
   __uint(type, BPF_MAP_TYPE_ARRAY);
   __type(key, __u32);
@@ -24,6 +25,25 @@ struct {
   __uint(map_flags, NO_FLAGS);
 } ctl_array SEC(".maps");
 
+// macs of next hops; first ctl_array[NEXTHOPS_CNT_POS] entries are in use
+// and one of them is picked by flow's hash
+struct {
+  __uint(type, BPF_MAP_TYPE_ARRAY);
+  __type(key, __u32);
+  __type(value, struct ctl_value);
+  __uint(max_entries, MAX_NEXTHOPS);
+  __uint(map_flags, NO_FLAGS);
+} nexthop_macs SEC(".maps");
+
+// packets and bytes sent through each of next hops
+struct {
+  __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+  __type(key, __u32);
+  __type(value, struct lb_stats);
+  __uint(max_entries, MAX_NEXTHOPS);
+  __uint(map_flags, NO_FLAGS);
+} nexthop_stats SEC(".maps");
+
 #ifdef FLOMESHLB_INTROSPECTION
 
 struct {
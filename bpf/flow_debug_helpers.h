@@ -10,9 +10,11 @@
 
 #include "flow_debug_maps.h"
 
+// ports are kept in network order, the same way as in flow_key of the lru,
+// so both could be looked up with the same key
 __attribute__((__always_inline__)) static inline __u32
 get_next_ports(void *transport_hdr, __u8 proto, void *data_end) {
-  __u32 ports = 0;
+  __u16 ports[2] = {0};
   struct udphdr *udph = 0;
   struct tcphdr *tcph = 0;
 
@@ -20,20 +22,22 @@ get_next_ports(void *transport_hdr, __u8 proto, void *data_end) {
   case IPPROTO_UDP:
     udph = transport_hdr;
     if ((void *)udph + sizeof(struct udphdr) <= data_end) {
-      ports = (bpf_ntohs(udph->dest) << 16) | bpf_ntohs(udph->source);
+      ports[0] = udph->source;
+      ports[1] = udph->dest;
     }
     break;
   case IPPROTO_TCP:
     tcph = transport_hdr;
     if ((void *)tcph + sizeof(struct tcphdr) <= data_end) {
-      ports = (bpf_ntohs(tcph->dest) << 16) | bpf_ntohs(tcph->source);
+      ports[0] = tcph->source;
+      ports[1] = tcph->dest;
     }
     break;
   default:
     break;
   }
 
-  return ports;
+  return *(__u32 *)ports;
 }
 
 __attribute__((__always_inline__)) static inline void
@@ -1,8 +1,8 @@
 #ifndef __FLOW_DEBUG_MAPS_H
 #define __FLOW_DEBUG_MAPS_H
 
-#include "include/bpf.h"
-#include "include/bpf_helpers.h"
+#include "bpf.h"
+#include "bpf_helpers.h"
 
 #include "balancer_structs.h"
 #include "flow_debug.h"
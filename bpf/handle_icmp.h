@@ -19,6 +19,7 @@
 #include "balancer_consts.h"
 #include "balancer_helpers.h"
 #include "balancer_maps.h"
+#include "balancer_runtime_consts.h"
 #include "balancer_structs.h"
 
 __attribute__((__always_inline__)) static inline int
@@ -220,7 +221,7 @@ parse_icmpv6(void *data, void *data_end, __u64 off,
   }
 
   if (icmp_hdr->icmp6_type == ICMPV6_PKT_TOOBIG) {
-    __u32 stats_key = MAX_VIPS + ICMP_PTB_V6_STATS;
+    __u32 stats_key = max_vips + ICMP_PTB_V6_STATS;
     struct lb_stats *icmp_ptb_v6_stats =
         bpf_map_lookup_elem(&stats, &stats_key);
     if (!icmp_ptb_v6_stats) {
@@ -264,7 +265,7 @@ parse_icmp(void *data, void *data_end, __u64 off,
   }
 
   if (icmp_hdr->code == ICMP_FRAG_NEEDED) {
-    __u32 stats_key = MAX_VIPS + ICMP_PTB_V4_STATS;
+    __u32 stats_key = max_vips + ICMP_PTB_V4_STATS;
     struct lb_stats *icmp_ptb_v4_stats =
         bpf_map_lookup_elem(&stats, &stats_key);
     if (!icmp_ptb_v4_stats) {
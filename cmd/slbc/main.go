@@ -3,7 +3,10 @@ package main
 import (
 	"flag"
 	"fmt"
+	"os"
+	"strings"
 
+	"github.com/cybwan/l4slb/pkg/bpf/affinitize"
 	"github.com/cybwan/l4slb/pkg/cli"
 )
 
@@ -29,20 +32,35 @@ var (
 	showSumStats   = flag.Bool("sum", false, "Show summary stats")
 	showLruStats   = flag.Bool("lru", false, "Show LRU related stats")
 	showIcmpStats  = flag.Bool("icmp", false, "Show ICMP 'packet too big' related stats")
+	showLruMaps    = flag.Bool("lru_maps", false, "Show occupancy of per cpu connection tables")
+	showMonitor    = flag.Bool("monitor", false, "Show stats of introspection monitor")
 	listServices   = flag.Bool("l", false, "List configured services")
 	vipChangeFlags = flag.String("vf", "",
 		"change vip flags. Possible values: NO_SPORT, NO_LRU, QUIC_VIP, DPORT_HASH, LOCAL_VIP")
 	realChangeFlags = flag.String("rf", "",
 		"change real flags. Possible values: LOCAL_REAL")
 	unsetFlags = flag.Bool("unset", false, "Unset specified flags")
-	newHc      = flag.String("new_hc", "", "Address of new backend to healtcheck")
-	somark     = flag.Uint64("somark", 0, "Socket mark to specified backend")
-	delHc      = flag.Bool("del_hc", false, "Delete backend w/ specified somark")
-	listHc     = flag.Bool("list_hc", false, "List configured healthchecks")
-	listMac    = flag.Bool("list_mac", false,
+	ringSize   = flag.Uint("ring_size", 0,
+		"Size of consistent hashing ring of new virtual service, a prime number. balancer's default if 0")
+	hashFunction = flag.String("hash_function", "",
+		"Consistent hashing function of virtual service added with -A or edited with -E, balancer's default if empty. "+
+			"Possible values: maglev, maglev_v2, rendezvous, ketama, jump")
+	vipInfo = flag.Bool("vip_info", false,
+		"Show flags, hash function, ring size and number of reals of virtual service")
+	newHc   = flag.String("new_hc", "", "Address of new backend to healtcheck")
+	somark  = flag.Uint64("somark", 0, "Socket mark to specified backend")
+	delHc   = flag.Bool("del_hc", false, "Delete backend w/ specified somark")
+	listHc  = flag.Bool("list_hc", false, "List configured healthchecks")
+	listMac = flag.Bool("list_mac", false,
 		"List configured mac address of default router")
 	changeMac = flag.String("change_mac", "",
 		"Change configured mac address of default router")
+	addNexthop = flag.String("add_nexthop", "",
+		"Add mac address of next hop router. traffic is spread between next hops by flow's hash")
+	delNexthop = flag.String("del_nexthop", "",
+		"Delete mac address of next hop router")
+	listNexthops = flag.Bool("list_nexthops", false,
+		"List next hop routers and their counters")
 	clearAll    = flag.Bool("C", false, "Clear all configs")
 	quicMapping = flag.String("quic_mapping", "",
 		"mapping of real to connectionId. must be in <addr>=<id> format")
@@ -53,7 +71,210 @@ var (
 		"Flomesh lb server listen address")
 )
 
+// subcommands which work with their own flags, e.g. "slbc affinitize -i eth0"
+var subcommands = map[string]func(args []string){
+	"affinitize": affinitizeCmd,
+	"flow":       flowCmd,
+	"capture":    captureCmd,
+	"stats":      statsCmd,
+	"ring":       ringCmd,
+}
+
+func affinitizeCmd(args []string) {
+	fs := flag.NewFlagSet("affinitize", flag.ExitOnError)
+	intf := fs.String("i", "", "Interface whose irqs are affinitized")
+	strategy := fs.String("strategy", "seq_nodes",
+		"Strategy of irqs affinitizing. Possible values: seq_nodes, same_node, all_nodes")
+	root := fs.String("root", affinitize.DefaultRoot, "Root of proc and sys trees")
+	_ = fs.Parse(args)
+	if *intf == "" {
+		fmt.Fprintln(os.Stderr, "interface must be specified with -i")
+		fs.Usage()
+		os.Exit(2)
+	}
+	// dry run: only show the mapping, slbd -affinitize applies it
+	cli.ShowAffinitizeMapping(*root, *intf, *strategy)
+}
+
+func flowCmd(args []string) {
+	fs := flag.NewFlagSet("flow", flag.ExitOnError)
+	server := fs.String("server", "127.0.0.1:50051", "Flomesh lb server listen address")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: slbc flow debug [-server <addr>] <src>:<sport> <vip>:<port> <proto>")
+		fs.PrintDefaults()
+	}
+	if len(args) < 1 || args[0] != "debug" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	_ = fs.Parse(args[1:])
+	if fs.NArg() != 3 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	var sc cli.L4SlbClient
+	sc.Init(*server)
+	sc.ShowFlowDebugInfo(fs.Arg(0), fs.Arg(1), fs.Arg(2))
+}
+
+func captureCmd(args []string) {
+	fs := flag.NewFlagSet("capture", flag.ExitOnError)
+	server := fs.String("server", "127.0.0.1:50051", "Flomesh lb server listen address")
+	events := fs.String("events", "",
+		"Comma separated list of events to capture, all if empty. "+
+			"Possible values: tcp_nonsyn_lrumiss, packet_toobig, quic_packet_drop_no_real")
+	vip := fs.String("vip", "",
+		"Capture only packets sent to the vip. must be in format: <addr>:<port>, port 0 matches any")
+	proto := fs.String("proto", "", "Protocol of the vip: tcp, udp or protocol's number. any if empty")
+	output := fs.String("w", cli.CaptureToStdout, "Pcap file to write packets to, - for stdout")
+	count := fs.Int("c", 0, "Exit after capturing this number of packets, 0 - unlimited")
+	_ = fs.Parse(args)
+	var eventList []string
+	for _, event := range strings.Split(*events, ",") {
+		if event = strings.TrimSpace(event); event != "" {
+			eventList = append(eventList, event)
+		}
+	}
+	var sc cli.L4SlbClient
+	sc.Init(*server)
+	sc.Capture(eventList, *vip, *proto, *output, *count)
+}
+
+// stats subcommands, e.g. "slbc stats maps"
+var statsSubcommands = map[string]func(args []string){
+	"controller": controllerStatsCmd,
+	"lru-miss":   lruMissStatsCmd,
+	"maps":       mapStatsCmd,
+}
+
+func statsCmd(args []string) {
+	if len(args) > 0 {
+		if cmd, exists := statsSubcommands[args[0]]; exists {
+			cmd(args[1:])
+			return
+		}
+	}
+	fmt.Fprintln(os.Stderr, "usage: slbc stats controller|lru-miss|maps [-h]")
+	os.Exit(2)
+}
+
+func lruMissStatsCmd(args []string) {
+	fs := flag.NewFlagSet("stats lru-miss", flag.ExitOnError)
+	server := fs.String("server", "127.0.0.1:50051", "Flomesh lb server listen address")
+	vip := fs.String("vip", "",
+		"Start tracking lru misses of the vip per real, counters are reset. "+
+			"must be in format: <addr>:<port> or [<addr>]:<port>")
+	proto := fs.String("proto", "tcp", "Protocol of the vip: tcp, udp or protocol's number")
+	clear := fs.Bool("clear", false, "Stop tracking lru misses")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr,
+			"usage: slbc stats lru-miss [-server <addr>] [-vip <addr>:<port> [-proto <proto>] | -clear]")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if *clear && *vip != "" {
+		fmt.Fprintln(os.Stderr, "-vip and -clear are mutually exclusive")
+		os.Exit(2)
+	}
+	var sc cli.L4SlbClient
+	sc.Init(*server)
+	switch {
+	case *clear:
+		sc.ClearLruMissStatsVip()
+	case *vip != "":
+		sc.SetLruMissStatsVip(*vip, *proto)
+	default:
+		sc.ShowLruMissStatsForVip()
+	}
+}
+
+func controllerStatsCmd(args []string) {
+	fs := flag.NewFlagSet("stats controller", flag.ExitOnError)
+	server := fs.String("server", "127.0.0.1:50051", "Flomesh lb server listen address")
+	_ = fs.Parse(args)
+	var sc cli.L4SlbClient
+	sc.Init(*server)
+	sc.ShowControllerStats()
+}
+
+func mapStatsCmd(args []string) {
+	fs := flag.NewFlagSet("stats maps", flag.ExitOnError)
+	server := fs.String("server", "127.0.0.1:50051", "Flomesh lb server listen address")
+	_ = fs.Parse(args)
+	var sc cli.L4SlbClient
+	sc.Init(*server)
+	sc.ShowBpfMapStats()
+}
+
+// ring subcommands, e.g. "slbc ring simulate"
+var ringSubcommands = map[string]func(args []string){
+	"simulate": ringSimulateCmd,
+	"dump":     ringDumpCmd,
+}
+
+func ringCmd(args []string) {
+	if len(args) > 0 {
+		if cmd, exists := ringSubcommands[args[0]]; exists {
+			cmd(args[1:])
+			return
+		}
+	}
+	fmt.Fprintln(os.Stderr, "usage: slbc ring simulate|dump [-h]")
+	os.Exit(2)
+}
+
+func ringSimulateCmd(args []string) {
+	fs := flag.NewFlagSet("ring simulate", flag.ExitOnError)
+	server := fs.String("server", "127.0.0.1:50051", "Flomesh lb server listen address")
+	vip := fs.String("vip", "", "Vip whose ring is simulated. must be in format: <addr>:<port> or [<addr>]:<port>")
+	proto := fs.String("proto", "tcp", "Protocol of the vip: tcp, udp or protocol's number")
+	del := fs.Bool("d", false, "Simulate deletion of the reals instead of adding them or changing their weights")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr,
+			"usage: slbc ring simulate [-server <addr>] -vip <addr>:<port> [-proto <proto>] [-d] <real>[=<weight>]...")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if *vip == "" || fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	var sc cli.L4SlbClient
+	sc.Init(*server)
+	sc.SimulateRealsChange(*vip, *proto, fs.Args(), *del)
+}
+
+func ringDumpCmd(args []string) {
+	fs := flag.NewFlagSet("ring dump", flag.ExitOnError)
+	server := fs.String("server", "127.0.0.1:50051", "Flomesh lb server listen address")
+	vip := fs.String("vip", "", "Vip whose ring is dumped. must be in format: <addr>:<port> or [<addr>]:<port>")
+	proto := fs.String("proto", "tcp", "Protocol of the vip: tcp, udp or protocol's number")
+	kernel := fs.Bool("kernel", false, "Read the ring back from ch_rings and report positions which differ")
+	format := fs.String("format", cli.RingDumpText,
+		"Output format: text - positions of each real, csv - a row per position")
+	output := fs.String("o", cli.RingDumpToStdout, "File csv is written to, - for stdout")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr,
+			"usage: slbc ring dump [-server <addr>] -vip <addr>:<port> [-proto <proto>] [-kernel] [-format text|csv] [-o <file>]")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if *vip == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	var sc cli.L4SlbClient
+	sc.Init(*server)
+	sc.DumpHashRing(*vip, *proto, *kernel, *format, *output)
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		if cmd, exists := subcommands[os.Args[1]]; exists {
+			cmd(os.Args[2:])
+			return
+		}
+	}
 	flag.Parse()
 	var service string
 	var proto int
@@ -70,15 +291,28 @@ func main() {
 		sc.ChangeMac(*changeMac)
 	} else if *listMac {
 		sc.GetMac()
+	} else if *addNexthop != "" {
+		sc.AddNexthop(*addNexthop)
+	} else if *delNexthop != "" {
+		sc.DelNexthop(*delNexthop)
+	} else if *listNexthops {
+		sc.ListNexthops()
 	} else if *addService {
-		sc.AddOrModifyService(service, *vipChangeFlags, proto, false, true)
+		sc.AddOrModifyService(service, *vipChangeFlags, proto, uint32(*ringSize), *hashFunction, false, true)
 	} else if *listServices {
 		// TODO(tehnerd): print only specified tcp/udp service
 		sc.List("", 0)
 	} else if *delService {
 		sc.DelService(service, proto)
+	} else if *vipInfo {
+		sc.ShowVipInfo(service, proto)
 	} else if *editService {
-		sc.AddOrModifyService(service, *vipChangeFlags, proto, true, !*unsetFlags)
+		if *hashFunction != "" {
+			sc.ChangeHashFunction(service, proto, *hashFunction)
+		}
+		if *vipChangeFlags != "" || *hashFunction == "" {
+			sc.AddOrModifyService(service, *vipChangeFlags, proto, 0, "", true, !*unsetFlags)
+		}
 	} else if *addServer || *editServer {
 		sc.UpdateServerForVip(service, proto, *realServer, *realWeight, *realChangeFlags, false)
 	} else if *delServer {
@@ -104,6 +338,10 @@ func main() {
 			sc.ShowLruStats()
 		} else if *showIcmpStats {
 			sc.ShowIcmpStats()
+		} else if *showLruMaps {
+			sc.ShowLruMapsStats()
+		} else if *showMonitor {
+			sc.ShowMonitorStats()
 		} else {
 			sc.ShowPerVipStats()
 		}
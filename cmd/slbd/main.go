@@ -3,32 +3,87 @@ package main
 import (
 	"context"
 	"flag"
+	"github.com/cybwan/l4slb/pkg/bpf/affinitize"
 	"github.com/cybwan/l4slb/pkg/logger"
 	"github.com/cybwan/l4slb/pkg/signals"
+	"github.com/cybwan/l4slb/pkg/slb"
 	"github.com/cybwan/l4slb/pkg/slb/httpserver"
 	"github.com/cybwan/l4slb/pkg/slb/server"
 	"github.com/cybwan/l4slb/pkg/version"
 )
 
 var (
-	eth  = flag.String("default_route_device", "ens33", "The server default route device")
-	port = flag.Int("port", 50051, "The server port")
-	log  = logger.New("flomesh-lb-server")
+	eth        = flag.String("default_route_device", "ens33", "The server default route device")
+	port       = flag.Int("port", 50051, "The server port")
+	configFile = flag.String("config", "",
+		"Path to YAML config file of the balancer. flags which are set explicitly override it")
+	affinitizeIrqs = flag.Bool("affinitize", false,
+		"Bind irqs of default_route_device to cpus and use these cpus as forwarding cores")
+	affinitizeStrategy = flag.String("affinitize_strategy", "seq_nodes",
+		"Strategy of irqs affinitizing. Possible values: seq_nodes, same_node, all_nodes")
+	trackGwMac = flag.Bool("track_gw_mac", true,
+		"Follow the default gateway of default_route_device and update its mac address automatically")
+	log = logger.New("flomesh-lb-server")
 )
 
 func main() {
+	opts := slb.DefaultFlomeshLbOptions()
+	opts.BindFlags(flag.CommandLine)
 	flag.Parse()
+
+	if *configFile != "" {
+		fileOpts, err := slb.LoadFlomeshLbOptions(*configFile)
+		if err != nil {
+			log.Fatal().Err(err).Msgf("Failed to load config")
+		}
+		if err = fileOpts.ApplyFlags(flag.CommandLine); err != nil {
+			log.Fatal().Err(err).Msgf("Failed to load config")
+		}
+		opts = fileOpts
+	}
+	if opts.MainInterface == "" || isFlagSet("default_route_device") {
+		opts.MainInterface = *eth
+	}
+	if *affinitizeIrqs {
+		strategy, err := affinitize.ParseStrategy(*affinitizeStrategy)
+		if err != nil {
+			log.Fatal().Err(err).Msgf("Failed to affinitize irqs")
+		}
+		mapping, err := affinitize.AffinitizeIntf(affinitize.DefaultRoot, opts.MainInterface, strategy)
+		if err != nil {
+			log.Fatal().Err(err).Msgf("Failed to affinitize irqs")
+		}
+		if len(opts.ForwardingCores) > 0 {
+			log.Warn().Msgf("forwarding cores %v are replaced with affinitized ones", opts.ForwardingCores)
+		}
+		opts.ForwardingCores = mapping.ForwardingCores
+		opts.NumaNodes = mapping.NumaNodes
+	}
+	config, err := opts.Build()
+	if err != nil {
+		log.Fatal().Err(err).Msgf("Invalid config")
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	stop := signals.RegisterExitHandlers(cancel)
 
-	ctrlServer := server.NewL4SlbControlServer()
-	release, err := ctrlServer.Start(ctx, cancel, *eth, *port)
+	ctrlServer := server.NewL4SlbControlServer(config)
+	release, err := ctrlServer.Start(ctx, cancel, *port)
 	if err != nil {
 		log.Fatal().Err(err).Msgf("Failed to start L4Slb Control server")
 	}
 
 	httpServer := httpserver.NewHTTPServer(80)
 	httpServer.AddHandler("/version", version.GetVersionHandler())
+	httpServer.AddHandler("/metrics", ctrlServer.GetMetricsHandler())
+
+	if *trackGwMac {
+		tracker, err := ctrlServer.TrackDefaultGwMac(ctx, config.MainInterface())
+		if err != nil {
+			log.Fatal().Err(err).Msgf("Failed to track default gateway's mac")
+		}
+		httpServer.AddHandler("/gateway/events", tracker.GetEventsHandler())
+	}
 	// Start HTTP server
 	if err := httpServer.Start(); err != nil {
 		log.Fatal().Err(err).Msgf("Failed to start L4Slb HTTP server")
@@ -39,3 +94,11 @@ func main() {
 	cancel()
 	log.Info().Msgf("Stopping L4Slb Controller %s; %s; %s", version.Version, version.GitCommit, version.BuildDate)
 }
+
+func isFlagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		set = set || f.Name == name
+	})
+	return set
+}
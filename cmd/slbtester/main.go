@@ -0,0 +1,192 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/cybwan/l4slb/pkg/logger"
+	"github.com/cybwan/l4slb/pkg/slb"
+	"github.com/cybwan/l4slb/pkg/tester"
+)
+
+// names of fixture files written by -generate, -pcap and -expected* flags take them
+const (
+	kInputPcap      = "input.pcap"
+	kExpectedPcap   = "expected.pcap"
+	kExpectedReport = "expected.txt"
+)
+
+var (
+	verbose   = flag.Bool("verbose", false, "Print every fixture, not only failed ones")
+	setupFile = flag.String("setup", "",
+		"Path to YAML file with gateway mac, vips and reals to configure the balancer with. built-in ones if empty")
+	generate = flag.String("generate", "",
+		"Write built-in fixtures as "+kInputPcap+", "+kExpectedPcap+" and "+kExpectedReport+" into the directory and exit")
+	pcapFile = flag.String("pcap", "",
+		"Replay packets of pcap file through the balancer instead of built-in fixtures")
+	outputPcap = flag.String("output", "",
+		"Path of pcap file the balancer's output packets of replayed ones are written to")
+	outputReport = flag.String("report", "",
+		"Path of per packet verdict report of replayed packets, stdout if empty")
+	expectedPcap = flag.String("expected_pcap", "",
+		"Pcap file with expected output packets of replayed ones, they are checked if it is set")
+	expectedReport = flag.String("expected_report", "",
+		"Report with expected verdicts of replayed packets, required by -expected_pcap")
+	log = logger.New("flomesh-lb-tester")
+)
+
+func main() {
+	opts := slb.DefaultFlomeshLbOptions()
+	// the balancer is not attached to any interface and flows of fixtures
+	// fit into small connection tables
+	opts.EnableHc = false
+	opts.LruSize = 1000
+	opts.BindFlags(flag.CommandLine)
+	flag.Parse()
+
+	if *generate != "" {
+		if err := generateFixtures(*generate); err != nil {
+			log.Fatal().Err(err).Msgf("Failed to generate fixtures")
+		}
+		return
+	}
+
+	setup := tester.DefaultSetup()
+	fixtures := tester.DefaultFixtures()
+	if *setupFile != "" {
+		var err error
+		if setup, err = tester.LoadSetup(*setupFile); err != nil {
+			log.Fatal().Err(err).Msgf("Failed to load setup")
+		}
+	}
+	if *pcapFile != "" {
+		var err error
+		if fixtures, err = loadFixtures(); err != nil {
+			log.Fatal().Err(err).Msgf("Failed to load packets")
+		}
+	}
+
+	t, err := tester.NewTester(opts)
+	if err != nil {
+		log.Fatal().Err(err).Msgf("Failed to load balancer")
+	}
+	defer t.Close()
+
+	if err = t.Program(setup); err != nil {
+		log.Fatal().Err(err).Msgf("Failed to configure balancer")
+	}
+
+	results := t.Run(fixtures)
+	if *pcapFile != "" {
+		if err = writeResults(results); err != nil {
+			log.Fatal().Err(err).Msgf("Failed to write results")
+		}
+		if *expectedPcap == "" {
+			return
+		}
+	}
+
+	failed := 0
+	for i := range results {
+		result := &results[i]
+		if result.Passed() {
+			if *verbose {
+				fmt.Printf("PASS %d %s\n", i+1, result.Fixture.Description)
+			}
+			continue
+		}
+		failed++
+		fmt.Printf("FAIL %d %s\n%s", i+1, result.Fixture.Description, result.Diff())
+	}
+	fmt.Printf("%d fixtures, %d passed, %d failed\n", len(results), len(results)-failed, failed)
+	if failed > 0 {
+		t.Close()
+		os.Exit(1)
+	}
+}
+
+func generateFixtures(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	files := make([]*os.File, 0, 3)
+	defer func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}()
+	for _, name := range []string{kInputPcap, kExpectedPcap, kExpectedReport} {
+		f, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		files = append(files, f)
+	}
+	if err := tester.WriteFixtures(tester.DefaultFixtures(), files[0], files[1], files[2]); err != nil {
+		return err
+	}
+	for _, f := range files {
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	files = nil
+	fmt.Printf("fixtures are written into %s\n", dir)
+	return nil
+}
+
+func loadFixtures() ([]tester.Fixture, error) {
+	input, err := os.Open(*pcapFile)
+	if err != nil {
+		return nil, err
+	}
+	defer input.Close()
+	fixtures, err := tester.ReadFixtures(input)
+	if err != nil {
+		return nil, err
+	}
+	if *expectedPcap == "" {
+		return fixtures, nil
+	}
+	if *expectedReport == "" {
+		return nil, fmt.Errorf("-expected_report is required by -expected_pcap")
+	}
+	expected, err := os.Open(*expectedPcap)
+	if err != nil {
+		return nil, err
+	}
+	defer expected.Close()
+	report, err := os.Open(*expectedReport)
+	if err != nil {
+		return nil, err
+	}
+	defer report.Close()
+	if err = tester.SetExpected(fixtures, expected, report); err != nil {
+		return nil, err
+	}
+	return fixtures, nil
+}
+
+func writeResults(results []tester.Result) error {
+	output, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if *outputPcap != "" {
+		output, err = os.Create(*outputPcap)
+	}
+	if err != nil {
+		return err
+	}
+	defer output.Close()
+	report := os.Stdout
+	if *outputReport != "" {
+		if report, err = os.Create(*outputReport); err != nil {
+			return err
+		}
+		defer report.Close()
+	}
+	if err = tester.WriteResults(results, output, report); err != nil {
+		return err
+	}
+	return output.Close()
+}
@@ -0,0 +1,95 @@
+package adapter
+
+import (
+	"fmt"
+	"runtime"
+	"unsafe"
+
+	"github.com/cilium/ebpf"
+	"golang.org/x/sys/unix"
+)
+
+// Backend is a map a Handle refers to. it is satisfied by maps loaded into the
+// kernel, as well as by FakeMap, which keeps entries in memory, so the controller
+// could be run and checked without root privileges
+type Backend interface {
+	Type() ebpf.MapType
+	KeySize() uint32
+	ValueSize() uint32
+	MaxEntries() uint32
+
+	Lookup(key, valueOut interface{}) error
+	LookupWithFlags(key, valueOut interface{}, flags ebpf.MapLookupFlags) error
+	Update(key, value interface{}, flags ebpf.MapUpdateFlags) error
+	Delete(key interface{}) error
+	NextKey(key, nextKeyOut interface{}) error
+
+	BatchLookup(prevKey, nextKeyOut, keysOut, valuesOut interface{}, opts *ebpf.BatchOptions) (int, error)
+	BatchUpdate(keys, values interface{}, opts *ebpf.BatchOptions) (int, error)
+	BatchDelete(keys interface{}, opts *ebpf.BatchOptions) (int, error)
+
+	Iterate() MapIterator
+}
+
+// MapIterator walks entries of a Backend, as ebpf.MapIterator does
+type MapIterator interface {
+	Next(keyOut, valueOut interface{}) bool
+	Err() error
+}
+
+// kernelMap is a Backend of a map loaded into the kernel
+type kernelMap struct {
+	*ebpf.Map
+}
+
+func (m kernelMap) Iterate() MapIterator {
+	return m.Map.Iterate()
+}
+
+// kBpfMapUpdateBatch is BPF_MAP_UPDATE_BATCH command of bpf syscall
+const kBpfMapUpdateBatch = 26
+
+// mapBatchAttr is batch part of union bpf_attr
+type mapBatchAttr struct {
+	inBatch   uint64
+	outBatch  uint64
+	keys      uint64
+	values    uint64
+	count     uint32
+	mapFd     uint32
+	elemFlags uint64
+	flags     uint64
+}
+
+// resetPerCPUBatch zeroes entries [first, first + count) of per cpu array on all cpus by
+// one BPF_MAP_UPDATE_BATCH, as ebpf.Map does not support batch operations of per cpu maps
+func (m kernelMap) resetPerCPUBatch(first, count uint32, nrCpus int) error {
+	if m.Type() != ebpf.PerCPUArray {
+		return fmt.Errorf("batch reset of %s map: %w", m.Type(), ebpf.ErrNotSupported)
+	}
+	if count == 0 {
+		return nil
+	}
+	keys := make([]uint32, count)
+	for i := range keys {
+		keys[i] = first + uint32(i)
+	}
+	// kernel expects value of every cpu rounded up to 8 bytes
+	values := make([]byte, int(count)*nrCpus*int((m.ValueSize()+7)&^7))
+	attr := mapBatchAttr{
+		keys:   uint64(uintptr(unsafe.Pointer(&keys[0]))),
+		values: uint64(uintptr(unsafe.Pointer(&values[0]))),
+		count:  count,
+		mapFd:  uint32(m.FD()),
+	}
+	_, _, errno := unix.Syscall(unix.SYS_BPF, kBpfMapUpdateBatch, uintptr(unsafe.Pointer(&attr)), unsafe.Sizeof(attr))
+	runtime.KeepAlive(keys)
+	runtime.KeepAlive(values)
+	if errno != 0 {
+		return fmt.Errorf("batch reset of %d entries: %w", count, errno)
+	}
+	if attr.count != count {
+		return fmt.Errorf("batch reset only reset: %d elements out of: %d", attr.count, count)
+	}
+	return nil
+}
@@ -0,0 +1,621 @@
+package adapter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"sync"
+	"unsafe"
+
+	"github.com/cilium/ebpf"
+)
+
+// nativeEndian is byte order keys and values are passed to the kernel in
+var nativeEndian binary.ByteOrder = func() binary.ByteOrder {
+	x := uint16(1)
+	if *(*byte)(unsafe.Pointer(&x)) == 1 {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}()
+
+// FakeOpType is type of an operation recorded by FakeMap
+type FakeOpType int
+
+const (
+	FakeUpdate FakeOpType = iota
+	FakeDelete
+)
+
+func (t FakeOpType) String() string {
+	switch t {
+	case FakeUpdate:
+		return "update"
+	case FakeDelete:
+		return "delete"
+	default:
+		return fmt.Sprintf("FakeOpType(%d)", int(t))
+	}
+}
+
+// FakeOp is a successful update or delete of FakeMap. key and value are as they would have
+// been passed to the kernel; value of per cpu map holds values of all cpus one after another
+type FakeOp struct {
+	Op    FakeOpType
+	Key   []byte
+	Value []byte
+}
+
+// FakeMap is a Backend which keeps entries in memory. it follows semantics of the kernel's
+// maps closely enough for the controller: arrays have all their entries, hash maps are
+// bounded by max entries and lru hash maps evict the oldest entry. every update and delete
+// is recorded. lpm tries are matched by exact keys, and maps of maps, perf event and
+// program arrays are not supported
+type FakeMap struct {
+	name       string
+	mapType    ebpf.MapType
+	keySize    uint32
+	valueSize  uint32
+	maxEntries uint32
+	nrCpus     int
+
+	mu sync.Mutex
+	// keys of hash maps in order of insertion
+	keys    []string
+	entries map[string][][]byte
+	ops     []FakeOp
+}
+
+// NewFakeMap creates an empty map as described by spec. maps of unsupported
+// types are created as well, all their operations fail
+func NewFakeMap(spec *ebpf.MapSpec) (*FakeMap, error) {
+	fake := &FakeMap{
+		name:       spec.Name,
+		mapType:    spec.Type,
+		keySize:    spec.KeySize,
+		valueSize:  spec.ValueSize,
+		maxEntries: spec.MaxEntries,
+		nrCpus:     1,
+		entries:    make(map[string][][]byte),
+	}
+	if fake.check() != nil {
+		return fake, nil
+	}
+	if spec.KeySize == 0 || spec.ValueSize == 0 || spec.MaxEntries == 0 {
+		return nil, fmt.Errorf("map %s: key size %d, value size %d and max entries %d must be set",
+			spec.Name, spec.KeySize, spec.ValueSize, spec.MaxEntries)
+	}
+	if isPerCPU(spec.Type) {
+		var err error
+		if fake.nrCpus, err = GetPossibleCpus(); err != nil {
+			return nil, err
+		}
+	}
+	return fake, nil
+}
+
+// RegisterFakeMaps registers a FakeMap for each of specs which is known by names,
+// i.e. names of maps in the object file mapped to names of the registry
+func (r *Registry) RegisterFakeMaps(specs map[string]*ebpf.MapSpec, names map[string]BpfMapName) error {
+	for specName, spec := range specs {
+		name, exists := names[specName]
+		if !exists {
+			continue
+		}
+		fake, err := NewFakeMap(spec)
+		if err != nil {
+			return err
+		}
+		if err = r.RegisterBackend(name, fake); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isPerCPU(mapType ebpf.MapType) bool {
+	return mapType == ebpf.PerCPUHash || mapType == ebpf.PerCPUArray || mapType == ebpf.LRUCPUHash
+}
+
+func (m *FakeMap) isArray() bool {
+	return m.mapType == ebpf.Array || m.mapType == ebpf.PerCPUArray
+}
+
+func (m *FakeMap) isLru() bool {
+	return m.mapType == ebpf.LRUHash || m.mapType == ebpf.LRUCPUHash
+}
+
+func (m *FakeMap) check() error {
+	switch m.mapType {
+	case ebpf.Array, ebpf.PerCPUArray, ebpf.Hash, ebpf.PerCPUHash, ebpf.LRUHash, ebpf.LRUCPUHash, ebpf.LPMTrie:
+		return nil
+	}
+	return fmt.Errorf("fake map %s of type %s: %w", m.name, m.mapType, ebpf.ErrNotSupported)
+}
+
+func (m *FakeMap) Type() ebpf.MapType {
+	return m.mapType
+}
+
+func (m *FakeMap) KeySize() uint32 {
+	return m.keySize
+}
+
+func (m *FakeMap) ValueSize() uint32 {
+	return m.valueSize
+}
+
+func (m *FakeMap) MaxEntries() uint32 {
+	return m.maxEntries
+}
+
+// Ops returns updates and deletes done since the map has been created or ResetOps has been called
+func (m *FakeMap) Ops() []FakeOp {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]FakeOp(nil), m.ops...)
+}
+
+// ResetOps forgets recorded updates and deletes, entries are kept
+func (m *FakeMap) ResetOps() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ops = nil
+}
+
+// Len returns number of entries, arrays always have max entries
+func (m *FakeMap) Len() int {
+	if m.isArray() {
+		return int(m.maxEntries)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.keys)
+}
+
+func (m *FakeMap) Lookup(key, valueOut interface{}) error {
+	return m.LookupWithFlags(key, valueOut, 0)
+}
+
+func (m *FakeMap) LookupWithFlags(key, valueOut interface{}, flags ebpf.MapLookupFlags) error {
+	if err := m.check(); err != nil {
+		return err
+	}
+	rawKey, err := marshalFixed(key, m.keySize)
+	if err != nil {
+		return fmt.Errorf("fake map %s: %w", m.name, err)
+	}
+	m.mu.Lock()
+	values, err := m.lookup(rawKey)
+	m.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return m.unmarshalValue(valueOut, values)
+}
+
+func (m *FakeMap) lookup(rawKey []byte) ([][]byte, error) {
+	if m.isArray() && nativeEndian.Uint32(rawKey) >= m.maxEntries {
+		return nil, fmt.Errorf("fake map %s: index out of range: %w", m.name, ebpf.ErrKeyNotExist)
+	}
+	values, exists := m.entries[string(rawKey)]
+	if exists {
+		return values, nil
+	}
+	if m.isArray() {
+		values = make([][]byte, m.nrCpus)
+		for cpu := range values {
+			values[cpu] = make([]byte, m.valueSize)
+		}
+		return values, nil
+	}
+	return nil, fmt.Errorf("fake map %s: lookup: %w", m.name, ebpf.ErrKeyNotExist)
+}
+
+func (m *FakeMap) Update(key, value interface{}, flags ebpf.MapUpdateFlags) error {
+	if err := m.check(); err != nil {
+		return err
+	}
+	rawKey, err := marshalFixed(key, m.keySize)
+	if err != nil {
+		return fmt.Errorf("fake map %s: %w", m.name, err)
+	}
+	values, err := m.marshalValue(value)
+	if err != nil {
+		return fmt.Errorf("fake map %s: %w", m.name, err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.update(rawKey, values, flags)
+}
+
+func (m *FakeMap) update(rawKey []byte, values [][]byte, flags ebpf.MapUpdateFlags) error {
+	key := string(rawKey)
+	_, exists := m.entries[key]
+	if m.isArray() {
+		if nativeEndian.Uint32(rawKey) >= m.maxEntries {
+			return fmt.Errorf("fake map %s: index out of range", m.name)
+		}
+		exists = true
+	}
+	if exists && flags == ebpf.UpdateNoExist {
+		return fmt.Errorf("fake map %s: update: %w", m.name, ebpf.ErrKeyExist)
+	}
+	if !exists && flags == ebpf.UpdateExist {
+		return fmt.Errorf("fake map %s: update: %w", m.name, ebpf.ErrKeyNotExist)
+	}
+	if _, stored := m.entries[key]; !stored && !m.isArray() {
+		if len(m.keys) >= int(m.maxEntries) {
+			if !m.isLru() {
+				return fmt.Errorf("fake map %s is full", m.name)
+			}
+			m.remove(m.keys[0])
+		}
+		m.keys = append(m.keys, key)
+	}
+	m.entries[key] = values
+	m.ops = append(m.ops, FakeOp{Op: FakeUpdate, Key: rawKey, Value: bytes.Join(values, nil)})
+	return nil
+}
+
+func (m *FakeMap) Delete(key interface{}) error {
+	if err := m.check(); err != nil {
+		return err
+	}
+	rawKey, err := marshalFixed(key, m.keySize)
+	if err != nil {
+		return fmt.Errorf("fake map %s: %w", m.name, err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.delete(rawKey)
+}
+
+func (m *FakeMap) delete(rawKey []byte) error {
+	if m.isArray() {
+		return fmt.Errorf("fake map %s: elements of arrays can't be deleted", m.name)
+	}
+	if _, exists := m.entries[string(rawKey)]; !exists {
+		return fmt.Errorf("fake map %s: delete: %w", m.name, ebpf.ErrKeyNotExist)
+	}
+	m.remove(string(rawKey))
+	m.ops = append(m.ops, FakeOp{Op: FakeDelete, Key: rawKey})
+	return nil
+}
+
+func (m *FakeMap) remove(key string) {
+	delete(m.entries, key)
+	for i := range m.keys {
+		if m.keys[i] == key {
+			m.keys = append(m.keys[:i], m.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+func (m *FakeMap) NextKey(key, nextKeyOut interface{}) error {
+	if err := m.check(); err != nil {
+		return err
+	}
+	var rawKey []byte
+	if key != nil {
+		var err error
+		if rawKey, err = marshalFixed(key, m.keySize); err != nil {
+			return fmt.Errorf("fake map %s: %w", m.name, err)
+		}
+	}
+	m.mu.Lock()
+	next, exists := m.nextKey(rawKey)
+	m.mu.Unlock()
+	if !exists {
+		return fmt.Errorf("fake map %s: next key: %w", m.name, ebpf.ErrKeyNotExist)
+	}
+	return unmarshalFixed(nextKeyOut, next)
+}
+
+// nextKey returns key following rawKey. as in the kernel, walk starts
+// from the first key if rawKey is nil or doesn't exist
+func (m *FakeMap) nextKey(rawKey []byte) ([]byte, bool) {
+	if m.isArray() {
+		next := uint32(0)
+		if rawKey != nil {
+			if index := nativeEndian.Uint32(rawKey); index < m.maxEntries {
+				next = index + 1
+			}
+		}
+		if next >= m.maxEntries {
+			return nil, false
+		}
+		nextKey := make([]byte, m.keySize)
+		nativeEndian.PutUint32(nextKey, next)
+		return nextKey, true
+	}
+	next := 0
+	if rawKey != nil {
+		for i := range m.keys {
+			if m.keys[i] == string(rawKey) {
+				next = i + 1
+				break
+			}
+		}
+	}
+	if next >= len(m.keys) {
+		return nil, false
+	}
+	return []byte(m.keys[next]), true
+}
+
+// BatchLookup reads entries which follow prevKey, or starting at the first key if it is nil.
+// nextKeyOut is set to the last key read, which is prevKey of the next batch, as ebpf.Map
+// does for array maps. per cpu maps are not supported, as by ebpf.Map
+func (m *FakeMap) BatchLookup(prevKey, nextKeyOut, keysOut, valuesOut interface{},
+	opts *ebpf.BatchOptions) (int, error) {
+	if err := m.check(); err != nil {
+		return 0, err
+	}
+	if isPerCPU(m.mapType) {
+		return 0, fmt.Errorf("fake map %s: batch lookup of per cpu map: %w", m.name, ebpf.ErrNotSupported)
+	}
+	keys := reflect.ValueOf(keysOut)
+	values := reflect.ValueOf(valuesOut)
+	if keys.Kind() != reflect.Slice || values.Kind() != reflect.Slice {
+		return 0, fmt.Errorf("fake map %s: keys and values must be slices", m.name)
+	}
+	size := keys.Len()
+	if values.Len() < size {
+		size = values.Len()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var rawKey []byte
+	if prevKey != nil {
+		var err error
+		if rawKey, err = marshalFixed(prevKey, m.keySize); err != nil {
+			return 0, fmt.Errorf("fake map %s: %w", m.name, err)
+		}
+	}
+	// the first key follows prevKey, as get_next_key of the kernel gives it
+	rawKey, exists := m.nextKey(rawKey)
+	var lastKey []byte
+	count := 0
+	for ; exists && count < size; count++ {
+		entry, err := m.lookup(rawKey)
+		if err != nil {
+			return count, err
+		}
+		if err = unmarshalFixed(keys.Index(count).Addr().Interface(), rawKey); err != nil {
+			return count, err
+		}
+		if err = unmarshalFixed(values.Index(count).Addr().Interface(), entry[0]); err != nil {
+			return count, err
+		}
+		lastKey = rawKey
+		rawKey, exists = m.nextKey(rawKey)
+	}
+	if count > 0 {
+		if err := unmarshalFixed(nextKeyOut, lastKey); err != nil {
+			return count, err
+		}
+	}
+	if !exists {
+		return count, fmt.Errorf("fake map %s: batch lookup: %w", m.name, ebpf.ErrKeyNotExist)
+	}
+	return count, nil
+}
+
+// BatchUpdate updates entries one by one. per cpu maps are not supported, as by ebpf.Map
+func (m *FakeMap) BatchUpdate(keys, values interface{}, opts *ebpf.BatchOptions) (int, error) {
+	if err := m.check(); err != nil {
+		return 0, err
+	}
+	if isPerCPU(m.mapType) {
+		return 0, fmt.Errorf("fake map %s: batch update of per cpu map: %w", m.name, ebpf.ErrNotSupported)
+	}
+	keysValue := reflect.ValueOf(keys)
+	valuesValue := reflect.ValueOf(values)
+	if keysValue.Kind() != reflect.Slice || valuesValue.Kind() != reflect.Slice {
+		return 0, fmt.Errorf("fake map %s: keys and values must be slices", m.name)
+	}
+	if keysValue.Len() != valuesValue.Len() {
+		return 0, fmt.Errorf("fake map %s: %d keys and %d values", m.name, keysValue.Len(), valuesValue.Len())
+	}
+	flags := ebpf.UpdateAny
+	if opts != nil {
+		flags = ebpf.MapUpdateFlags(opts.ElemFlags)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := 0; i < keysValue.Len(); i++ {
+		rawKey, err := marshalFixed(keysValue.Index(i).Interface(), m.keySize)
+		if err != nil {
+			return i, fmt.Errorf("fake map %s: %w", m.name, err)
+		}
+		value, err := marshalFixed(valuesValue.Index(i).Interface(), m.valueSize)
+		if err != nil {
+			return i, fmt.Errorf("fake map %s: %w", m.name, err)
+		}
+		if err = m.update(rawKey, [][]byte{value}, flags); err != nil {
+			return i, err
+		}
+	}
+	return keysValue.Len(), nil
+}
+
+// BatchDelete deletes entries one by one
+func (m *FakeMap) BatchDelete(keys interface{}, opts *ebpf.BatchOptions) (int, error) {
+	if err := m.check(); err != nil {
+		return 0, err
+	}
+	keysValue := reflect.ValueOf(keys)
+	if keysValue.Kind() != reflect.Slice {
+		return 0, fmt.Errorf("fake map %s: keys must be a slice", m.name)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := 0; i < keysValue.Len(); i++ {
+		rawKey, err := marshalFixed(keysValue.Index(i).Interface(), m.keySize)
+		if err != nil {
+			return i, fmt.Errorf("fake map %s: %w", m.name, err)
+		}
+		if err = m.delete(rawKey); err != nil {
+			return i, err
+		}
+	}
+	return keysValue.Len(), nil
+}
+
+func (m *FakeMap) Iterate() MapIterator {
+	return &fakeMapIterator{fake: m}
+}
+
+// marshalValue returns value of every cpu. value of per cpu map is a slice, or
+// pointer to a slice, of at most number of possible cpus values
+func (m *FakeMap) marshalValue(value interface{}) ([][]byte, error) {
+	if !isPerCPU(m.mapType) {
+		raw, err := marshalFixed(value, m.valueSize)
+		if err != nil {
+			return nil, err
+		}
+		return [][]byte{raw}, nil
+	}
+	slice := reflect.Indirect(reflect.ValueOf(value))
+	if slice.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("per cpu value requires a slice, got %T", value)
+	}
+	if slice.Len() > m.nrCpus {
+		return nil, fmt.Errorf("per cpu value exceeds number of cpus: %d", slice.Len())
+	}
+	values := make([][]byte, m.nrCpus)
+	for cpu := range values {
+		if cpu >= slice.Len() {
+			values[cpu] = make([]byte, m.valueSize)
+			continue
+		}
+		raw, err := marshalFixed(slice.Index(cpu).Interface(), m.valueSize)
+		if err != nil {
+			return nil, err
+		}
+		values[cpu] = raw
+	}
+	return values, nil
+}
+
+// unmarshalValue decodes value; value of per cpu map is decoded into a pointer to a slice
+func (m *FakeMap) unmarshalValue(valueOut interface{}, values [][]byte) error {
+	if !isPerCPU(m.mapType) {
+		return unmarshalFixed(valueOut, values[0])
+	}
+	slicePtr := reflect.ValueOf(valueOut)
+	if slicePtr.Kind() != reflect.Ptr || slicePtr.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("per cpu value requires pointer to slice, got %T", valueOut)
+	}
+	slice := reflect.MakeSlice(slicePtr.Elem().Type(), len(values), len(values))
+	for cpu, raw := range values {
+		if err := unmarshalFixed(slice.Index(cpu).Addr().Interface(), raw); err != nil {
+			return err
+		}
+	}
+	slicePtr.Elem().Set(slice)
+	return nil
+}
+
+// marshalFixed encodes fixed size data, or takes raw bytes, as the kernel gets it
+func marshalFixed(data interface{}, size uint32) ([]byte, error) {
+	var raw []byte
+	if bytesData, ok := data.([]byte); ok {
+		raw = append([]byte(nil), bytesData...)
+	} else {
+		var buf bytes.Buffer
+		if err := binary.Write(&buf, nativeEndian, data); err != nil {
+			return nil, fmt.Errorf("can't marshal %T: %w", data, err)
+		}
+		raw = buf.Bytes()
+	}
+	if len(raw) != int(size) {
+		return nil, fmt.Errorf("%T has size %d, expected %d", data, len(raw), size)
+	}
+	return raw, nil
+}
+
+func unmarshalFixed(dataOut interface{}, raw []byte) error {
+	switch out := dataOut.(type) {
+	case *[]byte:
+		*out = append([]byte(nil), raw...)
+		return nil
+	case []byte:
+		copy(out, raw)
+		return nil
+	}
+	// types of pkg/bpf keep their fields unexported, which binary.Read can't set.
+	// plain data is copied as is, as the kernel does
+	if out := reflect.ValueOf(dataOut); out.Kind() == reflect.Ptr && !out.IsNil() &&
+		isPlainData(out.Elem().Type()) && int(out.Elem().Type().Size()) == len(raw) {
+		copy(unsafe.Slice((*byte)(out.UnsafePointer()), len(raw)), raw)
+		return nil
+	}
+	if err := binary.Read(bytes.NewReader(raw), nativeEndian, dataOut); err != nil {
+		return fmt.Errorf("can't unmarshal %T: %w", dataOut, err)
+	}
+	return nil
+}
+
+// isPlainData returns true if t consists of numbers only, so its memory could be copied
+func isPlainData(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Array:
+		return isPlainData(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if !isPlainData(t.Field(i).Type) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+type fakeMapIterator struct {
+	fake    *FakeMap
+	prevKey []byte
+	done    bool
+	err     error
+}
+
+func (it *fakeMapIterator) Next(keyOut, valueOut interface{}) bool {
+	if it.done || it.err != nil {
+		return false
+	}
+	if it.err = it.fake.check(); it.err != nil {
+		return false
+	}
+	it.fake.mu.Lock()
+	var values [][]byte
+	rawKey, exists := it.fake.nextKey(it.prevKey)
+	if exists {
+		values, it.err = it.fake.lookup(rawKey)
+	}
+	it.fake.mu.Unlock()
+	if !exists {
+		it.done = true
+		return false
+	}
+	if it.err != nil {
+		return false
+	}
+	it.prevKey = rawKey
+	if it.err = unmarshalFixed(keyOut, rawKey); it.err != nil {
+		return false
+	}
+	it.err = it.fake.unmarshalValue(valueOut, values)
+	return it.err == nil
+}
+
+func (it *fakeMapIterator) Err() error {
+	return it.err
+}
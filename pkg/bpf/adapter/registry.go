@@ -0,0 +1,188 @@
+package adapter
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/cilium/ebpf"
+)
+
+// program names of registries
+const (
+	BalancerProg       = "balancer"
+	HealthcheckingProg = "healthchecking"
+	PktcntrProg        = "pktcntr"
+	RootProg           = "root"
+)
+
+// Registry keeps maps of one loaded bpf program by their names. every program
+// has its own registry, so programs may use the same names for their maps, and
+// independent instances of the balancer may live in one process
+type Registry struct {
+	program string
+
+	mu   sync.RWMutex
+	maps map[BpfMapName]Backend
+}
+
+// NewRegistry creates an empty registry of program's maps
+func NewRegistry(program string) *Registry {
+	return &Registry{
+		program: program,
+		maps:    make(map[BpfMapName]Backend),
+	}
+}
+
+// Program returns name of the program whose maps are kept
+func (r *Registry) Program() string {
+	return r.program
+}
+
+// Register adds loaded map. name could be registered only once
+func (r *Registry) Register(name BpfMapName, bpfMap *ebpf.Map) error {
+	if bpfMap == nil {
+		return fmt.Errorf("can't register nil map %s of %s", name, r.program)
+	}
+	return r.RegisterBackend(name, kernelMap{bpfMap})
+}
+
+// RegisterBackend adds map of any backend, e.g. FakeMap. name could be registered only once
+func (r *Registry) RegisterBackend(name BpfMapName, backend Backend) error {
+	if backend == nil {
+		return fmt.Errorf("can't register nil map %s of %s", name, r.program)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.maps[name]; exists {
+		return fmt.Errorf("map %s of %s is already registered", name, r.program)
+	}
+	r.maps[name] = backend
+	return nil
+}
+
+// Reset forgets all maps, e.g. before the program is loaded again. maps are not closed
+func (r *Registry) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.maps = make(map[BpfMapName]Backend)
+}
+
+// Has returns true if map has been registered, i.e. the program has been
+// built with the feature which uses it
+func (r *Registry) Has(name BpfMapName) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.maps[name] != nil
+}
+
+// Names returns names of all registered maps, sorted
+func (r *Registry) Names() []BpfMapName {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]BpfMapName, 0, len(r.maps))
+	for name := range r.maps {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
+
+// Map returns handle of the map. handle of a map which has not been registered is
+// returned as well, all its operations fail
+func (r *Registry) Map(name BpfMapName) Handle {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return Handle{program: r.program, name: name, backend: r.maps[name]}
+}
+
+// Handle refers to a map of a loaded program
+type Handle struct {
+	program string
+	name    BpfMapName
+	backend Backend
+}
+
+// Name returns name of the map
+func (h Handle) Name() BpfMapName {
+	return h.name
+}
+
+// Valid returns true if the map has been registered
+func (h Handle) Valid() bool {
+	return h.backend != nil
+}
+
+// Map returns map loaded into the kernel, e.g. to read perf event array, or nil
+// if the map is not registered or is not a kernel's one
+func (h Handle) Map() *ebpf.Map {
+	if m, ok := h.backend.(kernelMap); ok {
+		return m.Map
+	}
+	return nil
+}
+
+// Backend returns the map the handle refers to, or nil
+func (h Handle) Backend() Backend {
+	return h.backend
+}
+
+func (h Handle) check() error {
+	if h.backend == nil {
+		return fmt.Errorf("not found map:%s of %s", h.name, h.program)
+	}
+	return nil
+}
+
+func (h Handle) Update(key, value interface{}, flags ebpf.MapUpdateFlags) error {
+	if err := h.check(); err != nil {
+		return err
+	}
+	return h.backend.Update(key, value, flags)
+}
+
+func (h Handle) UpdateBatch(keys, values interface{}, count int) error {
+	if err := h.check(); err != nil {
+		return err
+	}
+	opts := ebpf.BatchOptions{
+		ElemFlags: 0,
+		Flags:     0,
+	}
+	numUpdated, err := h.backend.BatchUpdate(keys, values, &opts)
+	if err != nil {
+		return err
+	}
+	if count != numUpdated {
+		return fmt.Errorf("Batch update only updated: %d elements out of: %d", numUpdated, count)
+	}
+	return nil
+}
+
+func (h Handle) Lookup(key, valueOut interface{}) error {
+	if err := h.check(); err != nil {
+		return err
+	}
+	return h.backend.Lookup(key, valueOut)
+}
+
+func (h Handle) LookupWithFlags(key, valueOut interface{}, flags ebpf.MapLookupFlags) error {
+	if err := h.check(); err != nil {
+		return err
+	}
+	return h.backend.LookupWithFlags(key, valueOut, flags)
+}
+
+func (h Handle) Delete(key interface{}) error {
+	if err := h.check(); err != nil {
+		return err
+	}
+	return h.backend.Delete(key)
+}
+
+func (h Handle) NextKey(key, nextKey interface{}) error {
+	if err := h.check(); err != nil {
+		return err
+	}
+	return h.backend.NextKey(key, nextKey)
+}
@@ -0,0 +1,275 @@
+package adapter
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cilium/ebpf"
+)
+
+// kBatchSize is number of entries read or written by one batch operation
+const kBatchSize = 256
+
+// Map is a typed view of a hash, lru or array map with keys K and values V.
+// K and V are fixed size types laid out as bpf structs (e.g. types of pkg/bpf),
+// so passing a key or value of another map does not compile. types which are
+// read from the map must be decodable by encoding/binary, i.e. have exported fields
+type Map[K, V any] struct {
+	handle Handle
+}
+
+// NewMap returns typed view of the map
+func NewMap[K, V any](handle Handle) Map[K, V] {
+	return Map[K, V]{handle: handle}
+}
+
+// Valid returns true if the map has been registered
+func (m Map[K, V]) Valid() bool {
+	return m.handle.Valid()
+}
+
+// Handle returns untyped handle of the map
+func (m Map[K, V]) Handle() Handle {
+	return m.handle
+}
+
+func (m Map[K, V]) Lookup(key K) (V, error) {
+	var value V
+	err := m.handle.Lookup(&key, &value)
+	return value, err
+}
+
+func (m Map[K, V]) Update(key K, value V, flags ebpf.MapUpdateFlags) error {
+	return m.handle.Update(&key, &value, flags)
+}
+
+func (m Map[K, V]) Delete(key K) error {
+	return m.handle.Delete(&key)
+}
+
+// BatchUpdate updates all keys with values in one syscall
+func (m Map[K, V]) BatchUpdate(keys []K, values []V) error {
+	if len(keys) != len(values) {
+		return fmt.Errorf("%d keys and %d values of map %s", len(keys), len(values), m.handle.Name())
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	return m.handle.UpdateBatch(keys, values, len(keys))
+}
+
+// BatchDelete deletes all keys in one syscall
+func (m Map[K, V]) BatchDelete(keys []K) error {
+	if err := m.handle.check(); err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	deleted, err := m.handle.backend.BatchDelete(keys, nil)
+	if err != nil {
+		return err
+	}
+	if deleted != len(keys) {
+		return fmt.Errorf("Batch delete only deleted: %d elements out of: %d", deleted, len(keys))
+	}
+	return nil
+}
+
+// BatchLookup reads all entries of the map, kBatchSize entries per syscall.
+// maps which don't support batch api are iterated key by key
+func (m Map[K, V]) BatchLookup() ([]K, []V, error) {
+	if err := m.handle.check(); err != nil {
+		return nil, nil, err
+	}
+	var allKeys []K
+	var allValues []V
+	keys := make([]K, kBatchSize)
+	values := make([]V, kBatchSize)
+	var prevKey interface{}
+	for {
+		var nextKey K
+		count, err := m.handle.backend.BatchLookup(prevKey, &nextKey, keys, values, nil)
+		allKeys = append(allKeys, keys[:count]...)
+		allValues = append(allValues, values[:count]...)
+		if errors.Is(err, ebpf.ErrKeyNotExist) {
+			return allKeys, allValues, nil
+		}
+		if errors.Is(err, ebpf.ErrNotSupported) && prevKey == nil {
+			return m.lookupAll()
+		}
+		if err != nil {
+			return allKeys, allValues, err
+		}
+		prevKey = &nextKey
+	}
+}
+
+// BatchLookupAfter reads at most count entries which follow prevKey, or the first ones
+// if it is nil, kBatchSize entries per syscall. entries of an array map which follow
+// index i are the ones at i+1, i+2 and so on. fewer entries are read if the map ends
+func (m Map[K, V]) BatchLookupAfter(prevKey *K, count int) ([]K, []V, error) {
+	if err := m.handle.check(); err != nil {
+		return nil, nil, err
+	}
+	batchSize := kBatchSize
+	if count < batchSize {
+		batchSize = count
+	}
+	allKeys := make([]K, 0, count)
+	allValues := make([]V, 0, count)
+	keys := make([]K, batchSize)
+	values := make([]V, batchSize)
+	var startKey interface{}
+	if prevKey != nil {
+		startKey = prevKey
+	}
+	for len(allKeys) < count {
+		size := count - len(allKeys)
+		if size > batchSize {
+			size = batchSize
+		}
+		var nextKey K
+		read, err := m.handle.backend.BatchLookup(startKey, &nextKey, keys[:size], values[:size], nil)
+		allKeys = append(allKeys, keys[:read]...)
+		allValues = append(allValues, values[:read]...)
+		if errors.Is(err, ebpf.ErrKeyNotExist) {
+			break
+		}
+		if err != nil {
+			return allKeys, allValues, err
+		}
+		startKey = &nextKey
+	}
+	return allKeys, allValues, nil
+}
+
+func (m Map[K, V]) lookupAll() ([]K, []V, error) {
+	var keys []K
+	var values []V
+	err := m.Iterate(func(key K, value V) bool {
+		keys = append(keys, key)
+		values = append(values, value)
+		return true
+	})
+	return keys, values, err
+}
+
+// Iterate calls fn for every entry of the map until it returns false
+func (m Map[K, V]) Iterate(fn func(key K, value V) bool) error {
+	if err := m.handle.check(); err != nil {
+		return err
+	}
+	var key K
+	var value V
+	iter := m.handle.backend.Iterate()
+	for iter.Next(&key, &value) {
+		if !fn(key, value) {
+			return nil
+		}
+	}
+	return iter.Err()
+}
+
+// LPMTrie is a typed view of a longest prefix match trie. K must start
+// with prefix length, as struct bpf_lpm_trie_key does
+type LPMTrie[K, V any] struct {
+	Map[K, V]
+}
+
+// NewLPMTrie returns typed view of the trie
+func NewLPMTrie[K, V any](handle Handle) LPMTrie[K, V] {
+	return LPMTrie[K, V]{Map: NewMap[K, V](handle)}
+}
+
+// PerCPUArray is a typed view of a per cpu array with values V
+type PerCPUArray[V any] struct {
+	handle Handle
+}
+
+// NewPerCPUArray returns typed view of the per cpu array
+func NewPerCPUArray[V any](handle Handle) PerCPUArray[V] {
+	return PerCPUArray[V]{handle: handle}
+}
+
+// Valid returns true if the map has been registered
+func (a PerCPUArray[V]) Valid() bool {
+	return a.handle.Valid()
+}
+
+// Lookup returns values of all possible cpus at index
+func (a PerCPUArray[V]) Lookup(index uint32) ([]V, error) {
+	var values []V
+	if err := a.handle.Lookup(&index, &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// LookupSum returns values of all cpus at index, summed by add
+func (a PerCPUArray[V]) LookupSum(index uint32, add func(sum V, value V) V) (V, error) {
+	var sum V
+	values, err := a.Lookup(index)
+	if err != nil {
+		return sum, err
+	}
+	for _, value := range values {
+		sum = add(sum, value)
+	}
+	return sum, nil
+}
+
+// Update writes values of all possible cpus at index
+func (a PerCPUArray[V]) Update(index uint32, values []V) error {
+	return a.handle.Update(&index, values, ebpf.UpdateAny)
+}
+
+// UpdateAll writes value at index for every cpu
+func (a PerCPUArray[V]) UpdateAll(index uint32, value V) error {
+	nrCpus, err := GetPossibleCpus()
+	if err != nil {
+		return err
+	}
+	values := make([]V, nrCpus)
+	for i := range values {
+		values[i] = value
+	}
+	return a.Update(index, values)
+}
+
+// Reset zeroes value at index on all cpus
+func (a PerCPUArray[V]) Reset(index uint32) error {
+	var zero V
+	return a.UpdateAll(index, zero)
+}
+
+// ResetAll zeroes values at indexes [0, count) on all cpus. maps in the kernel are
+// reset kBatchSize entries per syscall, others (or kernels without batch api
+// for arrays) one by one
+func (a PerCPUArray[V]) ResetAll(count uint32) error {
+	if err := a.handle.check(); err != nil {
+		return err
+	}
+	first := uint32(0)
+	if m, ok := a.handle.backend.(kernelMap); ok {
+		nrCpus, err := GetPossibleCpus()
+		if err != nil {
+			return err
+		}
+		for ; first < count; first += kBatchSize {
+			batch := count - first
+			if batch > kBatchSize {
+				batch = kBatchSize
+			}
+			if err = m.resetPerCPUBatch(first, batch, nrCpus); err != nil {
+				break
+			}
+		}
+	}
+	for index := first; index < count; index++ {
+		if err := a.Reset(index); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package adapter
+
+import (
+	"os"
+	"testing"
+
+	"github.com/cilium/ebpf"
+)
+
+const kTestEntries = 600
+
+func checkResetAll(t *testing.T, registry *Registry) {
+	t.Helper()
+	array := NewPerCPUArray[uint32](registry.Map(LruMissStats))
+	for index := uint32(0); index < kTestEntries; index++ {
+		if err := array.UpdateAll(index, index+1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// entries above count are kept
+	if err := array.ResetAll(kTestEntries - 1); err != nil {
+		t.Fatalf("can't reset entries: %v", err)
+	}
+	for index := uint32(0); index < kTestEntries; index++ {
+		values, err := array.Lookup(index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := uint32(0)
+		if index == kTestEntries-1 {
+			expected = kTestEntries
+		}
+		for cpu, value := range values {
+			if value != expected {
+				t.Fatalf("value of cpu %d at %d is %d, expected %d", cpu, index, value, expected)
+			}
+		}
+	}
+}
+
+func perCPUArraySpec() *ebpf.MapSpec {
+	return &ebpf.MapSpec{
+		Name: "lru_miss_stats", Type: ebpf.PerCPUArray, KeySize: 4, ValueSize: 4, MaxEntries: kTestEntries,
+	}
+}
+
+func TestPerCPUArrayResetAll(t *testing.T) {
+	fake, err := NewFakeMap(perCPUArraySpec())
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := NewRegistry(BalancerProg)
+	if err = registry.RegisterBackend(LruMissStats, fake); err != nil {
+		t.Fatal(err)
+	}
+	checkResetAll(t, registry)
+}
+
+func TestKernelPerCPUArrayResetAll(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("creating bpf maps requires root")
+	}
+	bpfMap, err := ebpf.NewMap(perCPUArraySpec())
+	if err != nil {
+		t.Skipf("can't create per cpu array: %v", err)
+	}
+	defer bpfMap.Close()
+	// batch api is used by kernels which support it
+	nrCpus, err := GetPossibleCpus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = (kernelMap{bpfMap}).resetPerCPUBatch(0, 1, nrCpus); err != nil {
+		t.Logf("batch reset is not supported, entries are reset one by one: %v", err)
+	}
+	registry := NewRegistry(BalancerProg)
+	if err = registry.Register(LruMissStats, bpfMap); err != nil {
+		t.Fatal(err)
+	}
+	checkResetAll(t, registry)
+}
+
+func checkBatchLookupAfter(t *testing.T, registry *Registry) {
+	t.Helper()
+	rings := NewMap[uint32, uint32](registry.Map(ChRings))
+	for index := uint32(0); index < kTestEntries; index++ {
+		if err := rings.Update(index, index*2, ebpf.UpdateAny); err != nil {
+			t.Fatal(err)
+		}
+	}
+	after := func(index uint32) *uint32 { return &index }
+	for _, test := range []struct {
+		prevKey *uint32
+		count   int
+		first   uint32
+		read    int
+	}{
+		{nil, 3, 0, 3},
+		{after(99), 300, 100, 300},
+		{after(99), kBatchSize, 100, kBatchSize},
+		// the map ends before count entries are read
+		{after(589), 20, 590, 10},
+		{after(kTestEntries - 1), 5, 0, 0},
+		{nil, 0, 0, 0},
+	} {
+		keys, values, err := rings.BatchLookupAfter(test.prevKey, test.count)
+		if err != nil {
+			t.Fatalf("can't read %d entries: %v", test.count, err)
+		}
+		if len(keys) != test.read || len(values) != test.read {
+			t.Fatalf("%d keys and %d values are read, expected %d", len(keys), len(values), test.read)
+		}
+		for i := range keys {
+			if expected := test.first + uint32(i); keys[i] != expected || values[i] != expected*2 {
+				t.Fatalf("entry %d is %d: %d, expected %d: %d", i, keys[i], values[i], expected, expected*2)
+			}
+		}
+	}
+}
+
+func arraySpec() *ebpf.MapSpec {
+	return &ebpf.MapSpec{
+		Name: "ch_rings", Type: ebpf.Array, KeySize: 4, ValueSize: 4, MaxEntries: kTestEntries,
+	}
+}
+
+func TestBatchLookupAfter(t *testing.T) {
+	fake, err := NewFakeMap(arraySpec())
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := NewRegistry(BalancerProg)
+	if err = registry.RegisterBackend(ChRings, fake); err != nil {
+		t.Fatal(err)
+	}
+	checkBatchLookupAfter(t, registry)
+}
+
+func TestKernelBatchLookupAfter(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("creating bpf maps requires root")
+	}
+	bpfMap, err := ebpf.NewMap(arraySpec())
+	if err != nil {
+		t.Skipf("can't create array: %v", err)
+	}
+	defer bpfMap.Close()
+	registry := NewRegistry(BalancerProg)
+	if err = registry.Register(ChRings, bpfMap); err != nil {
+		t.Fatal(err)
+	}
+	checkBatchLookupAfter(t, registry)
+}
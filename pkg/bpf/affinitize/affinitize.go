@@ -2,17 +2,22 @@ package affinitize
 
 import (
 	"fmt"
-	"github.com/cybwan/l4slb/pkg/bpf/topology"
-	"io/ioutil"
-	"log"
+	"os"
+	"path/filepath"
 	"strconv"
 
 	"github.com/cybwan/l4slb/pkg/bpf/irq"
+	"github.com/cybwan/l4slb/pkg/bpf/topology"
+	"github.com/cybwan/l4slb/pkg/logger"
 )
 
+// paths are relative to the root of proc tree
 const (
-	IRQ_DIR         string = "/proc/irq/"
+	IRQ_DIR         string = "proc/irq/"
 	IRQ_FILE_SUFFIX string = "/smp_affinity"
+
+	// DefaultRoot is the root of proc and sys trees on the host
+	DefaultRoot string = "/"
 )
 
 // affinitizing strategy.
@@ -27,6 +32,40 @@ const (
 	NUMA_NODE int = 0
 )
 
+var (
+	log = logger.New("affinitize")
+
+	strategyNames = map[string]int{
+		"seq_nodes": SEQ_NODES,
+		"same_node": SAME_NODE,
+		"all_nodes": ALL_NODES,
+	}
+)
+
+// IrqAffinity describes cpu which irq is (or would be) bound to
+type IrqAffinity struct {
+	Irq      int
+	Cpu      int
+	NumaNode int
+	Mask     string
+}
+
+// Mapping is the result of affinitizing of interface's irqs. ForwardingCores and
+// NumaNodes have the same length and could be used as FlomeshLbConfig's ones
+type Mapping struct {
+	Irqs            []IrqAffinity
+	ForwardingCores []int32
+	NumaNodes       []int32
+}
+
+// ParseStrategy returns strategy by its name: seq_nodes, same_node or all_nodes
+func ParseStrategy(name string) (int, error) {
+	if strategy, exists := strategyNames[name]; exists {
+		return strategy, nil
+	}
+	return 0, fmt.Errorf("unsupported strategy: %q", name)
+}
+
 // array of cpus is small and we do this only on startup.
 // so no point to make anything complex here
 func searchSlice(i int, s []int) bool {
@@ -38,108 +77,100 @@ func searchSlice(i int, s []int) bool {
 	return false
 }
 
-func writeAffinityToFile(irq int, cpu uint64, ncpus int) {
-	mask := make([]string, ncpus/32+1, ncpus/32+1)
-	for i, _ := range mask {
+func affinityMask(cpu int, ncpus int) string {
+	mask := make([]string, ncpus/32+1)
+	for i := range mask {
 		mask[i] = "00000000"
 	}
-	mask[cpu/32] = fmt.Sprintf("%08x", 1<<(cpu%32))
+	mask[cpu/32] = fmt.Sprintf("%08x", uint32(1)<<(cpu%32))
 	cpu_flag_str := mask[len(mask)-1]
 	for i := len(mask) - 2; i >= 0; i-- {
 		cpu_flag_str += ("," + mask[i])
 	}
-	irq_str := strconv.Itoa(irq)
-	log.Printf("affinitizing irq %d to cpu %d mask %s\n",
-		irq, cpu, cpu_flag_str)
-	filename := IRQ_DIR + irq_str + IRQ_FILE_SUFFIX
-	err := ioutil.WriteFile(filename, []byte(cpu_flag_str), 0644)
-	if err != nil {
-		log.Fatal("error while writing affinitiy for irq and cpu:",
-			irq, cpu, err)
-	}
+	return cpu_flag_str
 }
 
-func affinitizeSeqNodes(intf string, write bool) []int {
-	var forwarding_cpus []int
-	irqs := irq.GetInterfaceIrq(intf)
-	topo := topology.GetCpuTopology()
-	for i, irq := range irqs {
-		cpu := i % topo.Ncpus
-		if !searchSlice(cpu, forwarding_cpus) {
-			forwarding_cpus = append(forwarding_cpus, cpu)
-		}
-		if write {
-			writeAffinityToFile(irq, uint64(cpu), topo.Ncpus)
-		}
+func writeAffinityToFile(root string, affinity *IrqAffinity) error {
+	log.Info().Msgf("affinitizing irq %d to cpu %d mask %s",
+		affinity.Irq, affinity.Cpu, affinity.Mask)
+	filename := filepath.Join(root, IRQ_DIR+strconv.Itoa(affinity.Irq)+IRQ_FILE_SUFFIX)
+	if err := os.WriteFile(filename, []byte(affinity.Mask), 0644); err != nil {
+		return fmt.Errorf("error while writing affinity of irq %d to cpu %d: %w",
+			affinity.Irq, affinity.Cpu, err)
 	}
-	return forwarding_cpus
+	return nil
 }
 
-func affinitizeSameNode(intf string, write bool) []int {
-	var forwarding_cpus []int
-	irqs := irq.GetInterfaceIrq(intf)
-	topo := topology.GetCpuTopology()
-	for i, irq := range irqs {
-		cpu_idx := i % len(topo.Numa2Cpu[NUMA_NODE])
-		cpu := topo.Numa2Cpu[NUMA_NODE][cpu_idx]
-		if !searchSlice(cpu, forwarding_cpus) {
-			forwarding_cpus = append(forwarding_cpus, cpu)
-		}
-		if write {
-			writeAffinityToFile(irq, uint64(cpu), topo.Ncpus)
+// pickCpu returns cpu for i-th irq of the interface
+func pickCpu(topo *topology.CpuTopology, strategy int, i int) (int, error) {
+	switch strategy {
+	case SEQ_NODES:
+		return topo.Cpus[i%len(topo.Cpus)], nil
+	case SAME_NODE:
+		cpus := topo.Numa2Cpu[NUMA_NODE]
+		if len(cpus) == 0 {
+			return 0, fmt.Errorf("numa node %d has no cpus", NUMA_NODE)
 		}
+		return cpus[i%len(cpus)], nil
+	case ALL_NODES:
+		node := topo.Nodes[i%len(topo.Nodes)]
+		cpus := topo.Numa2Cpu[node]
+		// assumption that all numa nodes has same number of cpus
+		return cpus[(i/len(topo.Nodes))%len(cpus)], nil
+	default:
+		return 0, fmt.Errorf("unsupported strategy: %d", strategy)
 	}
-	return forwarding_cpus
 }
 
-func affinitizeAllNodes(intf string, write bool) []int {
+// GetAffinitizeMapping calculates which cpus irqs of intf should be bound to with
+// strategy, without changing anything. root is the root of proc and sys trees
+func GetAffinitizeMapping(root string, intf string, strategy int) (*Mapping, error) {
+	irqs, err := irq.GetInterfaceIrq(root, intf)
+	if err != nil {
+		return nil, err
+	}
+	if len(irqs) == 0 {
+		return nil, fmt.Errorf("no irqs found for interface %s", intf)
+	}
+	topo, err := topology.GetCpuTopology(root)
+	if err != nil {
+		return nil, err
+	}
+	maxCpu := topo.Cpus[len(topo.Cpus)-1]
+
 	var forwarding_cpus []int
-	irqs := irq.GetInterfaceIrq(intf)
-	topo := topology.GetCpuTopology()
-	for i, irq := range irqs {
-		numa_idx := i % len(topo.Numa2Cpu)
-		// assumption that all numa nodes has same number of cpus
-		cpu_idx := (i / len(topo.Numa2Cpu)) % len(topo.Numa2Cpu[NUMA_NODE])
-		cpu := topo.Numa2Cpu[numa_idx][cpu_idx]
+	mapping := new(Mapping)
+	for i, irqNum := range irqs {
+		cpu, err := pickCpu(&topo, strategy, i)
+		if err != nil {
+			return nil, err
+		}
+		mapping.Irqs = append(mapping.Irqs, IrqAffinity{
+			Irq:      irqNum,
+			Cpu:      cpu,
+			NumaNode: topo.Cpu2Numa[cpu],
+			Mask:     affinityMask(cpu, maxCpu+1),
+		})
 		if !searchSlice(cpu, forwarding_cpus) {
 			forwarding_cpus = append(forwarding_cpus, cpu)
-		}
-		if write {
-			writeAffinityToFile(irq, uint64(cpu), topo.Ncpus)
+			mapping.ForwardingCores = append(mapping.ForwardingCores, int32(cpu))
+			mapping.NumaNodes = append(mapping.NumaNodes, int32(topo.Cpu2Numa[cpu]))
 		}
 	}
-	return forwarding_cpus
+	return mapping, nil
 }
 
-func AffinitizeIntf(intf string, strategy int) {
-	switch strategy {
-	case SEQ_NODES:
-		affinitizeSeqNodes(intf, true)
-		break
-	case SAME_NODE:
-		affinitizeSameNode(intf, true)
-		break
-	case ALL_NODES:
-		affinitizeAllNodes(intf, true)
-		break
-	default:
-		log.Println("unsupported strategy: ", strategy)
+// AffinitizeIntf binds irqs of intf to cpus chosen with strategy
+// and returns the mapping. root is the root of proc and sys trees
+func AffinitizeIntf(root string, intf string, strategy int) (*Mapping, error) {
+	mapping, err := GetAffinitizeMapping(root, intf, strategy)
+	if err != nil {
+		return nil, err
 	}
-}
-
-func GetAffinitizeMapping(intf string, strategy int) []int {
-	switch strategy {
-	case SEQ_NODES:
-		return affinitizeSeqNodes(intf, false)
-		break
-	case SAME_NODE:
-		return affinitizeSameNode(intf, false)
-		break
-	case ALL_NODES:
-		return affinitizeAllNodes(intf, false)
-		break
-	default:
-		log.Fatal("unsupported strategy: ", strategy)
+	for i := range mapping.Irqs {
+		if err = writeAffinityToFile(root, &mapping.Irqs[i]); err != nil {
+			return nil, err
+		}
 	}
-	return []int{}
+	return mapping, nil
 }
@@ -0,0 +1,128 @@
+package affinitize
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const kTestRoot = "testdata/host"
+
+func TestGetAffinitizeMapping(t *testing.T) {
+	// cpus 0-3 are on numa node 0, 32-35 on node 1
+	tests := []struct {
+		strategy string
+		cpus     []int
+		cores    []int32
+		nodes    []int32
+	}{
+		{"seq_nodes", []int{0, 1, 2, 3, 32, 33}, []int32{0, 1, 2, 3, 32, 33}, []int32{0, 0, 0, 0, 1, 1}},
+		{"same_node", []int{0, 1, 2, 3, 0, 1}, []int32{0, 1, 2, 3}, []int32{0, 0, 0, 0}},
+		{"all_nodes", []int{0, 32, 1, 33, 2, 34}, []int32{0, 32, 1, 33, 2, 34}, []int32{0, 1, 0, 1, 0, 1}},
+	}
+	for _, test := range tests {
+		strategy, err := ParseStrategy(test.strategy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mapping, err := GetAffinitizeMapping(kTestRoot, "eth0", strategy)
+		if err != nil {
+			t.Fatalf("%s: can't get mapping: %v", test.strategy, err)
+		}
+		var cpus []int
+		for i, irq := range mapping.Irqs {
+			if irq.Irq != 60+i {
+				t.Errorf("%s: irq %d at position %d, expected %d", test.strategy, irq.Irq, i, 60+i)
+			}
+			cpus = append(cpus, irq.Cpu)
+		}
+		if !reflect.DeepEqual(cpus, test.cpus) {
+			t.Errorf("%s: irqs are bound to %v, expected %v", test.strategy, cpus, test.cpus)
+		}
+		if !reflect.DeepEqual(mapping.ForwardingCores, test.cores) || !reflect.DeepEqual(mapping.NumaNodes, test.nodes) {
+			t.Errorf("%s: forwarding cores %v on nodes %v, expected %v on %v", test.strategy,
+				mapping.ForwardingCores, mapping.NumaNodes, test.cores, test.nodes)
+		}
+	}
+}
+
+func TestGetAffinitizeMappingErrors(t *testing.T) {
+	if _, err := ParseStrategy("random"); err == nil {
+		t.Error("unknown strategy has been parsed")
+	}
+	if mapping, err := GetAffinitizeMapping(kTestRoot, "eth0", ALL_NODES+1); err == nil {
+		t.Errorf("mapping %+v with unknown strategy, expected error", mapping)
+	}
+	if mapping, err := GetAffinitizeMapping(kTestRoot, "eth9", SEQ_NODES); err == nil {
+		t.Errorf("mapping %+v of unknown interface, expected error", mapping)
+	}
+}
+
+func TestAffinityMask(t *testing.T) {
+	tests := []struct {
+		cpu   int
+		ncpus int
+		mask  string
+	}{
+		{0, 4, "00000001"},
+		{3, 4, "00000008"},
+		{31, 32, "00000000,80000000"},
+		{33, 36, "00000002,00000000"},
+		{64, 96, "00000000,00000001,00000000,00000000"},
+	}
+	for _, test := range tests {
+		if mask := affinityMask(test.cpu, test.ncpus); mask != test.mask {
+			t.Errorf("mask of cpu %d of %d is %s, expected %s", test.cpu, test.ncpus, mask, test.mask)
+		}
+	}
+}
+
+func TestAffinitizeIntf(t *testing.T) {
+	root := copyTree(t, kTestRoot)
+	mapping, err := AffinitizeIntf(root, "eth0", ALL_NODES)
+	if err != nil {
+		t.Fatalf("can't affinitize irqs: %v", err)
+	}
+	for _, irq := range mapping.Irqs {
+		written, err := os.ReadFile(filepath.Join(root, IRQ_DIR+strconv.Itoa(irq.Irq)+IRQ_FILE_SUFFIX))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := affinityMask(irq.Cpu, 36)
+		if string(written) != expected || irq.Mask != expected {
+			t.Errorf("affinity of irq %d is %q (%q), expected %q of cpu %d", irq.Irq, written, irq.Mask, expected, irq.Cpu)
+		}
+	}
+	// timer irq is left alone
+	if _, err = os.Stat(filepath.Join(root, IRQ_DIR+"0")); !os.IsNotExist(err) {
+		t.Errorf("affinity of irq 0 has been written")
+	}
+}
+
+// copyTree copies fake proc and sys tree into temporary directory, so it could be written to
+func copyTree(t *testing.T, src string) string {
+	t.Helper()
+	dst := t.TempDir()
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, strings.TrimPrefix(path, src))
+		if d.IsDir() {
+			return os.MkdirAll(target, 0o755)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, 0o644)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dst
+}
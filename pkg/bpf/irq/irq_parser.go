@@ -1,16 +1,18 @@
 package irq
 
 import (
-	"io/ioutil"
-	"log"
+	"fmt"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
 )
 
+// paths are relative to the root of proc and sys trees
 const (
-	PROC_IRQ_FILE      string = "/proc/interrupts"
-	MSI_IRQ_DIR_PREFIX string = "/sys/class/net/"
+	PROC_IRQ_FILE      string = "proc/interrupts"
+	MSI_IRQ_DIR_PREFIX string = "sys/class/net/"
 	MSI_IRQ_DIR_SUFFIX string = "/device/msi_irqs/"
 	kError             int    = -1
 )
@@ -31,16 +33,16 @@ func parseInterruptLine(line string) int {
 	return irq
 }
 
-func parseProcIrqs(intf string) []int {
+func parseProcIrqs(root string, intf string) ([]int, error) {
 	var irqs []int
-	regexp_string := ".*" + intf + ".*"
+	regexp_string := ".*" + regexp.QuoteMeta(intf) + ".*"
 	intf_regexp, err := regexp.Compile(regexp_string)
 	if err != nil {
-		log.Fatal("cannot compile regexp for intf ", intf)
+		return nil, fmt.Errorf("cannot compile regexp for intf %s: %w", intf, err)
 	}
-	file_bytes, err := ioutil.ReadFile(PROC_IRQ_FILE)
+	file_bytes, err := os.ReadFile(filepath.Join(root, PROC_IRQ_FILE))
 	if err != nil {
-		log.Fatal("cannot read /proc/interrupts file")
+		return nil, fmt.Errorf("cannot read interrupts file: %w", err)
 	}
 	lines := strings.Split(string(file_bytes), "\n")
 	for _, line := range lines {
@@ -58,24 +60,24 @@ func parseProcIrqs(intf string) []int {
 			}
 		}
 	}
-	return irqs
+	return irqs, nil
 }
 
-func getMsiIrqForDevice(intf string) []int {
+func getMsiIrqForDevice(root string, intf string) ([]int, error) {
 	var msi_irqs []int
-	msi_dir := MSI_IRQ_DIR_PREFIX + intf + MSI_IRQ_DIR_SUFFIX
-	files, err := ioutil.ReadDir(msi_dir)
+	msi_dir := filepath.Join(root, MSI_IRQ_DIR_PREFIX+intf+MSI_IRQ_DIR_SUFFIX)
+	files, err := os.ReadDir(msi_dir)
 	if err != nil {
-		log.Fatal("cannot read directory with msi irq numbers for ", intf, err)
+		return nil, fmt.Errorf("cannot read directory with msi irq numbers for %s: %w", intf, err)
 	}
 	for _, file := range files {
 		irq, err := strconv.Atoi(file.Name())
 		if err != nil {
-			log.Fatal("cannot parse irq to int ", err)
+			return nil, fmt.Errorf("cannot parse irq %q to int: %w", file.Name(), err)
 		}
 		msi_irqs = append(msi_irqs, irq)
 	}
-	return msi_irqs
+	return msi_irqs, nil
 }
 
 func searchSlice(i int, s []int) bool {
@@ -87,23 +89,34 @@ func searchSlice(i int, s []int) bool {
 	return false
 }
 
-func parseMsiIrqs(intf string) []int {
+func parseMsiIrqs(root string, intf string) ([]int, error) {
 	var irqs []int
-	reg_irqs := parseProcIrqs("")
-	msi_irqs := getMsiIrqForDevice(intf)
+	reg_irqs, err := parseProcIrqs(root, "")
+	if err != nil {
+		return nil, err
+	}
+	msi_irqs, err := getMsiIrqForDevice(root, intf)
+	if err != nil {
+		return nil, err
+	}
 	for _, irq := range msi_irqs {
 		if searchSlice(irq, reg_irqs) {
 			irqs = append(irqs, irq)
 		}
 	}
-	return irqs
+	return irqs, nil
 }
 
-func GetInterfaceIrq(intf string) []int {
-	irqs := parseProcIrqs(intf)
+// GetInterfaceIrq returns irqs of intf, found by its name in interrupts file or,
+// if there is none, among msi irqs of its device. root is the root of proc and
+// sys trees, "/" on the host
+func GetInterfaceIrq(root string, intf string) ([]int, error) {
+	irqs, err := parseProcIrqs(root, intf)
+	if err != nil {
+		return nil, err
+	}
 	if len(irqs) != 0 {
-		return irqs
-	} else {
-		return parseMsiIrqs(intf)
+		return irqs, nil
 	}
+	return parseMsiIrqs(root, intf)
 }
@@ -0,0 +1,61 @@
+package irq
+
+import (
+	"reflect"
+	"testing"
+)
+
+const kTestRoot = "testdata/host"
+
+func TestGetInterfaceIrq(t *testing.T) {
+	tests := []struct {
+		intf string
+		irqs []int
+	}{
+		// irqs named after the interface
+		{"eth0", []int{24, 25, 26, 27}},
+		// msi irqs of the device which are present in interrupts file
+		{"eth1", []int{40, 41, 42}},
+	}
+	for _, test := range tests {
+		irqs, err := GetInterfaceIrq(kTestRoot, test.intf)
+		if err != nil {
+			t.Fatalf("can't get irqs of %s: %v", test.intf, err)
+		}
+		if !reflect.DeepEqual(irqs, test.irqs) {
+			t.Errorf("irqs of %s are %v, expected %v", test.intf, irqs, test.irqs)
+		}
+	}
+}
+
+func TestGetInterfaceIrqErrors(t *testing.T) {
+	// no irqs by name and no device
+	if irqs, err := GetInterfaceIrq(kTestRoot, "eth9"); err == nil {
+		t.Errorf("irqs %v of unknown interface, expected error", irqs)
+	}
+	// invalid msi irq name
+	if irqs, err := GetInterfaceIrq(kTestRoot, "eth2"); err == nil {
+		t.Errorf("irqs %v of interface with invalid msi irqs, expected error", irqs)
+	}
+	if irqs, err := GetInterfaceIrq("testdata/missing", "eth0"); err == nil {
+		t.Errorf("irqs %v without interrupts file, expected error", irqs)
+	}
+}
+
+func TestParseInterruptLine(t *testing.T) {
+	tests := []struct {
+		line string
+		irq  int
+	}{
+		{" 25:       1000          0   PCI-MSI 524289-edge      eth0-TxRx-0", 25},
+		{"  0:         36          0   IO-APIC    2-edge      timer", 0},
+		{"           CPU0       CPU1", kError},
+		{"NMI:          0          0   Non-maskable interrupts", kError},
+		{"", kError},
+	}
+	for _, test := range tests {
+		if irq := parseInterruptLine(test.line); irq != test.irq {
+			t.Errorf("irq of %q is %d, expected %d", test.line, irq, test.irq)
+		}
+	}
+}
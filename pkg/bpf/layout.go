@@ -0,0 +1,33 @@
+package bpf
+
+import (
+	"unsafe"
+
+	"github.com/cybwan/l4slb/pkg/bpf/progs/balancer"
+	"github.com/cybwan/l4slb/pkg/bpf/progs/healthchecking/kern"
+)
+
+// types which are laid out by hand instead of embedding ones generated by bpf2go
+// must have the same size as generated types. each pair of constants overflows,
+// and so does not compile, if sizes differ, e.g. after bpf structs have been
+// changed and `make generate` has been run
+const (
+	_ = uintptr(unsafe.Sizeof(CtlValue{}) - unsafe.Sizeof(balancer.CtlValue_{}))
+	_ = uintptr(unsafe.Sizeof(balancer.CtlValue_{}) - unsafe.Sizeof(CtlValue{}))
+
+	_ = uintptr(unsafe.Sizeof(FlowKey{}) - unsafe.Sizeof(balancer.FlowKey_{}))
+	_ = uintptr(unsafe.Sizeof(balancer.FlowKey_{}) - unsafe.Sizeof(FlowKey{}))
+
+	_ = uintptr(unsafe.Sizeof(VipDefinition{}) - unsafe.Sizeof(balancer.VipDefinition_{}))
+	_ = uintptr(unsafe.Sizeof(balancer.VipDefinition_{}) - unsafe.Sizeof(VipDefinition{}))
+
+	_ = uintptr(unsafe.Sizeof(HcMac{}) - unsafe.Sizeof(kern.HcMac_{}))
+	_ = uintptr(unsafe.Sizeof(kern.HcMac_{}) - unsafe.Sizeof(HcMac{}))
+)
+
+// AddLbStats returns sum of per cpu stats, e.g. for PerCPUArray.LookupSum
+func AddLbStats(sum LbStats, value LbStats) LbStats {
+	sum.V1 += value.V1
+	sum.V2 += value.V2
+	return sum
+}
@@ -0,0 +1,50 @@
+package monitor
+
+import (
+	"fmt"
+	"sort"
+)
+
+// EventId is id of introspection event, the same as in introspection.h
+type EventId uint32
+
+const (
+	TcpNonSynLruMiss EventId = iota
+	PacketTooBig
+	QuicPacketDropNoReal
+)
+
+var (
+	eventNames = map[EventId]string{
+		TcpNonSynLruMiss:     "tcp_nonsyn_lrumiss",
+		PacketTooBig:         "packet_toobig",
+		QuicPacketDropNoReal: "quic_packet_drop_no_real",
+	}
+)
+
+func (e EventId) String() string {
+	if name, exists := eventNames[e]; exists {
+		return name
+	}
+	return fmt.Sprintf("event_%d", uint32(e))
+}
+
+// ParseEventId returns event by its name, e.g. tcp_nonsyn_lrumiss
+func ParseEventId(name string) (EventId, error) {
+	for event, eventName := range eventNames {
+		if eventName == name {
+			return event, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown event: %q", name)
+}
+
+// AllEvents returns all known events, sorted by id
+func AllEvents() []EventId {
+	events := make([]EventId, 0, len(eventNames))
+	for event := range eventNames {
+		events = append(events, event)
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i] < events[j] })
+	return events
+}
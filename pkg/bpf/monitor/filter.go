@@ -0,0 +1,86 @@
+package monitor
+
+import (
+	"encoding/binary"
+	"net"
+)
+
+const (
+	kEthHdrLen      = 14
+	kVlanHdrLen     = 4
+	kIpv6HdrLen     = 40
+	kEthPIp         = 0x0800
+	kEthPIpv6       = 0x86dd
+	kEthP8021Q      = 0x8100
+	kIpv4MinHdrLen  = 20
+	kIpv4ProtoPos   = 9
+	kIpv4DstPos     = 16
+	kIpv6NextHdrPos = 6
+	kIpv6DstPos     = 24
+	kDstPortPos     = 2
+)
+
+// Filter selects packets sent to the vip. zero Port or Proto match any
+type Filter struct {
+	Dst   net.IP
+	Port  uint16
+	Proto uint8
+}
+
+// Match returns true if destination of the packet is the vip of the filter.
+// packets whose headers have not been captured (e.g. due to snap length) do not match
+func (f *Filter) Match(pkt *Packet) bool {
+	if f == nil {
+		return true
+	}
+	dst, proto, l4, ok := parseL3(pkt.Data)
+	if !ok || !dst.Equal(f.Dst) {
+		return false
+	}
+	if f.Proto != 0 && f.Proto != proto {
+		return false
+	}
+	if f.Port == 0 {
+		return true
+	}
+	if len(l4) < kDstPortPos+2 {
+		return false
+	}
+	return binary.BigEndian.Uint16(l4[kDstPortPos:]) == f.Port
+}
+
+// parseL3 returns destination address, l4 protocol and l4 header of ethernet frame
+func parseL3(data []byte) (net.IP, uint8, []byte, bool) {
+	if len(data) < kEthHdrLen {
+		return nil, 0, nil, false
+	}
+	ethType := binary.BigEndian.Uint16(data[kEthHdrLen-2:])
+	data = data[kEthHdrLen:]
+	if ethType == kEthP8021Q {
+		if len(data) < kVlanHdrLen {
+			return nil, 0, nil, false
+		}
+		ethType = binary.BigEndian.Uint16(data[kVlanHdrLen-2:])
+		data = data[kVlanHdrLen:]
+	}
+	switch ethType {
+	case kEthPIp:
+		if len(data) < kIpv4MinHdrLen {
+			return nil, 0, nil, false
+		}
+		hdrLen := int(data[0]&0x0f) * 4
+		if hdrLen < kIpv4MinHdrLen || len(data) < hdrLen {
+			return nil, 0, nil, false
+		}
+		dst := net.IP(data[kIpv4DstPos : kIpv4DstPos+net.IPv4len])
+		return dst, data[kIpv4ProtoPos], data[hdrLen:], true
+	case kEthPIpv6:
+		if len(data) < kIpv6HdrLen {
+			return nil, 0, nil, false
+		}
+		dst := net.IP(data[kIpv6DstPos : kIpv6DstPos+net.IPv6len])
+		return dst, data[kIpv6NextHdrPos], data[kIpv6HdrLen:], true
+	default:
+		return nil, 0, nil, false
+	}
+}
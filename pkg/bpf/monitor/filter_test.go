@@ -0,0 +1,76 @@
+package monitor
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+)
+
+// testFrame builds ethernet frame of packet to dst:port, tagged with vlan 10 if vlan is set.
+// l4 header has ports only
+func testFrame(dst string, port uint16, proto uint8, vlan bool) []byte {
+	ip := net.ParseIP(dst)
+	frame := make([]byte, kEthHdrLen-2)
+	if vlan {
+		frame = binary.BigEndian.AppendUint16(frame, kEthP8021Q)
+		frame = binary.BigEndian.AppendUint16(frame, 10)
+	}
+	var l3 []byte
+	if ip4 := ip.To4(); ip4 != nil {
+		frame = binary.BigEndian.AppendUint16(frame, kEthPIp)
+		l3 = make([]byte, kIpv4MinHdrLen)
+		l3[0] = 0x45
+		l3[kIpv4ProtoPos] = proto
+		copy(l3[kIpv4DstPos:], ip4)
+	} else {
+		frame = binary.BigEndian.AppendUint16(frame, kEthPIpv6)
+		l3 = make([]byte, kIpv6HdrLen)
+		l3[0] = 0x60
+		l3[kIpv6NextHdrPos] = proto
+		copy(l3[kIpv6DstPos:], ip)
+	}
+	l4 := binary.BigEndian.AppendUint16(nil, 31337)
+	l4 = binary.BigEndian.AppendUint16(l4, port)
+	return append(append(frame, l3...), l4...)
+}
+
+func TestFilterMatch(t *testing.T) {
+	v4 := &Filter{Dst: net.ParseIP("10.200.1.1"), Port: 80, Proto: 6}
+	v6 := &Filter{Dst: net.ParseIP("fc00:1::1"), Port: 443, Proto: 17}
+	anyPort := &Filter{Dst: net.ParseIP("10.200.1.1"), Proto: 6}
+	anyProto := &Filter{Dst: net.ParseIP("fc00:1::1"), Port: 443}
+	full := testFrame("10.200.1.1", 80, 6, false)
+
+	for _, test := range []struct {
+		name   string
+		filter *Filter
+		data   []byte
+		match  bool
+	}{
+		{"v4", v4, full, true},
+		{"v4 vlan", v4, testFrame("10.200.1.1", 80, 6, true), true},
+		{"v4 other dst", v4, testFrame("10.200.1.2", 80, 6, false), false},
+		{"v4 other port", v4, testFrame("10.200.1.1", 81, 6, false), false},
+		{"v4 other proto", v4, testFrame("10.200.1.1", 80, 17, false), false},
+		{"v4 filter of v6 packet", v4, testFrame("fc00:1::1", 80, 6, false), false},
+		{"v6", v6, testFrame("fc00:1::1", 443, 17, false), true},
+		{"v6 vlan", v6, testFrame("fc00:1::1", 443, 17, true), true},
+		{"v6 other dst", v6, testFrame("fc00:1::2", 443, 17, false), false},
+		{"v6 other port", v6, testFrame("fc00:1::1", 80, 17, false), false},
+		{"port 0", anyPort, testFrame("10.200.1.1", 8080, 6, true), true},
+		{"port 0 other proto", anyPort, testFrame("10.200.1.1", 8080, 17, false), false},
+		{"proto 0", anyProto, testFrame("fc00:1::1", 443, 132, false), true},
+		{"proto 0 other port", anyProto, testFrame("fc00:1::1", 444, 6, false), false},
+		{"nil filter", nil, []byte{1}, true},
+		// headers which are cut by snap length
+		{"no l4 header", v4, full[:len(full)-4], false},
+		{"no l4 header, port 0", anyPort, full[:len(full)-4], true},
+		{"no ip header", anyPort, full[:kEthHdrLen+10], false},
+		{"no vlan header", anyPort, testFrame("10.200.1.1", 80, 6, true)[:kEthHdrLen+2], false},
+		{"not ip", anyPort, append(make([]byte, kEthHdrLen-2), 0x08, 0x06), false},
+	} {
+		if match := test.filter.Match(&Packet{Data: test.data}); match != test.match {
+			t.Errorf("%s: match %v, expected %v", test.name, match, test.match)
+		}
+	}
+}
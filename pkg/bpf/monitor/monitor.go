@@ -0,0 +1,429 @@
+package monitor
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/perf"
+
+	"github.com/cybwan/l4slb/pkg/logger"
+)
+
+// StorageFormat is where packets of events are written to
+type StorageFormat int
+
+const (
+	// File writes packets of every event into <path>/<event>.pcap
+	File StorageFormat = iota
+	// Buffer keeps last packets of every event in memory
+	Buffer
+	// Pipe does not store packets, they are only sent to subscribers
+	Pipe
+)
+
+func (f StorageFormat) String() string {
+	switch f {
+	case File:
+		return "file"
+	case Buffer:
+		return "buffer"
+	case Pipe:
+		return "pipe"
+	default:
+		return fmt.Sprintf("storage_%d", int(f))
+	}
+}
+
+const (
+	// size of struct event_metadata from balancer_structs.h
+	kEventMetadataSize = 12
+)
+
+var (
+	log = logger.New("monitor")
+)
+
+// SampleReader reads samples the balancer submits into event pipe, as *perf.Reader does.
+// Read must return perf.ErrClosed once the reader is closed
+type SampleReader interface {
+	Read() (perf.Record, error)
+	Close() error
+}
+
+// Config of the monitor
+type Config struct {
+	// perf event array the balancer submits events into
+	EventPipe *ebpf.Map
+	// reads samples instead of perf reader of EventPipe if set, e.g. samples made up by tests
+	Reader SampleReader
+	// size of per cpu perf buffer, in pages
+	Pages uint32
+	// max number of packets between perf reader and writer
+	QueueSize uint32
+	// max number of packets written into storage, 0 - unlimited
+	PcktLimit uint32
+	// packets are truncated to SnapLen bytes
+	SnapLen uint32
+	Storage StorageFormat
+	// directory of pcap files for File storage
+	Path string
+	// max number of bytes of packets kept per event for Buffer storage
+	BufferSize uint32
+	// events which are read, all if empty
+	Events []EventId
+}
+
+// Stats of the monitor
+type Stats struct {
+	// max number of packets written into storage, 0 - unlimited
+	Limit uint32
+	// number of packets written into storage
+	Amount uint32
+	// number of packets lost because perf buffer or queue were full
+	BufferFull uint32
+}
+
+// Subscription receives packets of events it has been subscribed to
+type Subscription struct {
+	events  map[EventId]bool
+	filter  *Filter
+	packets chan *Packet
+	dropped uint32
+}
+
+// Packets returns channel of packets; it is closed when the monitor is stopped
+// or subscription is cancelled
+func (s *Subscription) Packets() <-chan *Packet {
+	return s.packets
+}
+
+// Monitor reads introspection events of the balancer from perf event array
+// and writes packets which have triggered them into storage and to subscribers
+type Monitor struct {
+	config Config
+	reader SampleReader
+	queue  chan *Packet
+	wg     sync.WaitGroup
+
+	mu          sync.Mutex
+	events      map[EventId]bool
+	files       map[EventId]*os.File
+	writers     map[EventId]*PcapWriter
+	buffers     map[EventId]*packetRing
+	subscribers map[*Subscription]bool
+	stats       Stats
+	stopped     bool
+}
+
+// New creates the monitor. it does not read events until Start is called
+func New(config Config) (*Monitor, error) {
+	if config.EventPipe == nil && config.Reader == nil {
+		return nil, fmt.Errorf("event pipe is not specified")
+	}
+	if config.Pages == 0 || config.QueueSize == 0 {
+		return nil, fmt.Errorf("pages and queue size must be greater than 0")
+	}
+	if config.Storage == File {
+		if err := os.MkdirAll(config.Path, 0755); err != nil {
+			return nil, fmt.Errorf("can't create directory for pcap files: %w", err)
+		}
+	}
+	reader := config.Reader
+	if reader == nil {
+		var err error
+		if reader, err = perf.NewReader(config.EventPipe, int(config.Pages)*os.Getpagesize()); err != nil {
+			return nil, fmt.Errorf("can't create perf reader: %w", err)
+		}
+	}
+	m := &Monitor{
+		config:      config,
+		reader:      reader,
+		queue:       make(chan *Packet, config.QueueSize),
+		events:      make(map[EventId]bool),
+		files:       make(map[EventId]*os.File),
+		writers:     make(map[EventId]*PcapWriter),
+		buffers:     make(map[EventId]*packetRing),
+		subscribers: make(map[*Subscription]bool),
+		stats:       Stats{Limit: config.PcktLimit},
+	}
+	events := config.Events
+	if len(events) == 0 {
+		events = AllEvents()
+	}
+	for _, event := range events {
+		m.events[event] = true
+	}
+	return m, nil
+}
+
+// Start starts reading of events
+func (m *Monitor) Start() {
+	m.wg.Add(2)
+	go m.readEvents()
+	go m.writeEvents()
+	log.Info().Msgf("monitor started, storage: %s events: %v", m.config.Storage, m.getEvents())
+}
+
+// Stop stops reading of events, closes pcap files and subscriptions
+func (m *Monitor) Stop() {
+	m.mu.Lock()
+	if m.stopped {
+		m.mu.Unlock()
+		return
+	}
+	m.stopped = true
+	m.mu.Unlock()
+
+	m.reader.Close()
+	m.wg.Wait()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for event, f := range m.files {
+		if err := f.Close(); err != nil {
+			log.Error().Msgf("can't close pcap file of %s, error: %v", event, err)
+		}
+	}
+	for sub := range m.subscribers {
+		close(sub.packets)
+	}
+	m.subscribers = make(map[*Subscription]bool)
+	log.Info().Msgf("monitor stopped, %d packets written, %d lost", m.stats.Amount, m.stats.BufferFull)
+}
+
+// GetStats returns stats of the monitor
+func (m *Monitor) GetStats() Stats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stats
+}
+
+// SetEvents changes events which are read; events which are not read are dropped
+// right after they have been received from the balancer
+func (m *Monitor) SetEvents(events []EventId) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = make(map[EventId]bool)
+	for _, event := range events {
+		m.events[event] = true
+	}
+}
+
+func (m *Monitor) getEvents() []EventId {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var events []EventId
+	for _, event := range AllEvents() {
+		if m.events[event] {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// Subscribe returns subscription to packets of events (all if empty) which match
+// filter (all if nil) and such packets kept in Buffer storage. if subscriber does not
+// read packets fast enough and queueSize packets are pending, new packets are dropped
+func (m *Monitor) Subscribe(events []EventId, filter *Filter, queueSize int) (*Subscription, []*Packet, error) {
+	if len(events) == 0 {
+		events = AllEvents()
+	}
+	sub := &Subscription{
+		events:  make(map[EventId]bool),
+		filter:  filter,
+		packets: make(chan *Packet, queueSize),
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stopped {
+		return nil, nil, fmt.Errorf("monitor is stopped")
+	}
+	var backlog []*Packet
+	for _, event := range events {
+		sub.events[event] = true
+		if ring, exists := m.buffers[event]; exists {
+			for _, pkt := range ring.packets() {
+				if filter.Match(pkt) {
+					backlog = append(backlog, pkt)
+				}
+			}
+		}
+	}
+	m.subscribers[sub] = true
+	return sub, backlog, nil
+}
+
+// Unsubscribe cancels subscription and returns number of packets which have been
+// dropped because subscriber was slow
+func (m *Monitor) Unsubscribe(sub *Subscription) uint32 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.subscribers[sub] {
+		delete(m.subscribers, sub)
+		close(sub.packets)
+	}
+	return sub.dropped
+}
+
+func (m *Monitor) readEvents() {
+	defer m.wg.Done()
+	defer close(m.queue)
+	for {
+		record, err := m.reader.Read()
+		if errors.Is(err, perf.ErrClosed) {
+			return
+		}
+		if err != nil {
+			log.Error().Msgf("can't read event, error: %v", err)
+			continue
+		}
+		if record.LostSamples > 0 {
+			m.addBufferFull(uint32(record.LostSamples))
+			continue
+		}
+		pkt, err := parseSample(record.RawSample, m.config.SnapLen)
+		if err != nil {
+			log.Error().Msgf("can't parse event of cpu %d, error: %v", record.CPU, err)
+			continue
+		}
+		if !m.isEnabled(pkt.Event) {
+			continue
+		}
+		select {
+		case m.queue <- pkt:
+		default:
+			m.addBufferFull(1)
+		}
+	}
+}
+
+func (m *Monitor) writeEvents() {
+	defer m.wg.Done()
+	for pkt := range m.queue {
+		m.mu.Lock()
+		m.store(pkt)
+		for sub := range m.subscribers {
+			if !sub.events[pkt.Event] || !sub.filter.Match(pkt) {
+				continue
+			}
+			select {
+			case sub.packets <- pkt:
+			default:
+				sub.dropped++
+			}
+		}
+		m.mu.Unlock()
+	}
+}
+
+// store writes packet into storage. must be called with mu held
+func (m *Monitor) store(pkt *Packet) {
+	if m.config.Storage == Pipe {
+		return
+	}
+	if m.stats.Limit != 0 && m.stats.Amount >= m.stats.Limit {
+		return
+	}
+	switch m.config.Storage {
+	case File:
+		writer, err := m.getFileWriter(pkt.Event)
+		if err == nil {
+			err = writer.WritePacket(pkt)
+		}
+		if err != nil {
+			log.Error().Msgf("can't write packet of %s, error: %v", pkt.Event, err)
+			return
+		}
+	case Buffer:
+		ring, exists := m.buffers[pkt.Event]
+		if !exists {
+			ring = newPacketRing(m.config.BufferSize)
+			m.buffers[pkt.Event] = ring
+		}
+		ring.push(pkt)
+	}
+	m.stats.Amount++
+}
+
+func (m *Monitor) getFileWriter(event EventId) (*PcapWriter, error) {
+	if writer, exists := m.writers[event]; exists {
+		return writer, nil
+	}
+	f, err := os.Create(filepath.Join(m.config.Path, event.String()+".pcap"))
+	if err != nil {
+		return nil, err
+	}
+	m.files[event] = f
+	m.writers[event] = NewPcapWriter(f, m.config.SnapLen)
+	return m.writers[event], nil
+}
+
+func (m *Monitor) isEnabled(event EventId) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.events[event]
+}
+
+func (m *Monitor) addBufferFull(n uint32) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stats.BufferFull += n
+}
+
+// parseSample parses struct event_metadata and the packet which follows it
+func parseSample(sample []byte, snapLen uint32) (*Packet, error) {
+	if len(sample) < kEventMetadataSize {
+		return nil, fmt.Errorf("sample is too short: %d bytes", len(sample))
+	}
+	event := binary.LittleEndian.Uint32(sample[0:4])
+	pktSize := binary.LittleEndian.Uint32(sample[4:8])
+	dataLen := binary.LittleEndian.Uint32(sample[8:12])
+	data := sample[kEventMetadataSize:]
+	if uint32(len(data)) < dataLen {
+		return nil, fmt.Errorf("sample has %d bytes of packet, expected %d", len(data), dataLen)
+	}
+	data = data[:dataLen]
+	if snapLen != 0 && uint32(len(data)) > snapLen {
+		data = data[:snapLen]
+	}
+	return &Packet{
+		Event:     EventId(event),
+		Timestamp: time.Now(),
+		PktSize:   pktSize,
+		Data:      append([]byte(nil), data...),
+	}, nil
+}
+
+// packetRing keeps last packets which fit into size bytes
+type packetRing struct {
+	size  uint32
+	used  uint32
+	queue []*Packet
+}
+
+func newPacketRing(size uint32) *packetRing {
+	return &packetRing{size: size}
+}
+
+func (r *packetRing) push(pkt *Packet) {
+	pktLen := uint32(len(pkt.Data))
+	if pktLen > r.size {
+		return
+	}
+	for r.used+pktLen > r.size {
+		r.used -= uint32(len(r.queue[0].Data))
+		r.queue = r.queue[1:]
+	}
+	r.queue = append(r.queue, pkt)
+	r.used += pktLen
+}
+
+func (r *packetRing) packets() []*Packet {
+	return append([]*Packet(nil), r.queue...)
+}
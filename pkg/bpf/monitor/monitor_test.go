@@ -0,0 +1,190 @@
+package monitor
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/cilium/ebpf/perf"
+)
+
+// fakeReader returns records which have been put into it, as if they were read from perf buffer
+type fakeReader struct {
+	records chan perf.Record
+}
+
+func newFakeReader() *fakeReader {
+	return &fakeReader{records: make(chan perf.Record, 64)}
+}
+
+func (r *fakeReader) Read() (perf.Record, error) {
+	record, ok := <-r.records
+	if !ok {
+		return perf.Record{}, perf.ErrClosed
+	}
+	return record, nil
+}
+
+// Close is a no-op; samples are read until the test closes records
+func (r *fakeReader) Close() error {
+	return nil
+}
+
+// testSample builds struct event_metadata followed by data
+func testSample(event EventId, pktSize uint32, data []byte) []byte {
+	sample := binary.LittleEndian.AppendUint32(nil, uint32(event))
+	sample = binary.LittleEndian.AppendUint32(sample, pktSize)
+	sample = binary.LittleEndian.AppendUint32(sample, uint32(len(data)))
+	return append(sample, data...)
+}
+
+// runMonitor feeds records to the monitor and stops it once they have been read
+func runMonitor(t *testing.T, config Config, records ...perf.Record) *Monitor {
+	t.Helper()
+	reader := newFakeReader()
+	config.Reader = reader
+	if config.Pages == 0 {
+		config.Pages, config.QueueSize = 1, 64
+	}
+	m, err := New(config)
+	if err != nil {
+		t.Fatalf("can't create monitor: %v", err)
+	}
+	m.Start()
+	for _, record := range records {
+		reader.records <- record
+	}
+	close(reader.records)
+	m.Stop()
+	return m
+}
+
+func TestParseSample(t *testing.T) {
+	data := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	for _, test := range []struct {
+		name    string
+		sample  []byte
+		snapLen uint32
+		data    []byte
+		err     bool
+	}{
+		{"whole packet", testSample(PacketTooBig, 1500, data), 0, data, false},
+		{"snap length", testSample(PacketTooBig, 1500, data), 5, data[:5], false},
+		{"snap length above data", testSample(PacketTooBig, 1500, data), 64, data, false},
+		{"padding after data", append(testSample(PacketTooBig, 1500, data), 0, 0, 0, 0), 0, data, false},
+		{"no data", testSample(PacketTooBig, 1500, nil), 0, nil, false},
+		{"short metadata", testSample(PacketTooBig, 1500, nil)[:kEventMetadataSize-1], 0, nil, true},
+		{"short data", testSample(PacketTooBig, 1500, data)[:kEventMetadataSize+7], 0, nil, true},
+	} {
+		pkt, err := parseSample(test.sample, test.snapLen)
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: sample is parsed, expected error", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: can't parse sample: %v", test.name, err)
+			continue
+		}
+		if pkt.Event != PacketTooBig || pkt.PktSize != 1500 || !reflect.DeepEqual(pkt.Data, test.data) {
+			t.Errorf("%s: packet %s %d %v, expected packet_toobig 1500 %v", test.name,
+				pkt.Event, pkt.PktSize, pkt.Data, test.data)
+		}
+	}
+}
+
+func TestPacketRing(t *testing.T) {
+	pkt := func(id byte, size int) *Packet {
+		data := make([]byte, size)
+		data[0] = id
+		return &Packet{Data: data}
+	}
+	for _, test := range []struct {
+		name    string
+		sizes   []int
+		packets []byte
+	}{
+		{"fits", []int{3, 3, 4}, []byte{0, 1, 2}},
+		{"evicts the oldest", []int{4, 4, 4}, []byte{1, 2}},
+		{"evicts until the packet fits", []int{2, 2, 2, 9}, []byte{3}},
+		{"drops packet larger than ring", []int{4, 11, 4}, []byte{0, 2}},
+		{"exactly the size", []int{10, 1}, []byte{1}},
+	} {
+		ring := newPacketRing(10)
+		for i, size := range test.sizes {
+			ring.push(pkt(byte(i), size))
+		}
+		var ids []byte
+		used := 0
+		for _, p := range ring.packets() {
+			ids = append(ids, p.Data[0])
+			used += len(p.Data)
+		}
+		if !reflect.DeepEqual(ids, test.packets) || uint32(used) != ring.used {
+			t.Errorf("%s: packets %v of %d bytes (%d used), expected %v", test.name, ids, used, ring.used,
+				test.packets)
+		}
+	}
+}
+
+func TestPcktLimit(t *testing.T) {
+	var records []perf.Record
+	for i := 0; i < 5; i++ {
+		records = append(records, perf.Record{RawSample: testSample(TcpNonSynLruMiss, 60, []byte{byte(i)})})
+	}
+	for _, storage := range []StorageFormat{Buffer, File} {
+		config := Config{Storage: storage, PcktLimit: 3, BufferSize: 1024, Path: t.TempDir(),
+			Events: AllEvents()}
+		m := runMonitor(t, config, records...)
+		if stats := m.GetStats(); stats.Amount != 3 || stats.Limit != 3 {
+			t.Errorf("%s: stats %+v, expected 3 of 5 packets to be stored", storage, stats)
+		}
+		var stored []*Packet
+		if storage == Buffer {
+			stored = m.buffers[TcpNonSynLruMiss].packets()
+		} else {
+			f, err := os.Open(filepath.Join(config.Path, "tcp_nonsyn_lrumiss.pcap"))
+			if err != nil {
+				t.Fatalf("can't open pcap file: %v", err)
+			}
+			reader, err := NewPcapReader(f)
+			for err == nil {
+				var pkt *Packet
+				if pkt, err = reader.ReadPacket(); err == nil {
+					stored = append(stored, pkt)
+				}
+			}
+			f.Close()
+		}
+		// storage stops at the limit, the first packets are kept
+		if len(stored) != 3 || stored[0].Data[0] != 0 || stored[2].Data[0] != 2 {
+			t.Errorf("%s: %d packets are stored, expected the first 3", storage, len(stored))
+		}
+	}
+}
+
+func TestReadEvents(t *testing.T) {
+	config := Config{Storage: Buffer, BufferSize: 1024, SnapLen: 2, Events: []EventId{PacketTooBig}}
+	m := runMonitor(t, config,
+		perf.Record{RawSample: testSample(PacketTooBig, 60, []byte{1, 2, 3})},
+		perf.Record{LostSamples: 7},
+		// disabled event and a broken sample are skipped
+		perf.Record{RawSample: testSample(TcpNonSynLruMiss, 60, []byte{4})},
+		perf.Record{RawSample: []byte{1}},
+		perf.Record{RawSample: testSample(PacketTooBig, 60, []byte{5})},
+	)
+	if stats := m.GetStats(); stats.Amount != 2 || stats.BufferFull != 7 {
+		t.Errorf("stats %+v, expected 2 stored packets and 7 lost", stats)
+	}
+	packets := m.buffers[PacketTooBig].packets()
+	if len(packets) != 2 || !reflect.DeepEqual(packets[0].Data, []byte{1, 2}) ||
+		!reflect.DeepEqual(packets[1].Data, []byte{5}) {
+		t.Errorf("packets %v, expected [1 2] truncated by snap length and [5]", packets)
+	}
+	if len(m.buffers) != 1 {
+		t.Errorf("packets of %d events are stored, expected packet_toobig only", len(m.buffers))
+	}
+}
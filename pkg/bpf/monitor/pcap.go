@@ -0,0 +1,190 @@
+package monitor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+)
+
+// pcap file format, see https://wiki.wireshark.org/Development/LibpcapFileFormat
+const (
+	kPcapMagic        uint32 = 0xa1b2c3d4
+	kPcapMagicNsec    uint32 = 0xa1b23c4d
+	kPcapVersionMajor uint16 = 2
+	kPcapVersionMinor uint16 = 4
+	kLinkTypeEthernet uint32 = 1
+
+	// DefaultSnapLen is snap length of pcap files, if it is not specified
+	DefaultSnapLen uint32 = 65535
+)
+
+type pcapFileHeader struct {
+	Magic        uint32
+	VersionMajor uint16
+	VersionMinor uint16
+	ThisZone     int32
+	SigFigs      uint32
+	SnapLen      uint32
+	LinkType     uint32
+}
+
+type pcapRecordHeader struct {
+	TsSec   uint32
+	TsUsec  uint32
+	InclLen uint32
+	OrigLen uint32
+}
+
+// Packet is a packet which has triggered introspection event
+type Packet struct {
+	Event     EventId
+	Timestamp time.Time
+	// size of the packet on the wire
+	PktSize uint32
+	// first bytes of the packet, up to snap length
+	Data []byte
+}
+
+// PcapWriter writes packets in pcap format. file header is written
+// before the first packet
+type PcapWriter struct {
+	w             io.Writer
+	byteOrder     binary.ByteOrder
+	snapLen       uint32
+	headerWritten bool
+}
+
+// NewPcapWriter creates pcap writer of little endian file; packets are truncated
+// to snapLen bytes
+func NewPcapWriter(w io.Writer, snapLen uint32) *PcapWriter {
+	return NewPcapWriterWithByteOrder(w, snapLen, binary.LittleEndian)
+}
+
+// NewPcapWriterWithByteOrder creates pcap writer of file in byteOrder, e.g. as written
+// by a big endian host
+func NewPcapWriterWithByteOrder(w io.Writer, snapLen uint32, byteOrder binary.ByteOrder) *PcapWriter {
+	if snapLen == 0 {
+		snapLen = DefaultSnapLen
+	}
+	return &PcapWriter{w: w, byteOrder: byteOrder, snapLen: snapLen}
+}
+
+// WriteHeader writes pcap file header, if it has not been written yet
+func (pw *PcapWriter) WriteHeader() error {
+	if pw.headerWritten {
+		return nil
+	}
+	header := pcapFileHeader{
+		Magic:        kPcapMagic,
+		VersionMajor: kPcapVersionMajor,
+		VersionMinor: kPcapVersionMinor,
+		SnapLen:      pw.snapLen,
+		LinkType:     kLinkTypeEthernet,
+	}
+	if err := binary.Write(pw.w, pw.byteOrder, &header); err != nil {
+		return err
+	}
+	pw.headerWritten = true
+	return nil
+}
+
+// WritePacket writes packet's record
+func (pw *PcapWriter) WritePacket(pkt *Packet) error {
+	if err := pw.WriteHeader(); err != nil {
+		return err
+	}
+	data := pkt.Data
+	if uint32(len(data)) > pw.snapLen {
+		data = data[:pw.snapLen]
+	}
+	origLen := pkt.PktSize
+	if origLen < uint32(len(data)) {
+		origLen = uint32(len(data))
+	}
+	record := pcapRecordHeader{
+		TsSec:   uint32(pkt.Timestamp.Unix()),
+		TsUsec:  uint32(pkt.Timestamp.Nanosecond() / int(time.Microsecond)),
+		InclLen: uint32(len(data)),
+		OrigLen: origLen,
+	}
+	if err := binary.Write(pw.w, pw.byteOrder, &record); err != nil {
+		return err
+	}
+	_, err := pw.w.Write(data)
+	return err
+}
+
+// PcapReader reads packets of pcap file with ethernet link type, in either byte order
+// and with either micro or nanosecond timestamps
+type PcapReader struct {
+	r         io.Reader
+	byteOrder binary.ByteOrder
+	nsec      bool
+	snapLen   uint32
+}
+
+// NewPcapReader reads pcap file header from r
+func NewPcapReader(r io.Reader) (*PcapReader, error) {
+	var buf [24]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return nil, fmt.Errorf("can't read pcap file header: %w", err)
+	}
+	pr := &PcapReader{r: r}
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		switch order.Uint32(buf[0:]) {
+		case kPcapMagic:
+			pr.byteOrder = order
+		case kPcapMagicNsec:
+			pr.byteOrder = order
+			pr.nsec = true
+		}
+	}
+	if pr.byteOrder == nil {
+		return nil, fmt.Errorf("not a pcap file, magic %#x", binary.LittleEndian.Uint32(buf[0:]))
+	}
+	var header pcapFileHeader
+	if err := binary.Read(bytes.NewReader(buf[:]), pr.byteOrder, &header); err != nil {
+		return nil, err
+	}
+	if header.LinkType != kLinkTypeEthernet {
+		return nil, fmt.Errorf("unsupported link type %d, only ethernet is supported", header.LinkType)
+	}
+	pr.snapLen = header.SnapLen
+	return pr, nil
+}
+
+// SnapLen returns snap length of the file
+func (pr *PcapReader) SnapLen() uint32 {
+	return pr.snapLen
+}
+
+// ReadPacket reads next packet's record, io.EOF is returned after the last one.
+// Event of read packets is not set
+func (pr *PcapReader) ReadPacket() (*Packet, error) {
+	var record pcapRecordHeader
+	if err := binary.Read(pr.r, pr.byteOrder, &record); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, fmt.Errorf("truncated pcap record header: %w", err)
+		}
+		return nil, err
+	}
+	// bound the allocation in case of a corrupted file
+	if pr.snapLen != 0 && record.InclLen > pr.snapLen {
+		return nil, fmt.Errorf("pcap record of %d bytes exceeds snap length %d", record.InclLen, pr.snapLen)
+	}
+	data := make([]byte, record.InclLen)
+	if _, err := io.ReadFull(pr.r, data); err != nil {
+		return nil, fmt.Errorf("truncated pcap record: %w", err)
+	}
+	frac := time.Duration(record.TsUsec) * time.Microsecond
+	if pr.nsec {
+		frac = time.Duration(record.TsUsec)
+	}
+	return &Packet{
+		Timestamp: time.Unix(int64(record.TsSec), int64(frac)),
+		PktSize:   record.OrigLen,
+		Data:      data,
+	}, nil
+}
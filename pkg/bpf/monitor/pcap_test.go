@@ -0,0 +1,89 @@
+package monitor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPcapRoundTrip(t *testing.T) {
+	packets := []*Packet{
+		{Timestamp: time.Unix(1700000000, 123456000), PktSize: 60, Data: testFrame("10.200.1.1", 80, 6, false)},
+		{Timestamp: time.Unix(1700000001, 999999000), PktSize: 1500, Data: testFrame("fc00:1::1", 443, 17, true)},
+		// truncated by snap length of the file
+		{Timestamp: time.Unix(1700000002, 0), PktSize: 9000, Data: make([]byte, 200)},
+	}
+	for _, test := range []struct {
+		name      string
+		byteOrder binary.ByteOrder
+		magic     []byte
+	}{
+		{"little endian", binary.LittleEndian, []byte{0xd4, 0xc3, 0xb2, 0xa1}},
+		{"big endian", binary.BigEndian, []byte{0xa1, 0xb2, 0xc3, 0xd4}},
+	} {
+		var buf bytes.Buffer
+		writer := NewPcapWriterWithByteOrder(&buf, 128, test.byteOrder)
+		for _, pkt := range packets {
+			if err := writer.WritePacket(pkt); err != nil {
+				t.Fatalf("%s: can't write packet: %v", test.name, err)
+			}
+		}
+		if !bytes.HasPrefix(buf.Bytes(), test.magic) {
+			t.Errorf("%s: file starts with % x, expected magic % x", test.name, buf.Bytes()[:4], test.magic)
+		}
+
+		reader, err := NewPcapReader(&buf)
+		if err != nil {
+			t.Fatalf("%s: can't read file header: %v", test.name, err)
+		}
+		if reader.SnapLen() != 128 {
+			t.Errorf("%s: snap length %d, expected 128", test.name, reader.SnapLen())
+		}
+		for i, expected := range packets {
+			pkt, err := reader.ReadPacket()
+			if err != nil {
+				t.Fatalf("%s: can't read packet %d: %v", test.name, i, err)
+			}
+			data := expected.Data
+			if len(data) > 128 {
+				data = data[:128]
+			}
+			if !pkt.Timestamp.Equal(expected.Timestamp) || pkt.PktSize != expected.PktSize ||
+				!reflect.DeepEqual(pkt.Data, data) {
+				t.Errorf("%s: packet %d is %v %d %x, expected %v %d %x", test.name, i,
+					pkt.Timestamp, pkt.PktSize, pkt.Data, expected.Timestamp, expected.PktSize, data)
+			}
+		}
+		if _, err = reader.ReadPacket(); err != io.EOF {
+			t.Errorf("%s: error %v after the last packet, expected EOF", test.name, err)
+		}
+	}
+}
+
+func TestPcapReaderErrors(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewPcapWriter(&buf, 64).WritePacket(&Packet{PktSize: 60, Data: make([]byte, 60)}); err != nil {
+		t.Fatal(err)
+	}
+	file := buf.Bytes()
+	for _, test := range []struct {
+		name string
+		data []byte
+	}{
+		{"short header", file[:10]},
+		{"bad magic", append([]byte{1, 2, 3, 4}, file[4:]...)},
+		{"truncated record header", file[:24+8]},
+		{"truncated record", file[:len(file)-1]},
+	} {
+		reader, err := NewPcapReader(bytes.NewReader(test.data))
+		if err == nil {
+			_, err = reader.ReadPacket()
+		}
+		if err == nil || err == io.EOF {
+			t.Errorf("%s: error %v, expected failure", test.name, err)
+		}
+	}
+}
@@ -1,47 +1,186 @@
 package balancer
 
 import (
+	"fmt"
+
 	"github.com/cilium/ebpf"
 
 	"github.com/cybwan/l4slb/pkg/bpf/adapter"
+	"github.com/cybwan/l4slb/pkg/logger"
 )
 
 // $BPF_CLANG and $BPF_CFLAGS are set by the Makefile.
 //go:generate go run github.com/cilium/ebpf/cmd/bpf2go -cc $BPF_CLANG -cflags $BPF_CFLAGS balancer ../../../../bpf/balancer_kern.c -- -I../../../../bpf/headers
 
+const (
+	kProgName = "balancer_ingress"
+
+	// compile time sizes from balancer_consts.h, used when object has been
+	// built without runtime sizes
+	kCompiledMaxVips  uint32 = 512
+	kCompiledMaxReals uint32 = 4096
+	kCompiledRingSize uint32 = 65537
+)
+
+// Config holds sizes of balancer's maps. they are written into the
+// CollectionSpec before loading, so they always agree with FlomeshLb
+type Config struct {
+	MaxVips    uint32
+	MaxReals   uint32
+	ChRingSize uint32
+	// number of positions of ch_rings, MaxVips * ChRingSize if 0
+	ChRingsSize uint32
+	// size of per cpu LRU (inner maps of lru_mapping)
+	LruSize       uint32
+	GlobalLruSize uint32
+	MaxLpmSrcSize uint32
+	MaxDecapDst   uint32
+}
+
+// Program is the balancer loaded into the kernel. every instance has its own
+// collection, so several balancers could be loaded by one process
+type Program struct {
+	coll *ebpf.Collection
+	// spec the collection has been loaded from, used as template of inner maps
+	spec *ebpf.CollectionSpec
+}
+
 var (
-	objs = balancerObjects{}
+	log = logger.New("balancer")
+
+	// maps of the balancer by their names in the object file. maps which
+	// depend on compile time features (e.g. nexthop_macs) are optional and
+	// only registered when the object has been built with them
+	knownMaps = map[string]adapter.BpfMapName{
+		"ch_rings":           adapter.ChRings,
+		"ctl_array":          adapter.CtlArray,
+		"event_pipe":         adapter.EventPipe,
+		"fallback_cache":     adapter.FallbackCache,
+		"fallback_glru":      adapter.FallbackGlru,
+		"flow_debug_maps":    adapter.FlowDebugMaps,
+		"global_lru_maps":    adapter.GlobalLruMaps,
+		"lpm_src_v4":         adapter.LpmSrcV4,
+		"lpm_src_v6":         adapter.LpmSrcV6,
+		"lru_mapping":        adapter.LruMapping,
+		"lru_miss_stats":     adapter.LruMissStats,
+		"lru_miss_stats_vip": adapter.LruMissStatsVip,
+		"nexthop_macs":       adapter.NexthopMacs,
+		"nexthop_stats":      adapter.NexthopStats,
+		"reals":              adapter.Reals,
+		"reals_stats":        adapter.RealsStats,
+		"server_id_map":      adapter.ServerIdMap,
+		"stats":              adapter.Stats,
+		"vip_map":            adapter.VipMap,
+		"vip_rings":          adapter.VipRings,
+	}
 )
 
-func Load() error {
+// Load loads the balancer with map sizes from config and registers its maps into registry
+func Load(config *Config, registry *adapter.Registry) (*Program, error) {
+	spec, err := loadBalancer()
+	if err != nil {
+		return nil, err
+	}
+	if err = configure(spec, config); err != nil {
+		return nil, err
+	}
+
 	// Load pre-compiled programs into the kernel.
-	if err := loadBalancerObjects(&objs, nil); err != nil {
+	coll, err := ebpf.NewCollection(spec)
+	if err != nil {
+		return nil, err
+	}
+	if coll.Programs[kProgName] == nil {
+		coll.Close()
+		return nil, fmt.Errorf("not found prog:%s", kProgName)
+	}
+
+	for name, bpfMap := range coll.Maps {
+		if mapName, exists := knownMaps[name]; exists {
+			if err = registry.Register(mapName, bpfMap); err != nil {
+				coll.Close()
+				return nil, err
+			}
+		}
+	}
+
+	return &Program{coll: coll, spec: spec}, nil
+}
+
+// LoadFake registers in-memory fakes of the balancer's maps, sized by config, into
+// registry. nothing is loaded into the kernel, so the controller could be run by tests
+func LoadFake(config *Config, registry *adapter.Registry) error {
+	spec, err := loadBalancer()
+	if err != nil {
 		return err
 	}
+	resize(spec, config)
+	return registry.RegisterFakeMaps(spec.Maps, knownMaps)
+}
 
-	adapter.BpfAddKnownMap(adapter.ChRings, objs.ChRings)
-	adapter.BpfAddKnownMap(adapter.CtlArray, objs.CtlArray)
-	adapter.BpfAddKnownMap(adapter.FallbackCache, objs.FallbackCache)
-	adapter.BpfAddKnownMap(adapter.FallbackGlru, objs.FallbackGlru)
-	adapter.BpfAddKnownMap(adapter.GlobalLruMaps, objs.GlobalLruMaps)
-	adapter.BpfAddKnownMap(adapter.LpmSrcV4, objs.LpmSrcV4)
-	adapter.BpfAddKnownMap(adapter.LpmSrcV6, objs.LpmSrcV6)
-	adapter.BpfAddKnownMap(adapter.LruMapping, objs.LruMapping)
-	adapter.BpfAddKnownMap(adapter.LruMissStats, objs.LruMissStats)
-	adapter.BpfAddKnownMap(adapter.LruMissStatsVip, objs.LruMissStatsVip)
-	adapter.BpfAddKnownMap(adapter.Reals, objs.Reals)
-	adapter.BpfAddKnownMap(adapter.RealsStats, objs.RealsStats)
-	adapter.BpfAddKnownMap(adapter.ServerIdMap, objs.ServerIdMap)
-	adapter.BpfAddKnownMap(adapter.Stats, objs.Stats)
-	adapter.BpfAddKnownMap(adapter.VipMap, objs.VipMap)
+func configure(spec *ebpf.CollectionSpec, config *Config) error {
+	resize(spec, config)
 
+	err := spec.RewriteConstants(map[string]interface{}{
+		"max_vips":  config.MaxVips,
+		"max_reals": config.MaxReals,
+		"ring_size": config.ChRingSize,
+	})
+	if err != nil {
+		if config.MaxVips != kCompiledMaxVips || config.MaxReals != kCompiledMaxReals ||
+			config.ChRingSize != kCompiledRingSize {
+			return fmt.Errorf("balancer has been built without runtime sizes, "+
+				"only max vips %d, max reals %d and ring size %d are supported: %w",
+				kCompiledMaxVips, kCompiledMaxReals, kCompiledRingSize, err)
+		}
+		log.Warn().Err(err).Msg("balancer has been built without runtime sizes, using compiled ones")
+	}
 	return nil
 }
 
-func Prog() *ebpf.Program {
-	return objs.BalancerIngress
+// resize sets max entries of maps, and of inner maps of maps-in-maps, as in config
+func resize(spec *ebpf.CollectionSpec, config *Config) {
+	maxEntries := map[string]uint32{
+		"vip_map":        config.MaxVips,
+		"ch_rings":       config.chRingsSize(),
+		"vip_rings":      config.MaxVips,
+		"reals":          config.MaxReals,
+		"reals_stats":    config.MaxReals,
+		"lru_miss_stats": config.MaxReals,
+		"stats":          config.MaxVips * 2,
+		"lpm_src_v4":     config.MaxLpmSrcSize,
+		"lpm_src_v6":     config.MaxLpmSrcSize,
+		"decap_dst":      config.MaxDecapDst,
+	}
+	for name, size := range maxEntries {
+		// maps of optional features could be missing
+		if mapSpec, exists := spec.Maps[name]; exists && size > 0 {
+			mapSpec.MaxEntries = size
+		}
+	}
+	innerMaxEntries := map[string]uint32{
+		"lru_mapping":     config.LruSize,
+		"global_lru_maps": config.GlobalLruSize,
+	}
+	for name, size := range innerMaxEntries {
+		if mapSpec, exists := spec.Maps[name]; exists && mapSpec.InnerMap != nil && size > 0 {
+			mapSpec.InnerMap.MaxEntries = size
+		}
+	}
+}
+
+// chRingsSize returns number of positions of ch_rings
+func (config *Config) chRingsSize() uint32 {
+	if config.ChRingsSize != 0 {
+		return config.ChRingsSize
+	}
+	return config.MaxVips * config.ChRingSize
+}
+
+func (p *Program) Prog() *ebpf.Program {
+	return p.coll.Programs[kProgName]
 }
 
-func Close() {
-	objs.Close()
+func (p *Program) Close() {
+	p.coll.Close()
 }
@@ -78,6 +78,11 @@ type balancerVipMeta struct {
 	VipNum uint32
 }
 
+type balancerVipRing struct {
+	Offset uint32
+	Size   uint32
+}
+
 // loadBalancer returns the embedded CollectionSpec for balancer.
 func loadBalancer() (*ebpf.CollectionSpec, error) {
 	reader := bytes.NewReader(_BalancerBytes)
@@ -136,12 +141,15 @@ type balancerMapSpecs struct {
 	LruMapping          *ebpf.MapSpec `ebpf:"lru_mapping"`
 	LruMissStats        *ebpf.MapSpec `ebpf:"lru_miss_stats"`
 	LruMissStatsVip     *ebpf.MapSpec `ebpf:"lru_miss_stats_vip"`
+	NexthopMacs         *ebpf.MapSpec `ebpf:"nexthop_macs"`
+	NexthopStats        *ebpf.MapSpec `ebpf:"nexthop_stats"`
 	QuicPacketsStatsMap *ebpf.MapSpec `ebpf:"quic_packets_stats_map"`
 	Reals               *ebpf.MapSpec `ebpf:"reals"`
 	RealsStats          *ebpf.MapSpec `ebpf:"reals_stats"`
 	ServerIdMap         *ebpf.MapSpec `ebpf:"server_id_map"`
 	Stats               *ebpf.MapSpec `ebpf:"stats"`
 	VipMap              *ebpf.MapSpec `ebpf:"vip_map"`
+	VipRings            *ebpf.MapSpec `ebpf:"vip_rings"`
 }
 
 // balancerObjects contains all objects after they have been loaded into the kernel.
@@ -173,12 +181,15 @@ type balancerMaps struct {
 	LruMapping          *ebpf.Map `ebpf:"lru_mapping"`
 	LruMissStats        *ebpf.Map `ebpf:"lru_miss_stats"`
 	LruMissStatsVip     *ebpf.Map `ebpf:"lru_miss_stats_vip"`
+	NexthopMacs         *ebpf.Map `ebpf:"nexthop_macs"`
+	NexthopStats        *ebpf.Map `ebpf:"nexthop_stats"`
 	QuicPacketsStatsMap *ebpf.Map `ebpf:"quic_packets_stats_map"`
 	Reals               *ebpf.Map `ebpf:"reals"`
 	RealsStats          *ebpf.Map `ebpf:"reals_stats"`
 	ServerIdMap         *ebpf.Map `ebpf:"server_id_map"`
 	Stats               *ebpf.Map `ebpf:"stats"`
 	VipMap              *ebpf.Map `ebpf:"vip_map"`
+	VipRings            *ebpf.Map `ebpf:"vip_rings"`
 }
 
 func (m *balancerMaps) Close() error {
@@ -193,12 +204,15 @@ func (m *balancerMaps) Close() error {
 		m.LruMapping,
 		m.LruMissStats,
 		m.LruMissStatsVip,
+		m.NexthopMacs,
+		m.NexthopStats,
 		m.QuicPacketsStatsMap,
 		m.Reals,
 		m.RealsStats,
 		m.ServerIdMap,
 		m.Stats,
 		m.VipMap,
+		m.VipRings,
 	)
 }
 
@@ -0,0 +1,32 @@
+package balancer
+
+import (
+	"fmt"
+
+	"github.com/cilium/ebpf"
+	"golang.org/x/sys/unix"
+
+	"github.com/cybwan/l4slb/pkg/bpf/adapter"
+)
+
+// NewInnerMap creates a map which could be inserted into map-in-map outer
+// (e.g. LruMapping), with maxEntries entries and allocated on numaNode.
+// negative numaNode lets kernel place the map
+func (p *Program) NewInnerMap(outer adapter.BpfMapName, maxEntries uint32, numaNode int) (*ebpf.Map, error) {
+	var outerSpec *ebpf.MapSpec
+	for name, mapName := range knownMaps {
+		if mapName == outer {
+			outerSpec = p.spec.Maps[name]
+		}
+	}
+	if outerSpec == nil || outerSpec.InnerMap == nil {
+		return nil, fmt.Errorf("not found map-in-map:%s", outer)
+	}
+	innerSpec := outerSpec.InnerMap.Copy()
+	innerSpec.MaxEntries = maxEntries
+	if numaNode >= 0 {
+		innerSpec.Flags |= unix.BPF_F_NUMA_NODE
+		innerSpec.NumaNode = uint32(numaNode)
+	}
+	return ebpf.NewMap(innerSpec)
+}
@@ -10,27 +10,42 @@ import (
 //
 //go:generate go run github.com/cilium/ebpf/cmd/bpf2go -cc $BPF_CLANG -cflags $BPF_CFLAGS healthchecking ../../../../../bpf/healthchecking_ipip_kern.c -- -I../../../../../bpf/headers
 
-var (
-	objs = healthcheckingObjects{}
-)
+// Program is the ipip healthchecking program loaded into the kernel. every instance has its own
+// objects, so the program could be loaded several times by one process
+type Program struct {
+	objs healthcheckingObjects
+}
 
-func Load() error {
+// Load loads the program and registers its maps into registry
+func Load(registry *adapter.Registry) (*Program, error) {
+	p := &Program{}
 	// Load pre-compiled programs into the kernel.
-	if err := loadHealthcheckingObjects(&objs, nil); err != nil {
-		return err
+	if err := loadHealthcheckingObjects(&p.objs, nil); err != nil {
+		return nil, err
 	}
 
-	adapter.BpfAddKnownMap(adapter.HcCtrlMap, objs.HcCtrlMap)
-	adapter.BpfAddKnownMap(adapter.HcRealsMap, objs.HcRealsMap)
-	adapter.BpfAddKnownMap(adapter.HcStatsMap, objs.HcStatsMap)
+	maps := []struct {
+		name   adapter.BpfMapName
+		bpfMap *ebpf.Map
+	}{
+		{adapter.HcCtrlMap, p.objs.HcCtrlMap},
+		{adapter.HcRealsMap, p.objs.HcRealsMap},
+		{adapter.HcStatsMap, p.objs.HcStatsMap},
+	}
+	for _, m := range maps {
+		if err := registry.Register(m.name, m.bpfMap); err != nil {
+			p.objs.Close()
+			return nil, err
+		}
+	}
 
-	return nil
+	return p, nil
 }
 
-func Prog() *ebpf.Program {
-	return objs.HealthcheckEncap
+func (p *Program) Prog() *ebpf.Program {
+	return p.objs.HealthcheckEncap
 }
 
-func Close() {
-	objs.Close()
+func (p *Program) Close() {
+	p.objs.Close()
 }
@@ -8,32 +8,64 @@ import (
 // $BPF_CLANG and $BPF_CFLAGS are set by the Makefile.
 //go:generate go run github.com/cilium/ebpf/cmd/bpf2go -cc $BPF_CLANG -cflags $BPF_CFLAGS healthchecking ../../../../../bpf/healthchecking_kern.c -- -I../../../../../bpf/headers
 
-var (
-	objs = healthcheckingObjects{}
-)
+// Program is the healthchecking program loaded into the kernel. every instance has its own
+// objects, so the program could be loaded several times by one process
+type Program struct {
+	objs healthcheckingObjects
+}
 
-func Load() error {
+// Load loads the program and registers its maps into registry
+func Load(registry *adapter.Registry) (*Program, error) {
+	p := &Program{}
 	// Load pre-compiled programs into the kernel.
-	if err := loadHealthcheckingObjects(&objs, nil); err != nil {
-		return err
+	if err := loadHealthcheckingObjects(&p.objs, nil); err != nil {
+		return nil, err
 	}
 
-	adapter.BpfAddKnownMap(adapter.HcCtrlMap, objs.HcCtrlMap)
-	adapter.BpfAddKnownMap(adapter.HcRealsMap, objs.HcRealsMap)
-	adapter.BpfAddKnownMap(adapter.HcStatsMap, objs.HcStatsMap)
+	maps := []struct {
+		name   adapter.BpfMapName
+		bpfMap *ebpf.Map
+	}{
+		{adapter.HcCtrlMap, p.objs.HcCtrlMap},
+		{adapter.HcRealsMap, p.objs.HcRealsMap},
+		{adapter.HcStatsMap, p.objs.HcStatsMap},
+		{adapter.HcKeyMap, p.objs.HcKeyMap},
+		{adapter.HcPcktMacs, p.objs.HcPcktMacs},
+		{adapter.HcPcktSrcsMap, p.objs.HcPcktSrcsMap},
+		{adapter.PerHckeyStats, p.objs.PerHckeyStats},
+	}
+	for _, m := range maps {
+		if err := registry.Register(m.name, m.bpfMap); err != nil {
+			p.objs.Close()
+			return nil, err
+		}
+	}
 
-	adapter.BpfAddKnownMap(adapter.HcKeyMap, objs.HcKeyMap)
-	adapter.BpfAddKnownMap(adapter.HcPcktMacs, objs.HcPcktMacs)
-	adapter.BpfAddKnownMap(adapter.HcPcktSrcsMap, objs.HcPcktSrcsMap)
-	adapter.BpfAddKnownMap(adapter.PerHckeyStats, objs.PerHckeyStats)
+	return p, nil
+}
 
-	return nil
+// LoadFake registers in-memory fakes of the program's maps into registry.
+// nothing is loaded into the kernel, so the controller could be run by tests
+func LoadFake(registry *adapter.Registry) error {
+	spec, err := loadHealthchecking()
+	if err != nil {
+		return err
+	}
+	return registry.RegisterFakeMaps(spec.Maps, map[string]adapter.BpfMapName{
+		"hc_ctrl_map":      adapter.HcCtrlMap,
+		"hc_reals_map":     adapter.HcRealsMap,
+		"hc_stats_map":     adapter.HcStatsMap,
+		"hc_key_map":       adapter.HcKeyMap,
+		"hc_pckt_macs":     adapter.HcPcktMacs,
+		"hc_pckt_srcs_map": adapter.HcPcktSrcsMap,
+		"per_hckey_stats":  adapter.PerHckeyStats,
+	})
 }
 
-func Prog() *ebpf.Program {
-	return objs.HealthcheckEncap
+func (p *Program) Prog() *ebpf.Program {
+	return p.objs.HealthcheckEncap
 }
 
-func Close() {
-	objs.Close()
+func (p *Program) Close() {
+	p.objs.Close()
 }
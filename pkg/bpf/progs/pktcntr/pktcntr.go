@@ -8,26 +8,41 @@ import (
 // $BPF_CLANG and $BPF_CFLAGS are set by the Makefile.
 //go:generate go run github.com/cilium/ebpf/cmd/bpf2go -cc $BPF_CLANG -cflags $BPF_CFLAGS pktcntr ../../../../bpf/xdp_pktcntr_kern.c -- -I../../../../bpf/headers
 
-var (
-	objs = pktcntrObjects{}
-)
+// Program is the packet counter loaded into the kernel. every instance has its own
+// objects, so the program could be loaded several times by one process
+type Program struct {
+	objs pktcntrObjects
+}
 
-func Load() error {
+// Load loads the program and registers its maps into registry
+func Load(registry *adapter.Registry) (*Program, error) {
+	p := &Program{}
 	// Load pre-compiled programs into the kernel.
-	if err := loadPktcntrObjects(&objs, nil); err != nil {
-		return err
+	if err := loadPktcntrObjects(&p.objs, nil); err != nil {
+		return nil, err
 	}
 
-	adapter.BpfAddKnownMap(adapter.PktcntrCntrsArray, objs.CntrsArray)
-	adapter.BpfAddKnownMap(adapter.PktcntrCtlArray, objs.CtlArray)
+	maps := []struct {
+		name   adapter.BpfMapName
+		bpfMap *ebpf.Map
+	}{
+		{adapter.PktcntrCntrsArray, p.objs.CntrsArray},
+		{adapter.PktcntrCtlArray, p.objs.CtlArray},
+	}
+	for _, m := range maps {
+		if err := registry.Register(m.name, m.bpfMap); err != nil {
+			p.objs.Close()
+			return nil, err
+		}
+	}
 
-	return nil
+	return p, nil
 }
 
-func Prog() *ebpf.Program {
-	return objs.Pktcntr
+func (p *Program) Prog() *ebpf.Program {
+	return p.objs.Pktcntr
 }
 
-func Close() {
-	objs.Close()
+func (p *Program) Close() {
+	p.objs.Close()
 }
@@ -4,7 +4,6 @@ import (
 	"github.com/cilium/ebpf"
 	"github.com/cilium/ebpf/link"
 	"github.com/cybwan/l4slb/pkg/bpf/adapter"
-	"github.com/cybwan/l4slb/pkg/bpf/progs/balancer"
 	"github.com/cybwan/l4slb/pkg/logger"
 	"net"
 )
@@ -12,32 +11,44 @@ import (
 // $BPF_CLANG and $BPF_CFLAGS are set by the Makefile.
 //go:generate go run github.com/cilium/ebpf/cmd/bpf2go -cc $BPF_CLANG -cflags $BPF_CFLAGS root ../../../../bpf/xdp_root_kern.c -- -I../../../../bpf/headers
 
-var (
-	objs = rootObjects{}
+// Program is the xdp root program loaded into the kernel. every instance has its own
+// objects, so the program could be loaded several times by one process
+type Program struct {
+	objs rootObjects
+}
 
+var (
 	log = logger.New("ebpf")
 )
 
-func Load() error {
+// Load loads the program and registers its maps into registry
+func Load(registry *adapter.Registry) (*Program, error) {
+	p := &Program{}
 	// Load pre-compiled programs into the kernel.
-	if err := loadRootObjects(&objs, nil); err != nil {
-		return err
+	if err := loadRootObjects(&p.objs, nil); err != nil {
+		return nil, err
 	}
 
-	adapter.BpfAddKnownMap(adapter.RootArray, objs.RootArray)
+	if err := registry.Register(adapter.RootArray, p.objs.RootArray); err != nil {
+		p.objs.Close()
+		return nil, err
+	}
 
-	return nil
+	return p, nil
 }
 
-func Prog() *ebpf.Program {
-	return objs.XdpRoot
+func (p *Program) Prog() *ebpf.Program {
+	return p.objs.XdpRoot
 }
 
-func Close() {
-	objs.Close()
+func (p *Program) Close() {
+	p.objs.Close()
 }
 
-func Attach(ifaceName string) func() {
+// Attach attaches xdp root program to ifaceName with XDP_FLAGS_* flags and registers
+// loaded balancer at position pos of root array. returned func detaches and closes
+// the root program, the balancer is closed by its owner
+func (p *Program) Attach(ifaceName string, flags uint32, pos uint32, balancer *ebpf.Program) func() {
 	// Look up the network interface by name.
 	iface, err := net.InterfaceByName(ifaceName)
 	if err != nil {
@@ -46,27 +57,21 @@ func Attach(ifaceName string) func() {
 
 	// Attach the program.
 	l, err := link.AttachXDP(link.XDPOptions{
-		Program:   objs.XdpRoot,
+		Program:   p.objs.XdpRoot,
 		Interface: iface.Index,
+		Flags:     link.XDPAttachFlags(flags),
 	})
 	if err != nil {
 		log.Fatal().Msgf("could not attach XDP program: %s", err)
 	}
 
-	if err = balancer.Load(); err == nil {
-		key := 1
-		err = objs.RootArray.Put(uint32(key), balancer.Prog())
-		if err != nil {
-			log.Fatal().Msgf("put root array map failed:%s", err)
-		}
-		log.Printf("Press Ctrl-C to exit and remove the program")
-	} else {
-		log.Fatal().Msgf("loading balancer objects: %s", err)
+	if err = p.objs.RootArray.Put(pos, balancer); err != nil {
+		log.Fatal().Msgf("put root array map failed:%s", err)
 	}
+	log.Printf("Press Ctrl-C to exit and remove the program")
 
 	return func() {
-		objs.Close()
+		p.objs.Close()
 		l.Close()
-		balancer.Close()
 	}
 }
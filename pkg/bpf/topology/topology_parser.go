@@ -3,14 +3,16 @@ package topology
 import (
 	"bytes"
 	"fmt"
-	"io/ioutil"
-	"log"
-	"runtime"
+	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
 )
 
+// paths are relative to the root of sys tree
 const (
-	TOPOLOGY_DIR   string = "/sys/devices/system/cpu/cpu"
+	TOPOLOGY_DIR   string = "sys/devices/system/cpu/cpu"
 	NUMA_NODE_FILE string = "/topology/physical_package_id"
 )
 
@@ -18,48 +20,84 @@ type CpuTopology struct {
 	Cpu2Numa map[int]int
 	Numa2Cpu map[int][]int
 	Ncpus    int
+	// sorted ids of cpus and numa nodes
+	Cpus  []int
+	Nodes []int
 }
 
-func getNumaNodeOfCpu(i int) int {
-	fileName := TOPOLOGY_DIR + strconv.Itoa(i) + NUMA_NODE_FILE
-	numa_bytes, err := ioutil.ReadFile(fileName)
+func getNumaNodeOfCpu(root string, i int) (int, error) {
+	fileName := filepath.Join(root, TOPOLOGY_DIR+strconv.Itoa(i)+NUMA_NODE_FILE)
+	numa_bytes, err := os.ReadFile(fileName)
 	if err != nil {
-		log.Fatal("cannot parse numa node id for file ", i, err)
+		return 0, fmt.Errorf("cannot read numa node id of cpu %d: %w", i, err)
 	}
 	numa_slice := bytes.Split(numa_bytes, []byte{'\n'})
 	if len(numa_slice) < 1 {
-		log.Fatal("invalid numa file format ", string(numa_bytes))
+		return 0, fmt.Errorf("invalid numa file format %q", string(numa_bytes))
 	}
-	numa, err := strconv.Atoi(string(numa_slice[0]))
+	numa, err := strconv.Atoi(strings.TrimSpace(string(numa_slice[0])))
 	if err != nil {
-		log.Fatal("cannot converit numa id to int ", err)
+		return 0, fmt.Errorf("cannot convert numa id of cpu %d to int: %w", i, err)
 	}
-	return numa
+	return numa, nil
 }
 
-func (topo *CpuTopology) GetNumaListForCpus(cpus []int) []int {
+// getCpus returns sorted ids of cpus which have topology info (i.e. are online)
+func getCpus(root string) ([]int, error) {
+	pattern := filepath.Join(root, TOPOLOGY_DIR+"*"+NUMA_NODE_FILE)
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	prefix := filepath.Join(root, TOPOLOGY_DIR)
+	var cpus []int
+	for _, file := range files {
+		id := strings.TrimSuffix(strings.TrimPrefix(file, prefix), NUMA_NODE_FILE)
+		if cpu, err := strconv.Atoi(id); err == nil {
+			cpus = append(cpus, cpu)
+		}
+	}
+	sort.Ints(cpus)
+	return cpus, nil
+}
+
+func (topo *CpuTopology) GetNumaListForCpus(cpus []int) ([]int, error) {
 	var numa_nodes []int
 	for _, cpu := range cpus {
 		if node, exists := topo.Cpu2Numa[cpu]; exists {
 			numa_nodes = append(numa_nodes, node)
 		} else {
-			log.Fatal("cant find numa mapping for cpu: ", cpu)
+			return nil, fmt.Errorf("cant find numa mapping for cpu: %d", cpu)
 		}
 	}
-	return numa_nodes
+	return numa_nodes, nil
 }
 
-func GetCpuTopology() CpuTopology {
-	ncpus := runtime.NumCPU()
-	fmt.Println("number of CPUs ", ncpus)
+// GetCpuTopology reads numa node of every cpu. root is the root of sys tree, "/" on the host
+func GetCpuTopology(root string) (CpuTopology, error) {
 	var topology CpuTopology
+	cpus, err := getCpus(root)
+	if err != nil {
+		return topology, err
+	}
+	if len(cpus) == 0 {
+		return topology, fmt.Errorf("no cpus found in %s", filepath.Join(root, TOPOLOGY_DIR+"*"))
+	}
 	topology.Cpu2Numa = make(map[int]int)
 	topology.Numa2Cpu = make(map[int][]int)
-	topology.Ncpus = ncpus
-	for i := 0; i < ncpus; i++ {
-		numa := getNumaNodeOfCpu(i)
+	topology.Ncpus = len(cpus)
+	topology.Cpus = cpus
+	for _, i := range cpus {
+		numa, err := getNumaNodeOfCpu(root, i)
+		if err != nil {
+			return topology, err
+		}
+		if _, exists := topology.Numa2Cpu[numa]; !exists {
+			topology.Nodes = append(topology.Nodes, numa)
+		}
 		topology.Cpu2Numa[i] = numa
 		topology.Numa2Cpu[numa] = append(topology.Numa2Cpu[numa], i)
 	}
-	return topology
+	sort.Ints(topology.Nodes)
+	return topology, nil
 }
@@ -0,0 +1,42 @@
+package topology
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetCpuTopology(t *testing.T) {
+	topo, err := GetCpuTopology("testdata/two_nodes")
+	if err != nil {
+		t.Fatalf("can't get topology: %v", err)
+	}
+	// cpu 3 is offline
+	cpus := []int{0, 1, 2, 4, 5, 6, 7, 8, 9, 10, 11}
+	if !reflect.DeepEqual(topo.Cpus, cpus) || topo.Ncpus != len(cpus) {
+		t.Errorf("cpus %v (%d), expected %v", topo.Cpus, topo.Ncpus, cpus)
+	}
+	if !reflect.DeepEqual(topo.Nodes, []int{0, 1}) {
+		t.Errorf("numa nodes %v, expected [0 1]", topo.Nodes)
+	}
+	if !reflect.DeepEqual(topo.Numa2Cpu[0], []int{0, 1, 2, 4, 5}) ||
+		!reflect.DeepEqual(topo.Numa2Cpu[1], []int{6, 7, 8, 9, 10, 11}) {
+		t.Errorf("cpus of numa nodes %v", topo.Numa2Cpu)
+	}
+
+	nodes, err := topo.GetNumaListForCpus([]int{11, 0, 6})
+	if err != nil || !reflect.DeepEqual(nodes, []int{1, 0, 1}) {
+		t.Errorf("numa nodes of cpus %v, %v, expected [1 0 1]", nodes, err)
+	}
+	if nodes, err = topo.GetNumaListForCpus([]int{3}); err == nil {
+		t.Errorf("numa node %v of offline cpu, expected error", nodes)
+	}
+}
+
+func TestGetCpuTopologyErrors(t *testing.T) {
+	if topo, err := GetCpuTopology("testdata/missing"); err == nil {
+		t.Errorf("topology %+v without sys tree, expected error", topo)
+	}
+	if topo, err := GetCpuTopology("testdata/broken"); err == nil {
+		t.Errorf("topology %+v with invalid numa node id, expected error", topo)
+	}
+}
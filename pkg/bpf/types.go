@@ -35,7 +35,63 @@ func (v *CtlValue) GetMac() []uint8 {
 }
 
 type FlowKey struct {
-	balancer.FlowKey_
+	src   [16]byte
+	dst   [16]byte
+	ports [2]uint16
+	proto uint8
+	_     [3]byte
+}
+
+func (v *FlowKey) SetSrc(ipaddr net.IP) {
+	setAddr(v.src[:], ipaddr)
+}
+
+func (v *FlowKey) SetDst(ipaddr net.IP) {
+	setAddr(v.dst[:], ipaddr)
+}
+
+func (v *FlowKey) SetPorts(srcPort, dstPort uint16) {
+	v.ports[0] = endian.BigEndian16(srcPort)
+	v.ports[1] = endian.BigEndian16(dstPort)
+}
+
+func (v *FlowKey) SetProto(proto uint8) {
+	v.proto = proto
+}
+
+// FlowDebugInfo is outer addresses of gue packet, recorded by flow debug
+type FlowDebugInfo struct {
+	L4Hop   [16]byte
+	ThisHop [16]byte
+}
+
+// GetL4Hop returns address of load balancer which has encapsulated the packet
+func (v *FlowDebugInfo) GetL4Hop() net.IP {
+	return getAddr(v.L4Hop[:])
+}
+
+// GetThisHop returns address the packet has been encapsulated to
+func (v *FlowDebugInfo) GetThisHop() net.IP {
+	return getAddr(v.ThisHop[:])
+}
+
+func setAddr(dst []byte, ipaddr net.IP) {
+	if ip4Addr := ipaddr.To4(); ip4Addr != nil {
+		copy(dst, ip4Addr)
+	} else {
+		copy(dst, ipaddr.To16())
+	}
+}
+
+// getAddr treats address as v4 one if only first 4 bytes are set,
+// as the family of the address is not stored
+func getAddr(src []byte) net.IP {
+	for _, b := range src[net.IPv4len:] {
+		if b != 0 {
+			return net.IP(append([]byte(nil), src...))
+		}
+	}
+	return net.IPv4(src[0], src[1], src[2], src[3])
 }
 
 type LbStats struct {
@@ -86,6 +142,12 @@ type VipMeta struct {
 	balancer.VipMeta_
 }
 
+// VipRing is where ring of the vip is placed in ch_rings, as struct vip_ring
+type VipRing struct {
+	Offset uint32
+	Size   uint32
+}
+
 type HcRealDefinition struct {
 	ipip.HcRealDefinition_
 	//kern.HcRealDefinition_
@@ -0,0 +1,125 @@
+package ch
+
+import (
+	"math"
+	"sort"
+)
+
+// EndpointShare is part of the ring an endpoint owns, versus part it should own by its weight
+type EndpointShare struct {
+	Num       uint32
+	Weight    uint32
+	Positions uint32
+	// fraction of ring's positions owned by the endpoint
+	Share float64
+	// weight of the endpoint over total weight
+	IdealShare float64
+}
+
+// RingStats describes how evenly the ring is spread between endpoints
+type RingStats struct {
+	// endpoints in order of their numbers
+	Shares []EndpointShare
+	// max over min of share / ideal share of endpoints with non zero weight;
+	// 1 for perfect balance, +Inf if some of them own no positions
+	Imbalance float64
+}
+
+// RingSimulation is outcome of replacing endpoints of the ring with proposed ones
+type RingSimulation struct {
+	RingSize uint32
+	// positions which would change owner
+	ChangedPositions uint32
+	Before           RingStats
+	After            RingStats
+}
+
+// ChangedFraction returns fraction of positions which would change owner,
+// i.e. of flows which would be moved to other endpoints if they are not in connection tables
+func (s *RingSimulation) ChangedFraction() float64 {
+	if s.RingSize == 0 {
+		return 0
+	}
+	return float64(s.ChangedPositions) / float64(s.RingSize)
+}
+
+// AnalyzeRing returns shares of endpoints in ring, which has been generated for them
+func AnalyzeRing(ring []int, endpoints []Endpoint) RingStats {
+	positions := make(map[int]uint32, len(endpoints))
+	for _, num := range ring {
+		positions[num]++
+	}
+	totalWeight := uint64(0)
+	for _, endpoint := range endpoints {
+		totalWeight += uint64(endpoint.Weight)
+	}
+
+	stats := RingStats{Shares: make([]EndpointShare, 0, len(endpoints))}
+	minRatio, maxRatio := math.Inf(1), 0.0
+	for _, endpoint := range endpoints {
+		share := EndpointShare{
+			Num:       endpoint.Num,
+			Weight:    endpoint.Weight,
+			Positions: positions[int(endpoint.Num)],
+		}
+		if len(ring) > 0 {
+			share.Share = float64(share.Positions) / float64(len(ring))
+		}
+		if totalWeight > 0 {
+			share.IdealShare = float64(endpoint.Weight) / float64(totalWeight)
+		}
+		if share.IdealShare > 0 {
+			ratio := share.Share / share.IdealShare
+			minRatio = math.Min(minRatio, ratio)
+			maxRatio = math.Max(maxRatio, ratio)
+		}
+		stats.Shares = append(stats.Shares, share)
+	}
+	sort.Slice(stats.Shares, func(i, j int) bool {
+		return stats.Shares[i].Num < stats.Shares[j].Num
+	})
+	switch {
+	case math.IsInf(minRatio, 1):
+		// no endpoint has weight
+		stats.Imbalance = 0
+	case minRatio == 0:
+		stats.Imbalance = math.Inf(1)
+	default:
+		stats.Imbalance = maxRatio / minRatio
+	}
+	return stats
+}
+
+// ChangedPositions returns number of positions whose owners differ in two rings of the same size
+func ChangedPositions(before, after []int) uint32 {
+	changed := uint32(0)
+	for i := 0; i < len(before) && i < len(after); i++ {
+		if before[i] != after[i] {
+			changed++
+		}
+	}
+	return changed
+}
+
+// Simulate generates rings of current and proposed endpoints by chash and compares them,
+// nothing is programmed
+func Simulate(chash ConsistentHash, ringSize uint32, current, proposed []Endpoint) *RingSimulation {
+	if ringSize == 0 {
+		ringSize = kDefaultChRingSize
+	}
+	return SimulateFrom(chash, chash.GenerateHashRing(current, ringSize), current, proposed)
+}
+
+// SimulateFrom generates ring of proposed endpoints by chash and compares it with ring before
+// of current endpoints, e.g. the one which is programmed. it may differ from the ring chash
+// generates for current endpoints, as reals of the ring could have been changed one by one
+func SimulateFrom(chash ConsistentHash, before []int, current, proposed []Endpoint) *RingSimulation {
+	ringSize := uint32(len(before))
+	after := chash.GenerateHashRing(proposed, ringSize)
+	return &RingSimulation{
+		RingSize:         ringSize,
+		ChangedPositions: ChangedPositions(before, after),
+		Before:           AnalyzeRing(before, current),
+		After:            AnalyzeRing(after, proposed),
+	}
+}
@@ -0,0 +1,105 @@
+package ch
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAnalyzeRing(t *testing.T) {
+	endpoints := []Endpoint{{Num: 7, Weight: 1}, {Num: 3, Weight: 3}}
+	stats := AnalyzeRing([]int{3, 3, 7, 3, 3, 3, 7, 3}, endpoints)
+	if len(stats.Shares) != 2 || stats.Shares[0].Num != 3 || stats.Shares[1].Num != 7 {
+		t.Fatalf("shares %+v, expected ones of endpoints 3 and 7", stats.Shares)
+	}
+	three, seven := stats.Shares[0], stats.Shares[1]
+	if three.Positions != 6 || three.Share != 0.75 || three.IdealShare != 0.75 {
+		t.Errorf("share of endpoint 3 %+v, expected 6 positions, share and ideal share 0.75", three)
+	}
+	if seven.Positions != 2 || seven.Share != 0.25 || seven.IdealShare != 0.25 {
+		t.Errorf("share of endpoint 7 %+v, expected 2 positions, share and ideal share 0.25", seven)
+	}
+	if stats.Imbalance != 1 {
+		t.Errorf("imbalance %f of perfectly balanced ring, expected 1", stats.Imbalance)
+	}
+
+	// endpoint 7 should own half of the ring
+	endpoints[1].Weight = 1
+	if stats = AnalyzeRing([]int{3, 3, 7, 3}, endpoints); stats.Imbalance != 3 {
+		t.Errorf("imbalance %f, expected 1.5 / 0.5", stats.Imbalance)
+	}
+	if stats = AnalyzeRing([]int{3, 3, 3, 3}, endpoints); !math.IsInf(stats.Imbalance, 1) {
+		t.Errorf("imbalance %f of ring without one of endpoints, expected +Inf", stats.Imbalance)
+	}
+	noWeights := []Endpoint{{Num: 1}, {Num: 2}}
+	if stats = AnalyzeRing([]int{1, 2}, noWeights); stats.Imbalance != 0 || stats.Shares[0].IdealShare != 0 {
+		t.Errorf("stats %+v of endpoints without weights, expected zero imbalance and ideal shares", stats)
+	}
+}
+
+func TestChangedPositions(t *testing.T) {
+	before := []int{1, 2, 3, 1, 2}
+	if changed := ChangedPositions(before, before); changed != 0 {
+		t.Errorf("%d positions of the same ring have changed", changed)
+	}
+	if changed := ChangedPositions(before, []int{1, 3, 3, 1, 1}); changed != 2 {
+		t.Errorf("%d positions have changed, expected 2", changed)
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	current := testEndpoints(5, 10)
+	proposed := append(append([]Endpoint(nil), current...), Endpoint{Num: 5, Weight: 10, Hash: MurmurHash3(5, 0, 0)})
+	const ringSize = 1009
+	for _, hfunc := range []HashFunction{Maglev, MaglevV2, Rendezvous, Ketama, Jump} {
+		chash := Make(hfunc)
+		// rings before and after the change, generated separately
+		before := Make(hfunc).GenerateHashRing(current, ringSize)
+		after := Make(hfunc).GenerateHashRing(proposed, ringSize)
+
+		sim := Simulate(chash, ringSize, current, proposed)
+		if sim.RingSize != ringSize || sim.ChangedPositions != ChangedPositions(before, after) {
+			t.Errorf("%s: simulation of %d positions changed %d, rings differ in %d", hfunc,
+				sim.RingSize, sim.ChangedPositions, ChangedPositions(before, after))
+		}
+		if fraction := float64(sim.ChangedPositions) / ringSize; sim.ChangedFraction() != fraction {
+			t.Errorf("%s: changed fraction %f, expected %f", hfunc, sim.ChangedFraction(), fraction)
+		}
+		if len(sim.Before.Shares) != len(current) || len(sim.After.Shares) != len(proposed) {
+			t.Errorf("%s: %d shares before and %d after, expected %d and %d", hfunc,
+				len(sim.Before.Shares), len(sim.After.Shares), len(current), len(proposed))
+		}
+		if newShare := sim.After.Shares[5]; newShare.Num != 5 || newShare.Positions == 0 {
+			t.Errorf("%s: new endpoint's share %+v", hfunc, newShare)
+		}
+	}
+
+	if sim := Simulate(Make(Maglev), 0, current, current); sim.RingSize != kDefaultChRingSize || sim.ChangedPositions != 0 {
+		t.Errorf("simulation without changes of default ring: %d of %d positions changed",
+			sim.ChangedPositions, sim.RingSize)
+	}
+	if fraction := (&RingSimulation{}).ChangedFraction(); fraction != 0 {
+		t.Errorf("changed fraction %f of empty ring", fraction)
+	}
+}
+
+func TestSimulateFrom(t *testing.T) {
+	current := testEndpoints(4, 10)
+	proposed := testEndpoints(5, 10)
+	const ringSize = 1009
+	chash := Make(Maglev)
+	// ring which is programmed differs from one generated for current endpoints
+	before := make([]int, ringSize)
+	for i := range before {
+		before[i] = int(current[0].Num)
+	}
+	after := chash.GenerateHashRing(proposed, ringSize)
+
+	sim := SimulateFrom(chash, before, current, proposed)
+	if sim.RingSize != ringSize || sim.ChangedPositions != ChangedPositions(before, after) {
+		t.Errorf("simulation of %d positions changed %d, rings differ in %d", sim.RingSize,
+			sim.ChangedPositions, ChangedPositions(before, after))
+	}
+	if share := sim.Before.Shares[0]; share.Num != current[0].Num || share.Positions != ringSize {
+		t.Errorf("share %+v of the programmed ring, expected all %d positions", share, ringSize)
+	}
+}
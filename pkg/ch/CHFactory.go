@@ -4,6 +4,12 @@ func Make(hfunc HashFunction) ConsistentHash {
 	switch hfunc {
 	case MaglevV2:
 		return new(maglevHashV2)
+	case Ketama:
+		return new(ketamaHash)
+	case Jump:
+		return new(jumpHash)
+	case Rendezvous:
+		return new(rendezvousHash)
 	case Maglev:
 		return new(maglevHashV1)
 	default:
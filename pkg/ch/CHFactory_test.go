@@ -0,0 +1,168 @@
+package ch
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+var kHashFunctions = []HashFunction{Maglev, MaglevV2, Rendezvous, Ketama, Jump}
+
+// weightedEndpoints returns n endpoints of weights 1-4
+func weightedEndpoints(n int) []Endpoint {
+	endpoints := testEndpoints(n, 0)
+	for i := range endpoints {
+		endpoints[i].Weight = uint32(i%4 + 1)
+	}
+	return endpoints
+}
+
+func TestRingIsFilled(t *testing.T) {
+	for _, hfunc := range kHashFunctions {
+		for _, n := range []int{1, 2, 5, 50} {
+			endpoints := weightedEndpoints(n)
+			nums := make(map[int]bool, n)
+			for _, endpoint := range endpoints {
+				nums[int(endpoint.Num)] = true
+			}
+			for pos, num := range Make(hfunc).GenerateHashRing(endpoints, 1009) {
+				if !nums[num] {
+					t.Fatalf("%s: position %d of ring of %d endpoints has %d", hfunc, pos, n, num)
+				}
+			}
+		}
+		for pos, num := range Make(hfunc).GenerateHashRing(nil, 13) {
+			if num != -1 {
+				t.Fatalf("%s: position %d of ring without endpoints has %d", hfunc, pos, num)
+			}
+		}
+	}
+}
+
+func TestShareIsProportionalToWeight(t *testing.T) {
+	// most deviation of share from the ideal one. maglev v1 resolves weights in its
+	// first pass only, as katran does, so its rings are not checked
+	tolerance := map[HashFunction]float64{
+		MaglevV2:   0.01,
+		Rendezvous: 0.1,
+		Jump:       0.1,
+		// continuum of 160 points per server is that uneven in libketama too
+		Ketama: 0.35,
+	}
+	endpoints := weightedEndpoints(20)
+	for hfunc, tol := range tolerance {
+		stats := AnalyzeRing(Make(hfunc).GenerateHashRing(endpoints, kTestRingSize), endpoints)
+		for _, share := range stats.Shares {
+			if deviation := math.Abs(share.Share/share.IdealShare - 1); deviation > tol {
+				t.Errorf("%s: endpoint %d of weight %d has share %.4f, ideal %.4f", hfunc,
+					share.Num, share.Weight, share.Share, share.IdealShare)
+			}
+		}
+	}
+}
+
+func TestRingIsDeterministic(t *testing.T) {
+	endpoints := weightedEndpoints(10)
+	for _, hfunc := range kHashFunctions {
+		chash := Make(hfunc)
+		first := chash.GenerateHashRing(endpoints, 1009)
+		// scratch buffers of the previous generation don't matter
+		chash.GenerateHashRing(weightedEndpoints(3), 13)
+		second := chash.GenerateHashRing(endpoints, 1009)
+		fresh := Make(hfunc).GenerateHashRing(endpoints, 1009)
+		into := make([]int, 1009)
+		Make(hfunc).GenerateHashRingInto(into, endpoints)
+		if ChangedPositions(first, second) != 0 || ChangedPositions(first, fresh) != 0 || ChangedPositions(first, into) != 0 {
+			t.Errorf("%s: rings of the same endpoints differ", hfunc)
+		}
+	}
+}
+
+func TestMinimalDisruption(t *testing.T) {
+	endpoints := testEndpoints(20, 10)
+	added := append(append([]Endpoint(nil), endpoints...), Endpoint{Num: 20, Weight: 10, Hash: MurmurHash3(20, 0, 0)})
+	// most fraction of the ring which may move between endpoints which are not changed.
+	// maglev moves a few positions between them, the others move none
+	tolerance := map[HashFunction]float64{
+		Maglev:     0.01,
+		MaglevV2:   0.01,
+		Rendezvous: 0,
+		Ketama:     0,
+		Jump:       0,
+	}
+	for hfunc, tol := range tolerance {
+		// jump keeps positions only when the last endpoint changes, see jumpHash
+		removed := 7
+		if hfunc == Jump {
+			removed = len(endpoints) - 1
+		}
+		proposed := append(append([]Endpoint(nil), endpoints[:removed]...), endpoints[removed+1:]...)
+
+		chash := Make(hfunc)
+		before := chash.GenerateHashRing(endpoints, kTestRingSize)
+		afterRemoval := chash.GenerateHashRing(proposed, kTestRingSize)
+		afterAddition := chash.GenerateHashRing(added, kTestRingSize)
+		var movedByRemoval, movedByAddition int
+		for pos := range before {
+			if before[pos] != removed && before[pos] != afterRemoval[pos] {
+				movedByRemoval++
+			}
+			if afterAddition[pos] != 20 && before[pos] != afterAddition[pos] {
+				movedByAddition++
+			}
+		}
+		if fraction := float64(movedByRemoval) / kTestRingSize; fraction > tol {
+			t.Errorf("%s: removal of an endpoint moved %.4f of the ring between the others", hfunc, fraction)
+		}
+		if fraction := float64(movedByAddition) / kTestRingSize; fraction > tol {
+			t.Errorf("%s: addition of an endpoint moved %.4f of the ring between the others", hfunc, fraction)
+		}
+	}
+}
+
+func TestEndpointsAreNotModified(t *testing.T) {
+	for _, hfunc := range kHashFunctions {
+		endpoints := weightedEndpoints(10)
+		endpoints[3].Weight = 0
+		endpoints[5].Key = "10.0.0.5"
+		original := append([]Endpoint(nil), endpoints...)
+		Make(hfunc).GenerateHashRing(endpoints, 1009)
+		Make(hfunc).GenerateHashRingInto(make([]int, 13), endpoints)
+		for i := range endpoints {
+			if endpoints[i] != original[i] {
+				t.Errorf("%s: endpoint %d is %+v after generation, was %+v", hfunc, i, endpoints[i], original[i])
+			}
+		}
+	}
+}
+
+func TestParseHashFunction(t *testing.T) {
+	for _, hfunc := range kHashFunctions {
+		if parsed, err := ParseHashFunction(hfunc.String()); err != nil || parsed != hfunc {
+			t.Errorf("%s is parsed as %s (%v)", hfunc, parsed, err)
+		}
+	}
+	if _, err := ParseHashFunction("crc32"); err == nil {
+		t.Error("unknown hash function is parsed")
+	}
+	if name := HashFunction(42).String(); name != "HashFunction(42)" {
+		t.Errorf("unknown hash function is named %q", name)
+	}
+}
+
+func BenchmarkGenerateHashRing(b *testing.B) {
+	for _, hfunc := range kHashFunctions {
+		for _, n := range []int{10, 100} {
+			endpoints := weightedEndpoints(n)
+			b.Run(fmt.Sprintf("%s/%d", hfunc, n), func(b *testing.B) {
+				chash := Make(hfunc)
+				ring := make([]int, kDefaultChRingSize)
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					chash.GenerateHashRingInto(ring, endpoints)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package ch
+
+const (
+	// buckets of an endpoint of average weight, weights are resolved to 1/kJumpBucketsPerEndpoint
+	kJumpBucketsPerEndpoint = 160
+)
+
+// jumpHash fills the ring by jump consistent hash of positions' hashes into buckets,
+// which are virtual nodes of endpoints in their order, numbered proportionally to weights.
+// jump hash only keeps keys in place when buckets are added or removed at the end, so
+// positions move least when added or removed endpoint has the highest Hash. buckets are
+// indexes, not endpoints: adding or removing any other endpoint shifts buckets of all
+// endpoints after it, and change of any weight changes number of buckets of every endpoint,
+// so a large part of the ring is reshuffled. it suits vips whose reals rarely change
+type jumpHash struct {
+	// scratch buffers, reused by generations of the ring
+	buckets []int
+	nodes   []uint32
+}
+
+func (j *jumpHash) GenerateHashRing(endpoints []Endpoint, ringSize uint32) []int {
+	return generateHashRing(j, endpoints, ringSize)
+}
+
+func (j *jumpHash) GenerateHashRingInto(result []int, endpoints []Endpoint) {
+	if !initRing(result, endpoints) {
+		return
+	}
+
+	j.nodes = virtualNodes(j.nodes, endpoints, kJumpBucketsPerEndpoint)
+	buckets := j.buckets[:0]
+	for i := range endpoints {
+		for n := uint32(0); n < j.nodes[i]; n++ {
+			buckets = append(buckets, i)
+		}
+	}
+	j.buckets = buckets
+
+	for pos := range result {
+		bucket := JumpConsistentHash(positionKey(uint32(pos)), int32(len(buckets)))
+		result[pos] = int(endpoints[buckets[bucket]].Num)
+	}
+}
+
+// JumpConsistentHash maps key to one of numBuckets buckets, see
+// "A Fast, Minimal Memory, Consistent Hash Algorithm" by Lamping and Veach
+func JumpConsistentHash(key uint64, numBuckets int32) int32 {
+	b, j := int64(-1), int64(0)
+	for j < int64(numBuckets) {
+		b = j
+		key = key*2862933555777941757 + 1
+		j = int64(float64(b+1) * (float64(int64(1)<<31) / float64((key>>33)+1)))
+	}
+	return int32(b)
+}
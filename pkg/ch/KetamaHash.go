@@ -0,0 +1,131 @@
+package ch
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"math"
+	"sort"
+	"strconv"
+)
+
+const (
+	// md5 digests of an endpoint of average weight, each gives kKetamaPointsPerDigest points
+	kKetamaDigestsPerEndpoint = 40
+	kKetamaPointsPerDigest    = 4
+)
+
+// ketamaHash fills the ring as libketama (and proxies which use it, e.g. twemproxy) builds
+// its continuum: endpoint of host key K gets floor(40 * n * weight / total) md5 digests of
+// "K-0", "K-1", ..., every one of them gives 4 points of 32 bit little endian words, and a key
+// goes to the first point at or after md5 of the key. every position of the ring is looked up
+// by its number in decimal, so ring has the same endpoints as the proxies would select for
+// these keys. endpoints with zero weight get no points, unless all of them have it
+type ketamaHash struct {
+	// scratch buffer, reused by generations of the ring
+	points []ketamaPoint
+}
+
+type ketamaPoint struct {
+	hash  uint32
+	index int
+}
+
+func (k *ketamaHash) GenerateHashRing(endpoints []Endpoint, ringSize uint32) []int {
+	return generateHashRing(k, endpoints, ringSize)
+}
+
+func (k *ketamaHash) GenerateHashRingInto(result []int, endpoints []Endpoint) {
+	if !initRing(result, endpoints) {
+		return
+	}
+
+	k.points = ketamaPoints(k.points[:0], endpoints)
+	points := k.points
+	var buf []byte
+	for pos := range result {
+		buf = strconv.AppendInt(buf[:0], int64(pos), 10)
+		key := ketamaHashKey(buf)
+		i := sort.Search(len(points), func(i int) bool {
+			return points[i].hash >= key
+		})
+		if i == len(points) {
+			i = 0
+		}
+		result[pos] = int(endpoints[points[i].index].Num)
+	}
+}
+
+// ketamaPoints appends points of endpoints on the continuum to points, sorted by their hashes.
+// points of equal hashes are ordered by host keys, libketama leaves their order to qsort
+func ketamaPoints(points []ketamaPoint, endpoints []Endpoint) []ketamaPoint {
+	totalWeight := uint64(0)
+	for _, endpoint := range endpoints {
+		totalWeight += uint64(endpoint.Weight)
+	}
+	var buf []byte
+	for i := range endpoints {
+		endpoint := &endpoints[i]
+		digests := uint64(kKetamaDigestsPerEndpoint)
+		if totalWeight != 0 {
+			// floorf(pct * 40.0 * (float)numservers) of libketama, in its precision
+			pct := float32(endpoint.Weight) / float32(totalWeight)
+			digests = uint64(math.Floor(float64(float32(
+				float64(pct) * kKetamaDigestsPerEndpoint * float64(float32(len(endpoints)))))))
+		}
+		key := endpoint.HostKey()
+		for d := uint64(0); d < digests; d++ {
+			buf = strconv.AppendUint(append(append(buf[:0], key...), '-'), d, 10)
+			digest := md5.Sum(buf)
+			for p := 0; p < kKetamaPointsPerDigest; p++ {
+				points = append(points, ketamaPoint{
+					hash:  binary.LittleEndian.Uint32(digest[p*4:]),
+					index: i,
+				})
+			}
+		}
+	}
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].hash != points[j].hash {
+			return points[i].hash < points[j].hash
+		}
+		return endpoints[points[i].index].HostKey() < endpoints[points[j].index].HostKey()
+	})
+	return points
+}
+
+// ketamaHashKey is libketama's ketama_hashi: first 32 bit little endian word of md5 of key
+func ketamaHashKey(key []byte) uint32 {
+	digest := md5.Sum(key)
+	return binary.LittleEndian.Uint32(digest[:4])
+}
+
+// positionKey returns 64 bit hash of ring's position, for functions which map keys to endpoints
+func positionKey(pos uint32) uint64 {
+	return MurmurHash3(uint64(pos), kHashSeed3, kHashSeed1)
+}
+
+// virtualNodes returns number of virtual nodes of every endpoint in nodes buffer, perEndpoint
+// for average weight and at least one for non zero weights. endpoints with zero weight get
+// none, unless all of them have it
+func virtualNodes(nodes []uint32, endpoints []Endpoint, perEndpoint uint32) []uint32 {
+	nodes = resizeUint32(nodes, len(endpoints))
+	totalWeight := uint64(0)
+	for _, endpoint := range endpoints {
+		totalWeight += uint64(endpoint.Weight)
+	}
+	for i, endpoint := range endpoints {
+		if totalWeight == 0 {
+			nodes[i] = perEndpoint
+			continue
+		}
+		if endpoint.Weight == 0 {
+			continue
+		}
+		n := (uint64(endpoint.Weight)*uint64(perEndpoint)*uint64(len(endpoints)) + totalWeight/2) / totalWeight
+		if n == 0 {
+			n = 1
+		}
+		nodes[i] = uint32(n)
+	}
+	return nodes
+}
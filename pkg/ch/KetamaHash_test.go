@@ -0,0 +1,113 @@
+package ch
+
+import (
+	"testing"
+)
+
+// vectors are computed by libketama's algorithm (ketama_create_continuum and ketama_get_server):
+// servers of the same addresses and weights select these servers for keys "0", "1", ..., "30"
+var kKetamaVectors = []struct {
+	name    string
+	keys    []string
+	weights []uint32
+	points  int
+	// first points of the continuum, and the servers they belong to
+	first      []uint32
+	firstOwner []int
+	ring       []int
+}{
+	{
+		name:       "equal weights",
+		keys:       []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"},
+		weights:    []uint32{1, 1, 1},
+		points:     480,
+		first:      []uint32{4635516, 11062586, 18272749},
+		firstOwner: []int{2, 2, 1},
+		ring: []int{0, 0, 2, 2, 0, 1, 1, 0, 0, 0, 1, 0, 2, 1, 0, 1,
+			2, 2, 0, 2, 1, 1, 2, 2, 2, 0, 2, 1, 0, 1, 0},
+	},
+	{
+		name:       "weights 100, 200, 300",
+		keys:       []string{"10.0.0.1", "10.0.0.2", "fc00::1"},
+		weights:    []uint32{100, 200, 300},
+		points:     480,
+		first:      []uint32{16110042, 18272749, 36885389},
+		firstOwner: []int{2, 1, 1},
+		ring: []int{2, 2, 1, 1, 2, 2, 2, 2, 2, 0, 1, 2, 2, 1, 2, 2,
+			1, 2, 1, 2, 1, 1, 2, 1, 2, 1, 2, 1, 0, 2, 2},
+	},
+}
+
+func TestKetamaVectors(t *testing.T) {
+	for _, test := range kKetamaVectors {
+		endpoints := make([]Endpoint, len(test.keys))
+		for i := range endpoints {
+			// numbers and hashes differ from order of the servers, ketama only uses keys
+			endpoints[i] = Endpoint{Num: uint32(10 + i), Weight: test.weights[i], Hash: uint64(100 - i), Key: test.keys[i]}
+		}
+		points := ketamaPoints(nil, endpoints)
+		if len(points) != test.points {
+			t.Errorf("%s: %d points on the continuum, expected %d", test.name, len(points), test.points)
+		}
+		for i, hash := range test.first {
+			if points[i].hash != hash || points[i].index != test.firstOwner[i] {
+				t.Errorf("%s: point %d is %d of server %d, expected %d of %d",
+					test.name, i, points[i].hash, points[i].index, hash, test.firstOwner[i])
+			}
+		}
+		ring := Make(Ketama).GenerateHashRing(endpoints, uint32(len(test.ring)))
+		for pos, server := range test.ring {
+			if ring[pos] != int(endpoints[server].Num) {
+				t.Errorf("%s: position %d belongs to %d, expected %d of server %s",
+					test.name, pos, ring[pos], endpoints[server].Num, test.keys[server])
+			}
+		}
+	}
+}
+
+func TestKetamaHostKey(t *testing.T) {
+	named := Endpoint{Hash: 42, Key: "10.0.0.1"}
+	unnamed := Endpoint{Hash: 42}
+	if named.HostKey() != "10.0.0.1" || unnamed.HostKey() != "42" {
+		t.Errorf("host keys %q and %q, expected \"10.0.0.1\" and \"42\"", named.HostKey(), unnamed.HostKey())
+	}
+}
+
+func TestKetamaWeights(t *testing.T) {
+	endpoints := testEndpoints(10, 0)
+	for i := range endpoints {
+		endpoints[i].Weight = uint32(i%2 + 1)
+	}
+	endpoints[3].Weight = 0
+	stats := AnalyzeRing(Make(Ketama).GenerateHashRing(endpoints, kTestRingSize), endpoints)
+	for _, share := range stats.Shares {
+		if share.Weight == 0 && share.Positions != 0 {
+			t.Errorf("endpoint %d of zero weight owns %d positions", share.Num, share.Positions)
+		}
+	}
+	// 160 points of every server keep its share within ~10% of the ideal one
+	if stats.Imbalance > 1.3 {
+		t.Errorf("imbalance of ketama ring %.3f", stats.Imbalance)
+	}
+}
+
+func TestJumpDisruption(t *testing.T) {
+	endpoints := testEndpoints(10, 10)
+	jump := Make(Jump)
+	before := jump.GenerateHashRing(endpoints, kTestRingSize)
+
+	// removal of the last endpoint only moves its positions
+	last := len(endpoints) - 1
+	after := jump.GenerateHashRing(endpoints[:last], kTestRingSize)
+	for pos := range before {
+		if before[pos] != last && before[pos] != after[pos] {
+			t.Fatalf("removal of the last endpoint moved position %d from %d to %d", pos, before[pos], after[pos])
+		}
+	}
+
+	// removal of the first one reshuffles the ring, as documented
+	sim := Simulate(jump, kTestRingSize, endpoints, endpoints[1:])
+	if sim.ChangedFraction() < 0.5 {
+		t.Errorf("removal of the first endpoint changed %.4f of the ring, expected most of it", sim.ChangedFraction())
+	}
+}
@@ -8,6 +8,10 @@ const (
 )
 
 type maglevBase struct {
+	// scratch buffers, reused by generations of the ring
+	permutation []uint32
+	next        []uint32
+	weights     []uint32
 }
 
 func (m *maglevBase) genMaglevPermutation(permutation []uint32, endpoint Endpoint, pos int, ringSize uint32) {
@@ -18,3 +22,27 @@ func (m *maglevBase) genMaglevPermutation(permutation []uint32, endpoint Endpoin
 	permutation[2*pos] = offset
 	permutation[2*pos+1] = skip
 }
+
+// prepare generates permutations of endpoints and returns them with zeroed
+// per endpoint counters of the next position and of weight
+func (m *maglevBase) prepare(endpoints []Endpoint, ringSize uint32) (permutation, next, weights []uint32) {
+	m.permutation = resizeUint32(m.permutation, len(endpoints)*2)
+	m.next = resizeUint32(m.next, len(endpoints))
+	m.weights = resizeUint32(m.weights, len(endpoints))
+	for i := 0; i < len(endpoints); i++ {
+		m.genMaglevPermutation(m.permutation, endpoints[i], i, ringSize)
+	}
+	return m.permutation, m.next, m.weights
+}
+
+// resizeUint32 returns zeroed buf of n elements, reallocated only if it is too small
+func resizeUint32(buf []uint32, n int) []uint32 {
+	if cap(buf) < n {
+		return make([]uint32, n)
+	}
+	buf = buf[:n]
+	for i := range buf {
+		buf[i] = 0
+	}
+	return buf
+}
@@ -0,0 +1,71 @@
+package ch
+
+import (
+	"testing"
+)
+
+// indexEndpoints returns endpoints whose number and hash are their index
+func indexEndpoints(n int, weight func(i int) uint32) []Endpoint {
+	endpoints := make([]Endpoint, n)
+	for i := range endpoints {
+		endpoints[i] = Endpoint{Num: uint32(i), Weight: weight(i), Hash: uint64(i)}
+	}
+	return endpoints
+}
+
+// TestMaglevRingSnapshot pins rings of 31 positions which MaglevHash and MaglevHashV2
+// generate, so a change of generation, which would move flows of programmed vips, is noticed.
+// rings are a snapshot of this implementation, not vectors of another one
+func TestMaglevRingSnapshot(t *testing.T) {
+	same := indexEndpoints(5, func(int) uint32 { return 1 })
+	weighted := indexEndpoints(5, func(i int) uint32 { return uint32(i + 1) })
+	for _, test := range []struct {
+		name      string
+		hfunc     HashFunction
+		endpoints []Endpoint
+		ring      []int
+	}{
+		{"v1 same weights", Maglev, same, []int{0, 1, 0, 1, 2, 3, 3, 1, 3, 2, 1, 3, 0, 2, 4, 0,
+			2, 1, 4, 4, 1, 4, 4, 3, 2, 3, 0, 4, 2, 0, 0}},
+		{"v1 weights 1-5", Maglev, weighted, []int{0, 1, 0, 1, 2, 2, 4, 1, 3, 2, 1, 3, 0, 2, 4, 0,
+			2, 3, 4, 4, 1, 4, 3, 3, 4, 3, 3, 4, 2, 4, 0}},
+		{"v2 weights 1-5", MaglevV2, weighted, []int{0, 1, 4, 3, 2, 3, 4, 4, 3, 4, 1, 3, 2, 2, 4, 1,
+			2, 3, 3, 4, 1, 4, 4, 3, 2, 3, 4, 4, 2, 4, 0}},
+	} {
+		ring := Make(test.hfunc).GenerateHashRing(test.endpoints, uint32(len(test.ring)))
+		for pos := range test.ring {
+			if ring[pos] != test.ring[pos] {
+				t.Errorf("%s: ring %v, expected %v", test.name, ring, test.ring)
+				break
+			}
+		}
+	}
+}
+
+// shares of 400 endpoints on ring of default size
+func TestMaglevBalance(t *testing.T) {
+	count := func(ring []int, n int) []int {
+		freq := make([]int, n)
+		for _, num := range ring {
+			freq[num]++
+		}
+		return freq
+	}
+	same := indexEndpoints(400, func(int) uint32 { return 1 })
+	for _, hfunc := range []HashFunction{Maglev, MaglevV2} {
+		for num, positions := range count(Make(hfunc).GenerateHashRing(same, 0), 400) {
+			if positions != 163 && positions != 164 {
+				t.Fatalf("%s: endpoint %d of the same weight owns %d positions, expected 163 or 164",
+					hfunc, num, positions)
+			}
+		}
+	}
+
+	// v2 gives endpoints of double weight double share
+	double := indexEndpoints(400, func(i int) uint32 { return uint32(i%2 + 1) })
+	for num, positions := range count(Make(MaglevV2).GenerateHashRing(double, 0), 400) {
+		if expected := 109 * (num%2 + 1); positions < expected || positions > expected+1 {
+			t.Fatalf("endpoint %d of weight %d owns %d positions, expected %d", num, num%2+1, positions, expected)
+		}
+	}
+}
@@ -5,24 +5,13 @@ type maglevHashV2 struct {
 }
 
 func (m *maglevHashV2) GenerateHashRing(endpoints []Endpoint, ringSize uint32) []int {
-	if ringSize == 0 {
-		ringSize = kDefaultChRingSize
-	}
-
-	result := make([]int, ringSize)
-	for i := uint32(0); i < ringSize; i++ {
-		result[i] = -1
-	}
-
-	if len(endpoints) == 0 {
-		return result
-	}
+	return generateHashRing(m, endpoints, ringSize)
+}
 
-	if len(endpoints) == 1 {
-		for i := uint32(0); i < ringSize; i++ {
-			result[i] = int(endpoints[0].Num)
-		}
-		return result
+func (m *maglevHashV2) GenerateHashRingInto(result []int, endpoints []Endpoint) {
+	ringSize := uint32(len(result))
+	if !initRing(result, endpoints) {
+		return
 	}
 
 	maxWeight := uint32(0)
@@ -33,13 +22,7 @@ func (m *maglevHashV2) GenerateHashRing(endpoints []Endpoint, ringSize uint32) [
 	}
 
 	runs := uint32(0)
-	permutation := make([]uint32, len(endpoints)*2)
-	next := make([]uint32, len(endpoints))
-	cumWeight := make([]uint32, len(endpoints))
-
-	for i := 0; i < len(endpoints); i++ {
-		m.genMaglevPermutation(permutation, endpoints[i], i, ringSize)
-	}
+	permutation, next, cumWeight := m.prepare(endpoints, ringSize)
 
 	for {
 		for i := 0; i < len(endpoints); i++ {
@@ -57,7 +40,7 @@ func (m *maglevHashV2) GenerateHashRing(endpoints []Endpoint, ringSize uint32) [
 				next[i] += 1
 				runs++
 				if runs == ringSize {
-					return result
+					return
 				}
 			}
 		}
@@ -0,0 +1,67 @@
+package ch
+
+import "math"
+
+// RendezvousMaxEndpoints is the most endpoints a ring of rendezvous hashing is generated for.
+// generation takes ringSize * len(endpoints) hashes and logarithms: ring of 65537 positions
+// takes ~10ms for 10 endpoints and ~75ms for 100 of them, so vips with more reals
+// should use maglev, whose generation barely depends on number of endpoints
+const RendezvousMaxEndpoints = 256
+
+// rendezvousHash fills the ring by weighted rendezvous (highest random weight) hashing:
+// every position goes to the endpoint with the highest score -weight / ln(h), where h is
+// hash of the endpoint and the position mapped into (0, 1). when an endpoint is removed
+// only its positions move, and they are spread over the rest proportionally to weights.
+// it suits vips with few reals, see RendezvousMaxEndpoints
+type rendezvousHash struct {
+	// scratch buffer, reused by generations of the ring
+	weights []float64
+}
+
+func (r *rendezvousHash) GenerateHashRing(endpoints []Endpoint, ringSize uint32) []int {
+	return generateHashRing(r, endpoints, ringSize)
+}
+
+func (r *rendezvousHash) GenerateHashRingInto(result []int, endpoints []Endpoint) {
+	if !initRing(result, endpoints) {
+		return
+	}
+
+	if cap(r.weights) < len(endpoints) {
+		r.weights = make([]float64, len(endpoints))
+	}
+	weights := r.weights[:len(endpoints)]
+	totalWeight := uint64(0)
+	for i, endpoint := range endpoints {
+		weights[i] = float64(endpoint.Weight)
+		totalWeight += uint64(endpoint.Weight)
+	}
+	// endpoints with zero weight never get positions, unless all of them have it
+	if totalWeight == 0 {
+		for i := range weights {
+			weights[i] = 1
+		}
+	}
+
+	for pos := range result {
+		best := -1
+		bestScore := 0.0
+		for i := range endpoints {
+			if weights[i] == 0 {
+				continue
+			}
+			h := MurmurHash3(endpoints[i].Hash, uint64(pos), kHashSeed1)
+			score := -weights[i] / math.Log(unitInterval(h))
+			if best < 0 || score > bestScore {
+				best = i
+				bestScore = score
+			}
+		}
+		result[pos] = int(endpoints[best].Num)
+	}
+}
+
+// unitInterval maps hash to (0, 1), zero and one excluded
+func unitInterval(h uint64) float64 {
+	return (float64(h>>11) + 0.5) / (1 << 53)
+}
@@ -0,0 +1,102 @@
+package ch
+
+import (
+	"testing"
+)
+
+const kTestRingSize = 65537
+
+// testEndpoints returns n endpoints of weight with well spread hashes, sorted by number
+func testEndpoints(n int, weight uint32) []Endpoint {
+	endpoints := make([]Endpoint, n)
+	for i := range endpoints {
+		endpoints[i] = Endpoint{Num: uint32(i), Weight: weight, Hash: MurmurHash3(uint64(i), 0, 0)}
+	}
+	return endpoints
+}
+
+func TestRendezvousBalance(t *testing.T) {
+	equal := testEndpoints(20, 10)
+	weighted := testEndpoints(20, 0)
+	for i := range weighted {
+		weighted[i].Weight = uint32(i%4 + 1)
+	}
+	for _, test := range []struct {
+		name      string
+		endpoints []Endpoint
+		// maglev whose balance rendezvous should be close to. v1 ignores weights after
+		// the first pass, so weighted rings are compared with v2
+		maglev HashFunction
+	}{
+		{"equal weights", equal, Maglev},
+		{"weights 1-4", weighted, MaglevV2},
+	} {
+		rendezvous := AnalyzeRing(Make(Rendezvous).GenerateHashRing(test.endpoints, kTestRingSize), test.endpoints)
+		maglev := AnalyzeRing(Make(test.maglev).GenerateHashRing(test.endpoints, kTestRingSize), test.endpoints)
+		if rendezvous.Imbalance > 1.1 || rendezvous.Imbalance > maglev.Imbalance+0.1 {
+			t.Errorf("%s: imbalance of rendezvous ring %.3f, of %s ring %.3f",
+				test.name, rendezvous.Imbalance, test.maglev, maglev.Imbalance)
+		}
+	}
+}
+
+func TestRendezvousDisruption(t *testing.T) {
+	endpoints := testEndpoints(20, 10)
+	rendezvous := Make(Rendezvous)
+	before := rendezvous.GenerateHashRing(endpoints, kTestRingSize)
+
+	for _, removed := range []int{0, 7, len(endpoints) - 1} {
+		proposed := append(append([]Endpoint(nil), endpoints[:removed]...), endpoints[removed+1:]...)
+		after := rendezvous.GenerateHashRing(proposed, kTestRingSize)
+		// only positions of the removed endpoint move
+		for pos := range before {
+			if before[pos] != removed && before[pos] != after[pos] {
+				t.Fatalf("removal of %d moved position %d from %d to %d", removed, pos, before[pos], after[pos])
+			}
+		}
+		sim := Simulate(rendezvous, kTestRingSize, endpoints, proposed)
+		maglev := Simulate(Make(Maglev), kTestRingSize, endpoints, proposed)
+		if sim.ChangedFraction() > maglev.ChangedFraction() {
+			t.Errorf("removal of %d changed %.4f of rendezvous ring, %.4f of maglev one",
+				removed, sim.ChangedFraction(), maglev.ChangedFraction())
+		}
+	}
+
+	// positions of a new endpoint are taken from the others, nothing else moves
+	added := append(append([]Endpoint(nil), endpoints...), Endpoint{Num: 20, Weight: 10, Hash: MurmurHash3(20, 0, 0)})
+	after := rendezvous.GenerateHashRing(added, kTestRingSize)
+	moved := 0
+	for pos := range before {
+		if before[pos] != after[pos] {
+			if after[pos] != 20 {
+				t.Fatalf("addition moved position %d from %d to %d", pos, before[pos], after[pos])
+			}
+			moved++
+		}
+	}
+	if share := float64(moved) / kTestRingSize; share < 0.04 || share > 0.055 {
+		t.Errorf("new endpoint took %.4f of the ring, expected about 1/21", share)
+	}
+}
+
+func TestRendezvousWeights(t *testing.T) {
+	endpoints := testEndpoints(3, 10)
+	endpoints[1].Weight = 0
+	ring := Make(Rendezvous).GenerateHashRing(endpoints, kTestRingSize)
+	for pos, num := range ring {
+		if num == 1 {
+			t.Fatalf("endpoint of zero weight owns position %d", pos)
+		}
+	}
+
+	// endpoints are used as is if all of them have zero weight
+	for i := range endpoints {
+		endpoints[i].Weight = 0
+	}
+	stats := AnalyzeRing(Make(Rendezvous).GenerateHashRing(endpoints, kTestRingSize), endpoints)
+	for _, share := range stats.Shares {
+		if share.Positions == 0 {
+			t.Errorf("endpoint %d owns no positions of ring of zero weight endpoints", share.Num)
+		}
+	}
+}
@@ -1,5 +1,10 @@
 package ch
 
+import (
+	"fmt"
+	"strconv"
+)
+
 const (
 	kDefaultChRingSize = uint32(65537)
 )
@@ -11,6 +16,17 @@ type Endpoint struct {
 	Num    uint32
 	Weight uint32
 	Hash   uint64
+	// name of the endpoint for hash functions which follow other implementations, e.g. ketama
+	// hashes it as proxies hash names of their servers. decimal Hash if empty
+	Key string
+}
+
+// HostKey returns Key of the endpoint, or its Hash in decimal if it has no key
+func (e *Endpoint) HostKey() string {
+	if e.Key != "" {
+		return e.Key
+	}
+	return strconv.FormatUint(e.Hash, 10)
 }
 
 type EndpointSlice []Endpoint
@@ -30,8 +46,67 @@ type HashFunction int
 const (
 	Maglev HashFunction = iota
 	MaglevV2
+	Rendezvous
+	// ring of libketama's continuum, to select the same reals as proxies which use it
+	Ketama
+	// jump consistent hash; change of a real other than the one with the highest Hash reshuffles the ring
+	Jump
 )
 
+var hashFunctionNames = map[HashFunction]string{
+	Maglev:     "maglev",
+	MaglevV2:   "maglev_v2",
+	Rendezvous: "rendezvous",
+	Ketama:     "ketama",
+	Jump:       "jump",
+}
+
+func (h HashFunction) String() string {
+	if name, exists := hashFunctionNames[h]; exists {
+		return name
+	}
+	return fmt.Sprintf("HashFunction(%d)", int(h))
+}
+
+// ParseHashFunction returns hash function by its name: "maglev", "maglev_v2", "rendezvous", "ketama" or "jump"
+func ParseHashFunction(name string) (HashFunction, error) {
+	for hfunc, hname := range hashFunctionNames {
+		if hname == name {
+			return hfunc, nil
+		}
+	}
+	return Maglev, fmt.Errorf("unknown hash function %q", name)
+}
+
+// ConsistentHash generates ch rings. implementations keep scratch buffers between
+// generations, so an instance must not be used by several goroutines at once
 type ConsistentHash interface {
+	// GenerateHashRing returns ring of ringSize positions with numbers of endpoints,
+	// -1 if there are no endpoints. endpoints are not modified, and the same endpoints in
+	// the same order always give the same ring; vips pass them sorted by Hash
 	GenerateHashRing(endpoints []Endpoint, ringSize uint32) []int
+	// GenerateHashRingInto generates ring of len(ring) positions into ring, as GenerateHashRing
+	GenerateHashRingInto(ring []int, endpoints []Endpoint)
+}
+
+// generateHashRing allocates ring of ringSize positions, kDefaultChRingSize if zero, and generates it
+func generateHashRing(chash ConsistentHash, endpoints []Endpoint, ringSize uint32) []int {
+	if ringSize == 0 {
+		ringSize = kDefaultChRingSize
+	}
+	ring := make([]int, ringSize)
+	chash.GenerateHashRingInto(ring, endpoints)
+	return ring
+}
+
+// initRing fills ring with -1, or with the only endpoint. returns false if there is nothing more to do
+func initRing(ring []int, endpoints []Endpoint) bool {
+	owner := -1
+	if len(endpoints) == 1 {
+		owner = int(endpoints[0].Num)
+	}
+	for i := range ring {
+		ring[i] = owner
+	}
+	return len(endpoints) > 1 && len(ring) > 0
 }
@@ -0,0 +1,20 @@
+package cli
+
+import (
+	"github.com/cybwan/l4slb/pkg/bpf/affinitize"
+)
+
+// ShowAffinitizeMapping prints which cpus irqs of intf would be bound to with
+// strategy. nothing is changed on the host; root is the root of proc and sys trees
+func ShowAffinitizeMapping(root string, intf string, strategy string) {
+	s, err := affinitize.ParseStrategy(strategy)
+	checkError(err)
+	mapping, err := affinitize.GetAffinitizeMapping(root, intf, s)
+	checkError(err)
+	for _, irq := range mapping.Irqs {
+		log.Info().Msgf("irq: %6d cpu: %4d numa node: %2d mask: %s",
+			irq.Irq, irq.Cpu, irq.NumaNode, irq.Mask)
+	}
+	log.Info().Msgf("forwarding cores: %v", mapping.ForwardingCores)
+	log.Info().Msgf("numa nodes: %v", mapping.NumaNodes)
+}
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/cybwan/l4slb/pkg/bpf/monitor"
+	"github.com/cybwan/l4slb/pkg/pb"
+)
+
+// CaptureToStdout is the output which makes Capture write pcap to stdout
+const CaptureToStdout = "-"
+
+// Capture streams packets which have triggered introspection events and writes them
+// in pcap format into output (stdout if it is "-"), until count packets have been
+// written (0 - unlimited) or it is interrupted. events are names of events to
+// capture, all if empty; if vip is specified (<addr>:<port>, port 0 matches any),
+// only packets sent to it are captured. progress is reported to stderr, so
+// stdout could be piped into tcpdump
+func (kc *L4SlbClient) Capture(events []string, vip string, proto string, output string, count int) {
+	request := pb.CaptureRequest{Events: events}
+	for _, event := range events {
+		_, err := monitor.ParseEventId(event)
+		checkError(err)
+	}
+	if vip != "" {
+		protocol := 0
+		if proto != "" {
+			protocol = parseProto(proto)
+		}
+		v := parseToVip(vip, protocol)
+		request.Vip = &v
+	}
+
+	var out io.Writer = os.Stdout
+	if output != CaptureToStdout {
+		f, err := os.Create(output)
+		checkError(err)
+		defer f.Close()
+		out = f
+	}
+	w := bufio.NewWriter(out)
+	writer := monitor.NewPcapWriter(w, monitor.DefaultSnapLen)
+	// header is written right away, so readers of the pipe could start
+	checkError(writer.WriteHeader())
+	checkError(w.Flush())
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	stream, err := kc.client.CapturePackets(ctx, &request)
+	checkError(err)
+
+	written := 0
+	for count == 0 || written < count {
+		pkt, err := stream.Recv()
+		if err == io.EOF || ctx.Err() != nil {
+			break
+		}
+		checkError(err)
+		event, _ := monitor.ParseEventId(pkt.Event)
+		err = writer.WritePacket(&monitor.Packet{
+			Event:     event,
+			Timestamp: time.Unix(0, pkt.Timestamp),
+			PktSize:   pkt.PktSize,
+			Data:      pkt.Data,
+		})
+		if err == nil {
+			err = w.Flush()
+		}
+		checkError(err)
+		written++
+	}
+	fmt.Fprintf(os.Stderr, "%d packets captured\n", written)
+}
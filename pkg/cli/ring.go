@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/cybwan/l4slb/pkg/pb"
+)
+
+// formats of DumpHashRing
+const (
+	RingDumpText = "text"
+	RingDumpCsv  = "csv"
+)
+
+// RingDumpToStdout is the output which makes DumpHashRing write csv to stdout
+const RingDumpToStdout = "-"
+
+// number of mismatched positions printed by text dump
+const kMaxMismatchesShown = 16
+
+// DumpHashRing prints ring of the vip. text format is a summary of positions of each
+// real; csv has a row per position, with real in ch_rings as well if kernel is set,
+// for offline analysis. rows are written into output, stdout if it is "-"
+func (kc *L4SlbClient) DumpHashRing(addr string, proto string, kernel bool, format string, output string) {
+	if format != RingDumpText && format != RingDumpCsv {
+		checkError(fmt.Errorf("unknown format %q, expected %s or %s", format, RingDumpText, RingDumpCsv))
+	}
+	vip := parseToVip(addr, parseProto(proto))
+	ring, err := kc.client.GetHashRing(context.Background(), &pb.HashRingRequest{Vip: &vip, ReadKernel: kernel})
+	checkError(err)
+
+	if format == RingDumpCsv {
+		var out io.Writer = os.Stdout
+		if output != RingDumpToStdout {
+			f, err := os.Create(output)
+			checkError(err)
+			defer f.Close()
+			out = f
+		}
+		checkError(writeHashRingCsv(out, ring))
+		return
+	}
+
+	log.Info().Msgf("ring size: %d offset: %d hash function: %s",
+		ring.RingSize, ring.RingOffset, ring.HashFunction)
+	counts := make(map[int32]int)
+	for _, num := range ring.Positions {
+		counts[num]++
+	}
+	nums := make([]int32, 0, len(counts))
+	for num := range counts {
+		nums = append(nums, num)
+	}
+	sort.Slice(nums, func(i, j int) bool {
+		return nums[i] < nums[j]
+	})
+	for _, num := range nums {
+		log.Info().Msgf("real: %-39s positions: %6d share: %6.2f%%",
+			realName(ring, num), counts[num], float64(counts[num])*100/float64(ring.RingSize))
+	}
+	if !ring.KernelRead {
+		return
+	}
+	log.Info().Msgf("ch_rings: %d of %d positions differ from the generated ring",
+		len(ring.Mismatched), ring.RingSize)
+	for i, pos := range ring.Mismatched {
+		if i == kMaxMismatchesShown {
+			log.Info().Msgf("... %d more, see csv dump", len(ring.Mismatched)-i)
+			break
+		}
+		log.Info().Msgf("position: %6d generated: %s ch_rings: %s", pos,
+			realName(ring, ring.Positions[pos]), realName(ring, int32(ring.KernelPositions[pos])))
+	}
+}
+
+// writeHashRingCsv writes a row per position of the ring: position, real's number and address
+// and, if ch_rings has been read, real's number and address there and whether they match
+func writeHashRingCsv(out io.Writer, ring *pb.HashRing) error {
+	w := csv.NewWriter(out)
+	header := []string{"position", "real", "address"}
+	if ring.KernelRead {
+		header = append(header, "kernel_real", "kernel_address", "match")
+	}
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	mismatched := make(map[uint32]bool, len(ring.Mismatched))
+	for _, pos := range ring.Mismatched {
+		mismatched[pos] = true
+	}
+	for pos, num := range ring.Positions {
+		row := []string{strconv.Itoa(pos), strconv.Itoa(int(num)), realAddress(ring, num)}
+		if ring.KernelRead {
+			kernelNum := ring.KernelPositions[pos]
+			row = append(row, strconv.FormatUint(uint64(kernelNum), 10), realAddress(ring, int32(kernelNum)),
+				strconv.FormatBool(!mismatched[uint32(pos)]))
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// realAddress returns address of the real with num, empty if there is no real or it is unknown
+func realAddress(ring *pb.HashRing, num int32) string {
+	if num < 0 {
+		return ""
+	}
+	return ring.Addresses[uint32(num)]
+}
+
+func realName(ring *pb.HashRing, num int32) string {
+	if num < 0 {
+		return "none"
+	}
+	if address := realAddress(ring, num); address != "" {
+		return fmt.Sprintf("%s (%d)", address, num)
+	}
+	return fmt.Sprintf("unknown (%d)", num)
+}
@@ -32,7 +32,8 @@ const (
 )
 
 var (
-	log = logger.New("l4slb-cli")
+	// stdout is used for output of commands, e.g. by capture for pcap
+	log = logger.NewStderr("l4slb-cli")
 
 	vipFlagTranslationTable = map[string]int32{
 		"NO_SPORT":   NO_SPORT,
@@ -83,6 +84,37 @@ func (kc *L4SlbClient) GetMac() {
 	log.Info().Msgf("Mac address is %v", mac.GetMac())
 }
 
+func (kc *L4SlbClient) AddNexthop(mac string) {
+	ok, err := kc.client.AddNexthop(context.Background(), &pb.Mac{Mac: mac})
+	checkError(err)
+	if !ok.Success {
+		log.Info().Msgf("error while adding next hop %v", mac)
+	}
+}
+
+func (kc *L4SlbClient) DelNexthop(mac string) {
+	ok, err := kc.client.DelNexthop(context.Background(), &pb.Mac{Mac: mac})
+	checkError(err)
+	if !ok.Success {
+		log.Info().Msgf("error while deleting next hop %v", mac)
+	}
+}
+
+func (kc *L4SlbClient) ListNexthops() {
+	nexthops, err := kc.client.GetNexthops(context.Background(), &pb.Empty{})
+	checkError(err)
+	if len(nexthops.Nexthops) == 0 {
+		log.Info().Msgf("no next hops, default router's mac is used")
+		return
+	}
+	for _, nh := range nexthops.Nexthops {
+		log.Info().Msgf("next hop: %20v pkts: %10v bytes: %10v",
+			nh.Mac,
+			nh.Stats.GetV1(),
+			nh.Stats.GetV2())
+	}
+}
+
 func parseToVip(addr string, proto int) pb.Vip {
 	var vip pb.Vip
 	vip.Protocol = int32(proto)
@@ -133,7 +165,7 @@ func parseToQuicReal(mapping string) pb.QuicReal {
 }
 
 func (kc *L4SlbClient) AddOrModifyService(
-	addr string, flagsString string, proto int, modify bool, setFlags bool) {
+	addr string, flagsString string, proto int, ringSize uint32, hashFunction string, modify bool, setFlags bool) {
 	log.Info().Msgf("Adding service: %v %v", addr, proto)
 	vip := parseToVip(addr, proto)
 	var flags int32
@@ -147,7 +179,7 @@ func (kc *L4SlbClient) AddOrModifyService(
 	if modify {
 		kc.UpdateService(vip, flags, MODIFY_VIP, setFlags)
 	} else {
-		kc.UpdateService(vip, flags, ADD_VIP, setFlags)
+		kc.addService(&vip, flags, ringSize, hashFunction)
 	}
 }
 
@@ -169,6 +201,16 @@ func (kc *L4SlbClient) UpdateReal(addr string, flags int32, setFlags bool) {
 	}
 }
 
+// addService adds vip with its own ring size and hash function, balancer's defaults if they are not set
+func (kc *L4SlbClient) addService(vip *pb.Vip, flags int32, ringSize uint32, hashFunction string) {
+	vMeta := pb.VipMeta{Vip: vip, Flags: flags, RingSize: ringSize, HashFunction: hashFunction}
+	ok, err := kc.client.AddVip(context.Background(), &vMeta)
+	checkError(err)
+	if ok.Success {
+		log.Info().Msgf("Vip added")
+	}
+}
+
 func (kc *L4SlbClient) UpdateService(
 	vip pb.Vip, flags int32, action int, setFlags bool) {
 	var vMeta pb.VipMeta
@@ -196,6 +238,27 @@ func (kc *L4SlbClient) UpdateService(
 	}
 }
 
+// ChangeHashFunction regenerates ring of the vip by hashFunction
+func (kc *L4SlbClient) ChangeHashFunction(addr string, proto int, hashFunction string) {
+	vip := parseToVip(addr, proto)
+	ok, err := kc.client.ChangeHashFunction(context.Background(),
+		&pb.VipHashFunction{Vip: &vip, HashFunction: hashFunction})
+	checkError(err)
+	if ok.Success {
+		log.Info().Msgf("Hash function of vip changed to %s", hashFunction)
+	}
+}
+
+// ShowVipInfo prints flags, hash function, ring size and number of reals of the vip
+func (kc *L4SlbClient) ShowVipInfo(addr string, proto int) {
+	vip := parseToVip(addr, proto)
+	info, err := kc.client.GetVipInfo(context.Background(), &vip)
+	checkError(err)
+	log.Info().Msgf("VIP: %20v Port: %6v Protocol: %v", vip.Address, vip.Port, vip.Protocol)
+	log.Info().Msgf("flags: %v hash function: %s ring size: %d reals: %d",
+		parseVipFlags(info.Flags), info.HashFunction, info.RingSize, info.Reals)
+}
+
 func (kc *L4SlbClient) UpdateServerForVip(
 	vipAddr string, proto int, realAddr string, weight int64, realFlags string, delete bool) {
 	vip := parseToVip(vipAddr, proto)
@@ -478,6 +541,174 @@ func (kc *L4SlbClient) ShowLruStats() {
 	}
 }
 
+func (kc *L4SlbClient) ShowLruMapsStats() {
+	stats, err := kc.client.GetLruMapsStats(context.Background(), &pb.Empty{})
+	checkError(err)
+	printLruMapsStats("connection table", stats.Lru)
+	printLruMapsStats("global connection table", stats.GlobalLru)
+}
+
+func printLruMapsStats(name string, stats []*pb.LruMapStats) {
+	for _, stat := range stats {
+		kind := "forwarding"
+		if stat.Fallback {
+			kind = "fallback"
+		}
+		usage := float64(0)
+		if stat.MaxEntries != 0 {
+			usage = float64(stat.CurrentEntries) / float64(stat.MaxEntries)
+		}
+		log.Info().Msgf("%s of cpu %3d (%s, numa node: %2d): %10d / %10d entries (%.2f%%)",
+			name, stat.Cpu, kind, stat.NumaNode, stat.CurrentEntries, stat.MaxEntries, usage*100)
+	}
+}
+
+func (kc *L4SlbClient) ShowMonitorStats() {
+	stats, err := kc.client.GetMonitorStats(context.Background(), &pb.Empty{})
+	checkError(err)
+	if !stats.Enabled {
+		log.Info().Msgf("introspection is disabled")
+		return
+	}
+	limit := "unlimited"
+	if stats.Limit != 0 {
+		limit = strconv.FormatUint(uint64(stats.Limit), 10)
+	}
+	log.Info().Msgf("monitor: packets written: %d limit: %s lost (buffer full): %d",
+		stats.Amount, limit, stats.BufferFull)
+}
+
+// ShowFlowDebugInfo prints what cpus know about the flow: which real it has been sent
+// to and, if flow debug is enabled, outer addresses of gue packets. src and dst are
+// in <addr>:<port> or [<addr>]:<port> format, proto is tcp, udp or protocol's number
+func (kc *L4SlbClient) ShowFlowDebugInfo(src string, dst string, proto string) {
+	protocol := parseProto(proto)
+	srcAddr := parseToVip(src, protocol)
+	dstAddr := parseToVip(dst, protocol)
+	flow := pb.Flow{
+		Src:      srcAddr.Address,
+		Dst:      dstAddr.Address,
+		SrcPort:  srcAddr.Port,
+		DstPort:  dstAddr.Port,
+		Protocol: int32(protocol),
+	}
+	infos, err := kc.client.GetFlowDebugInfo(context.Background(), &flow)
+	checkError(err)
+	if !infos.FlowDebug {
+		log.Info().Msgf("flow debug is disabled, only connection tables are looked up")
+	}
+	if len(infos.Infos) == 0 {
+		log.Info().Msgf("flow %s -> %s %s is not known to any cpu", src, dst, proto)
+		return
+	}
+	for _, info := range infos.Infos {
+		if info.InLru {
+			log.Info().Msgf("cpu %3d: connection table: real %s atime %d",
+				info.Cpu, info.Real, info.Atime)
+		}
+		if info.InGlobalLru {
+			log.Info().Msgf("cpu %3d: global connection table: real %s", info.Cpu, info.GlobalReal)
+		}
+		if info.HasRoute {
+			log.Info().Msgf("cpu %3d: encapsulated by %s to %s", info.Cpu, info.L4Hop, info.ThisHop)
+		}
+	}
+}
+
+// ShowControllerStats prints counters of the controller: failures, ch rings
+// generation and programming, and free space for vips, reals and hc keys
+func (kc *L4SlbClient) ShowControllerStats() {
+	stats, err := kc.client.GetControllerStats(context.Background(), &pb.Empty{})
+	checkError(err)
+	log.Info().Msgf("bpf failed calls: %d address validation failed: %d",
+		stats.BpfFailedCalls, stats.AddrValidationFailed)
+	avgGeneration := float64(0)
+	if stats.RingRecomputations != 0 {
+		avgGeneration = float64(stats.RingGenerationTimeUs) / float64(stats.RingRecomputations)
+	}
+	log.Info().Msgf("ring recomputations: %d total time: %dus avg: %.1fus",
+		stats.RingRecomputations, stats.RingGenerationTimeUs, avgGeneration)
+	avgBatch := float64(0)
+	if stats.RingBatchUpdates != 0 {
+		avgBatch = float64(stats.RingPositionsWritten) / float64(stats.RingBatchUpdates)
+	}
+	log.Info().Msgf("ring batch updates: %d positions written: %d avg batch: %.1f max batch: %d",
+		stats.RingBatchUpdates, stats.RingPositionsWritten, avgBatch, stats.MaxRingBatchUpdateSize)
+	log.Info().Msgf("free numbers: vips: %d reals: %d hc keys: %d ring positions: %d",
+		stats.FreeVipNums, stats.FreeRealNums, stats.FreeHcKeyNums, stats.FreeRingPositions)
+}
+
+// ShowBpfMapStats prints occupancy of all bpf maps of the balancer
+func (kc *L4SlbClient) ShowBpfMapStats() {
+	stats, err := kc.client.GetBpfMapStats(context.Background(), &pb.Empty{})
+	checkError(err)
+	for _, m := range stats.Maps {
+		usage := float64(0)
+		if m.MaxEntries != 0 {
+			usage = float64(m.CurrentEntries) / float64(m.MaxEntries) * 100
+		}
+		log.Info().Msgf("map: %-20s entries: %10d / %-10d %6.2f%%",
+			m.Name, m.CurrentEntries, m.MaxEntries, usage)
+	}
+}
+
+// SetLruMissStatsVip starts tracking of lru misses of the vip per real;
+// counters of the previously tracked vip are reset. proto is tcp, udp or protocol's number
+func (kc *L4SlbClient) SetLruMissStatsVip(addr string, proto string) {
+	vip := parseToVip(addr, parseProto(proto))
+	ok, err := kc.client.SetLruMissStatsVip(context.Background(), &vip)
+	checkError(err)
+	if !ok.Success {
+		log.Fatal().Msgf("can't track lru misses of vip %s, is it configured?", addr)
+	}
+	log.Info().Msgf("tracking lru misses of vip %s", addr)
+}
+
+// ClearLruMissStatsVip stops tracking of lru misses
+func (kc *L4SlbClient) ClearLruMissStatsVip() {
+	ok, err := kc.client.ClearLruMissStatsVip(context.Background(), &pb.Empty{})
+	checkError(err)
+	if !ok.Success {
+		log.Fatal().Msgf("can't stop tracking of lru misses")
+	}
+	log.Info().Msgf("lru misses are not tracked anymore")
+}
+
+// ShowLruMissStatsForVip prints lru misses of the tracked vip per real, most missed first
+func (kc *L4SlbClient) ShowLruMissStatsForVip() {
+	stats, err := kc.client.GetLruMissStatsForVip(context.Background(), &pb.Empty{})
+	checkError(err)
+	if !stats.Tracked {
+		log.Info().Msgf("lru misses are not tracked for any vip")
+		return
+	}
+	total := uint64(0)
+	for _, real := range stats.Reals {
+		total += real.Misses
+	}
+	log.Info().Msgf("lru misses of vip %s:%d proto %d: %d",
+		stats.Vip.Address, stats.Vip.Port, stats.Vip.Protocol, total)
+	for _, real := range stats.Reals {
+		share := float64(0)
+		if total != 0 {
+			share = float64(real.Misses) / float64(total) * 100
+		}
+		log.Info().Msgf("real: %-39s misses: %-10d %.2f%%", real.Address, real.Misses, share)
+	}
+}
+
+func parseProto(proto string) int {
+	switch strings.ToLower(proto) {
+	case "tcp":
+		return IPPROTO_TCP
+	case "udp":
+		return IPPROTO_UDP
+	}
+	protocol, err := strconv.ParseUint(proto, 10, 8)
+	checkError(err)
+	return int(protocol)
+}
+
 func (kc *L4SlbClient) ShowPerVipStats() {
 	vips := kc.GetAllVips()
 	statsMap := make(map[string]uint64)
@@ -525,3 +756,57 @@ func (kc *L4SlbClient) ShowIcmpStats() {
 		time.Sleep(1 * time.Second)
 	}
 }
+
+// parseRealWeights parses reals in <addr>[=<weight>] format, weight is 1 if omitted
+func parseRealWeights(reals []string) *pb.Reals {
+	var result pb.Reals
+	for _, r := range reals {
+		addr, weight := r, int64(1)
+		if i := strings.LastIndex(r, "="); i >= 0 {
+			var err error
+			addr = r[:i]
+			weight, err = strconv.ParseInt(r[i+1:], 10, 32)
+			checkError(err)
+		}
+		real := parseToReal(addr, weight, 0)
+		result.Reals = append(result.Reals, &real)
+	}
+	return &result
+}
+
+// SimulateRealsChange prints how many ring positions of the vip would change owner if reals
+// are added (or their weights changed), or deleted, and shares of reals before and after
+func (kc *L4SlbClient) SimulateRealsChange(addr string, proto string, reals []string, delete bool) {
+	vip := parseToVip(addr, parseProto(proto))
+	mReals := pb.ModifiedRealsForVip{Vip: &vip, Real: parseRealWeights(reals), Action: pb.Action_ADD}
+	if delete {
+		mReals.Action = pb.Action_DEL
+	}
+	sim, err := kc.client.SimulateRealsChange(context.Background(), &mReals)
+	checkError(err)
+	log.Info().Msgf("ring size: %d positions changing owner: %d (%.2f%%)",
+		sim.RingSize, sim.ChangedPositions, sim.ChangedFraction*100)
+	log.Info().Msgf("imbalance (max/min share to ideal share): before: %.3f after: %.3f",
+		sim.ImbalanceBefore, sim.ImbalanceAfter)
+
+	before := make(map[string]*pb.RealRingShare, len(sim.Before))
+	for _, share := range sim.Before {
+		before[share.Address] = share
+	}
+	remaining := make(map[string]bool, len(sim.After))
+	for _, after := range sim.After {
+		remaining[after.Address] = true
+		prev := before[after.Address]
+		if prev == nil {
+			prev = &pb.RealRingShare{}
+		}
+		log.Info().Msgf("real: %-39s weight: %6d -> %-6d share: %6.2f%% -> %6.2f%% ideal: %6.2f%%",
+			after.Address, prev.Weight, after.Weight, prev.Share*100, after.Share*100, after.IdealShare*100)
+	}
+	for _, prev := range sim.Before {
+		if !remaining[prev.Address] {
+			log.Info().Msgf("real: %-39s weight: %6d -> %-6d share: %6.2f%% -> %6.2f%% ideal: %6.2f%%",
+				prev.Address, prev.Weight, 0, prev.Share*100, 0.0, 0.0)
+		}
+	}
+}
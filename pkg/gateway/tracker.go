@@ -0,0 +1,386 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+// NewTracker creates a tracker for the default gateway of ifName
+func NewTracker(ifName string, onChange MacChangeFunc) *Tracker {
+	return &Tracker{
+		ifName:   ifName,
+		onChange: onChange,
+		gateways: make(map[int][]net.IP),
+		macs:     make(map[string]net.HardwareAddr),
+	}
+}
+
+// OnNexthopsChange sets callback which receives macs of all next hops of the
+// default route (e.g. of ECMP route through several ToRs). must be called before Start
+func (t *Tracker) OnNexthopsChange(onNexthops NexthopsChangeFunc) {
+	t.onNexthops = onNexthops
+}
+
+// Start resolves the current gateway's mac and keeps following route and neighbor
+// updates until ctx is done.
+func (t *Tracker) Start(ctx context.Context) error {
+	link, err := netlink.LinkByName(t.ifName)
+	if err != nil {
+		return fmt.Errorf("lookup network iface %q: %w", t.ifName, err)
+	}
+	t.ifIndex = link.Attrs().Index
+
+	routeCh := make(chan netlink.RouteUpdate)
+	neighCh := make(chan netlink.NeighUpdate)
+	if err = netlink.RouteSubscribe(routeCh, ctx.Done()); err != nil {
+		return fmt.Errorf("can't subscribe to route updates: %w", err)
+	}
+	if err = netlink.NeighSubscribe(neighCh, ctx.Done()); err != nil {
+		return fmt.Errorf("can't subscribe to neighbor updates: %w", err)
+	}
+
+	t.Resync()
+
+	go func() {
+		ticker := time.NewTicker(kResyncInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case update, ok := <-routeCh:
+				if !ok {
+					log.Warn().Msg("route updates subscription closed")
+					routeCh = nil
+					continue
+				}
+				if t.isDefaultRoute(&update.Route) {
+					t.Resync()
+				}
+			case update, ok := <-neighCh:
+				if !ok {
+					log.Warn().Msg("neighbor updates subscription closed")
+					neighCh = nil
+					continue
+				}
+				t.onNeighUpdate(&update)
+			case <-ticker.C:
+				t.Resync()
+			}
+		}
+	}()
+	return nil
+}
+
+// Resync re-reads default routes and neighbors of the tracked interface
+func (t *Tracker) Resync() {
+	for _, family := range []int{netlink.FAMILY_V4, netlink.FAMILY_V6} {
+		gws, err := t.getDefaultGateways(family)
+		if err != nil {
+			log.Error().Err(err).Msgf("can't get default gateway for %s", familyName(family))
+			continue
+		}
+		t.setGateways(family, gws)
+		for _, gw := range gws {
+			if mac := t.getNeighMac(gw, family); mac != nil {
+				t.setMac(family, gw, mac)
+			}
+		}
+	}
+	t.apply()
+}
+
+// GetMac returns the mac address currently programmed into the balancer
+func (t *Tracker) GetMac() net.HardwareAddr {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.current
+}
+
+// GetNexthops returns mac addresses of next hops currently programmed into the balancer
+func (t *Tracker) GetNexthops() []net.HardwareAddr {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	nexthops := make([]net.HardwareAddr, len(t.nexthops))
+	copy(nexthops, t.nexthops)
+	return nexthops
+}
+
+// Events returns the log of gateway mac changes, oldest first
+func (t *Tracker) Events() []Event {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	events := make([]Event, len(t.events))
+	copy(events, t.events)
+	return events
+}
+
+// GetEventsHandler returns an HTTP handler which serves the event log
+func (t *Tracker) GetEventsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if jsonEvents, err := json.Marshal(t.Events()); err != nil {
+			log.Error().Err(err).Msg("Error marshaling gateway events")
+		} else {
+			_, _ = w.Write(jsonEvents)
+		}
+	})
+}
+
+func (t *Tracker) isDefaultRoute(route *netlink.Route) bool {
+	if route.Table != 0 && route.Table != unix.RT_TABLE_MAIN {
+		return false
+	}
+	if route.Dst != nil {
+		if ones, _ := route.Dst.Mask.Size(); ones != 0 {
+			return false
+		}
+	}
+	if route.LinkIndex == t.ifIndex {
+		return true
+	}
+	for _, nh := range route.MultiPath {
+		if nh.LinkIndex == t.ifIndex {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *Tracker) getDefaultGateways(family int) ([]net.IP, error) {
+	routes, err := netlink.RouteListFiltered(family, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	var gws []net.IP
+	for i := range routes {
+		route := &routes[i]
+		if !t.isDefaultRoute(route) {
+			continue
+		}
+		if route.Gw != nil {
+			gws = appendIP(gws, route.Gw)
+		}
+		for _, nh := range route.MultiPath {
+			if nh.LinkIndex == t.ifIndex && nh.Gw != nil {
+				gws = appendIP(gws, nh.Gw)
+			}
+		}
+	}
+	return gws, nil
+}
+
+func (t *Tracker) getNeighMac(gw net.IP, family int) net.HardwareAddr {
+	if gw == nil {
+		return nil
+	}
+	neighs, err := netlink.NeighList(t.ifIndex, family)
+	if err != nil {
+		log.Error().Err(err).Msgf("can't list neighbors of %s", t.ifName)
+		return nil
+	}
+	for _, neigh := range neighs {
+		if neigh.IP.Equal(gw) && isUsable(neigh.State) && len(neigh.HardwareAddr) > 0 {
+			return neigh.HardwareAddr
+		}
+	}
+	// no (valid) neighbor entry yet. make kernel resolve it for us,
+	// neighbor notification will be received once it's done
+	t.probe(gw)
+	return nil
+}
+
+func (t *Tracker) onNeighUpdate(update *netlink.NeighUpdate) {
+	if update.LinkIndex != t.ifIndex {
+		return
+	}
+	t.mu.Lock()
+	var gw net.IP
+	for _, ip := range t.gateways[update.Family] {
+		if update.IP.Equal(ip) {
+			gw = ip
+		}
+	}
+	t.mu.Unlock()
+	if gw == nil {
+		return
+	}
+	if update.Type == unix.RTM_DELNEIGH || !isUsable(update.State) || len(update.HardwareAddr) == 0 {
+		// entry is gone or became invalid; keep last known mac and ask kernel to resolve again
+		t.probe(gw)
+		return
+	}
+	t.setMac(update.Family, gw, update.HardwareAddr)
+	t.apply()
+}
+
+func (t *Tracker) probe(gw net.IP) {
+	addr := &net.UDPAddr{IP: gw, Port: kDiscardPort}
+	if gw.IsLinkLocalUnicast() {
+		addr.Zone = t.ifName
+	}
+	conn, err := net.DialUDP("udp", nil, addr)
+	if err != nil {
+		log.Debug().Err(err).Msgf("can't probe gateway %s", gw)
+		return
+	}
+	defer conn.Close()
+	_, _ = conn.Write([]byte{0})
+}
+
+func (t *Tracker) setGateways(family int, gws []net.IP) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	prevGws := t.gateways[family]
+	if equalIPs(gws, prevGws) {
+		return
+	}
+	log.Info().Msgf("default %s gateways of %s: %v -> %v", familyName(family), t.ifName, prevGws, gws)
+	for _, gw := range prevGws {
+		if !containsIP(gws, gw) {
+			// gateway is gone. its mac will be resolved again if it comes back
+			delete(t.macs, gw.String())
+		}
+	}
+	t.gateways[family] = gws
+}
+
+func (t *Tracker) setMac(family int, gw net.IP, mac net.HardwareAddr) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	prevMac := t.macs[gw.String()]
+	if bytes.Equal(mac, prevMac) {
+		return
+	}
+	t.macs[gw.String()] = mac
+	t.addEvent(Event{
+		Time:    time.Now(),
+		Family:  familyName(family),
+		Gateway: gw,
+		OldMac:  prevMac.String(),
+		NewMac:  mac.String(),
+	})
+	log.Info().Msgf("mac of default %s gateway %s changed: %v -> %v", familyName(family), gw, prevMac, mac)
+}
+
+// apply programs macs of current gateways through callbacks if they have changed
+func (t *Tracker) apply() {
+	t.mu.Lock()
+	// v4 gateways take precedence, v6 ones are used on v6 only hosts
+	var nexthops []net.HardwareAddr
+	for _, family := range []int{netlink.FAMILY_V4, netlink.FAMILY_V6} {
+		for _, gw := range t.gateways[family] {
+			if mac := t.macs[gw.String()]; mac != nil && !containsMac(nexthops, mac) {
+				nexthops = append(nexthops, mac)
+			}
+		}
+		if len(nexthops) > 0 {
+			break
+		}
+	}
+	if len(nexthops) == 0 {
+		// nothing is resolved; keep last known macs
+		t.mu.Unlock()
+		return
+	}
+	effective := nexthops[0]
+	changeMac := !bytes.Equal(effective, t.current)
+	changeNexthops := t.onNexthops != nil && !equalMacs(nexthops, t.nexthops)
+	t.mu.Unlock()
+
+	// on failure current macs stay the same, so we will retry on next resync
+	if changeMac {
+		if t.onChange != nil && !t.onChange(effective) {
+			log.Error().Msgf("can't program new default gateway mac %v", effective)
+		} else {
+			t.mu.Lock()
+			t.current = effective
+			t.mu.Unlock()
+		}
+	}
+	if changeNexthops {
+		if !t.onNexthops(nexthops) {
+			log.Error().Msgf("can't program new next hops %v", nexthops)
+		} else {
+			t.mu.Lock()
+			t.nexthops = nexthops
+			t.mu.Unlock()
+		}
+	}
+}
+
+func (t *Tracker) addEvent(event Event) {
+	if len(t.events) == kMaxEvents {
+		copy(t.events, t.events[1:])
+		t.events = t.events[:kMaxEvents-1]
+	}
+	t.events = append(t.events, event)
+}
+
+func isUsable(state int) bool {
+	return state&(netlink.NUD_REACHABLE|netlink.NUD_STALE|netlink.NUD_DELAY|
+		netlink.NUD_PROBE|netlink.NUD_PERMANENT|netlink.NUD_NOARP) != 0
+}
+
+func appendIP(ips []net.IP, ip net.IP) []net.IP {
+	if containsIP(ips, ip) {
+		return ips
+	}
+	return append(ips, ip)
+}
+
+func containsIP(ips []net.IP, ip net.IP) bool {
+	for _, i := range ips {
+		if i.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func equalIPs(a, b []net.IP) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsMac(macs []net.HardwareAddr, mac net.HardwareAddr) bool {
+	for _, m := range macs {
+		if bytes.Equal(m, mac) {
+			return true
+		}
+	}
+	return false
+}
+
+func equalMacs(a, b []net.HardwareAddr) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func familyName(family int) string {
+	if family == netlink.FAMILY_V6 {
+		return "ipv6"
+	}
+	return "ipv4"
+}
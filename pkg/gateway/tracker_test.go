@@ -0,0 +1,201 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+var (
+	gwV4     = net.ParseIP("192.0.2.1")
+	gwV4Peer = net.ParseIP("192.0.2.2")
+	gwV6     = net.ParseIP("2001:db8::1")
+
+	macA = net.HardwareAddr{0x02, 0, 0, 0, 0, 0x0a}
+	macB = net.HardwareAddr{0x02, 0, 0, 0, 0, 0x0b}
+	macC = net.HardwareAddr{0x02, 0, 0, 0, 0, 0x0c}
+)
+
+// recorder collects macs programmed through tracker's callbacks
+type recorder struct {
+	macs     []net.HardwareAddr
+	nexthops [][]net.HardwareAddr
+	fail     bool
+}
+
+func newTestTracker(r *recorder) *Tracker {
+	t := NewTracker("eth0", func(mac net.HardwareAddr) bool {
+		if r.fail {
+			return false
+		}
+		r.macs = append(r.macs, mac)
+		return true
+	})
+	t.OnNexthopsChange(func(macs []net.HardwareAddr) bool {
+		if r.fail {
+			return false
+		}
+		r.nexthops = append(r.nexthops, macs)
+		return true
+	})
+	return t
+}
+
+func neighUpdate(family int, ip net.IP, mac net.HardwareAddr) *netlink.NeighUpdate {
+	return &netlink.NeighUpdate{
+		Type: unix.RTM_NEWNEIGH,
+		Neigh: netlink.Neigh{
+			Family:       family,
+			IP:           ip,
+			HardwareAddr: mac,
+			State:        netlink.NUD_REACHABLE,
+		},
+	}
+}
+
+func TestTrackerGatewayFailover(t *testing.T) {
+	r := &recorder{}
+	tracker := newTestTracker(r)
+	tracker.setGateways(netlink.FAMILY_V4, []net.IP{gwV4})
+
+	tracker.onNeighUpdate(neighUpdate(netlink.FAMILY_V4, gwV4, macA))
+	// the same mac again is not a change
+	tracker.onNeighUpdate(neighUpdate(netlink.FAMILY_V4, gwV4, macA))
+	// router failover
+	tracker.onNeighUpdate(neighUpdate(netlink.FAMILY_V4, gwV4, macB))
+
+	if len(r.macs) != 2 || r.macs[0].String() != macA.String() || r.macs[1].String() != macB.String() {
+		t.Fatalf("programmed macs %v, expected [%v %v]", r.macs, macA, macB)
+	}
+	if mac := tracker.GetMac(); mac.String() != macB.String() {
+		t.Errorf("current mac %v, expected %v", mac, macB)
+	}
+	events := tracker.Events()
+	if len(events) != 2 {
+		t.Fatalf("got %d events, expected 2", len(events))
+	}
+	if events[0].OldMac != "" || events[0].NewMac != macA.String() {
+		t.Errorf("first event %+v, expected change from none to %v", events[0], macA)
+	}
+	if events[1].OldMac != macA.String() || events[1].NewMac != macB.String() ||
+		events[1].Family != "ipv4" || !events[1].Gateway.Equal(gwV4) {
+		t.Errorf("second event %+v, expected ipv4 change %v -> %v of %v", events[1], macA, macB, gwV4)
+	}
+}
+
+func TestTrackerIgnoresOtherNeighbors(t *testing.T) {
+	r := &recorder{}
+	tracker := newTestTracker(r)
+	tracker.setGateways(netlink.FAMILY_V4, []net.IP{gwV4})
+
+	// neighbor which is not a gateway
+	tracker.onNeighUpdate(neighUpdate(netlink.FAMILY_V4, net.ParseIP("192.0.2.100"), macA))
+	// gateway's address on another link
+	other := neighUpdate(netlink.FAMILY_V4, gwV4, macA)
+	other.LinkIndex = tracker.ifIndex + 1
+	tracker.onNeighUpdate(other)
+
+	if len(r.macs) != 0 || len(tracker.Events()) != 0 {
+		t.Errorf("programmed macs %v, events %v, expected none", r.macs, tracker.Events())
+	}
+}
+
+func TestTrackerRetriesFailedChange(t *testing.T) {
+	r := &recorder{fail: true}
+	tracker := newTestTracker(r)
+	tracker.setGateways(netlink.FAMILY_V4, []net.IP{gwV4})
+	tracker.onNeighUpdate(neighUpdate(netlink.FAMILY_V4, gwV4, macA))
+	if mac := tracker.GetMac(); mac != nil {
+		t.Fatalf("current mac %v after failed change, expected none", mac)
+	}
+
+	r.fail = false
+	tracker.apply()
+	if mac := tracker.GetMac(); mac.String() != macA.String() {
+		t.Errorf("current mac %v after retry, expected %v", mac, macA)
+	}
+}
+
+func TestTrackerFamilies(t *testing.T) {
+	r := &recorder{}
+	tracker := newTestTracker(r)
+
+	// v6 only host uses v6 gateway
+	tracker.setGateways(netlink.FAMILY_V6, []net.IP{gwV6})
+	tracker.onNeighUpdate(neighUpdate(netlink.FAMILY_V6, gwV6, macC))
+	if mac := tracker.GetMac(); mac.String() != macC.String() {
+		t.Fatalf("current mac %v, expected v6 gateway's %v", mac, macC)
+	}
+
+	// v4 gateway takes precedence once it is resolved
+	tracker.setGateways(netlink.FAMILY_V4, []net.IP{gwV4})
+	tracker.onNeighUpdate(neighUpdate(netlink.FAMILY_V4, gwV4, macA))
+	if mac := tracker.GetMac(); mac.String() != macA.String() {
+		t.Errorf("current mac %v, expected v4 gateway's %v", mac, macA)
+	}
+	if events := tracker.Events(); len(events) != 2 || events[0].Family != "ipv6" || events[1].Family != "ipv4" {
+		t.Errorf("events %+v, expected ipv6 and then ipv4 change", events)
+	}
+}
+
+func TestTrackerMultipathNexthops(t *testing.T) {
+	r := &recorder{}
+	tracker := newTestTracker(r)
+	tracker.setGateways(netlink.FAMILY_V4, []net.IP{gwV4, gwV4Peer})
+	tracker.onNeighUpdate(neighUpdate(netlink.FAMILY_V4, gwV4, macA))
+	tracker.onNeighUpdate(neighUpdate(netlink.FAMILY_V4, gwV4Peer, macB))
+
+	nexthops := tracker.GetNexthops()
+	if len(nexthops) != 2 || nexthops[0].String() != macA.String() || nexthops[1].String() != macB.String() {
+		t.Fatalf("next hops %v, expected [%v %v]", nexthops, macA, macB)
+	}
+	if mac := tracker.GetMac(); mac.String() != macA.String() {
+		t.Errorf("current mac %v, expected first next hop %v", mac, macA)
+	}
+
+	// one of the routers is gone from the route
+	tracker.setGateways(netlink.FAMILY_V4, []net.IP{gwV4Peer})
+	tracker.apply()
+	nexthops = tracker.GetNexthops()
+	if len(nexthops) != 1 || nexthops[0].String() != macB.String() {
+		t.Fatalf("next hops %v, expected [%v]", nexthops, macB)
+	}
+	if mac := tracker.GetMac(); mac.String() != macB.String() {
+		t.Errorf("current mac %v, expected remaining next hop %v", mac, macB)
+	}
+	if len(r.nexthops) != 3 {
+		t.Errorf("next hops have been programmed %d times, expected 3", len(r.nexthops))
+	}
+}
+
+func TestTrackerEventLog(t *testing.T) {
+	tracker := newTestTracker(&recorder{})
+	tracker.setGateways(netlink.FAMILY_V4, []net.IP{gwV4})
+	for i := 0; i < kMaxEvents+10; i++ {
+		mac := net.HardwareAddr{0x02, 0, 0, 0, byte(i >> 8), byte(i)}
+		tracker.onNeighUpdate(neighUpdate(netlink.FAMILY_V4, gwV4, mac))
+	}
+	events := tracker.Events()
+	if len(events) != kMaxEvents {
+		t.Fatalf("event log has %d events, expected %d", len(events), kMaxEvents)
+	}
+	// the oldest events are dropped
+	if expected := (net.HardwareAddr{0x02, 0, 0, 0, 0, 10}).String(); events[0].NewMac != expected {
+		t.Errorf("oldest event is change to %s, expected %s", events[0].NewMac, expected)
+	}
+
+	w := httptest.NewRecorder()
+	tracker.GetEventsHandler().ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	var served []map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &served); err != nil {
+		t.Fatalf("can't parse served events: %v", err)
+	}
+	if len(served) != kMaxEvents || served[len(served)-1]["new_mac"] != events[len(events)-1].NewMac {
+		t.Errorf("served %d events ending with %v, expected %d ending with %s",
+			len(served), served[len(served)-1], kMaxEvents, events[len(events)-1].NewMac)
+	}
+}
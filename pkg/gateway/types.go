@@ -0,0 +1,65 @@
+// Package gateway tracks the default gateway of an interface and the MAC addresses
+// of its next hops (all of them for multipath routes), using netlink route and
+// neighbor notifications.
+package gateway
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/cybwan/l4slb/pkg/logger"
+)
+
+const (
+	// kMaxEvents is the number of mac change events kept in the event log
+	kMaxEvents = 128
+
+	// kResyncInterval is how often we re-read routes and neighbors even without notifications
+	kResyncInterval = 1 * time.Minute
+
+	// kDiscardPort is the port used to provoke neighbor resolution of a gateway
+	kDiscardPort = 9
+)
+
+var (
+	log = logger.New("gateway")
+)
+
+// Event describes a change of the default gateway's mac address
+type Event struct {
+	Time    time.Time `json:"time"`
+	Family  string    `json:"family"`
+	Gateway net.IP    `json:"gateway"`
+	// empty if the gateway's mac has not been known
+	OldMac string `json:"old_mac"`
+	NewMac string `json:"new_mac"`
+}
+
+// MacChangeFunc is called with the new default gateway's mac address
+type MacChangeFunc func(mac net.HardwareAddr) bool
+
+// NexthopsChangeFunc is called with mac addresses of all next hops of the default route
+type NexthopsChangeFunc func(macs []net.HardwareAddr) bool
+
+// Tracker follows the default route of one interface and reports next hop mac changes
+type Tracker struct {
+	ifName     string
+	ifIndex    int
+	onChange   MacChangeFunc
+	onNexthops NexthopsChangeFunc
+
+	mu sync.Mutex
+
+	// gateways (next hops of the default route) per address family (netlink.FAMILY_V4/V6)
+	gateways map[int][]net.IP
+	// mac address of the gateway by its address
+	macs map[string]net.HardwareAddr
+
+	// mac address which has been programmed through onChange
+	current net.HardwareAddr
+	// mac addresses which have been programmed through onNexthops
+	nexthops []net.HardwareAddr
+
+	events []Event
+}
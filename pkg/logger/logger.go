@@ -47,6 +47,15 @@ func NewPretty(component string) zerolog.Logger {
 	return newLogger(component).Output(zerolog.ConsoleWriter{Out: os.Stdout})
 }
 
+// NewStderr creates a new zerolog.Logger which always writes to stderr, for
+// commands whose stdout carries their output (e.g. pcap stream)
+func NewStderr(component string) zerolog.Logger {
+	if os.Getenv(EnvVarHumanReadableLogMessages) == "true" {
+		return newLogger(component).Output(zerolog.ConsoleWriter{Out: os.Stderr})
+	}
+	return newLogger(component).Output(os.Stderr)
+}
+
 // SetLogLevel sets the global logging level
 func SetLogLevel(verbosity string) error {
 	switch strings.ToLower(verbosity) {
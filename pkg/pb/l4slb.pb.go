@@ -223,6 +223,12 @@ type VipMeta struct {
 	Flags int32 `protobuf:"varint,2,opt,name=flags,proto3" json:"flags,omitempty"`
 	// setFlag controls if we setting this flags or removing it from the VIP
 	SetFlag bool `protobuf:"varint,3,opt,name=setFlag,proto3" json:"setFlag,omitempty"`
+	// size of consistent hashing ring of the new VIP, a prime number.
+	// balancer's ch_ring_size if 0
+	RingSize uint32 `protobuf:"varint,4,opt,name=ring_size,json=ringSize,proto3" json:"ring_size,omitempty"`
+	// consistent hashing function of the new VIP, e.g. maglev_v2.
+	// balancer's hash_function if empty
+	HashFunction string `protobuf:"bytes,5,opt,name=hash_function,json=hashFunction,proto3" json:"hash_function,omitempty"`
 }
 
 func (x *VipMeta) Reset() {
@@ -278,6 +284,147 @@ func (x *VipMeta) GetSetFlag() bool {
 	return false
 }
 
+func (x *VipMeta) GetRingSize() uint32 {
+	if x != nil {
+		return x.RingSize
+	}
+	return 0
+}
+
+func (x *VipMeta) GetHashFunction() string {
+	if x != nil {
+		return x.HashFunction
+	}
+	return ""
+}
+
+type VipHashFunction struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Vip          *Vip   `protobuf:"bytes,1,opt,name=vip,proto3" json:"vip,omitempty"`
+	HashFunction string `protobuf:"bytes,2,opt,name=hash_function,json=hashFunction,proto3" json:"hash_function,omitempty"`
+}
+
+func (x *VipHashFunction) Reset() {
+	*x = VipHashFunction{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_pb_l4slb_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VipHashFunction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VipHashFunction) ProtoMessage() {}
+
+func (x *VipHashFunction) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_l4slb_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VipHashFunction.ProtoReflect.Descriptor instead.
+func (*VipHashFunction) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *VipHashFunction) GetVip() *Vip {
+	if x != nil {
+		return x.Vip
+	}
+	return nil
+}
+
+func (x *VipHashFunction) GetHashFunction() string {
+	if x != nil {
+		return x.HashFunction
+	}
+	return ""
+}
+
+type VipInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Flags        uint64 `protobuf:"varint,1,opt,name=flags,proto3" json:"flags,omitempty"`
+	HashFunction string `protobuf:"bytes,2,opt,name=hash_function,json=hashFunction,proto3" json:"hash_function,omitempty"`
+	RingSize     uint32 `protobuf:"varint,3,opt,name=ring_size,json=ringSize,proto3" json:"ring_size,omitempty"`
+	// number of reals of the VIP, including ones with zero weight
+	Reals uint32 `protobuf:"varint,4,opt,name=reals,proto3" json:"reals,omitempty"`
+}
+
+func (x *VipInfo) Reset() {
+	*x = VipInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_pb_l4slb_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VipInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VipInfo) ProtoMessage() {}
+
+func (x *VipInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_l4slb_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VipInfo.ProtoReflect.Descriptor instead.
+func (*VipInfo) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *VipInfo) GetFlags() uint64 {
+	if x != nil {
+		return x.Flags
+	}
+	return 0
+}
+
+func (x *VipInfo) GetHashFunction() string {
+	if x != nil {
+		return x.HashFunction
+	}
+	return ""
+}
+
+func (x *VipInfo) GetRingSize() uint32 {
+	if x != nil {
+		return x.RingSize
+	}
+	return 0
+}
+
+func (x *VipInfo) GetReals() uint32 {
+	if x != nil {
+		return x.Reals
+	}
+	return 0
+}
+
 type RealMeta struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -292,7 +439,7 @@ type RealMeta struct {
 func (x *RealMeta) Reset() {
 	*x = RealMeta{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_pb_l4slb_proto_msgTypes[4]
+		mi := &file_pkg_pb_l4slb_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -305,7 +452,7 @@ func (x *RealMeta) String() string {
 func (*RealMeta) ProtoMessage() {}
 
 func (x *RealMeta) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_pb_l4slb_proto_msgTypes[4]
+	mi := &file_pkg_pb_l4slb_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -318,7 +465,7 @@ func (x *RealMeta) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RealMeta.ProtoReflect.Descriptor instead.
 func (*RealMeta) Descriptor() ([]byte, []int) {
-	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{4}
+	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *RealMeta) GetAddress() string {
@@ -355,7 +502,7 @@ type Real struct {
 func (x *Real) Reset() {
 	*x = Real{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_pb_l4slb_proto_msgTypes[5]
+		mi := &file_pkg_pb_l4slb_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -368,7 +515,7 @@ func (x *Real) String() string {
 func (*Real) ProtoMessage() {}
 
 func (x *Real) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_pb_l4slb_proto_msgTypes[5]
+	mi := &file_pkg_pb_l4slb_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -381,7 +528,7 @@ func (x *Real) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Real.ProtoReflect.Descriptor instead.
 func (*Real) Descriptor() ([]byte, []int) {
-	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{5}
+	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *Real) GetAddress() string {
@@ -417,7 +564,7 @@ type QuicReal struct {
 func (x *QuicReal) Reset() {
 	*x = QuicReal{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_pb_l4slb_proto_msgTypes[6]
+		mi := &file_pkg_pb_l4slb_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -430,7 +577,7 @@ func (x *QuicReal) String() string {
 func (*QuicReal) ProtoMessage() {}
 
 func (x *QuicReal) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_pb_l4slb_proto_msgTypes[6]
+	mi := &file_pkg_pb_l4slb_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -443,7 +590,7 @@ func (x *QuicReal) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use QuicReal.ProtoReflect.Descriptor instead.
 func (*QuicReal) Descriptor() ([]byte, []int) {
-	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{6}
+	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *QuicReal) GetAddress() string {
@@ -471,7 +618,7 @@ type Mac struct {
 func (x *Mac) Reset() {
 	*x = Mac{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_pb_l4slb_proto_msgTypes[7]
+		mi := &file_pkg_pb_l4slb_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -484,7 +631,7 @@ func (x *Mac) String() string {
 func (*Mac) ProtoMessage() {}
 
 func (x *Mac) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_pb_l4slb_proto_msgTypes[7]
+	mi := &file_pkg_pb_l4slb_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -497,7 +644,7 @@ func (x *Mac) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Mac.ProtoReflect.Descriptor instead.
 func (*Mac) Descriptor() ([]byte, []int) {
-	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{7}
+	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *Mac) GetMac() string {
@@ -507,32 +654,32 @@ func (x *Mac) GetMac() string {
 	return ""
 }
 
-type Stats struct {
+type Nexthop struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	V1 uint64 `protobuf:"varint,1,opt,name=v1,proto3" json:"v1,omitempty"`
-	V2 uint64 `protobuf:"varint,2,opt,name=v2,proto3" json:"v2,omitempty"`
+	Mac   string `protobuf:"bytes,1,opt,name=mac,proto3" json:"mac,omitempty"`
+	Stats *Stats `protobuf:"bytes,2,opt,name=stats,proto3" json:"stats,omitempty"`
 }
 
-func (x *Stats) Reset() {
-	*x = Stats{}
+func (x *Nexthop) Reset() {
+	*x = Nexthop{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_pb_l4slb_proto_msgTypes[8]
+		mi := &file_pkg_pb_l4slb_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *Stats) String() string {
+func (x *Nexthop) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Stats) ProtoMessage() {}
+func (*Nexthop) ProtoMessage() {}
 
-func (x *Stats) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_pb_l4slb_proto_msgTypes[8]
+func (x *Nexthop) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_l4slb_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -543,51 +690,50 @@ func (x *Stats) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Stats.ProtoReflect.Descriptor instead.
-func (*Stats) Descriptor() ([]byte, []int) {
-	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use Nexthop.ProtoReflect.Descriptor instead.
+func (*Nexthop) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *Stats) GetV1() uint64 {
+func (x *Nexthop) GetMac() string {
 	if x != nil {
-		return x.V1
+		return x.Mac
 	}
-	return 0
+	return ""
 }
 
-func (x *Stats) GetV2() uint64 {
+func (x *Nexthop) GetStats() *Stats {
 	if x != nil {
-		return x.V2
+		return x.Stats
 	}
-	return 0
+	return nil
 }
 
-type Healthcheck struct {
+type Nexthops struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Somark  uint32 `protobuf:"varint,1,opt,name=somark,proto3" json:"somark,omitempty"`
-	Address string `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	Nexthops []*Nexthop `protobuf:"bytes,1,rep,name=nexthops,proto3" json:"nexthops,omitempty"`
 }
 
-func (x *Healthcheck) Reset() {
-	*x = Healthcheck{}
+func (x *Nexthops) Reset() {
+	*x = Nexthops{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_pb_l4slb_proto_msgTypes[9]
+		mi := &file_pkg_pb_l4slb_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *Healthcheck) String() string {
+func (x *Nexthops) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Healthcheck) ProtoMessage() {}
+func (*Nexthops) ProtoMessage() {}
 
-func (x *Healthcheck) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_pb_l4slb_proto_msgTypes[9]
+func (x *Nexthops) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_l4slb_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -598,50 +744,44 @@ func (x *Healthcheck) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Healthcheck.ProtoReflect.Descriptor instead.
-func (*Healthcheck) Descriptor() ([]byte, []int) {
-	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{9}
-}
-
-func (x *Healthcheck) GetSomark() uint32 {
-	if x != nil {
-		return x.Somark
-	}
-	return 0
+// Deprecated: Use Nexthops.ProtoReflect.Descriptor instead.
+func (*Nexthops) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *Healthcheck) GetAddress() string {
+func (x *Nexthops) GetNexthops() []*Nexthop {
 	if x != nil {
-		return x.Address
+		return x.Nexthops
 	}
-	return ""
+	return nil
 }
 
-type HcMap struct {
+type Stats struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Healthchecks map[int32]string `protobuf:"bytes,1,rep,name=healthchecks,proto3" json:"healthchecks,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	V1 uint64 `protobuf:"varint,1,opt,name=v1,proto3" json:"v1,omitempty"`
+	V2 uint64 `protobuf:"varint,2,opt,name=v2,proto3" json:"v2,omitempty"`
 }
 
-func (x *HcMap) Reset() {
-	*x = HcMap{}
+func (x *Stats) Reset() {
+	*x = Stats{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_pb_l4slb_proto_msgTypes[10]
+		mi := &file_pkg_pb_l4slb_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *HcMap) String() string {
+func (x *Stats) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HcMap) ProtoMessage() {}
+func (*Stats) ProtoMessage() {}
 
-func (x *HcMap) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_pb_l4slb_proto_msgTypes[10]
+func (x *Stats) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_l4slb_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -652,43 +792,54 @@ func (x *HcMap) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HcMap.ProtoReflect.Descriptor instead.
-func (*HcMap) Descriptor() ([]byte, []int) {
-	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use Stats.ProtoReflect.Descriptor instead.
+func (*Stats) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{12}
 }
 
-func (x *HcMap) GetHealthchecks() map[int32]string {
+func (x *Stats) GetV1() uint64 {
 	if x != nil {
-		return x.Healthchecks
+		return x.V1
 	}
-	return nil
+	return 0
 }
 
-type Reals struct {
+func (x *Stats) GetV2() uint64 {
+	if x != nil {
+		return x.V2
+	}
+	return 0
+}
+
+type LruMapStats struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Reals []*Real `protobuf:"bytes,1,rep,name=reals,proto3" json:"reals,omitempty"`
+	Cpu            int32  `protobuf:"varint,1,opt,name=cpu,proto3" json:"cpu,omitempty"`
+	NumaNode       int32  `protobuf:"varint,2,opt,name=numa_node,json=numaNode,proto3" json:"numa_node,omitempty"`
+	Fallback       bool   `protobuf:"varint,3,opt,name=fallback,proto3" json:"fallback,omitempty"`
+	MaxEntries     uint32 `protobuf:"varint,4,opt,name=max_entries,json=maxEntries,proto3" json:"max_entries,omitempty"`
+	CurrentEntries uint32 `protobuf:"varint,5,opt,name=current_entries,json=currentEntries,proto3" json:"current_entries,omitempty"`
 }
 
-func (x *Reals) Reset() {
-	*x = Reals{}
+func (x *LruMapStats) Reset() {
+	*x = LruMapStats{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_pb_l4slb_proto_msgTypes[11]
+		mi := &file_pkg_pb_l4slb_proto_msgTypes[13]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *Reals) String() string {
+func (x *LruMapStats) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Reals) ProtoMessage() {}
+func (*LruMapStats) ProtoMessage() {}
 
-func (x *Reals) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_pb_l4slb_proto_msgTypes[11]
+func (x *LruMapStats) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_l4slb_proto_msgTypes[13]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -699,43 +850,1238 @@ func (x *Reals) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Reals.ProtoReflect.Descriptor instead.
-func (*Reals) Descriptor() ([]byte, []int) {
-	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{11}
+// Deprecated: Use LruMapStats.ProtoReflect.Descriptor instead.
+func (*LruMapStats) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{13}
 }
 
-func (x *Reals) GetReals() []*Real {
+func (x *LruMapStats) GetCpu() int32 {
 	if x != nil {
-		return x.Reals
+		return x.Cpu
 	}
-	return nil
+	return 0
 }
 
-type Vips struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
-
-	Vips []*Vip `protobuf:"bytes,1,rep,name=vips,proto3" json:"vips,omitempty"`
+func (x *LruMapStats) GetNumaNode() int32 {
+	if x != nil {
+		return x.NumaNode
+	}
+	return 0
 }
 
-func (x *Vips) Reset() {
-	*x = Vips{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_pb_l4slb_proto_msgTypes[12]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
+func (x *LruMapStats) GetFallback() bool {
+	if x != nil {
+		return x.Fallback
 	}
+	return false
 }
 
-func (x *Vips) String() string {
+func (x *LruMapStats) GetMaxEntries() uint32 {
+	if x != nil {
+		return x.MaxEntries
+	}
+	return 0
+}
+
+func (x *LruMapStats) GetCurrentEntries() uint32 {
+	if x != nil {
+		return x.CurrentEntries
+	}
+	return 0
+}
+
+type LruMapsStats struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Lru       []*LruMapStats `protobuf:"bytes,1,rep,name=lru,proto3" json:"lru,omitempty"`
+	GlobalLru []*LruMapStats `protobuf:"bytes,2,rep,name=global_lru,json=globalLru,proto3" json:"global_lru,omitempty"`
+}
+
+func (x *LruMapsStats) Reset() {
+	*x = LruMapsStats{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_pb_l4slb_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LruMapsStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LruMapsStats) ProtoMessage() {}
+
+func (x *LruMapsStats) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_l4slb_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LruMapsStats.ProtoReflect.Descriptor instead.
+func (*LruMapsStats) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *LruMapsStats) GetLru() []*LruMapStats {
+	if x != nil {
+		return x.Lru
+	}
+	return nil
+}
+
+func (x *LruMapsStats) GetGlobalLru() []*LruMapStats {
+	if x != nil {
+		return x.GlobalLru
+	}
+	return nil
+}
+
+type BpfMapStats struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name           string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	MaxEntries     uint32 `protobuf:"varint,2,opt,name=max_entries,json=maxEntries,proto3" json:"max_entries,omitempty"`
+	CurrentEntries uint32 `protobuf:"varint,3,opt,name=current_entries,json=currentEntries,proto3" json:"current_entries,omitempty"`
+}
+
+func (x *BpfMapStats) Reset() {
+	*x = BpfMapStats{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_pb_l4slb_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BpfMapStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BpfMapStats) ProtoMessage() {}
+
+func (x *BpfMapStats) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_l4slb_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BpfMapStats.ProtoReflect.Descriptor instead.
+func (*BpfMapStats) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *BpfMapStats) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *BpfMapStats) GetMaxEntries() uint32 {
+	if x != nil {
+		return x.MaxEntries
+	}
+	return 0
+}
+
+func (x *BpfMapStats) GetCurrentEntries() uint32 {
+	if x != nil {
+		return x.CurrentEntries
+	}
+	return 0
+}
+
+type BpfMapsStats struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Maps []*BpfMapStats `protobuf:"bytes,1,rep,name=maps,proto3" json:"maps,omitempty"`
+}
+
+func (x *BpfMapsStats) Reset() {
+	*x = BpfMapsStats{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_pb_l4slb_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BpfMapsStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BpfMapsStats) ProtoMessage() {}
+
+func (x *BpfMapsStats) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_l4slb_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BpfMapsStats.ProtoReflect.Descriptor instead.
+func (*BpfMapsStats) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *BpfMapsStats) GetMaps() []*BpfMapStats {
+	if x != nil {
+		return x.Maps
+	}
+	return nil
+}
+
+type ControllerStats struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	BpfFailedCalls       uint64 `protobuf:"varint,1,opt,name=bpf_failed_calls,json=bpfFailedCalls,proto3" json:"bpf_failed_calls,omitempty"`
+	AddrValidationFailed uint64 `protobuf:"varint,2,opt,name=addr_validation_failed,json=addrValidationFailed,proto3" json:"addr_validation_failed,omitempty"`
+	RingRecomputations   uint64 `protobuf:"varint,3,opt,name=ring_recomputations,json=ringRecomputations,proto3" json:"ring_recomputations,omitempty"`
+	// time spent generating ch rings, in microseconds
+	RingGenerationTimeUs   uint64 `protobuf:"varint,4,opt,name=ring_generation_time_us,json=ringGenerationTimeUs,proto3" json:"ring_generation_time_us,omitempty"`
+	RingBatchUpdates       uint64 `protobuf:"varint,5,opt,name=ring_batch_updates,json=ringBatchUpdates,proto3" json:"ring_batch_updates,omitempty"`
+	RingPositionsWritten   uint64 `protobuf:"varint,6,opt,name=ring_positions_written,json=ringPositionsWritten,proto3" json:"ring_positions_written,omitempty"`
+	MaxRingBatchUpdateSize uint64 `protobuf:"varint,7,opt,name=max_ring_batch_update_size,json=maxRingBatchUpdateSize,proto3" json:"max_ring_batch_update_size,omitempty"`
+	FreeVipNums            uint32 `protobuf:"varint,8,opt,name=free_vip_nums,json=freeVipNums,proto3" json:"free_vip_nums,omitempty"`
+	FreeRealNums           uint32 `protobuf:"varint,9,opt,name=free_real_nums,json=freeRealNums,proto3" json:"free_real_nums,omitempty"`
+	FreeHcKeyNums          uint32 `protobuf:"varint,10,opt,name=free_hc_key_nums,json=freeHcKeyNums,proto3" json:"free_hc_key_nums,omitempty"`
+	FreeRingPositions      uint32 `protobuf:"varint,11,opt,name=free_ring_positions,json=freeRingPositions,proto3" json:"free_ring_positions,omitempty"`
+}
+
+func (x *ControllerStats) Reset() {
+	*x = ControllerStats{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_pb_l4slb_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ControllerStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ControllerStats) ProtoMessage() {}
+
+func (x *ControllerStats) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_l4slb_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ControllerStats.ProtoReflect.Descriptor instead.
+func (*ControllerStats) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ControllerStats) GetBpfFailedCalls() uint64 {
+	if x != nil {
+		return x.BpfFailedCalls
+	}
+	return 0
+}
+
+func (x *ControllerStats) GetAddrValidationFailed() uint64 {
+	if x != nil {
+		return x.AddrValidationFailed
+	}
+	return 0
+}
+
+func (x *ControllerStats) GetRingRecomputations() uint64 {
+	if x != nil {
+		return x.RingRecomputations
+	}
+	return 0
+}
+
+func (x *ControllerStats) GetRingGenerationTimeUs() uint64 {
+	if x != nil {
+		return x.RingGenerationTimeUs
+	}
+	return 0
+}
+
+func (x *ControllerStats) GetRingBatchUpdates() uint64 {
+	if x != nil {
+		return x.RingBatchUpdates
+	}
+	return 0
+}
+
+func (x *ControllerStats) GetRingPositionsWritten() uint64 {
+	if x != nil {
+		return x.RingPositionsWritten
+	}
+	return 0
+}
+
+func (x *ControllerStats) GetMaxRingBatchUpdateSize() uint64 {
+	if x != nil {
+		return x.MaxRingBatchUpdateSize
+	}
+	return 0
+}
+
+func (x *ControllerStats) GetFreeVipNums() uint32 {
+	if x != nil {
+		return x.FreeVipNums
+	}
+	return 0
+}
+
+func (x *ControllerStats) GetFreeRealNums() uint32 {
+	if x != nil {
+		return x.FreeRealNums
+	}
+	return 0
+}
+
+func (x *ControllerStats) GetFreeHcKeyNums() uint32 {
+	if x != nil {
+		return x.FreeHcKeyNums
+	}
+	return 0
+}
+
+func (x *ControllerStats) GetFreeRingPositions() uint32 {
+	if x != nil {
+		return x.FreeRingPositions
+	}
+	return 0
+}
+
+type Flow struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Src      string `protobuf:"bytes,1,opt,name=src,proto3" json:"src,omitempty"`
+	Dst      string `protobuf:"bytes,2,opt,name=dst,proto3" json:"dst,omitempty"`
+	SrcPort  int32  `protobuf:"varint,3,opt,name=src_port,json=srcPort,proto3" json:"src_port,omitempty"`
+	DstPort  int32  `protobuf:"varint,4,opt,name=dst_port,json=dstPort,proto3" json:"dst_port,omitempty"`
+	Protocol int32  `protobuf:"varint,5,opt,name=protocol,proto3" json:"protocol,omitempty"`
+}
+
+func (x *Flow) Reset() {
+	*x = Flow{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_pb_l4slb_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Flow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Flow) ProtoMessage() {}
+
+func (x *Flow) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_l4slb_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Flow.ProtoReflect.Descriptor instead.
+func (*Flow) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *Flow) GetSrc() string {
+	if x != nil {
+		return x.Src
+	}
+	return ""
+}
+
+func (x *Flow) GetDst() string {
+	if x != nil {
+		return x.Dst
+	}
+	return ""
+}
+
+func (x *Flow) GetSrcPort() int32 {
+	if x != nil {
+		return x.SrcPort
+	}
+	return 0
+}
+
+func (x *Flow) GetDstPort() int32 {
+	if x != nil {
+		return x.DstPort
+	}
+	return 0
+}
+
+func (x *Flow) GetProtocol() int32 {
+	if x != nil {
+		return x.Protocol
+	}
+	return 0
+}
+
+type FlowDebugInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Cpu         int32  `protobuf:"varint,1,opt,name=cpu,proto3" json:"cpu,omitempty"`
+	InLru       bool   `protobuf:"varint,2,opt,name=in_lru,json=inLru,proto3" json:"in_lru,omitempty"`
+	Real        string `protobuf:"bytes,3,opt,name=real,proto3" json:"real,omitempty"`
+	Atime       uint64 `protobuf:"varint,4,opt,name=atime,proto3" json:"atime,omitempty"`
+	InGlobalLru bool   `protobuf:"varint,5,opt,name=in_global_lru,json=inGlobalLru,proto3" json:"in_global_lru,omitempty"`
+	GlobalReal  string `protobuf:"bytes,6,opt,name=global_real,json=globalReal,proto3" json:"global_real,omitempty"`
+	HasRoute    bool   `protobuf:"varint,7,opt,name=has_route,json=hasRoute,proto3" json:"has_route,omitempty"`
+	L4Hop       string `protobuf:"bytes,8,opt,name=l4_hop,json=l4Hop,proto3" json:"l4_hop,omitempty"`
+	ThisHop     string `protobuf:"bytes,9,opt,name=this_hop,json=thisHop,proto3" json:"this_hop,omitempty"`
+}
+
+func (x *FlowDebugInfo) Reset() {
+	*x = FlowDebugInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_pb_l4slb_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FlowDebugInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FlowDebugInfo) ProtoMessage() {}
+
+func (x *FlowDebugInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_l4slb_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FlowDebugInfo.ProtoReflect.Descriptor instead.
+func (*FlowDebugInfo) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *FlowDebugInfo) GetCpu() int32 {
+	if x != nil {
+		return x.Cpu
+	}
+	return 0
+}
+
+func (x *FlowDebugInfo) GetInLru() bool {
+	if x != nil {
+		return x.InLru
+	}
+	return false
+}
+
+func (x *FlowDebugInfo) GetReal() string {
+	if x != nil {
+		return x.Real
+	}
+	return ""
+}
+
+func (x *FlowDebugInfo) GetAtime() uint64 {
+	if x != nil {
+		return x.Atime
+	}
+	return 0
+}
+
+func (x *FlowDebugInfo) GetInGlobalLru() bool {
+	if x != nil {
+		return x.InGlobalLru
+	}
+	return false
+}
+
+func (x *FlowDebugInfo) GetGlobalReal() string {
+	if x != nil {
+		return x.GlobalReal
+	}
+	return ""
+}
+
+func (x *FlowDebugInfo) GetHasRoute() bool {
+	if x != nil {
+		return x.HasRoute
+	}
+	return false
+}
+
+func (x *FlowDebugInfo) GetL4Hop() string {
+	if x != nil {
+		return x.L4Hop
+	}
+	return ""
+}
+
+func (x *FlowDebugInfo) GetThisHop() string {
+	if x != nil {
+		return x.ThisHop
+	}
+	return ""
+}
+
+type FlowDebugInfos struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// flowDebug is false if flow debug maps have not been created,
+	// so only connection tables have been looked up
+	FlowDebug bool             `protobuf:"varint,1,opt,name=flowDebug,proto3" json:"flowDebug,omitempty"`
+	Infos     []*FlowDebugInfo `protobuf:"bytes,2,rep,name=infos,proto3" json:"infos,omitempty"`
+}
+
+func (x *FlowDebugInfos) Reset() {
+	*x = FlowDebugInfos{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_pb_l4slb_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FlowDebugInfos) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FlowDebugInfos) ProtoMessage() {}
+
+func (x *FlowDebugInfos) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_l4slb_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FlowDebugInfos.ProtoReflect.Descriptor instead.
+func (*FlowDebugInfos) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *FlowDebugInfos) GetFlowDebug() bool {
+	if x != nil {
+		return x.FlowDebug
+	}
+	return false
+}
+
+func (x *FlowDebugInfos) GetInfos() []*FlowDebugInfo {
+	if x != nil {
+		return x.Infos
+	}
+	return nil
+}
+
+type RealLruMiss struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Address string `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Misses  uint64 `protobuf:"varint,2,opt,name=misses,proto3" json:"misses,omitempty"`
+}
+
+func (x *RealLruMiss) Reset() {
+	*x = RealLruMiss{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_pb_l4slb_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RealLruMiss) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RealLruMiss) ProtoMessage() {}
+
+func (x *RealLruMiss) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_l4slb_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RealLruMiss.ProtoReflect.Descriptor instead.
+func (*RealLruMiss) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *RealLruMiss) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *RealLruMiss) GetMisses() uint64 {
+	if x != nil {
+		return x.Misses
+	}
+	return 0
+}
+
+type LruMissStatsForVip struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// tracked is false if lru misses are not tracked for any vip
+	Tracked bool `protobuf:"varint,1,opt,name=tracked,proto3" json:"tracked,omitempty"`
+	Vip     *Vip `protobuf:"bytes,2,opt,name=vip,proto3" json:"vip,omitempty"`
+	// reals of the vip and other reals which have been missed, most missed first
+	Reals []*RealLruMiss `protobuf:"bytes,3,rep,name=reals,proto3" json:"reals,omitempty"`
+}
+
+func (x *LruMissStatsForVip) Reset() {
+	*x = LruMissStatsForVip{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_pb_l4slb_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LruMissStatsForVip) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LruMissStatsForVip) ProtoMessage() {}
+
+func (x *LruMissStatsForVip) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_l4slb_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LruMissStatsForVip.ProtoReflect.Descriptor instead.
+func (*LruMissStatsForVip) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *LruMissStatsForVip) GetTracked() bool {
+	if x != nil {
+		return x.Tracked
+	}
+	return false
+}
+
+func (x *LruMissStatsForVip) GetVip() *Vip {
+	if x != nil {
+		return x.Vip
+	}
+	return nil
+}
+
+func (x *LruMissStatsForVip) GetReals() []*RealLruMiss {
+	if x != nil {
+		return x.Reals
+	}
+	return nil
+}
+
+type MonitorStats struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Enabled    bool   `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	Limit      uint32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	Amount     uint32 `protobuf:"varint,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	BufferFull uint32 `protobuf:"varint,4,opt,name=buffer_full,json=bufferFull,proto3" json:"buffer_full,omitempty"`
+}
+
+func (x *MonitorStats) Reset() {
+	*x = MonitorStats{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_pb_l4slb_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MonitorStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MonitorStats) ProtoMessage() {}
+
+func (x *MonitorStats) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_l4slb_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MonitorStats.ProtoReflect.Descriptor instead.
+func (*MonitorStats) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *MonitorStats) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *MonitorStats) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *MonitorStats) GetAmount() uint32 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *MonitorStats) GetBufferFull() uint32 {
+	if x != nil {
+		return x.BufferFull
+	}
+	return 0
+}
+
+type CaptureRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// names of events to capture, e.g. tcp_nonsyn_lrumiss. all if empty
+	Events []string `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	// only packets sent to the vip are captured if its address is set.
+	// zero port or protocol match any
+	Vip *Vip `protobuf:"bytes,2,opt,name=vip,proto3" json:"vip,omitempty"`
+}
+
+func (x *CaptureRequest) Reset() {
+	*x = CaptureRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_pb_l4slb_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CaptureRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CaptureRequest) ProtoMessage() {}
+
+func (x *CaptureRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_l4slb_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CaptureRequest.ProtoReflect.Descriptor instead.
+func (*CaptureRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *CaptureRequest) GetEvents() []string {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+func (x *CaptureRequest) GetVip() *Vip {
+	if x != nil {
+		return x.Vip
+	}
+	return nil
+}
+
+type CapturedPacket struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Event string `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	// unix time in nanoseconds
+	Timestamp int64  `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	PktSize   uint32 `protobuf:"varint,3,opt,name=pkt_size,json=pktSize,proto3" json:"pkt_size,omitempty"`
+	Data      []byte `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (x *CapturedPacket) Reset() {
+	*x = CapturedPacket{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_pb_l4slb_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CapturedPacket) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CapturedPacket) ProtoMessage() {}
+
+func (x *CapturedPacket) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_l4slb_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CapturedPacket.ProtoReflect.Descriptor instead.
+func (*CapturedPacket) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *CapturedPacket) GetEvent() string {
+	if x != nil {
+		return x.Event
+	}
+	return ""
+}
+
+func (x *CapturedPacket) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *CapturedPacket) GetPktSize() uint32 {
+	if x != nil {
+		return x.PktSize
+	}
+	return 0
+}
+
+func (x *CapturedPacket) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type Healthcheck struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Somark  uint32 `protobuf:"varint,1,opt,name=somark,proto3" json:"somark,omitempty"`
+	Address string `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+}
+
+func (x *Healthcheck) Reset() {
+	*x = Healthcheck{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_pb_l4slb_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Healthcheck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Healthcheck) ProtoMessage() {}
+
+func (x *Healthcheck) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_l4slb_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Healthcheck.ProtoReflect.Descriptor instead.
+func (*Healthcheck) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *Healthcheck) GetSomark() uint32 {
+	if x != nil {
+		return x.Somark
+	}
+	return 0
+}
+
+func (x *Healthcheck) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+type HcMap struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Healthchecks map[int32]string `protobuf:"bytes,1,rep,name=healthchecks,proto3" json:"healthchecks,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *HcMap) Reset() {
+	*x = HcMap{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_pb_l4slb_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HcMap) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HcMap) ProtoMessage() {}
+
+func (x *HcMap) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_l4slb_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HcMap.ProtoReflect.Descriptor instead.
+func (*HcMap) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *HcMap) GetHealthchecks() map[int32]string {
+	if x != nil {
+		return x.Healthchecks
+	}
+	return nil
+}
+
+type Reals struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Reals []*Real `protobuf:"bytes,1,rep,name=reals,proto3" json:"reals,omitempty"`
+}
+
+func (x *Reals) Reset() {
+	*x = Reals{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_pb_l4slb_proto_msgTypes[28]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Reals) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Reals) ProtoMessage() {}
+
+func (x *Reals) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_l4slb_proto_msgTypes[28]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Reals.ProtoReflect.Descriptor instead.
+func (*Reals) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *Reals) GetReals() []*Real {
+	if x != nil {
+		return x.Reals
+	}
+	return nil
+}
+
+type Vips struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Vips []*Vip `protobuf:"bytes,1,rep,name=vips,proto3" json:"vips,omitempty"`
+}
+
+func (x *Vips) Reset() {
+	*x = Vips{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_pb_l4slb_proto_msgTypes[29]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Vips) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Vips) ProtoMessage() {}
+
+func (x *Vips) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_l4slb_proto_msgTypes[29]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Vips.ProtoReflect.Descriptor instead.
+func (*Vips) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *Vips) GetVips() []*Vip {
+	if x != nil {
+		return x.Vips
+	}
+	return nil
+}
+
+type QuicReals struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Qreals []*QuicReal `protobuf:"bytes,1,rep,name=qreals,proto3" json:"qreals,omitempty"`
+}
+
+func (x *QuicReals) Reset() {
+	*x = QuicReals{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_pb_l4slb_proto_msgTypes[30]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QuicReals) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuicReals) ProtoMessage() {}
+
+func (x *QuicReals) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_l4slb_proto_msgTypes[30]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuicReals.ProtoReflect.Descriptor instead.
+func (*QuicReals) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *QuicReals) GetQreals() []*QuicReal {
+	if x != nil {
+		return x.Qreals
+	}
+	return nil
+}
+
+type ModifiedRealsForVip struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Action Action `protobuf:"varint,1,opt,name=action,proto3,enum=Action" json:"action,omitempty"`
+	Real   *Reals `protobuf:"bytes,2,opt,name=real,proto3" json:"real,omitempty"`
+	Vip    *Vip   `protobuf:"bytes,3,opt,name=vip,proto3" json:"vip,omitempty"`
+}
+
+func (x *ModifiedRealsForVip) Reset() {
+	*x = ModifiedRealsForVip{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_pb_l4slb_proto_msgTypes[31]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ModifiedRealsForVip) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ModifiedRealsForVip) ProtoMessage() {}
+
+func (x *ModifiedRealsForVip) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_l4slb_proto_msgTypes[31]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ModifiedRealsForVip.ProtoReflect.Descriptor instead.
+func (*ModifiedRealsForVip) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *ModifiedRealsForVip) GetAction() Action {
+	if x != nil {
+		return x.Action
+	}
+	return Action_ADD
+}
+
+func (x *ModifiedRealsForVip) GetReal() *Reals {
+	if x != nil {
+		return x.Real
+	}
+	return nil
+}
+
+func (x *ModifiedRealsForVip) GetVip() *Vip {
+	if x != nil {
+		return x.Vip
+	}
+	return nil
+}
+
+type ModifiedRealsForVips struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Vips []*ModifiedRealsForVip `protobuf:"bytes,1,rep,name=vips,proto3" json:"vips,omitempty"`
+}
+
+func (x *ModifiedRealsForVips) Reset() {
+	*x = ModifiedRealsForVips{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_pb_l4slb_proto_msgTypes[32]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ModifiedRealsForVips) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Vips) ProtoMessage() {}
+func (*ModifiedRealsForVips) ProtoMessage() {}
 
-func (x *Vips) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_pb_l4slb_proto_msgTypes[12]
+func (x *ModifiedRealsForVips) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_l4slb_proto_msgTypes[32]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -746,43 +2092,44 @@ func (x *Vips) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Vips.ProtoReflect.Descriptor instead.
-func (*Vips) Descriptor() ([]byte, []int) {
-	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{12}
+// Deprecated: Use ModifiedRealsForVips.ProtoReflect.Descriptor instead.
+func (*ModifiedRealsForVips) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{32}
 }
 
-func (x *Vips) GetVips() []*Vip {
+func (x *ModifiedRealsForVips) GetVips() []*ModifiedRealsForVip {
 	if x != nil {
 		return x.Vips
 	}
 	return nil
 }
 
-type QuicReals struct {
+type ModifiedQuicReals struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Qreals []*QuicReal `protobuf:"bytes,1,rep,name=qreals,proto3" json:"qreals,omitempty"`
+	Action Action     `protobuf:"varint,1,opt,name=action,proto3,enum=Action" json:"action,omitempty"`
+	Reals  *QuicReals `protobuf:"bytes,2,opt,name=reals,proto3" json:"reals,omitempty"`
 }
 
-func (x *QuicReals) Reset() {
-	*x = QuicReals{}
+func (x *ModifiedQuicReals) Reset() {
+	*x = ModifiedQuicReals{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_pb_l4slb_proto_msgTypes[13]
+		mi := &file_pkg_pb_l4slb_proto_msgTypes[33]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *QuicReals) String() string {
+func (x *ModifiedQuicReals) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*QuicReals) ProtoMessage() {}
+func (*ModifiedQuicReals) ProtoMessage() {}
 
-func (x *QuicReals) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_pb_l4slb_proto_msgTypes[13]
+func (x *ModifiedQuicReals) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_l4slb_proto_msgTypes[33]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -793,45 +2140,56 @@ func (x *QuicReals) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use QuicReals.ProtoReflect.Descriptor instead.
-func (*QuicReals) Descriptor() ([]byte, []int) {
-	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{13}
+// Deprecated: Use ModifiedQuicReals.ProtoReflect.Descriptor instead.
+func (*ModifiedQuicReals) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{33}
 }
 
-func (x *QuicReals) GetQreals() []*QuicReal {
+func (x *ModifiedQuicReals) GetAction() Action {
 	if x != nil {
-		return x.Qreals
+		return x.Action
+	}
+	return Action_ADD
+}
+
+func (x *ModifiedQuicReals) GetReals() *QuicReals {
+	if x != nil {
+		return x.Reals
 	}
 	return nil
 }
 
-type ModifiedRealsForVip struct {
+type RealRingShare struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Action Action `protobuf:"varint,1,opt,name=action,proto3,enum=Action" json:"action,omitempty"`
-	Real   *Reals `protobuf:"bytes,2,opt,name=real,proto3" json:"real,omitempty"`
-	Vip    *Vip   `protobuf:"bytes,3,opt,name=vip,proto3" json:"vip,omitempty"`
+	Address   string `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Weight    uint32 `protobuf:"varint,2,opt,name=weight,proto3" json:"weight,omitempty"`
+	Positions uint32 `protobuf:"varint,3,opt,name=positions,proto3" json:"positions,omitempty"`
+	// fraction of ring's positions owned by the real
+	Share float64 `protobuf:"fixed64,4,opt,name=share,proto3" json:"share,omitempty"`
+	// weight of the real over total weight of vip's reals
+	IdealShare float64 `protobuf:"fixed64,5,opt,name=ideal_share,json=idealShare,proto3" json:"ideal_share,omitempty"`
 }
 
-func (x *ModifiedRealsForVip) Reset() {
-	*x = ModifiedRealsForVip{}
+func (x *RealRingShare) Reset() {
+	*x = RealRingShare{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_pb_l4slb_proto_msgTypes[14]
+		mi := &file_pkg_pb_l4slb_proto_msgTypes[34]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *ModifiedRealsForVip) String() string {
+func (x *RealRingShare) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ModifiedRealsForVip) ProtoMessage() {}
+func (*RealRingShare) ProtoMessage() {}
 
-func (x *ModifiedRealsForVip) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_pb_l4slb_proto_msgTypes[14]
+func (x *RealRingShare) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_l4slb_proto_msgTypes[34]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -842,58 +2200,170 @@ func (x *ModifiedRealsForVip) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ModifiedRealsForVip.ProtoReflect.Descriptor instead.
-func (*ModifiedRealsForVip) Descriptor() ([]byte, []int) {
-	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{14}
+// Deprecated: Use RealRingShare.ProtoReflect.Descriptor instead.
+func (*RealRingShare) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{34}
 }
 
-func (x *ModifiedRealsForVip) GetAction() Action {
+func (x *RealRingShare) GetAddress() string {
 	if x != nil {
-		return x.Action
+		return x.Address
 	}
-	return Action_ADD
+	return ""
 }
 
-func (x *ModifiedRealsForVip) GetReal() *Reals {
+func (x *RealRingShare) GetWeight() uint32 {
 	if x != nil {
-		return x.Real
+		return x.Weight
+	}
+	return 0
+}
+
+func (x *RealRingShare) GetPositions() uint32 {
+	if x != nil {
+		return x.Positions
+	}
+	return 0
+}
+
+func (x *RealRingShare) GetShare() float64 {
+	if x != nil {
+		return x.Share
+	}
+	return 0
+}
+
+func (x *RealRingShare) GetIdealShare() float64 {
+	if x != nil {
+		return x.IdealShare
+	}
+	return 0
+}
+
+type RingSimulation struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RingSize uint32 `protobuf:"varint,1,opt,name=ring_size,json=ringSize,proto3" json:"ring_size,omitempty"`
+	// positions of the ring which would change owner
+	ChangedPositions uint32           `protobuf:"varint,2,opt,name=changed_positions,json=changedPositions,proto3" json:"changed_positions,omitempty"`
+	ChangedFraction  float64          `protobuf:"fixed64,3,opt,name=changed_fraction,json=changedFraction,proto3" json:"changed_fraction,omitempty"`
+	Before           []*RealRingShare `protobuf:"bytes,4,rep,name=before,proto3" json:"before,omitempty"`
+	// max over min of share / ideal_share of reals, 1 is perfect balance
+	ImbalanceBefore float64          `protobuf:"fixed64,5,opt,name=imbalance_before,json=imbalanceBefore,proto3" json:"imbalance_before,omitempty"`
+	After           []*RealRingShare `protobuf:"bytes,6,rep,name=after,proto3" json:"after,omitempty"`
+	ImbalanceAfter  float64          `protobuf:"fixed64,7,opt,name=imbalance_after,json=imbalanceAfter,proto3" json:"imbalance_after,omitempty"`
+}
+
+func (x *RingSimulation) Reset() {
+	*x = RingSimulation{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_pb_l4slb_proto_msgTypes[35]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RingSimulation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RingSimulation) ProtoMessage() {}
+
+func (x *RingSimulation) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_l4slb_proto_msgTypes[35]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RingSimulation.ProtoReflect.Descriptor instead.
+func (*RingSimulation) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *RingSimulation) GetRingSize() uint32 {
+	if x != nil {
+		return x.RingSize
+	}
+	return 0
+}
+
+func (x *RingSimulation) GetChangedPositions() uint32 {
+	if x != nil {
+		return x.ChangedPositions
+	}
+	return 0
+}
+
+func (x *RingSimulation) GetChangedFraction() float64 {
+	if x != nil {
+		return x.ChangedFraction
+	}
+	return 0
+}
+
+func (x *RingSimulation) GetBefore() []*RealRingShare {
+	if x != nil {
+		return x.Before
 	}
 	return nil
 }
 
-func (x *ModifiedRealsForVip) GetVip() *Vip {
+func (x *RingSimulation) GetImbalanceBefore() float64 {
 	if x != nil {
-		return x.Vip
+		return x.ImbalanceBefore
+	}
+	return 0
+}
+
+func (x *RingSimulation) GetAfter() []*RealRingShare {
+	if x != nil {
+		return x.After
 	}
 	return nil
 }
 
-type ModifiedQuicReals struct {
+func (x *RingSimulation) GetImbalanceAfter() float64 {
+	if x != nil {
+		return x.ImbalanceAfter
+	}
+	return 0
+}
+
+type HashRingRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Action Action     `protobuf:"varint,1,opt,name=action,proto3,enum=Action" json:"action,omitempty"`
-	Reals  *QuicReals `protobuf:"bytes,2,opt,name=reals,proto3" json:"reals,omitempty"`
+	Vip *Vip `protobuf:"bytes,1,opt,name=vip,proto3" json:"vip,omitempty"`
+	// read the ring back from ch_rings and compare it with the generated one
+	ReadKernel bool `protobuf:"varint,2,opt,name=read_kernel,json=readKernel,proto3" json:"read_kernel,omitempty"`
 }
 
-func (x *ModifiedQuicReals) Reset() {
-	*x = ModifiedQuicReals{}
+func (x *HashRingRequest) Reset() {
+	*x = HashRingRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_pb_l4slb_proto_msgTypes[15]
+		mi := &file_pkg_pb_l4slb_proto_msgTypes[36]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *ModifiedQuicReals) String() string {
+func (x *HashRingRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ModifiedQuicReals) ProtoMessage() {}
+func (*HashRingRequest) ProtoMessage() {}
 
-func (x *ModifiedQuicReals) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_pb_l4slb_proto_msgTypes[15]
+func (x *HashRingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_l4slb_proto_msgTypes[36]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -904,21 +2374,128 @@ func (x *ModifiedQuicReals) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ModifiedQuicReals.ProtoReflect.Descriptor instead.
-func (*ModifiedQuicReals) Descriptor() ([]byte, []int) {
-	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{15}
+// Deprecated: Use HashRingRequest.ProtoReflect.Descriptor instead.
+func (*HashRingRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{36}
 }
 
-func (x *ModifiedQuicReals) GetAction() Action {
+func (x *HashRingRequest) GetVip() *Vip {
 	if x != nil {
-		return x.Action
+		return x.Vip
 	}
-	return Action_ADD
+	return nil
 }
 
-func (x *ModifiedQuicReals) GetReals() *QuicReals {
+func (x *HashRingRequest) GetReadKernel() bool {
 	if x != nil {
-		return x.Reals
+		return x.ReadKernel
+	}
+	return false
+}
+
+type HashRing struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RingSize uint32 `protobuf:"varint,1,opt,name=ring_size,json=ringSize,proto3" json:"ring_size,omitempty"`
+	// position of the ring's first entry in ch_rings
+	RingOffset   uint32 `protobuf:"varint,2,opt,name=ring_offset,json=ringOffset,proto3" json:"ring_offset,omitempty"`
+	HashFunction string `protobuf:"bytes,3,opt,name=hash_function,json=hashFunction,proto3" json:"hash_function,omitempty"`
+	// number of the real at each position, -1 if no real has been placed there yet
+	Positions []int32 `protobuf:"varint,4,rep,packed,name=positions,proto3" json:"positions,omitempty"`
+	// addresses of reals of positions and kernel_positions by their numbers
+	Addresses       map[uint32]string `protobuf:"bytes,5,rep,name=addresses,proto3" json:"addresses,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	KernelRead      bool              `protobuf:"varint,6,opt,name=kernel_read,json=kernelRead,proto3" json:"kernel_read,omitempty"`
+	KernelPositions []uint32          `protobuf:"varint,7,rep,packed,name=kernel_positions,json=kernelPositions,proto3" json:"kernel_positions,omitempty"`
+	// positions whose real in ch_rings differs from the generated one
+	Mismatched []uint32 `protobuf:"varint,8,rep,packed,name=mismatched,proto3" json:"mismatched,omitempty"`
+}
+
+func (x *HashRing) Reset() {
+	*x = HashRing{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_pb_l4slb_proto_msgTypes[37]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HashRing) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HashRing) ProtoMessage() {}
+
+func (x *HashRing) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_l4slb_proto_msgTypes[37]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HashRing.ProtoReflect.Descriptor instead.
+func (*HashRing) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *HashRing) GetRingSize() uint32 {
+	if x != nil {
+		return x.RingSize
+	}
+	return 0
+}
+
+func (x *HashRing) GetRingOffset() uint32 {
+	if x != nil {
+		return x.RingOffset
+	}
+	return 0
+}
+
+func (x *HashRing) GetHashFunction() string {
+	if x != nil {
+		return x.HashFunction
+	}
+	return ""
+}
+
+func (x *HashRing) GetPositions() []int32 {
+	if x != nil {
+		return x.Positions
+	}
+	return nil
+}
+
+func (x *HashRing) GetAddresses() map[uint32]string {
+	if x != nil {
+		return x.Addresses
+	}
+	return nil
+}
+
+func (x *HashRing) GetKernelRead() bool {
+	if x != nil {
+		return x.KernelRead
+	}
+	return false
+}
+
+func (x *HashRing) GetKernelPositions() []uint32 {
+	if x != nil {
+		return x.KernelPositions
+	}
+	return nil
+}
+
+func (x *HashRing) GetMismatched() []uint32 {
+	if x != nil {
+		return x.Mismatched
 	}
 	return nil
 }
@@ -935,7 +2512,7 @@ type RealForVip struct {
 func (x *RealForVip) Reset() {
 	*x = RealForVip{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_pb_l4slb_proto_msgTypes[16]
+		mi := &file_pkg_pb_l4slb_proto_msgTypes[38]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -948,7 +2525,7 @@ func (x *RealForVip) String() string {
 func (*RealForVip) ProtoMessage() {}
 
 func (x *RealForVip) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_pb_l4slb_proto_msgTypes[16]
+	mi := &file_pkg_pb_l4slb_proto_msgTypes[38]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -961,7 +2538,7 @@ func (x *RealForVip) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RealForVip.ProtoReflect.Descriptor instead.
 func (*RealForVip) Descriptor() ([]byte, []int) {
-	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{16}
+	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{38}
 }
 
 func (x *RealForVip) GetReal() *Real {
@@ -989,7 +2566,7 @@ type Flags struct {
 func (x *Flags) Reset() {
 	*x = Flags{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_pb_l4slb_proto_msgTypes[17]
+		mi := &file_pkg_pb_l4slb_proto_msgTypes[39]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1002,7 +2579,7 @@ func (x *Flags) String() string {
 func (*Flags) ProtoMessage() {}
 
 func (x *Flags) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_pb_l4slb_proto_msgTypes[17]
+	mi := &file_pkg_pb_l4slb_proto_msgTypes[39]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1015,7 +2592,7 @@ func (x *Flags) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Flags.ProtoReflect.Descriptor instead.
 func (*Flags) Descriptor() ([]byte, []int) {
-	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{17}
+	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{39}
 }
 
 func (x *Flags) GetFlags() uint64 {
@@ -1036,7 +2613,7 @@ type Somark struct {
 func (x *Somark) Reset() {
 	*x = Somark{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_pb_l4slb_proto_msgTypes[18]
+		mi := &file_pkg_pb_l4slb_proto_msgTypes[40]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1049,7 +2626,7 @@ func (x *Somark) String() string {
 func (*Somark) ProtoMessage() {}
 
 func (x *Somark) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_pb_l4slb_proto_msgTypes[18]
+	mi := &file_pkg_pb_l4slb_proto_msgTypes[40]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1062,7 +2639,7 @@ func (x *Somark) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Somark.ProtoReflect.Descriptor instead.
 func (*Somark) Descriptor() ([]byte, []int) {
-	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{18}
+	return file_pkg_pb_l4slb_proto_rawDescGZIP(), []int{40}
 }
 
 func (x *Somark) GetSomark() uint32 {
@@ -1084,124 +2661,371 @@ var file_pkg_pb_l4slb_proto_rawDesc = []byte{
 	0x12, 0x12, 0x0a, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04,
 	0x70, 0x6f, 0x72, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c,
 	0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c,
-	0x22, 0x51, 0x0a, 0x07, 0x56, 0x69, 0x70, 0x4d, 0x65, 0x74, 0x61, 0x12, 0x16, 0x0a, 0x03, 0x76,
-	0x69, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x04, 0x2e, 0x56, 0x69, 0x70, 0x52, 0x03,
-	0x76, 0x69, 0x70, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x05, 0x52, 0x05, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x65, 0x74,
-	0x46, 0x6c, 0x61, 0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x73, 0x65, 0x74, 0x46,
-	0x6c, 0x61, 0x67, 0x22, 0x54, 0x0a, 0x08, 0x52, 0x65, 0x61, 0x6c, 0x4d, 0x65, 0x74, 0x61, 0x12,
-	0x18, 0x0a, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x6c, 0x61,
-	0x67, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x12,
-	0x18, 0x0a, 0x07, 0x73, 0x65, 0x74, 0x46, 0x6c, 0x61, 0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08,
-	0x52, 0x07, 0x73, 0x65, 0x74, 0x46, 0x6c, 0x61, 0x67, 0x22, 0x4e, 0x0a, 0x04, 0x52, 0x65, 0x61,
+	0x22, 0x93, 0x01, 0x0a, 0x07, 0x56, 0x69, 0x70, 0x4d, 0x65, 0x74, 0x61, 0x12, 0x16, 0x0a, 0x03,
+	0x76, 0x69, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x04, 0x2e, 0x56, 0x69, 0x70, 0x52,
+	0x03, 0x76, 0x69, 0x70, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x05, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x65,
+	0x74, 0x46, 0x6c, 0x61, 0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x73, 0x65, 0x74,
+	0x46, 0x6c, 0x61, 0x67, 0x12, 0x1b, 0x0a, 0x09, 0x72, 0x69, 0x6e, 0x67, 0x5f, 0x73, 0x69, 0x7a,
+	0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x72, 0x69, 0x6e, 0x67, 0x53, 0x69, 0x7a,
+	0x65, 0x12, 0x23, 0x0a, 0x0d, 0x68, 0x61, 0x73, 0x68, 0x5f, 0x66, 0x75, 0x6e, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x68, 0x61, 0x73, 0x68, 0x46, 0x75,
+	0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x4e, 0x0a, 0x0f, 0x56, 0x69, 0x70, 0x48, 0x61, 0x73,
+	0x68, 0x46, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x03, 0x76, 0x69, 0x70,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x04, 0x2e, 0x56, 0x69, 0x70, 0x52, 0x03, 0x76, 0x69,
+	0x70, 0x12, 0x23, 0x0a, 0x0d, 0x68, 0x61, 0x73, 0x68, 0x5f, 0x66, 0x75, 0x6e, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x68, 0x61, 0x73, 0x68, 0x46, 0x75,
+	0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x77, 0x0a, 0x07, 0x56, 0x69, 0x70, 0x49, 0x6e, 0x66,
+	0x6f, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x05, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x68, 0x61, 0x73, 0x68, 0x5f,
+	0x66, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c,
+	0x68, 0x61, 0x73, 0x68, 0x46, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1b, 0x0a, 0x09,
+	0x72, 0x69, 0x6e, 0x67, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x08, 0x72, 0x69, 0x6e, 0x67, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x65, 0x61,
+	0x6c, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x72, 0x65, 0x61, 0x6c, 0x73, 0x22,
+	0x54, 0x0a, 0x08, 0x52, 0x65, 0x61, 0x6c, 0x4d, 0x65, 0x74, 0x61, 0x12, 0x18, 0x0a, 0x07, 0x61,
+	0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x61, 0x64,
+	0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x73,
+	0x65, 0x74, 0x46, 0x6c, 0x61, 0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x73, 0x65,
+	0x74, 0x46, 0x6c, 0x61, 0x67, 0x22, 0x4e, 0x0a, 0x04, 0x52, 0x65, 0x61, 0x6c, 0x12, 0x18, 0x0a,
+	0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
+	0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x77, 0x65, 0x69, 0x67, 0x68,
+	0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x12,
+	0x14, 0x0a, 0x05, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05,
+	0x66, 0x6c, 0x61, 0x67, 0x73, 0x22, 0x34, 0x0a, 0x08, 0x51, 0x75, 0x69, 0x63, 0x52, 0x65, 0x61,
 	0x6c, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x77,
-	0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x77, 0x65, 0x69,
-	0x67, 0x68, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x18, 0x03, 0x20, 0x01,
-	0x28, 0x05, 0x52, 0x05, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x22, 0x34, 0x0a, 0x08, 0x51, 0x75, 0x69,
-	0x63, 0x52, 0x65, 0x61, 0x6c, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12,
-	0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x69, 0x64, 0x22,
-	0x17, 0x0a, 0x03, 0x4d, 0x61, 0x63, 0x12, 0x10, 0x0a, 0x03, 0x6d, 0x61, 0x63, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x03, 0x6d, 0x61, 0x63, 0x22, 0x27, 0x0a, 0x05, 0x53, 0x74, 0x61, 0x74,
-	0x73, 0x12, 0x0e, 0x0a, 0x02, 0x76, 0x31, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x02, 0x76,
-	0x31, 0x12, 0x0e, 0x0a, 0x02, 0x76, 0x32, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x02, 0x76,
-	0x32, 0x22, 0x3f, 0x0a, 0x0b, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x63, 0x68, 0x65, 0x63, 0x6b,
-	0x12, 0x16, 0x0a, 0x06, 0x73, 0x6f, 0x6d, 0x61, 0x72, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d,
-	0x52, 0x06, 0x73, 0x6f, 0x6d, 0x61, 0x72, 0x6b, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x64, 0x64, 0x72,
-	0x65, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65,
-	0x73, 0x73, 0x22, 0x86, 0x01, 0x0a, 0x05, 0x68, 0x63, 0x4d, 0x61, 0x70, 0x12, 0x3c, 0x0a, 0x0c,
-	0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x18, 0x01, 0x20, 0x03,
-	0x28, 0x0b, 0x32, 0x18, 0x2e, 0x68, 0x63, 0x4d, 0x61, 0x70, 0x2e, 0x48, 0x65, 0x61, 0x6c, 0x74,
-	0x68, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0c, 0x68, 0x65,
-	0x61, 0x6c, 0x74, 0x68, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x1a, 0x3f, 0x0a, 0x11, 0x48, 0x65,
-	0x61, 0x6c, 0x74, 0x68, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12,
-	0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x03, 0x6b, 0x65,
-	0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x24, 0x0a, 0x05, 0x52,
-	0x65, 0x61, 0x6c, 0x73, 0x12, 0x1b, 0x0a, 0x05, 0x72, 0x65, 0x61, 0x6c, 0x73, 0x18, 0x01, 0x20,
-	0x03, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x52, 0x65, 0x61, 0x6c, 0x52, 0x05, 0x72, 0x65, 0x61, 0x6c,
-	0x73, 0x22, 0x20, 0x0a, 0x04, 0x56, 0x69, 0x70, 0x73, 0x12, 0x18, 0x0a, 0x04, 0x76, 0x69, 0x70,
-	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x04, 0x2e, 0x56, 0x69, 0x70, 0x52, 0x04, 0x76,
-	0x69, 0x70, 0x73, 0x22, 0x2e, 0x0a, 0x09, 0x51, 0x75, 0x69, 0x63, 0x52, 0x65, 0x61, 0x6c, 0x73,
-	0x12, 0x21, 0x0a, 0x06, 0x71, 0x72, 0x65, 0x61, 0x6c, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
-	0x32, 0x09, 0x2e, 0x51, 0x75, 0x69, 0x63, 0x52, 0x65, 0x61, 0x6c, 0x52, 0x06, 0x71, 0x72, 0x65,
-	0x61, 0x6c, 0x73, 0x22, 0x6a, 0x0a, 0x13, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x69, 0x65, 0x64, 0x52,
-	0x65, 0x61, 0x6c, 0x73, 0x46, 0x6f, 0x72, 0x56, 0x69, 0x70, 0x12, 0x1f, 0x0a, 0x06, 0x61, 0x63,
-	0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x07, 0x2e, 0x41, 0x63, 0x74,
-	0x69, 0x6f, 0x6e, 0x52, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x04, 0x72,
-	0x65, 0x61, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x06, 0x2e, 0x52, 0x65, 0x61, 0x6c,
-	0x73, 0x52, 0x04, 0x72, 0x65, 0x61, 0x6c, 0x12, 0x16, 0x0a, 0x03, 0x76, 0x69, 0x70, 0x18, 0x03,
+	0x28, 0x09, 0x52, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x0e, 0x0a, 0x02, 0x69,
+	0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x69, 0x64, 0x22, 0x17, 0x0a, 0x03, 0x4d,
+	0x61, 0x63, 0x12, 0x10, 0x0a, 0x03, 0x6d, 0x61, 0x63, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x6d, 0x61, 0x63, 0x22, 0x39, 0x0a, 0x07, 0x4e, 0x65, 0x78, 0x74, 0x68, 0x6f, 0x70, 0x12,
+	0x10, 0x0a, 0x03, 0x6d, 0x61, 0x63, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6d, 0x61,
+	0x63, 0x12, 0x1c, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x06, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x73, 0x22,
+	0x30, 0x0a, 0x08, 0x4e, 0x65, 0x78, 0x74, 0x68, 0x6f, 0x70, 0x73, 0x12, 0x24, 0x0a, 0x08, 0x6e,
+	0x65, 0x78, 0x74, 0x68, 0x6f, 0x70, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x08, 0x2e,
+	0x4e, 0x65, 0x78, 0x74, 0x68, 0x6f, 0x70, 0x52, 0x08, 0x6e, 0x65, 0x78, 0x74, 0x68, 0x6f, 0x70,
+	0x73, 0x22, 0x27, 0x0a, 0x05, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x0e, 0x0a, 0x02, 0x76, 0x31,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x02, 0x76, 0x31, 0x12, 0x0e, 0x0a, 0x02, 0x76, 0x32,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x02, 0x76, 0x32, 0x22, 0xa2, 0x01, 0x0a, 0x0b, 0x4c,
+	0x72, 0x75, 0x4d, 0x61, 0x70, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x10, 0x0a, 0x03, 0x63, 0x70,
+	0x75, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x03, 0x63, 0x70, 0x75, 0x12, 0x1b, 0x0a, 0x09,
+	0x6e, 0x75, 0x6d, 0x61, 0x5f, 0x6e, 0x6f, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x08, 0x6e, 0x75, 0x6d, 0x61, 0x4e, 0x6f, 0x64, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x66, 0x61, 0x6c,
+	0x6c, 0x62, 0x61, 0x63, 0x6b, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x66, 0x61, 0x6c,
+	0x6c, 0x62, 0x61, 0x63, 0x6b, 0x12, 0x1f, 0x0a, 0x0b, 0x6d, 0x61, 0x78, 0x5f, 0x65, 0x6e, 0x74,
+	0x72, 0x69, 0x65, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x6d, 0x61, 0x78, 0x45,
+	0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e,
+	0x74, 0x5f, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x0e, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x22,
+	0x5b, 0x0a, 0x0c, 0x4c, 0x72, 0x75, 0x4d, 0x61, 0x70, 0x73, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12,
+	0x1e, 0x0a, 0x03, 0x6c, 0x72, 0x75, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x4c,
+	0x72, 0x75, 0x4d, 0x61, 0x70, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x03, 0x6c, 0x72, 0x75, 0x12,
+	0x2b, 0x0a, 0x0a, 0x67, 0x6c, 0x6f, 0x62, 0x61, 0x6c, 0x5f, 0x6c, 0x72, 0x75, 0x18, 0x02, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x4c, 0x72, 0x75, 0x4d, 0x61, 0x70, 0x53, 0x74, 0x61, 0x74,
+	0x73, 0x52, 0x09, 0x67, 0x6c, 0x6f, 0x62, 0x61, 0x6c, 0x4c, 0x72, 0x75, 0x22, 0x6b, 0x0a, 0x0b,
+	0x42, 0x70, 0x66, 0x4d, 0x61, 0x70, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12,
+	0x1f, 0x0a, 0x0b, 0x6d, 0x61, 0x78, 0x5f, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x6d, 0x61, 0x78, 0x45, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73,
+	0x12, 0x27, 0x0a, 0x0f, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x5f, 0x65, 0x6e, 0x74, 0x72,
+	0x69, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0e, 0x63, 0x75, 0x72, 0x72, 0x65,
+	0x6e, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x22, 0x30, 0x0a, 0x0c, 0x42, 0x70, 0x66,
+	0x4d, 0x61, 0x70, 0x73, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x20, 0x0a, 0x04, 0x6d, 0x61, 0x70,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x42, 0x70, 0x66, 0x4d, 0x61, 0x70,
+	0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x04, 0x6d, 0x61, 0x70, 0x73, 0x22, 0x9c, 0x04, 0x0a, 0x0f,
+	0x43, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x6c, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12,
+	0x28, 0x0a, 0x10, 0x62, 0x70, 0x66, 0x5f, 0x66, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x5f, 0x63, 0x61,
+	0x6c, 0x6c, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0e, 0x62, 0x70, 0x66, 0x46, 0x61,
+	0x69, 0x6c, 0x65, 0x64, 0x43, 0x61, 0x6c, 0x6c, 0x73, 0x12, 0x34, 0x0a, 0x16, 0x61, 0x64, 0x64,
+	0x72, 0x5f, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x66, 0x61, 0x69,
+	0x6c, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x14, 0x61, 0x64, 0x64, 0x72, 0x56,
+	0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x46, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x12,
+	0x2f, 0x0a, 0x13, 0x72, 0x69, 0x6e, 0x67, 0x5f, 0x72, 0x65, 0x63, 0x6f, 0x6d, 0x70, 0x75, 0x74,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x12, 0x72, 0x69,
+	0x6e, 0x67, 0x52, 0x65, 0x63, 0x6f, 0x6d, 0x70, 0x75, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x12, 0x35, 0x0a, 0x17, 0x72, 0x69, 0x6e, 0x67, 0x5f, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x75, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x14, 0x72, 0x69, 0x6e, 0x67, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x54, 0x69, 0x6d, 0x65, 0x55, 0x73, 0x12, 0x2c, 0x0a, 0x12, 0x72, 0x69, 0x6e, 0x67, 0x5f,
+	0x62, 0x61, 0x74, 0x63, 0x68, 0x5f, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x73, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x10, 0x72, 0x69, 0x6e, 0x67, 0x42, 0x61, 0x74, 0x63, 0x68, 0x55, 0x70,
+	0x64, 0x61, 0x74, 0x65, 0x73, 0x12, 0x34, 0x0a, 0x16, 0x72, 0x69, 0x6e, 0x67, 0x5f, 0x70, 0x6f,
+	0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x5f, 0x77, 0x72, 0x69, 0x74, 0x74, 0x65, 0x6e, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x04, 0x52, 0x14, 0x72, 0x69, 0x6e, 0x67, 0x50, 0x6f, 0x73, 0x69, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x57, 0x72, 0x69, 0x74, 0x74, 0x65, 0x6e, 0x12, 0x3a, 0x0a, 0x1a, 0x6d,
+	0x61, 0x78, 0x5f, 0x72, 0x69, 0x6e, 0x67, 0x5f, 0x62, 0x61, 0x74, 0x63, 0x68, 0x5f, 0x75, 0x70,
+	0x64, 0x61, 0x74, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x16, 0x6d, 0x61, 0x78, 0x52, 0x69, 0x6e, 0x67, 0x42, 0x61, 0x74, 0x63, 0x68, 0x55, 0x70, 0x64,
+	0x61, 0x74, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x22, 0x0a, 0x0d, 0x66, 0x72, 0x65, 0x65, 0x5f,
+	0x76, 0x69, 0x70, 0x5f, 0x6e, 0x75, 0x6d, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b,
+	0x66, 0x72, 0x65, 0x65, 0x56, 0x69, 0x70, 0x4e, 0x75, 0x6d, 0x73, 0x12, 0x24, 0x0a, 0x0e, 0x66,
+	0x72, 0x65, 0x65, 0x5f, 0x72, 0x65, 0x61, 0x6c, 0x5f, 0x6e, 0x75, 0x6d, 0x73, 0x18, 0x09, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x0c, 0x66, 0x72, 0x65, 0x65, 0x52, 0x65, 0x61, 0x6c, 0x4e, 0x75, 0x6d,
+	0x73, 0x12, 0x27, 0x0a, 0x10, 0x66, 0x72, 0x65, 0x65, 0x5f, 0x68, 0x63, 0x5f, 0x6b, 0x65, 0x79,
+	0x5f, 0x6e, 0x75, 0x6d, 0x73, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0d, 0x66, 0x72, 0x65,
+	0x65, 0x48, 0x63, 0x4b, 0x65, 0x79, 0x4e, 0x75, 0x6d, 0x73, 0x12, 0x2e, 0x0a, 0x13, 0x66, 0x72,
+	0x65, 0x65, 0x5f, 0x72, 0x69, 0x6e, 0x67, 0x5f, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e,
+	0x73, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x11, 0x66, 0x72, 0x65, 0x65, 0x52, 0x69, 0x6e,
+	0x67, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x7c, 0x0a, 0x04, 0x46, 0x6c,
+	0x6f, 0x77, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x72, 0x63, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x73, 0x72, 0x63, 0x12, 0x10, 0x0a, 0x03, 0x64, 0x73, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x64, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x73, 0x72, 0x63, 0x5f, 0x70, 0x6f,
+	0x72, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x73, 0x72, 0x63, 0x50, 0x6f, 0x72,
+	0x74, 0x12, 0x19, 0x0a, 0x08, 0x64, 0x73, 0x74, 0x5f, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x07, 0x64, 0x73, 0x74, 0x50, 0x6f, 0x72, 0x74, 0x12, 0x1a, 0x0a, 0x08,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x22, 0xf6, 0x01, 0x0a, 0x0d, 0x46, 0x6c, 0x6f,
+	0x77, 0x44, 0x65, 0x62, 0x75, 0x67, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x10, 0x0a, 0x03, 0x63, 0x70,
+	0x75, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x03, 0x63, 0x70, 0x75, 0x12, 0x15, 0x0a, 0x06,
+	0x69, 0x6e, 0x5f, 0x6c, 0x72, 0x75, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x69, 0x6e,
+	0x4c, 0x72, 0x75, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x65, 0x61, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x72, 0x65, 0x61, 0x6c, 0x12, 0x14, 0x0a, 0x05, 0x61, 0x74, 0x69, 0x6d, 0x65,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x61, 0x74, 0x69, 0x6d, 0x65, 0x12, 0x22, 0x0a,
+	0x0d, 0x69, 0x6e, 0x5f, 0x67, 0x6c, 0x6f, 0x62, 0x61, 0x6c, 0x5f, 0x6c, 0x72, 0x75, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x69, 0x6e, 0x47, 0x6c, 0x6f, 0x62, 0x61, 0x6c, 0x4c, 0x72,
+	0x75, 0x12, 0x1f, 0x0a, 0x0b, 0x67, 0x6c, 0x6f, 0x62, 0x61, 0x6c, 0x5f, 0x72, 0x65, 0x61, 0x6c,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x67, 0x6c, 0x6f, 0x62, 0x61, 0x6c, 0x52, 0x65,
+	0x61, 0x6c, 0x12, 0x1b, 0x0a, 0x09, 0x68, 0x61, 0x73, 0x5f, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x68, 0x61, 0x73, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x12,
+	0x15, 0x0a, 0x06, 0x6c, 0x34, 0x5f, 0x68, 0x6f, 0x70, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x6c, 0x34, 0x48, 0x6f, 0x70, 0x12, 0x19, 0x0a, 0x08, 0x74, 0x68, 0x69, 0x73, 0x5f, 0x68,
+	0x6f, 0x70, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x74, 0x68, 0x69, 0x73, 0x48, 0x6f,
+	0x70, 0x22, 0x54, 0x0a, 0x0e, 0x46, 0x6c, 0x6f, 0x77, 0x44, 0x65, 0x62, 0x75, 0x67, 0x49, 0x6e,
+	0x66, 0x6f, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x66, 0x6c, 0x6f, 0x77, 0x44, 0x65, 0x62, 0x75, 0x67,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x66, 0x6c, 0x6f, 0x77, 0x44, 0x65, 0x62, 0x75,
+	0x67, 0x12, 0x24, 0x0a, 0x05, 0x69, 0x6e, 0x66, 0x6f, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x0e, 0x2e, 0x46, 0x6c, 0x6f, 0x77, 0x44, 0x65, 0x62, 0x75, 0x67, 0x49, 0x6e, 0x66, 0x6f,
+	0x52, 0x05, 0x69, 0x6e, 0x66, 0x6f, 0x73, 0x22, 0x3f, 0x0a, 0x0b, 0x52, 0x65, 0x61, 0x6c, 0x4c,
+	0x72, 0x75, 0x4d, 0x69, 0x73, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73,
+	0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73,
+	0x12, 0x16, 0x0a, 0x06, 0x6d, 0x69, 0x73, 0x73, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x06, 0x6d, 0x69, 0x73, 0x73, 0x65, 0x73, 0x22, 0x6a, 0x0a, 0x12, 0x4c, 0x72, 0x75, 0x4d,
+	0x69, 0x73, 0x73, 0x53, 0x74, 0x61, 0x74, 0x73, 0x46, 0x6f, 0x72, 0x56, 0x69, 0x70, 0x12, 0x18,
+	0x0a, 0x07, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x07, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x65, 0x64, 0x12, 0x16, 0x0a, 0x03, 0x76, 0x69, 0x70, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x04, 0x2e, 0x56, 0x69, 0x70, 0x52, 0x03, 0x76, 0x69, 0x70,
+	0x12, 0x22, 0x0a, 0x05, 0x72, 0x65, 0x61, 0x6c, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x0c, 0x2e, 0x52, 0x65, 0x61, 0x6c, 0x4c, 0x72, 0x75, 0x4d, 0x69, 0x73, 0x73, 0x52, 0x05, 0x72,
+	0x65, 0x61, 0x6c, 0x73, 0x22, 0x77, 0x0a, 0x0c, 0x4d, 0x6f, 0x6e, 0x69, 0x74, 0x6f, 0x72, 0x53,
+	0x74, 0x61, 0x74, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x12, 0x14,
+	0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x6c,
+	0x69, 0x6d, 0x69, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x1f, 0x0a, 0x0b,
+	0x62, 0x75, 0x66, 0x66, 0x65, 0x72, 0x5f, 0x66, 0x75, 0x6c, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x0a, 0x62, 0x75, 0x66, 0x66, 0x65, 0x72, 0x46, 0x75, 0x6c, 0x6c, 0x22, 0x40, 0x0a,
+	0x0e, 0x43, 0x61, 0x70, 0x74, 0x75, 0x72, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x16, 0x0a, 0x06, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x06, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x16, 0x0a, 0x03, 0x76, 0x69, 0x70, 0x18, 0x02,
 	0x20, 0x01, 0x28, 0x0b, 0x32, 0x04, 0x2e, 0x56, 0x69, 0x70, 0x52, 0x03, 0x76, 0x69, 0x70, 0x22,
-	0x56, 0x0a, 0x11, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x69, 0x65, 0x64, 0x51, 0x75, 0x69, 0x63, 0x52,
-	0x65, 0x61, 0x6c, 0x73, 0x12, 0x1f, 0x0a, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x0e, 0x32, 0x07, 0x2e, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x06, 0x61,
-	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x20, 0x0a, 0x05, 0x72, 0x65, 0x61, 0x6c, 0x73, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x51, 0x75, 0x69, 0x63, 0x52, 0x65, 0x61, 0x6c, 0x73,
-	0x52, 0x05, 0x72, 0x65, 0x61, 0x6c, 0x73, 0x22, 0x3f, 0x0a, 0x0a, 0x72, 0x65, 0x61, 0x6c, 0x46,
-	0x6f, 0x72, 0x56, 0x69, 0x70, 0x12, 0x19, 0x0a, 0x04, 0x72, 0x65, 0x61, 0x6c, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x52, 0x65, 0x61, 0x6c, 0x52, 0x04, 0x72, 0x65, 0x61, 0x6c,
-	0x12, 0x16, 0x0a, 0x03, 0x76, 0x69, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x04, 0x2e,
-	0x56, 0x69, 0x70, 0x52, 0x03, 0x76, 0x69, 0x70, 0x22, 0x1d, 0x0a, 0x05, 0x46, 0x6c, 0x61, 0x67,
-	0x73, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04,
-	0x52, 0x05, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x22, 0x20, 0x0a, 0x06, 0x53, 0x6f, 0x6d, 0x61, 0x72,
-	0x6b, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x6f, 0x6d, 0x61, 0x72, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x0d, 0x52, 0x06, 0x73, 0x6f, 0x6d, 0x61, 0x72, 0x6b, 0x2a, 0x1a, 0x0a, 0x06, 0x41, 0x63, 0x74,
-	0x69, 0x6f, 0x6e, 0x12, 0x07, 0x0a, 0x03, 0x41, 0x44, 0x44, 0x10, 0x00, 0x12, 0x07, 0x0a, 0x03,
-	0x44, 0x45, 0x4c, 0x10, 0x01, 0x32, 0xad, 0x06, 0x0a, 0x0a, 0x53, 0x6c, 0x62, 0x53, 0x65, 0x72,
-	0x76, 0x69, 0x63, 0x65, 0x12, 0x18, 0x0a, 0x09, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x4d, 0x61,
-	0x63, 0x12, 0x04, 0x2e, 0x4d, 0x61, 0x63, 0x1a, 0x05, 0x2e, 0x42, 0x6f, 0x6f, 0x6c, 0x12, 0x16,
-	0x0a, 0x06, 0x67, 0x65, 0x74, 0x4d, 0x61, 0x63, 0x12, 0x06, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79,
-	0x1a, 0x04, 0x2e, 0x4d, 0x61, 0x63, 0x12, 0x19, 0x0a, 0x06, 0x61, 0x64, 0x64, 0x56, 0x69, 0x70,
-	0x12, 0x08, 0x2e, 0x56, 0x69, 0x70, 0x4d, 0x65, 0x74, 0x61, 0x1a, 0x05, 0x2e, 0x42, 0x6f, 0x6f,
-	0x6c, 0x12, 0x15, 0x0a, 0x06, 0x64, 0x65, 0x6c, 0x56, 0x69, 0x70, 0x12, 0x04, 0x2e, 0x56, 0x69,
-	0x70, 0x1a, 0x05, 0x2e, 0x42, 0x6f, 0x6f, 0x6c, 0x12, 0x1b, 0x0a, 0x0a, 0x67, 0x65, 0x74, 0x41,
-	0x6c, 0x6c, 0x56, 0x69, 0x70, 0x73, 0x12, 0x06, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x05,
-	0x2e, 0x56, 0x69, 0x70, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x79, 0x56,
-	0x69, 0x70, 0x12, 0x08, 0x2e, 0x56, 0x69, 0x70, 0x4d, 0x65, 0x74, 0x61, 0x1a, 0x05, 0x2e, 0x42,
-	0x6f, 0x6f, 0x6c, 0x12, 0x1e, 0x0a, 0x0a, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x79, 0x52, 0x65, 0x61,
-	0x6c, 0x12, 0x09, 0x2e, 0x52, 0x65, 0x61, 0x6c, 0x4d, 0x65, 0x74, 0x61, 0x1a, 0x05, 0x2e, 0x42,
-	0x6f, 0x6f, 0x6c, 0x12, 0x1b, 0x0a, 0x0b, 0x67, 0x65, 0x74, 0x56, 0x69, 0x70, 0x46, 0x6c, 0x61,
-	0x67, 0x73, 0x12, 0x04, 0x2e, 0x56, 0x69, 0x70, 0x1a, 0x06, 0x2e, 0x46, 0x6c, 0x61, 0x67, 0x73,
-	0x12, 0x1d, 0x0a, 0x0c, 0x67, 0x65, 0x74, 0x52, 0x65, 0x61, 0x6c, 0x46, 0x6c, 0x61, 0x67, 0x73,
-	0x12, 0x05, 0x2e, 0x52, 0x65, 0x61, 0x6c, 0x1a, 0x06, 0x2e, 0x46, 0x6c, 0x61, 0x67, 0x73, 0x12,
-	0x23, 0x0a, 0x0d, 0x61, 0x64, 0x64, 0x52, 0x65, 0x61, 0x6c, 0x46, 0x6f, 0x72, 0x56, 0x69, 0x70,
-	0x12, 0x0b, 0x2e, 0x72, 0x65, 0x61, 0x6c, 0x46, 0x6f, 0x72, 0x56, 0x69, 0x70, 0x1a, 0x05, 0x2e,
-	0x42, 0x6f, 0x6f, 0x6c, 0x12, 0x23, 0x0a, 0x0d, 0x64, 0x65, 0x6c, 0x52, 0x65, 0x61, 0x6c, 0x46,
-	0x6f, 0x72, 0x56, 0x69, 0x70, 0x12, 0x0b, 0x2e, 0x72, 0x65, 0x61, 0x6c, 0x46, 0x6f, 0x72, 0x56,
-	0x69, 0x70, 0x1a, 0x05, 0x2e, 0x42, 0x6f, 0x6f, 0x6c, 0x12, 0x30, 0x0a, 0x11, 0x6d, 0x6f, 0x64,
-	0x69, 0x66, 0x79, 0x52, 0x65, 0x61, 0x6c, 0x73, 0x46, 0x6f, 0x72, 0x56, 0x69, 0x70, 0x12, 0x14,
-	0x2e, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x69, 0x65, 0x64, 0x52, 0x65, 0x61, 0x6c, 0x73, 0x46, 0x6f,
-	0x72, 0x56, 0x69, 0x70, 0x1a, 0x05, 0x2e, 0x42, 0x6f, 0x6f, 0x6c, 0x12, 0x1e, 0x0a, 0x0e, 0x67,
-	0x65, 0x74, 0x52, 0x65, 0x61, 0x6c, 0x73, 0x46, 0x6f, 0x72, 0x56, 0x69, 0x70, 0x12, 0x04, 0x2e,
-	0x56, 0x69, 0x70, 0x1a, 0x06, 0x2e, 0x52, 0x65, 0x61, 0x6c, 0x73, 0x12, 0x33, 0x0a, 0x16, 0x6d,
-	0x6f, 0x64, 0x69, 0x66, 0x79, 0x51, 0x75, 0x69, 0x63, 0x52, 0x65, 0x61, 0x6c, 0x73, 0x4d, 0x61,
-	0x70, 0x70, 0x69, 0x6e, 0x67, 0x12, 0x12, 0x2e, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x69, 0x65, 0x64,
-	0x51, 0x75, 0x69, 0x63, 0x52, 0x65, 0x61, 0x6c, 0x73, 0x1a, 0x05, 0x2e, 0x42, 0x6f, 0x6f, 0x6c,
-	0x12, 0x29, 0x0a, 0x13, 0x67, 0x65, 0x74, 0x51, 0x75, 0x69, 0x63, 0x52, 0x65, 0x61, 0x6c, 0x73,
-	0x4d, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x12, 0x06, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a,
-	0x0a, 0x2e, 0x51, 0x75, 0x69, 0x63, 0x52, 0x65, 0x61, 0x6c, 0x73, 0x12, 0x1e, 0x0a, 0x0e, 0x67,
-	0x65, 0x74, 0x53, 0x74, 0x61, 0x74, 0x73, 0x46, 0x6f, 0x72, 0x56, 0x69, 0x70, 0x12, 0x04, 0x2e,
-	0x56, 0x69, 0x70, 0x1a, 0x06, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x1d, 0x0a, 0x0b, 0x67,
-	0x65, 0x74, 0x4c, 0x72, 0x75, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x06, 0x2e, 0x45, 0x6d, 0x70,
-	0x74, 0x79, 0x1a, 0x06, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x21, 0x0a, 0x0f, 0x67, 0x65,
-	0x74, 0x4c, 0x72, 0x75, 0x4d, 0x69, 0x73, 0x73, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x06, 0x2e,
-	0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x06, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x25, 0x0a,
-	0x13, 0x67, 0x65, 0x74, 0x4c, 0x72, 0x75, 0x46, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x53,
+	0x73, 0x0a, 0x0e, 0x43, 0x61, 0x70, 0x74, 0x75, 0x72, 0x65, 0x64, 0x50, 0x61, 0x63, 0x6b, 0x65,
+	0x74, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x19, 0x0a, 0x08, 0x70, 0x6b, 0x74, 0x5f, 0x73, 0x69, 0x7a,
+	0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x70, 0x6b, 0x74, 0x53, 0x69, 0x7a, 0x65,
+	0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04,
+	0x64, 0x61, 0x74, 0x61, 0x22, 0x3f, 0x0a, 0x0b, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x63, 0x68,
+	0x65, 0x63, 0x6b, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x6f, 0x6d, 0x61, 0x72, 0x6b, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x06, 0x73, 0x6f, 0x6d, 0x61, 0x72, 0x6b, 0x12, 0x18, 0x0a, 0x07, 0x61,
+	0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x61, 0x64,
+	0x64, 0x72, 0x65, 0x73, 0x73, 0x22, 0x86, 0x01, 0x0a, 0x05, 0x68, 0x63, 0x4d, 0x61, 0x70, 0x12,
+	0x3c, 0x0a, 0x0c, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x68, 0x63, 0x4d, 0x61, 0x70, 0x2e, 0x48, 0x65,
+	0x61, 0x6c, 0x74, 0x68, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52,
+	0x0c, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x1a, 0x3f, 0x0a,
+	0x11, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x24,
+	0x0a, 0x05, 0x52, 0x65, 0x61, 0x6c, 0x73, 0x12, 0x1b, 0x0a, 0x05, 0x72, 0x65, 0x61, 0x6c, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x52, 0x65, 0x61, 0x6c, 0x52, 0x05, 0x72,
+	0x65, 0x61, 0x6c, 0x73, 0x22, 0x20, 0x0a, 0x04, 0x56, 0x69, 0x70, 0x73, 0x12, 0x18, 0x0a, 0x04,
+	0x76, 0x69, 0x70, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x04, 0x2e, 0x56, 0x69, 0x70,
+	0x52, 0x04, 0x76, 0x69, 0x70, 0x73, 0x22, 0x2e, 0x0a, 0x09, 0x51, 0x75, 0x69, 0x63, 0x52, 0x65,
+	0x61, 0x6c, 0x73, 0x12, 0x21, 0x0a, 0x06, 0x71, 0x72, 0x65, 0x61, 0x6c, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x09, 0x2e, 0x51, 0x75, 0x69, 0x63, 0x52, 0x65, 0x61, 0x6c, 0x52, 0x06,
+	0x71, 0x72, 0x65, 0x61, 0x6c, 0x73, 0x22, 0x6a, 0x0a, 0x13, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x69,
+	0x65, 0x64, 0x52, 0x65, 0x61, 0x6c, 0x73, 0x46, 0x6f, 0x72, 0x56, 0x69, 0x70, 0x12, 0x1f, 0x0a,
+	0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x07, 0x2e,
+	0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1a,
+	0x0a, 0x04, 0x72, 0x65, 0x61, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x06, 0x2e, 0x52,
+	0x65, 0x61, 0x6c, 0x73, 0x52, 0x04, 0x72, 0x65, 0x61, 0x6c, 0x12, 0x16, 0x0a, 0x03, 0x76, 0x69,
+	0x70, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x04, 0x2e, 0x56, 0x69, 0x70, 0x52, 0x03, 0x76,
+	0x69, 0x70, 0x22, 0x40, 0x0a, 0x14, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x69, 0x65, 0x64, 0x52, 0x65,
+	0x61, 0x6c, 0x73, 0x46, 0x6f, 0x72, 0x56, 0x69, 0x70, 0x73, 0x12, 0x28, 0x0a, 0x04, 0x76, 0x69,
+	0x70, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x6d, 0x6f, 0x64, 0x69, 0x66,
+	0x69, 0x65, 0x64, 0x52, 0x65, 0x61, 0x6c, 0x73, 0x46, 0x6f, 0x72, 0x56, 0x69, 0x70, 0x52, 0x04,
+	0x76, 0x69, 0x70, 0x73, 0x22, 0x56, 0x0a, 0x11, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x69, 0x65, 0x64,
+	0x51, 0x75, 0x69, 0x63, 0x52, 0x65, 0x61, 0x6c, 0x73, 0x12, 0x1f, 0x0a, 0x06, 0x61, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x07, 0x2e, 0x41, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x52, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x20, 0x0a, 0x05, 0x72, 0x65,
+	0x61, 0x6c, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x51, 0x75, 0x69, 0x63,
+	0x52, 0x65, 0x61, 0x6c, 0x73, 0x52, 0x05, 0x72, 0x65, 0x61, 0x6c, 0x73, 0x22, 0x96, 0x01, 0x0a,
+	0x0d, 0x52, 0x65, 0x61, 0x6c, 0x52, 0x69, 0x6e, 0x67, 0x53, 0x68, 0x61, 0x72, 0x65, 0x12, 0x18,
+	0x0a, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x77, 0x65, 0x69, 0x67,
+	0x68, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74,
+	0x12, 0x1c, 0x0a, 0x09, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x09, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x14,
+	0x0a, 0x05, 0x73, 0x68, 0x61, 0x72, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x73,
+	0x68, 0x61, 0x72, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x69, 0x64, 0x65, 0x61, 0x6c, 0x5f, 0x73, 0x68,
+	0x61, 0x72, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x69, 0x64, 0x65, 0x61, 0x6c,
+	0x53, 0x68, 0x61, 0x72, 0x65, 0x22, 0xa7, 0x02, 0x0a, 0x0e, 0x52, 0x69, 0x6e, 0x67, 0x53, 0x69,
+	0x6d, 0x75, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1b, 0x0a, 0x09, 0x72, 0x69, 0x6e, 0x67,
+	0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x72, 0x69, 0x6e,
+	0x67, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x2b, 0x0a, 0x11, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64,
+	0x5f, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x10, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x12, 0x29, 0x0a, 0x10, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x5f, 0x66, 0x72,
+	0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0f, 0x63, 0x68,
+	0x61, 0x6e, 0x67, 0x65, 0x64, 0x46, 0x72, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x26, 0x0a,
+	0x06, 0x62, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e,
+	0x52, 0x65, 0x61, 0x6c, 0x52, 0x69, 0x6e, 0x67, 0x53, 0x68, 0x61, 0x72, 0x65, 0x52, 0x06, 0x62,
+	0x65, 0x66, 0x6f, 0x72, 0x65, 0x12, 0x29, 0x0a, 0x10, 0x69, 0x6d, 0x62, 0x61, 0x6c, 0x61, 0x6e,
+	0x63, 0x65, 0x5f, 0x62, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x0f, 0x69, 0x6d, 0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x42, 0x65, 0x66, 0x6f, 0x72, 0x65,
+	0x12, 0x24, 0x0a, 0x05, 0x61, 0x66, 0x74, 0x65, 0x72, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x0e, 0x2e, 0x52, 0x65, 0x61, 0x6c, 0x52, 0x69, 0x6e, 0x67, 0x53, 0x68, 0x61, 0x72, 0x65, 0x52,
+	0x05, 0x61, 0x66, 0x74, 0x65, 0x72, 0x12, 0x27, 0x0a, 0x0f, 0x69, 0x6d, 0x62, 0x61, 0x6c, 0x61,
+	0x6e, 0x63, 0x65, 0x5f, 0x61, 0x66, 0x74, 0x65, 0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x0e, 0x69, 0x6d, 0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x41, 0x66, 0x74, 0x65, 0x72, 0x22,
+	0x4a, 0x0a, 0x0f, 0x48, 0x61, 0x73, 0x68, 0x52, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x16, 0x0a, 0x03, 0x76, 0x69, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x04, 0x2e, 0x56, 0x69, 0x70, 0x52, 0x03, 0x76, 0x69, 0x70, 0x12, 0x1f, 0x0a, 0x0b, 0x72, 0x65,
+	0x61, 0x64, 0x5f, 0x6b, 0x65, 0x72, 0x6e, 0x65, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x0a, 0x72, 0x65, 0x61, 0x64, 0x4b, 0x65, 0x72, 0x6e, 0x65, 0x6c, 0x22, 0xed, 0x02, 0x0a, 0x08,
+	0x48, 0x61, 0x73, 0x68, 0x52, 0x69, 0x6e, 0x67, 0x12, 0x1b, 0x0a, 0x09, 0x72, 0x69, 0x6e, 0x67,
+	0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x72, 0x69, 0x6e,
+	0x67, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x72, 0x69, 0x6e, 0x67, 0x5f, 0x6f, 0x66,
+	0x66, 0x73, 0x65, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x72, 0x69, 0x6e, 0x67,
+	0x4f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x12, 0x23, 0x0a, 0x0d, 0x68, 0x61, 0x73, 0x68, 0x5f, 0x66,
+	0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x68,
+	0x61, 0x73, 0x68, 0x46, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x70,
+	0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x05, 0x52, 0x09,
+	0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x36, 0x0a, 0x09, 0x61, 0x64, 0x64,
+	0x72, 0x65, 0x73, 0x73, 0x65, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x48,
+	0x61, 0x73, 0x68, 0x52, 0x69, 0x6e, 0x67, 0x2e, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x65,
+	0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x09, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x65,
+	0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x6b, 0x65, 0x72, 0x6e, 0x65, 0x6c, 0x5f, 0x72, 0x65, 0x61, 0x64,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x6b, 0x65, 0x72, 0x6e, 0x65, 0x6c, 0x52, 0x65,
+	0x61, 0x64, 0x12, 0x29, 0x0a, 0x10, 0x6b, 0x65, 0x72, 0x6e, 0x65, 0x6c, 0x5f, 0x70, 0x6f, 0x73,
+	0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x0d, 0x52, 0x0f, 0x6b, 0x65,
+	0x72, 0x6e, 0x65, 0x6c, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1e, 0x0a,
+	0x0a, 0x6d, 0x69, 0x73, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x64, 0x18, 0x08, 0x20, 0x03, 0x28,
+	0x0d, 0x52, 0x0a, 0x6d, 0x69, 0x73, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x64, 0x1a, 0x3c, 0x0a,
+	0x0e, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12,
+	0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x6b, 0x65,
+	0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x3f, 0x0a, 0x0a, 0x72,
+	0x65, 0x61, 0x6c, 0x46, 0x6f, 0x72, 0x56, 0x69, 0x70, 0x12, 0x19, 0x0a, 0x04, 0x72, 0x65, 0x61,
+	0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x52, 0x65, 0x61, 0x6c, 0x52, 0x04,
+	0x72, 0x65, 0x61, 0x6c, 0x12, 0x16, 0x0a, 0x03, 0x76, 0x69, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x04, 0x2e, 0x56, 0x69, 0x70, 0x52, 0x03, 0x76, 0x69, 0x70, 0x22, 0x1d, 0x0a, 0x05,
+	0x46, 0x6c, 0x61, 0x67, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x22, 0x20, 0x0a, 0x06, 0x53,
+	0x6f, 0x6d, 0x61, 0x72, 0x6b, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x6f, 0x6d, 0x61, 0x72, 0x6b, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x73, 0x6f, 0x6d, 0x61, 0x72, 0x6b, 0x2a, 0x1a, 0x0a,
+	0x06, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x07, 0x0a, 0x03, 0x41, 0x44, 0x44, 0x10, 0x00,
+	0x12, 0x07, 0x0a, 0x03, 0x44, 0x45, 0x4c, 0x10, 0x01, 0x32, 0xff, 0x0b, 0x0a, 0x0a, 0x53, 0x6c,
+	0x62, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x18, 0x0a, 0x09, 0x63, 0x68, 0x61, 0x6e,
+	0x67, 0x65, 0x4d, 0x61, 0x63, 0x12, 0x04, 0x2e, 0x4d, 0x61, 0x63, 0x1a, 0x05, 0x2e, 0x42, 0x6f,
+	0x6f, 0x6c, 0x12, 0x16, 0x0a, 0x06, 0x67, 0x65, 0x74, 0x4d, 0x61, 0x63, 0x12, 0x06, 0x2e, 0x45,
+	0x6d, 0x70, 0x74, 0x79, 0x1a, 0x04, 0x2e, 0x4d, 0x61, 0x63, 0x12, 0x19, 0x0a, 0x0a, 0x61, 0x64,
+	0x64, 0x4e, 0x65, 0x78, 0x74, 0x68, 0x6f, 0x70, 0x12, 0x04, 0x2e, 0x4d, 0x61, 0x63, 0x1a, 0x05,
+	0x2e, 0x42, 0x6f, 0x6f, 0x6c, 0x12, 0x19, 0x0a, 0x0a, 0x64, 0x65, 0x6c, 0x4e, 0x65, 0x78, 0x74,
+	0x68, 0x6f, 0x70, 0x12, 0x04, 0x2e, 0x4d, 0x61, 0x63, 0x1a, 0x05, 0x2e, 0x42, 0x6f, 0x6f, 0x6c,
+	0x12, 0x20, 0x0a, 0x0b, 0x67, 0x65, 0x74, 0x4e, 0x65, 0x78, 0x74, 0x68, 0x6f, 0x70, 0x73, 0x12,
+	0x06, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x09, 0x2e, 0x4e, 0x65, 0x78, 0x74, 0x68, 0x6f,
+	0x70, 0x73, 0x12, 0x19, 0x0a, 0x06, 0x61, 0x64, 0x64, 0x56, 0x69, 0x70, 0x12, 0x08, 0x2e, 0x56,
+	0x69, 0x70, 0x4d, 0x65, 0x74, 0x61, 0x1a, 0x05, 0x2e, 0x42, 0x6f, 0x6f, 0x6c, 0x12, 0x15, 0x0a,
+	0x06, 0x64, 0x65, 0x6c, 0x56, 0x69, 0x70, 0x12, 0x04, 0x2e, 0x56, 0x69, 0x70, 0x1a, 0x05, 0x2e,
+	0x42, 0x6f, 0x6f, 0x6c, 0x12, 0x1b, 0x0a, 0x0a, 0x67, 0x65, 0x74, 0x41, 0x6c, 0x6c, 0x56, 0x69,
+	0x70, 0x73, 0x12, 0x06, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x05, 0x2e, 0x56, 0x69, 0x70,
+	0x73, 0x12, 0x1c, 0x0a, 0x09, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x79, 0x56, 0x69, 0x70, 0x12, 0x08,
+	0x2e, 0x56, 0x69, 0x70, 0x4d, 0x65, 0x74, 0x61, 0x1a, 0x05, 0x2e, 0x42, 0x6f, 0x6f, 0x6c, 0x12,
+	0x1e, 0x0a, 0x0a, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x79, 0x52, 0x65, 0x61, 0x6c, 0x12, 0x09, 0x2e,
+	0x52, 0x65, 0x61, 0x6c, 0x4d, 0x65, 0x74, 0x61, 0x1a, 0x05, 0x2e, 0x42, 0x6f, 0x6f, 0x6c, 0x12,
+	0x1b, 0x0a, 0x0b, 0x67, 0x65, 0x74, 0x56, 0x69, 0x70, 0x46, 0x6c, 0x61, 0x67, 0x73, 0x12, 0x04,
+	0x2e, 0x56, 0x69, 0x70, 0x1a, 0x06, 0x2e, 0x46, 0x6c, 0x61, 0x67, 0x73, 0x12, 0x2d, 0x0a, 0x12,
+	0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x48, 0x61, 0x73, 0x68, 0x46, 0x75, 0x6e, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x12, 0x10, 0x2e, 0x56, 0x69, 0x70, 0x48, 0x61, 0x73, 0x68, 0x46, 0x75, 0x6e, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x1a, 0x05, 0x2e, 0x42, 0x6f, 0x6f, 0x6c, 0x12, 0x1c, 0x0a, 0x0a, 0x67,
+	0x65, 0x74, 0x56, 0x69, 0x70, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x04, 0x2e, 0x56, 0x69, 0x70, 0x1a,
+	0x08, 0x2e, 0x56, 0x69, 0x70, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x1d, 0x0a, 0x0c, 0x67, 0x65, 0x74,
+	0x52, 0x65, 0x61, 0x6c, 0x46, 0x6c, 0x61, 0x67, 0x73, 0x12, 0x05, 0x2e, 0x52, 0x65, 0x61, 0x6c,
+	0x1a, 0x06, 0x2e, 0x46, 0x6c, 0x61, 0x67, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x61, 0x64, 0x64, 0x52,
+	0x65, 0x61, 0x6c, 0x46, 0x6f, 0x72, 0x56, 0x69, 0x70, 0x12, 0x0b, 0x2e, 0x72, 0x65, 0x61, 0x6c,
+	0x46, 0x6f, 0x72, 0x56, 0x69, 0x70, 0x1a, 0x05, 0x2e, 0x42, 0x6f, 0x6f, 0x6c, 0x12, 0x23, 0x0a,
+	0x0d, 0x64, 0x65, 0x6c, 0x52, 0x65, 0x61, 0x6c, 0x46, 0x6f, 0x72, 0x56, 0x69, 0x70, 0x12, 0x0b,
+	0x2e, 0x72, 0x65, 0x61, 0x6c, 0x46, 0x6f, 0x72, 0x56, 0x69, 0x70, 0x1a, 0x05, 0x2e, 0x42, 0x6f,
+	0x6f, 0x6c, 0x12, 0x30, 0x0a, 0x11, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x79, 0x52, 0x65, 0x61, 0x6c,
+	0x73, 0x46, 0x6f, 0x72, 0x56, 0x69, 0x70, 0x12, 0x14, 0x2e, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x69,
+	0x65, 0x64, 0x52, 0x65, 0x61, 0x6c, 0x73, 0x46, 0x6f, 0x72, 0x56, 0x69, 0x70, 0x1a, 0x05, 0x2e,
+	0x42, 0x6f, 0x6f, 0x6c, 0x12, 0x32, 0x0a, 0x12, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x79, 0x52, 0x65,
+	0x61, 0x6c, 0x73, 0x46, 0x6f, 0x72, 0x56, 0x69, 0x70, 0x73, 0x12, 0x15, 0x2e, 0x6d, 0x6f, 0x64,
+	0x69, 0x66, 0x69, 0x65, 0x64, 0x52, 0x65, 0x61, 0x6c, 0x73, 0x46, 0x6f, 0x72, 0x56, 0x69, 0x70,
+	0x73, 0x1a, 0x05, 0x2e, 0x42, 0x6f, 0x6f, 0x6c, 0x12, 0x1e, 0x0a, 0x0e, 0x67, 0x65, 0x74, 0x52,
+	0x65, 0x61, 0x6c, 0x73, 0x46, 0x6f, 0x72, 0x56, 0x69, 0x70, 0x12, 0x04, 0x2e, 0x56, 0x69, 0x70,
+	0x1a, 0x06, 0x2e, 0x52, 0x65, 0x61, 0x6c, 0x73, 0x12, 0x3c, 0x0a, 0x13, 0x73, 0x69, 0x6d, 0x75,
+	0x6c, 0x61, 0x74, 0x65, 0x52, 0x65, 0x61, 0x6c, 0x73, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x12,
+	0x14, 0x2e, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x69, 0x65, 0x64, 0x52, 0x65, 0x61, 0x6c, 0x73, 0x46,
+	0x6f, 0x72, 0x56, 0x69, 0x70, 0x1a, 0x0f, 0x2e, 0x52, 0x69, 0x6e, 0x67, 0x53, 0x69, 0x6d, 0x75,
+	0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x2a, 0x0a, 0x0b, 0x67, 0x65, 0x74, 0x48, 0x61, 0x73,
+	0x68, 0x52, 0x69, 0x6e, 0x67, 0x12, 0x10, 0x2e, 0x48, 0x61, 0x73, 0x68, 0x52, 0x69, 0x6e, 0x67,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x09, 0x2e, 0x48, 0x61, 0x73, 0x68, 0x52, 0x69,
+	0x6e, 0x67, 0x12, 0x33, 0x0a, 0x16, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x79, 0x51, 0x75, 0x69, 0x63,
+	0x52, 0x65, 0x61, 0x6c, 0x73, 0x4d, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x12, 0x12, 0x2e, 0x6d,
+	0x6f, 0x64, 0x69, 0x66, 0x69, 0x65, 0x64, 0x51, 0x75, 0x69, 0x63, 0x52, 0x65, 0x61, 0x6c, 0x73,
+	0x1a, 0x05, 0x2e, 0x42, 0x6f, 0x6f, 0x6c, 0x12, 0x29, 0x0a, 0x13, 0x67, 0x65, 0x74, 0x51, 0x75,
+	0x69, 0x63, 0x52, 0x65, 0x61, 0x6c, 0x73, 0x4d, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x12, 0x06,
+	0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x0a, 0x2e, 0x51, 0x75, 0x69, 0x63, 0x52, 0x65, 0x61,
+	0x6c, 0x73, 0x12, 0x1e, 0x0a, 0x0e, 0x67, 0x65, 0x74, 0x53, 0x74, 0x61, 0x74, 0x73, 0x46, 0x6f,
+	0x72, 0x56, 0x69, 0x70, 0x12, 0x04, 0x2e, 0x56, 0x69, 0x70, 0x1a, 0x06, 0x2e, 0x53, 0x74, 0x61,
+	0x74, 0x73, 0x12, 0x1d, 0x0a, 0x0b, 0x67, 0x65, 0x74, 0x4c, 0x72, 0x75, 0x53, 0x74, 0x61, 0x74,
+	0x73, 0x12, 0x06, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x06, 0x2e, 0x53, 0x74, 0x61, 0x74,
+	0x73, 0x12, 0x21, 0x0a, 0x0f, 0x67, 0x65, 0x74, 0x4c, 0x72, 0x75, 0x4d, 0x69, 0x73, 0x73, 0x53,
 	0x74, 0x61, 0x74, 0x73, 0x12, 0x06, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x06, 0x2e, 0x53,
-	0x74, 0x61, 0x74, 0x73, 0x12, 0x24, 0x0a, 0x12, 0x67, 0x65, 0x74, 0x49, 0x63, 0x6d, 0x70, 0x54,
-	0x6f, 0x6f, 0x42, 0x69, 0x67, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x06, 0x2e, 0x45, 0x6d, 0x70,
-	0x74, 0x79, 0x1a, 0x06, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x2a, 0x0a, 0x13, 0x61, 0x64,
-	0x64, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x65, 0x72, 0x44, 0x73,
-	0x74, 0x12, 0x0c, 0x2e, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x1a,
-	0x05, 0x2e, 0x42, 0x6f, 0x6f, 0x6c, 0x12, 0x25, 0x0a, 0x13, 0x64, 0x65, 0x6c, 0x48, 0x65, 0x61,
-	0x6c, 0x74, 0x68, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x65, 0x72, 0x44, 0x73, 0x74, 0x12, 0x07, 0x2e,
-	0x53, 0x6f, 0x6d, 0x61, 0x72, 0x6b, 0x1a, 0x05, 0x2e, 0x42, 0x6f, 0x6f, 0x6c, 0x12, 0x26, 0x0a,
-	0x14, 0x67, 0x65, 0x74, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x65,
-	0x72, 0x73, 0x44, 0x73, 0x74, 0x12, 0x06, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x06, 0x2e,
-	0x68, 0x63, 0x4d, 0x61, 0x70, 0x42, 0x07, 0x5a, 0x05, 0x2e, 0x2f, 0x3b, 0x70, 0x62, 0x62, 0x06,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x74, 0x61, 0x74, 0x73, 0x12, 0x21, 0x0a, 0x12, 0x73, 0x65, 0x74, 0x4c, 0x72, 0x75, 0x4d, 0x69,
+	0x73, 0x73, 0x53, 0x74, 0x61, 0x74, 0x73, 0x56, 0x69, 0x70, 0x12, 0x04, 0x2e, 0x56, 0x69, 0x70,
+	0x1a, 0x05, 0x2e, 0x42, 0x6f, 0x6f, 0x6c, 0x12, 0x25, 0x0a, 0x14, 0x63, 0x6c, 0x65, 0x61, 0x72,
+	0x4c, 0x72, 0x75, 0x4d, 0x69, 0x73, 0x73, 0x53, 0x74, 0x61, 0x74, 0x73, 0x56, 0x69, 0x70, 0x12,
+	0x06, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x05, 0x2e, 0x42, 0x6f, 0x6f, 0x6c, 0x12, 0x34,
+	0x0a, 0x15, 0x67, 0x65, 0x74, 0x4c, 0x72, 0x75, 0x4d, 0x69, 0x73, 0x73, 0x53, 0x74, 0x61, 0x74,
+	0x73, 0x46, 0x6f, 0x72, 0x56, 0x69, 0x70, 0x12, 0x06, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a,
+	0x13, 0x2e, 0x4c, 0x72, 0x75, 0x4d, 0x69, 0x73, 0x73, 0x53, 0x74, 0x61, 0x74, 0x73, 0x46, 0x6f,
+	0x72, 0x56, 0x69, 0x70, 0x12, 0x25, 0x0a, 0x13, 0x67, 0x65, 0x74, 0x4c, 0x72, 0x75, 0x46, 0x61,
+	0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x06, 0x2e, 0x45, 0x6d,
+	0x70, 0x74, 0x79, 0x1a, 0x06, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x24, 0x0a, 0x12, 0x67,
+	0x65, 0x74, 0x49, 0x63, 0x6d, 0x70, 0x54, 0x6f, 0x6f, 0x42, 0x69, 0x67, 0x53, 0x74, 0x61, 0x74,
+	0x73, 0x12, 0x06, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x06, 0x2e, 0x53, 0x74, 0x61, 0x74,
+	0x73, 0x12, 0x28, 0x0a, 0x0f, 0x67, 0x65, 0x74, 0x4c, 0x72, 0x75, 0x4d, 0x61, 0x70, 0x73, 0x53,
+	0x74, 0x61, 0x74, 0x73, 0x12, 0x06, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x0d, 0x2e, 0x4c,
+	0x72, 0x75, 0x4d, 0x61, 0x70, 0x73, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x27, 0x0a, 0x0e, 0x67,
+	0x65, 0x74, 0x42, 0x70, 0x66, 0x4d, 0x61, 0x70, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x06, 0x2e,
+	0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x0d, 0x2e, 0x42, 0x70, 0x66, 0x4d, 0x61, 0x70, 0x73, 0x53,
+	0x74, 0x61, 0x74, 0x73, 0x12, 0x2e, 0x0a, 0x12, 0x67, 0x65, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x72,
+	0x6f, 0x6c, 0x6c, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x06, 0x2e, 0x45, 0x6d, 0x70,
+	0x74, 0x79, 0x1a, 0x10, 0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x6c, 0x65, 0x72, 0x53,
+	0x74, 0x61, 0x74, 0x73, 0x12, 0x2a, 0x0a, 0x10, 0x67, 0x65, 0x74, 0x46, 0x6c, 0x6f, 0x77, 0x44,
+	0x65, 0x62, 0x75, 0x67, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x05, 0x2e, 0x46, 0x6c, 0x6f, 0x77, 0x1a,
+	0x0f, 0x2e, 0x46, 0x6c, 0x6f, 0x77, 0x44, 0x65, 0x62, 0x75, 0x67, 0x49, 0x6e, 0x66, 0x6f, 0x73,
+	0x12, 0x28, 0x0a, 0x0f, 0x67, 0x65, 0x74, 0x4d, 0x6f, 0x6e, 0x69, 0x74, 0x6f, 0x72, 0x53, 0x74,
+	0x61, 0x74, 0x73, 0x12, 0x06, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x0d, 0x2e, 0x4d, 0x6f,
+	0x6e, 0x69, 0x74, 0x6f, 0x72, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x34, 0x0a, 0x0e, 0x63, 0x61,
+	0x70, 0x74, 0x75, 0x72, 0x65, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x12, 0x0f, 0x2e, 0x43,
+	0x61, 0x70, 0x74, 0x75, 0x72, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0f, 0x2e,
+	0x43, 0x61, 0x70, 0x74, 0x75, 0x72, 0x65, 0x64, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x30, 0x01,
+	0x12, 0x2a, 0x0a, 0x13, 0x61, 0x64, 0x64, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x63, 0x68, 0x65,
+	0x63, 0x6b, 0x65, 0x72, 0x44, 0x73, 0x74, 0x12, 0x0c, 0x2e, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68,
+	0x63, 0x68, 0x65, 0x63, 0x6b, 0x1a, 0x05, 0x2e, 0x42, 0x6f, 0x6f, 0x6c, 0x12, 0x25, 0x0a, 0x13,
+	0x64, 0x65, 0x6c, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x65, 0x72,
+	0x44, 0x73, 0x74, 0x12, 0x07, 0x2e, 0x53, 0x6f, 0x6d, 0x61, 0x72, 0x6b, 0x1a, 0x05, 0x2e, 0x42,
+	0x6f, 0x6f, 0x6c, 0x12, 0x26, 0x0a, 0x14, 0x67, 0x65, 0x74, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68,
+	0x63, 0x68, 0x65, 0x63, 0x6b, 0x65, 0x72, 0x73, 0x44, 0x73, 0x74, 0x12, 0x06, 0x2e, 0x45, 0x6d,
+	0x70, 0x74, 0x79, 0x1a, 0x06, 0x2e, 0x68, 0x63, 0x4d, 0x61, 0x70, 0x42, 0x07, 0x5a, 0x05, 0x2e,
+	0x2f, 0x3b, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -1217,94 +3041,166 @@ func file_pkg_pb_l4slb_proto_rawDescGZIP() []byte {
 }
 
 var file_pkg_pb_l4slb_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_pkg_pb_l4slb_proto_msgTypes = make([]protoimpl.MessageInfo, 20)
+var file_pkg_pb_l4slb_proto_msgTypes = make([]protoimpl.MessageInfo, 43)
 var file_pkg_pb_l4slb_proto_goTypes = []interface{}{
-	(Action)(0),                 // 0: Action
-	(*Empty)(nil),               // 1: Empty
-	(*Bool)(nil),                // 2: Bool
-	(*Vip)(nil),                 // 3: Vip
-	(*VipMeta)(nil),             // 4: VipMeta
-	(*RealMeta)(nil),            // 5: RealMeta
-	(*Real)(nil),                // 6: Real
-	(*QuicReal)(nil),            // 7: QuicReal
-	(*Mac)(nil),                 // 8: Mac
-	(*Stats)(nil),               // 9: Stats
-	(*Healthcheck)(nil),         // 10: Healthcheck
-	(*HcMap)(nil),               // 11: hcMap
-	(*Reals)(nil),               // 12: Reals
-	(*Vips)(nil),                // 13: Vips
-	(*QuicReals)(nil),           // 14: QuicReals
-	(*ModifiedRealsForVip)(nil), // 15: modifiedRealsForVip
-	(*ModifiedQuicReals)(nil),   // 16: modifiedQuicReals
-	(*RealForVip)(nil),          // 17: realForVip
-	(*Flags)(nil),               // 18: Flags
-	(*Somark)(nil),              // 19: Somark
-	nil,                         // 20: hcMap.HealthchecksEntry
+	(Action)(0),                  // 0: Action
+	(*Empty)(nil),                // 1: Empty
+	(*Bool)(nil),                 // 2: Bool
+	(*Vip)(nil),                  // 3: Vip
+	(*VipMeta)(nil),              // 4: VipMeta
+	(*VipHashFunction)(nil),      // 5: VipHashFunction
+	(*VipInfo)(nil),              // 6: VipInfo
+	(*RealMeta)(nil),             // 7: RealMeta
+	(*Real)(nil),                 // 8: Real
+	(*QuicReal)(nil),             // 9: QuicReal
+	(*Mac)(nil),                  // 10: Mac
+	(*Nexthop)(nil),              // 11: Nexthop
+	(*Nexthops)(nil),             // 12: Nexthops
+	(*Stats)(nil),                // 13: Stats
+	(*LruMapStats)(nil),          // 14: LruMapStats
+	(*LruMapsStats)(nil),         // 15: LruMapsStats
+	(*BpfMapStats)(nil),          // 16: BpfMapStats
+	(*BpfMapsStats)(nil),         // 17: BpfMapsStats
+	(*ControllerStats)(nil),      // 18: ControllerStats
+	(*Flow)(nil),                 // 19: Flow
+	(*FlowDebugInfo)(nil),        // 20: FlowDebugInfo
+	(*FlowDebugInfos)(nil),       // 21: FlowDebugInfos
+	(*RealLruMiss)(nil),          // 22: RealLruMiss
+	(*LruMissStatsForVip)(nil),   // 23: LruMissStatsForVip
+	(*MonitorStats)(nil),         // 24: MonitorStats
+	(*CaptureRequest)(nil),       // 25: CaptureRequest
+	(*CapturedPacket)(nil),       // 26: CapturedPacket
+	(*Healthcheck)(nil),          // 27: Healthcheck
+	(*HcMap)(nil),                // 28: hcMap
+	(*Reals)(nil),                // 29: Reals
+	(*Vips)(nil),                 // 30: Vips
+	(*QuicReals)(nil),            // 31: QuicReals
+	(*ModifiedRealsForVip)(nil),  // 32: modifiedRealsForVip
+	(*ModifiedRealsForVips)(nil), // 33: modifiedRealsForVips
+	(*ModifiedQuicReals)(nil),    // 34: modifiedQuicReals
+	(*RealRingShare)(nil),        // 35: RealRingShare
+	(*RingSimulation)(nil),       // 36: RingSimulation
+	(*HashRingRequest)(nil),      // 37: HashRingRequest
+	(*HashRing)(nil),             // 38: HashRing
+	(*RealForVip)(nil),           // 39: realForVip
+	(*Flags)(nil),                // 40: Flags
+	(*Somark)(nil),               // 41: Somark
+	nil,                          // 42: hcMap.HealthchecksEntry
+	nil,                          // 43: HashRing.AddressesEntry
 }
 var file_pkg_pb_l4slb_proto_depIdxs = []int32{
 	3,  // 0: VipMeta.vip:type_name -> Vip
-	20, // 1: hcMap.healthchecks:type_name -> hcMap.HealthchecksEntry
-	6,  // 2: Reals.reals:type_name -> Real
-	3,  // 3: Vips.vips:type_name -> Vip
-	7,  // 4: QuicReals.qreals:type_name -> QuicReal
-	0,  // 5: modifiedRealsForVip.action:type_name -> Action
-	12, // 6: modifiedRealsForVip.real:type_name -> Reals
-	3,  // 7: modifiedRealsForVip.vip:type_name -> Vip
-	0,  // 8: modifiedQuicReals.action:type_name -> Action
-	14, // 9: modifiedQuicReals.reals:type_name -> QuicReals
-	6,  // 10: realForVip.real:type_name -> Real
-	3,  // 11: realForVip.vip:type_name -> Vip
-	8,  // 12: SlbService.changeMac:input_type -> Mac
-	1,  // 13: SlbService.getMac:input_type -> Empty
-	4,  // 14: SlbService.addVip:input_type -> VipMeta
-	3,  // 15: SlbService.delVip:input_type -> Vip
-	1,  // 16: SlbService.getAllVips:input_type -> Empty
-	4,  // 17: SlbService.modifyVip:input_type -> VipMeta
-	5,  // 18: SlbService.modifyReal:input_type -> RealMeta
-	3,  // 19: SlbService.getVipFlags:input_type -> Vip
-	6,  // 20: SlbService.getRealFlags:input_type -> Real
-	17, // 21: SlbService.addRealForVip:input_type -> realForVip
-	17, // 22: SlbService.delRealForVip:input_type -> realForVip
-	15, // 23: SlbService.modifyRealsForVip:input_type -> modifiedRealsForVip
-	3,  // 24: SlbService.getRealsForVip:input_type -> Vip
-	16, // 25: SlbService.modifyQuicRealsMapping:input_type -> modifiedQuicReals
-	1,  // 26: SlbService.getQuicRealsMapping:input_type -> Empty
-	3,  // 27: SlbService.getStatsForVip:input_type -> Vip
-	1,  // 28: SlbService.getLruStats:input_type -> Empty
-	1,  // 29: SlbService.getLruMissStats:input_type -> Empty
-	1,  // 30: SlbService.getLruFallbackStats:input_type -> Empty
-	1,  // 31: SlbService.getIcmpTooBigStats:input_type -> Empty
-	10, // 32: SlbService.addHealthcheckerDst:input_type -> Healthcheck
-	19, // 33: SlbService.delHealthcheckerDst:input_type -> Somark
-	1,  // 34: SlbService.getHealthcheckersDst:input_type -> Empty
-	2,  // 35: SlbService.changeMac:output_type -> Bool
-	8,  // 36: SlbService.getMac:output_type -> Mac
-	2,  // 37: SlbService.addVip:output_type -> Bool
-	2,  // 38: SlbService.delVip:output_type -> Bool
-	13, // 39: SlbService.getAllVips:output_type -> Vips
-	2,  // 40: SlbService.modifyVip:output_type -> Bool
-	2,  // 41: SlbService.modifyReal:output_type -> Bool
-	18, // 42: SlbService.getVipFlags:output_type -> Flags
-	18, // 43: SlbService.getRealFlags:output_type -> Flags
-	2,  // 44: SlbService.addRealForVip:output_type -> Bool
-	2,  // 45: SlbService.delRealForVip:output_type -> Bool
-	2,  // 46: SlbService.modifyRealsForVip:output_type -> Bool
-	12, // 47: SlbService.getRealsForVip:output_type -> Reals
-	2,  // 48: SlbService.modifyQuicRealsMapping:output_type -> Bool
-	14, // 49: SlbService.getQuicRealsMapping:output_type -> QuicReals
-	9,  // 50: SlbService.getStatsForVip:output_type -> Stats
-	9,  // 51: SlbService.getLruStats:output_type -> Stats
-	9,  // 52: SlbService.getLruMissStats:output_type -> Stats
-	9,  // 53: SlbService.getLruFallbackStats:output_type -> Stats
-	9,  // 54: SlbService.getIcmpTooBigStats:output_type -> Stats
-	2,  // 55: SlbService.addHealthcheckerDst:output_type -> Bool
-	2,  // 56: SlbService.delHealthcheckerDst:output_type -> Bool
-	11, // 57: SlbService.getHealthcheckersDst:output_type -> hcMap
-	35, // [35:58] is the sub-list for method output_type
-	12, // [12:35] is the sub-list for method input_type
-	12, // [12:12] is the sub-list for extension type_name
-	12, // [12:12] is the sub-list for extension extendee
-	0,  // [0:12] is the sub-list for field type_name
+	3,  // 1: VipHashFunction.vip:type_name -> Vip
+	13, // 2: Nexthop.stats:type_name -> Stats
+	11, // 3: Nexthops.nexthops:type_name -> Nexthop
+	14, // 4: LruMapsStats.lru:type_name -> LruMapStats
+	14, // 5: LruMapsStats.global_lru:type_name -> LruMapStats
+	16, // 6: BpfMapsStats.maps:type_name -> BpfMapStats
+	20, // 7: FlowDebugInfos.infos:type_name -> FlowDebugInfo
+	3,  // 8: LruMissStatsForVip.vip:type_name -> Vip
+	22, // 9: LruMissStatsForVip.reals:type_name -> RealLruMiss
+	3,  // 10: CaptureRequest.vip:type_name -> Vip
+	42, // 11: hcMap.healthchecks:type_name -> hcMap.HealthchecksEntry
+	8,  // 12: Reals.reals:type_name -> Real
+	3,  // 13: Vips.vips:type_name -> Vip
+	9,  // 14: QuicReals.qreals:type_name -> QuicReal
+	0,  // 15: modifiedRealsForVip.action:type_name -> Action
+	29, // 16: modifiedRealsForVip.real:type_name -> Reals
+	3,  // 17: modifiedRealsForVip.vip:type_name -> Vip
+	32, // 18: modifiedRealsForVips.vips:type_name -> modifiedRealsForVip
+	0,  // 19: modifiedQuicReals.action:type_name -> Action
+	31, // 20: modifiedQuicReals.reals:type_name -> QuicReals
+	35, // 21: RingSimulation.before:type_name -> RealRingShare
+	35, // 22: RingSimulation.after:type_name -> RealRingShare
+	3,  // 23: HashRingRequest.vip:type_name -> Vip
+	43, // 24: HashRing.addresses:type_name -> HashRing.AddressesEntry
+	8,  // 25: realForVip.real:type_name -> Real
+	3,  // 26: realForVip.vip:type_name -> Vip
+	10, // 27: SlbService.changeMac:input_type -> Mac
+	1,  // 28: SlbService.getMac:input_type -> Empty
+	10, // 29: SlbService.addNexthop:input_type -> Mac
+	10, // 30: SlbService.delNexthop:input_type -> Mac
+	1,  // 31: SlbService.getNexthops:input_type -> Empty
+	4,  // 32: SlbService.addVip:input_type -> VipMeta
+	3,  // 33: SlbService.delVip:input_type -> Vip
+	1,  // 34: SlbService.getAllVips:input_type -> Empty
+	4,  // 35: SlbService.modifyVip:input_type -> VipMeta
+	7,  // 36: SlbService.modifyReal:input_type -> RealMeta
+	3,  // 37: SlbService.getVipFlags:input_type -> Vip
+	5,  // 38: SlbService.changeHashFunction:input_type -> VipHashFunction
+	3,  // 39: SlbService.getVipInfo:input_type -> Vip
+	8,  // 40: SlbService.getRealFlags:input_type -> Real
+	39, // 41: SlbService.addRealForVip:input_type -> realForVip
+	39, // 42: SlbService.delRealForVip:input_type -> realForVip
+	32, // 43: SlbService.modifyRealsForVip:input_type -> modifiedRealsForVip
+	33, // 44: SlbService.modifyRealsForVips:input_type -> modifiedRealsForVips
+	3,  // 45: SlbService.getRealsForVip:input_type -> Vip
+	32, // 46: SlbService.simulateRealsChange:input_type -> modifiedRealsForVip
+	37, // 47: SlbService.getHashRing:input_type -> HashRingRequest
+	34, // 48: SlbService.modifyQuicRealsMapping:input_type -> modifiedQuicReals
+	1,  // 49: SlbService.getQuicRealsMapping:input_type -> Empty
+	3,  // 50: SlbService.getStatsForVip:input_type -> Vip
+	1,  // 51: SlbService.getLruStats:input_type -> Empty
+	1,  // 52: SlbService.getLruMissStats:input_type -> Empty
+	3,  // 53: SlbService.setLruMissStatsVip:input_type -> Vip
+	1,  // 54: SlbService.clearLruMissStatsVip:input_type -> Empty
+	1,  // 55: SlbService.getLruMissStatsForVip:input_type -> Empty
+	1,  // 56: SlbService.getLruFallbackStats:input_type -> Empty
+	1,  // 57: SlbService.getIcmpTooBigStats:input_type -> Empty
+	1,  // 58: SlbService.getLruMapsStats:input_type -> Empty
+	1,  // 59: SlbService.getBpfMapStats:input_type -> Empty
+	1,  // 60: SlbService.getControllerStats:input_type -> Empty
+	19, // 61: SlbService.getFlowDebugInfo:input_type -> Flow
+	1,  // 62: SlbService.getMonitorStats:input_type -> Empty
+	25, // 63: SlbService.capturePackets:input_type -> CaptureRequest
+	27, // 64: SlbService.addHealthcheckerDst:input_type -> Healthcheck
+	41, // 65: SlbService.delHealthcheckerDst:input_type -> Somark
+	1,  // 66: SlbService.getHealthcheckersDst:input_type -> Empty
+	2,  // 67: SlbService.changeMac:output_type -> Bool
+	10, // 68: SlbService.getMac:output_type -> Mac
+	2,  // 69: SlbService.addNexthop:output_type -> Bool
+	2,  // 70: SlbService.delNexthop:output_type -> Bool
+	12, // 71: SlbService.getNexthops:output_type -> Nexthops
+	2,  // 72: SlbService.addVip:output_type -> Bool
+	2,  // 73: SlbService.delVip:output_type -> Bool
+	30, // 74: SlbService.getAllVips:output_type -> Vips
+	2,  // 75: SlbService.modifyVip:output_type -> Bool
+	2,  // 76: SlbService.modifyReal:output_type -> Bool
+	40, // 77: SlbService.getVipFlags:output_type -> Flags
+	2,  // 78: SlbService.changeHashFunction:output_type -> Bool
+	6,  // 79: SlbService.getVipInfo:output_type -> VipInfo
+	40, // 80: SlbService.getRealFlags:output_type -> Flags
+	2,  // 81: SlbService.addRealForVip:output_type -> Bool
+	2,  // 82: SlbService.delRealForVip:output_type -> Bool
+	2,  // 83: SlbService.modifyRealsForVip:output_type -> Bool
+	2,  // 84: SlbService.modifyRealsForVips:output_type -> Bool
+	29, // 85: SlbService.getRealsForVip:output_type -> Reals
+	36, // 86: SlbService.simulateRealsChange:output_type -> RingSimulation
+	38, // 87: SlbService.getHashRing:output_type -> HashRing
+	2,  // 88: SlbService.modifyQuicRealsMapping:output_type -> Bool
+	31, // 89: SlbService.getQuicRealsMapping:output_type -> QuicReals
+	13, // 90: SlbService.getStatsForVip:output_type -> Stats
+	13, // 91: SlbService.getLruStats:output_type -> Stats
+	13, // 92: SlbService.getLruMissStats:output_type -> Stats
+	2,  // 93: SlbService.setLruMissStatsVip:output_type -> Bool
+	2,  // 94: SlbService.clearLruMissStatsVip:output_type -> Bool
+	23, // 95: SlbService.getLruMissStatsForVip:output_type -> LruMissStatsForVip
+	13, // 96: SlbService.getLruFallbackStats:output_type -> Stats
+	13, // 97: SlbService.getIcmpTooBigStats:output_type -> Stats
+	15, // 98: SlbService.getLruMapsStats:output_type -> LruMapsStats
+	17, // 99: SlbService.getBpfMapStats:output_type -> BpfMapsStats
+	18, // 100: SlbService.getControllerStats:output_type -> ControllerStats
+	21, // 101: SlbService.getFlowDebugInfo:output_type -> FlowDebugInfos
+	24, // 102: SlbService.getMonitorStats:output_type -> MonitorStats
+	26, // 103: SlbService.capturePackets:output_type -> CapturedPacket
+	2,  // 104: SlbService.addHealthcheckerDst:output_type -> Bool
+	2,  // 105: SlbService.delHealthcheckerDst:output_type -> Bool
+	28, // 106: SlbService.getHealthcheckersDst:output_type -> hcMap
+	67, // [67:107] is the sub-list for method output_type
+	27, // [27:67] is the sub-list for method input_type
+	27, // [27:27] is the sub-list for extension type_name
+	27, // [27:27] is the sub-list for extension extendee
+	0,  // [0:27] is the sub-list for field type_name
 }
 
 func init() { file_pkg_pb_l4slb_proto_init() }
@@ -1362,7 +3258,7 @@ func file_pkg_pb_l4slb_proto_init() {
 			}
 		}
 		file_pkg_pb_l4slb_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*RealMeta); i {
+			switch v := v.(*VipHashFunction); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1374,7 +3270,7 @@ func file_pkg_pb_l4slb_proto_init() {
 			}
 		}
 		file_pkg_pb_l4slb_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Real); i {
+			switch v := v.(*VipInfo); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1386,7 +3282,7 @@ func file_pkg_pb_l4slb_proto_init() {
 			}
 		}
 		file_pkg_pb_l4slb_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*QuicReal); i {
+			switch v := v.(*RealMeta); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1398,7 +3294,7 @@ func file_pkg_pb_l4slb_proto_init() {
 			}
 		}
 		file_pkg_pb_l4slb_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Mac); i {
+			switch v := v.(*Real); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1410,7 +3306,7 @@ func file_pkg_pb_l4slb_proto_init() {
 			}
 		}
 		file_pkg_pb_l4slb_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Stats); i {
+			switch v := v.(*QuicReal); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1422,7 +3318,7 @@ func file_pkg_pb_l4slb_proto_init() {
 			}
 		}
 		file_pkg_pb_l4slb_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Healthcheck); i {
+			switch v := v.(*Mac); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1434,7 +3330,7 @@ func file_pkg_pb_l4slb_proto_init() {
 			}
 		}
 		file_pkg_pb_l4slb_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*HcMap); i {
+			switch v := v.(*Nexthop); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1446,7 +3342,7 @@ func file_pkg_pb_l4slb_proto_init() {
 			}
 		}
 		file_pkg_pb_l4slb_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Reals); i {
+			switch v := v.(*Nexthops); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1458,7 +3354,7 @@ func file_pkg_pb_l4slb_proto_init() {
 			}
 		}
 		file_pkg_pb_l4slb_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Vips); i {
+			switch v := v.(*Stats); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1470,7 +3366,7 @@ func file_pkg_pb_l4slb_proto_init() {
 			}
 		}
 		file_pkg_pb_l4slb_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*QuicReals); i {
+			switch v := v.(*LruMapStats); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1482,7 +3378,7 @@ func file_pkg_pb_l4slb_proto_init() {
 			}
 		}
 		file_pkg_pb_l4slb_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ModifiedRealsForVip); i {
+			switch v := v.(*LruMapsStats); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1494,7 +3390,7 @@ func file_pkg_pb_l4slb_proto_init() {
 			}
 		}
 		file_pkg_pb_l4slb_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ModifiedQuicReals); i {
+			switch v := v.(*BpfMapStats); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1506,7 +3402,7 @@ func file_pkg_pb_l4slb_proto_init() {
 			}
 		}
 		file_pkg_pb_l4slb_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*RealForVip); i {
+			switch v := v.(*BpfMapsStats); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1518,7 +3414,7 @@ func file_pkg_pb_l4slb_proto_init() {
 			}
 		}
 		file_pkg_pb_l4slb_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Flags); i {
+			switch v := v.(*ControllerStats); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1530,6 +3426,270 @@ func file_pkg_pb_l4slb_proto_init() {
 			}
 		}
 		file_pkg_pb_l4slb_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Flow); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_pb_l4slb_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FlowDebugInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_pb_l4slb_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FlowDebugInfos); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_pb_l4slb_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RealLruMiss); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_pb_l4slb_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LruMissStatsForVip); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_pb_l4slb_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MonitorStats); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_pb_l4slb_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CaptureRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_pb_l4slb_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CapturedPacket); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_pb_l4slb_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Healthcheck); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_pb_l4slb_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HcMap); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_pb_l4slb_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Reals); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_pb_l4slb_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Vips); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_pb_l4slb_proto_msgTypes[30].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QuicReals); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_pb_l4slb_proto_msgTypes[31].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ModifiedRealsForVip); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_pb_l4slb_proto_msgTypes[32].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ModifiedRealsForVips); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_pb_l4slb_proto_msgTypes[33].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ModifiedQuicReals); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_pb_l4slb_proto_msgTypes[34].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RealRingShare); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_pb_l4slb_proto_msgTypes[35].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RingSimulation); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_pb_l4slb_proto_msgTypes[36].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HashRingRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_pb_l4slb_proto_msgTypes[37].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HashRing); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_pb_l4slb_proto_msgTypes[38].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RealForVip); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_pb_l4slb_proto_msgTypes[39].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Flags); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_pb_l4slb_proto_msgTypes[40].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Somark); i {
 			case 0:
 				return &v.state
@@ -1548,7 +3708,7 @@ func file_pkg_pb_l4slb_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_pkg_pb_l4slb_proto_rawDesc,
 			NumEnums:      1,
-			NumMessages:   20,
+			NumMessages:   43,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -24,24 +24,41 @@ const _ = grpc.SupportPackageIsVersion7
 type SlbServiceClient interface {
 	ChangeMac(ctx context.Context, in *Mac, opts ...grpc.CallOption) (*Bool, error)
 	GetMac(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Mac, error)
+	AddNexthop(ctx context.Context, in *Mac, opts ...grpc.CallOption) (*Bool, error)
+	DelNexthop(ctx context.Context, in *Mac, opts ...grpc.CallOption) (*Bool, error)
+	GetNexthops(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Nexthops, error)
 	AddVip(ctx context.Context, in *VipMeta, opts ...grpc.CallOption) (*Bool, error)
 	DelVip(ctx context.Context, in *Vip, opts ...grpc.CallOption) (*Bool, error)
 	GetAllVips(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Vips, error)
 	ModifyVip(ctx context.Context, in *VipMeta, opts ...grpc.CallOption) (*Bool, error)
 	ModifyReal(ctx context.Context, in *RealMeta, opts ...grpc.CallOption) (*Bool, error)
 	GetVipFlags(ctx context.Context, in *Vip, opts ...grpc.CallOption) (*Flags, error)
+	ChangeHashFunction(ctx context.Context, in *VipHashFunction, opts ...grpc.CallOption) (*Bool, error)
+	GetVipInfo(ctx context.Context, in *Vip, opts ...grpc.CallOption) (*VipInfo, error)
 	GetRealFlags(ctx context.Context, in *Real, opts ...grpc.CallOption) (*Flags, error)
 	AddRealForVip(ctx context.Context, in *RealForVip, opts ...grpc.CallOption) (*Bool, error)
 	DelRealForVip(ctx context.Context, in *RealForVip, opts ...grpc.CallOption) (*Bool, error)
 	ModifyRealsForVip(ctx context.Context, in *ModifiedRealsForVip, opts ...grpc.CallOption) (*Bool, error)
+	ModifyRealsForVips(ctx context.Context, in *ModifiedRealsForVips, opts ...grpc.CallOption) (*Bool, error)
 	GetRealsForVip(ctx context.Context, in *Vip, opts ...grpc.CallOption) (*Reals, error)
+	SimulateRealsChange(ctx context.Context, in *ModifiedRealsForVip, opts ...grpc.CallOption) (*RingSimulation, error)
+	GetHashRing(ctx context.Context, in *HashRingRequest, opts ...grpc.CallOption) (*HashRing, error)
 	ModifyQuicRealsMapping(ctx context.Context, in *ModifiedQuicReals, opts ...grpc.CallOption) (*Bool, error)
 	GetQuicRealsMapping(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*QuicReals, error)
 	GetStatsForVip(ctx context.Context, in *Vip, opts ...grpc.CallOption) (*Stats, error)
 	GetLruStats(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Stats, error)
 	GetLruMissStats(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Stats, error)
+	SetLruMissStatsVip(ctx context.Context, in *Vip, opts ...grpc.CallOption) (*Bool, error)
+	ClearLruMissStatsVip(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Bool, error)
+	GetLruMissStatsForVip(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*LruMissStatsForVip, error)
 	GetLruFallbackStats(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Stats, error)
 	GetIcmpTooBigStats(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Stats, error)
+	GetLruMapsStats(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*LruMapsStats, error)
+	GetBpfMapStats(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*BpfMapsStats, error)
+	GetControllerStats(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ControllerStats, error)
+	GetFlowDebugInfo(ctx context.Context, in *Flow, opts ...grpc.CallOption) (*FlowDebugInfos, error)
+	GetMonitorStats(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*MonitorStats, error)
+	CapturePackets(ctx context.Context, in *CaptureRequest, opts ...grpc.CallOption) (SlbService_CapturePacketsClient, error)
 	AddHealthcheckerDst(ctx context.Context, in *Healthcheck, opts ...grpc.CallOption) (*Bool, error)
 	DelHealthcheckerDst(ctx context.Context, in *Somark, opts ...grpc.CallOption) (*Bool, error)
 	GetHealthcheckersDst(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*HcMap, error)
@@ -73,6 +90,33 @@ func (c *slbServiceClient) GetMac(ctx context.Context, in *Empty, opts ...grpc.C
 	return out, nil
 }
 
+func (c *slbServiceClient) AddNexthop(ctx context.Context, in *Mac, opts ...grpc.CallOption) (*Bool, error) {
+	out := new(Bool)
+	err := c.cc.Invoke(ctx, "/SlbService/addNexthop", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *slbServiceClient) DelNexthop(ctx context.Context, in *Mac, opts ...grpc.CallOption) (*Bool, error) {
+	out := new(Bool)
+	err := c.cc.Invoke(ctx, "/SlbService/delNexthop", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *slbServiceClient) GetNexthops(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Nexthops, error) {
+	out := new(Nexthops)
+	err := c.cc.Invoke(ctx, "/SlbService/getNexthops", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *slbServiceClient) AddVip(ctx context.Context, in *VipMeta, opts ...grpc.CallOption) (*Bool, error) {
 	out := new(Bool)
 	err := c.cc.Invoke(ctx, "/SlbService/addVip", in, out, opts...)
@@ -127,6 +171,24 @@ func (c *slbServiceClient) GetVipFlags(ctx context.Context, in *Vip, opts ...grp
 	return out, nil
 }
 
+func (c *slbServiceClient) ChangeHashFunction(ctx context.Context, in *VipHashFunction, opts ...grpc.CallOption) (*Bool, error) {
+	out := new(Bool)
+	err := c.cc.Invoke(ctx, "/SlbService/changeHashFunction", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *slbServiceClient) GetVipInfo(ctx context.Context, in *Vip, opts ...grpc.CallOption) (*VipInfo, error) {
+	out := new(VipInfo)
+	err := c.cc.Invoke(ctx, "/SlbService/getVipInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *slbServiceClient) GetRealFlags(ctx context.Context, in *Real, opts ...grpc.CallOption) (*Flags, error) {
 	out := new(Flags)
 	err := c.cc.Invoke(ctx, "/SlbService/getRealFlags", in, out, opts...)
@@ -163,6 +225,15 @@ func (c *slbServiceClient) ModifyRealsForVip(ctx context.Context, in *ModifiedRe
 	return out, nil
 }
 
+func (c *slbServiceClient) ModifyRealsForVips(ctx context.Context, in *ModifiedRealsForVips, opts ...grpc.CallOption) (*Bool, error) {
+	out := new(Bool)
+	err := c.cc.Invoke(ctx, "/SlbService/modifyRealsForVips", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *slbServiceClient) GetRealsForVip(ctx context.Context, in *Vip, opts ...grpc.CallOption) (*Reals, error) {
 	out := new(Reals)
 	err := c.cc.Invoke(ctx, "/SlbService/getRealsForVip", in, out, opts...)
@@ -172,6 +243,24 @@ func (c *slbServiceClient) GetRealsForVip(ctx context.Context, in *Vip, opts ...
 	return out, nil
 }
 
+func (c *slbServiceClient) SimulateRealsChange(ctx context.Context, in *ModifiedRealsForVip, opts ...grpc.CallOption) (*RingSimulation, error) {
+	out := new(RingSimulation)
+	err := c.cc.Invoke(ctx, "/SlbService/simulateRealsChange", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *slbServiceClient) GetHashRing(ctx context.Context, in *HashRingRequest, opts ...grpc.CallOption) (*HashRing, error) {
+	out := new(HashRing)
+	err := c.cc.Invoke(ctx, "/SlbService/getHashRing", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *slbServiceClient) ModifyQuicRealsMapping(ctx context.Context, in *ModifiedQuicReals, opts ...grpc.CallOption) (*Bool, error) {
 	out := new(Bool)
 	err := c.cc.Invoke(ctx, "/SlbService/modifyQuicRealsMapping", in, out, opts...)
@@ -217,6 +306,33 @@ func (c *slbServiceClient) GetLruMissStats(ctx context.Context, in *Empty, opts
 	return out, nil
 }
 
+func (c *slbServiceClient) SetLruMissStatsVip(ctx context.Context, in *Vip, opts ...grpc.CallOption) (*Bool, error) {
+	out := new(Bool)
+	err := c.cc.Invoke(ctx, "/SlbService/setLruMissStatsVip", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *slbServiceClient) ClearLruMissStatsVip(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Bool, error) {
+	out := new(Bool)
+	err := c.cc.Invoke(ctx, "/SlbService/clearLruMissStatsVip", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *slbServiceClient) GetLruMissStatsForVip(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*LruMissStatsForVip, error) {
+	out := new(LruMissStatsForVip)
+	err := c.cc.Invoke(ctx, "/SlbService/getLruMissStatsForVip", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *slbServiceClient) GetLruFallbackStats(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Stats, error) {
 	out := new(Stats)
 	err := c.cc.Invoke(ctx, "/SlbService/getLruFallbackStats", in, out, opts...)
@@ -235,6 +351,83 @@ func (c *slbServiceClient) GetIcmpTooBigStats(ctx context.Context, in *Empty, op
 	return out, nil
 }
 
+func (c *slbServiceClient) GetLruMapsStats(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*LruMapsStats, error) {
+	out := new(LruMapsStats)
+	err := c.cc.Invoke(ctx, "/SlbService/getLruMapsStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *slbServiceClient) GetBpfMapStats(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*BpfMapsStats, error) {
+	out := new(BpfMapsStats)
+	err := c.cc.Invoke(ctx, "/SlbService/getBpfMapStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *slbServiceClient) GetControllerStats(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ControllerStats, error) {
+	out := new(ControllerStats)
+	err := c.cc.Invoke(ctx, "/SlbService/getControllerStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *slbServiceClient) GetFlowDebugInfo(ctx context.Context, in *Flow, opts ...grpc.CallOption) (*FlowDebugInfos, error) {
+	out := new(FlowDebugInfos)
+	err := c.cc.Invoke(ctx, "/SlbService/getFlowDebugInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *slbServiceClient) GetMonitorStats(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*MonitorStats, error) {
+	out := new(MonitorStats)
+	err := c.cc.Invoke(ctx, "/SlbService/getMonitorStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *slbServiceClient) CapturePackets(ctx context.Context, in *CaptureRequest, opts ...grpc.CallOption) (SlbService_CapturePacketsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &SlbService_ServiceDesc.Streams[0], "/SlbService/capturePackets", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &slbServiceCapturePacketsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type SlbService_CapturePacketsClient interface {
+	Recv() (*CapturedPacket, error)
+	grpc.ClientStream
+}
+
+type slbServiceCapturePacketsClient struct {
+	grpc.ClientStream
+}
+
+func (x *slbServiceCapturePacketsClient) Recv() (*CapturedPacket, error) {
+	m := new(CapturedPacket)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *slbServiceClient) AddHealthcheckerDst(ctx context.Context, in *Healthcheck, opts ...grpc.CallOption) (*Bool, error) {
 	out := new(Bool)
 	err := c.cc.Invoke(ctx, "/SlbService/addHealthcheckerDst", in, out, opts...)
@@ -268,24 +461,41 @@ func (c *slbServiceClient) GetHealthcheckersDst(ctx context.Context, in *Empty,
 type SlbServiceServer interface {
 	ChangeMac(context.Context, *Mac) (*Bool, error)
 	GetMac(context.Context, *Empty) (*Mac, error)
+	AddNexthop(context.Context, *Mac) (*Bool, error)
+	DelNexthop(context.Context, *Mac) (*Bool, error)
+	GetNexthops(context.Context, *Empty) (*Nexthops, error)
 	AddVip(context.Context, *VipMeta) (*Bool, error)
 	DelVip(context.Context, *Vip) (*Bool, error)
 	GetAllVips(context.Context, *Empty) (*Vips, error)
 	ModifyVip(context.Context, *VipMeta) (*Bool, error)
 	ModifyReal(context.Context, *RealMeta) (*Bool, error)
 	GetVipFlags(context.Context, *Vip) (*Flags, error)
+	ChangeHashFunction(context.Context, *VipHashFunction) (*Bool, error)
+	GetVipInfo(context.Context, *Vip) (*VipInfo, error)
 	GetRealFlags(context.Context, *Real) (*Flags, error)
 	AddRealForVip(context.Context, *RealForVip) (*Bool, error)
 	DelRealForVip(context.Context, *RealForVip) (*Bool, error)
 	ModifyRealsForVip(context.Context, *ModifiedRealsForVip) (*Bool, error)
+	ModifyRealsForVips(context.Context, *ModifiedRealsForVips) (*Bool, error)
 	GetRealsForVip(context.Context, *Vip) (*Reals, error)
+	SimulateRealsChange(context.Context, *ModifiedRealsForVip) (*RingSimulation, error)
+	GetHashRing(context.Context, *HashRingRequest) (*HashRing, error)
 	ModifyQuicRealsMapping(context.Context, *ModifiedQuicReals) (*Bool, error)
 	GetQuicRealsMapping(context.Context, *Empty) (*QuicReals, error)
 	GetStatsForVip(context.Context, *Vip) (*Stats, error)
 	GetLruStats(context.Context, *Empty) (*Stats, error)
 	GetLruMissStats(context.Context, *Empty) (*Stats, error)
+	SetLruMissStatsVip(context.Context, *Vip) (*Bool, error)
+	ClearLruMissStatsVip(context.Context, *Empty) (*Bool, error)
+	GetLruMissStatsForVip(context.Context, *Empty) (*LruMissStatsForVip, error)
 	GetLruFallbackStats(context.Context, *Empty) (*Stats, error)
 	GetIcmpTooBigStats(context.Context, *Empty) (*Stats, error)
+	GetLruMapsStats(context.Context, *Empty) (*LruMapsStats, error)
+	GetBpfMapStats(context.Context, *Empty) (*BpfMapsStats, error)
+	GetControllerStats(context.Context, *Empty) (*ControllerStats, error)
+	GetFlowDebugInfo(context.Context, *Flow) (*FlowDebugInfos, error)
+	GetMonitorStats(context.Context, *Empty) (*MonitorStats, error)
+	CapturePackets(*CaptureRequest, SlbService_CapturePacketsServer) error
 	AddHealthcheckerDst(context.Context, *Healthcheck) (*Bool, error)
 	DelHealthcheckerDst(context.Context, *Somark) (*Bool, error)
 	GetHealthcheckersDst(context.Context, *Empty) (*HcMap, error)
@@ -302,6 +512,15 @@ func (UnimplementedSlbServiceServer) ChangeMac(context.Context, *Mac) (*Bool, er
 func (UnimplementedSlbServiceServer) GetMac(context.Context, *Empty) (*Mac, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetMac not implemented")
 }
+func (UnimplementedSlbServiceServer) AddNexthop(context.Context, *Mac) (*Bool, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddNexthop not implemented")
+}
+func (UnimplementedSlbServiceServer) DelNexthop(context.Context, *Mac) (*Bool, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DelNexthop not implemented")
+}
+func (UnimplementedSlbServiceServer) GetNexthops(context.Context, *Empty) (*Nexthops, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetNexthops not implemented")
+}
 func (UnimplementedSlbServiceServer) AddVip(context.Context, *VipMeta) (*Bool, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method AddVip not implemented")
 }
@@ -320,6 +539,12 @@ func (UnimplementedSlbServiceServer) ModifyReal(context.Context, *RealMeta) (*Bo
 func (UnimplementedSlbServiceServer) GetVipFlags(context.Context, *Vip) (*Flags, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetVipFlags not implemented")
 }
+func (UnimplementedSlbServiceServer) ChangeHashFunction(context.Context, *VipHashFunction) (*Bool, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ChangeHashFunction not implemented")
+}
+func (UnimplementedSlbServiceServer) GetVipInfo(context.Context, *Vip) (*VipInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetVipInfo not implemented")
+}
 func (UnimplementedSlbServiceServer) GetRealFlags(context.Context, *Real) (*Flags, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetRealFlags not implemented")
 }
@@ -332,9 +557,18 @@ func (UnimplementedSlbServiceServer) DelRealForVip(context.Context, *RealForVip)
 func (UnimplementedSlbServiceServer) ModifyRealsForVip(context.Context, *ModifiedRealsForVip) (*Bool, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ModifyRealsForVip not implemented")
 }
+func (UnimplementedSlbServiceServer) ModifyRealsForVips(context.Context, *ModifiedRealsForVips) (*Bool, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ModifyRealsForVips not implemented")
+}
 func (UnimplementedSlbServiceServer) GetRealsForVip(context.Context, *Vip) (*Reals, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetRealsForVip not implemented")
 }
+func (UnimplementedSlbServiceServer) SimulateRealsChange(context.Context, *ModifiedRealsForVip) (*RingSimulation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SimulateRealsChange not implemented")
+}
+func (UnimplementedSlbServiceServer) GetHashRing(context.Context, *HashRingRequest) (*HashRing, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetHashRing not implemented")
+}
 func (UnimplementedSlbServiceServer) ModifyQuicRealsMapping(context.Context, *ModifiedQuicReals) (*Bool, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ModifyQuicRealsMapping not implemented")
 }
@@ -350,12 +584,39 @@ func (UnimplementedSlbServiceServer) GetLruStats(context.Context, *Empty) (*Stat
 func (UnimplementedSlbServiceServer) GetLruMissStats(context.Context, *Empty) (*Stats, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetLruMissStats not implemented")
 }
+func (UnimplementedSlbServiceServer) SetLruMissStatsVip(context.Context, *Vip) (*Bool, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetLruMissStatsVip not implemented")
+}
+func (UnimplementedSlbServiceServer) ClearLruMissStatsVip(context.Context, *Empty) (*Bool, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClearLruMissStatsVip not implemented")
+}
+func (UnimplementedSlbServiceServer) GetLruMissStatsForVip(context.Context, *Empty) (*LruMissStatsForVip, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLruMissStatsForVip not implemented")
+}
 func (UnimplementedSlbServiceServer) GetLruFallbackStats(context.Context, *Empty) (*Stats, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetLruFallbackStats not implemented")
 }
 func (UnimplementedSlbServiceServer) GetIcmpTooBigStats(context.Context, *Empty) (*Stats, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetIcmpTooBigStats not implemented")
 }
+func (UnimplementedSlbServiceServer) GetLruMapsStats(context.Context, *Empty) (*LruMapsStats, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLruMapsStats not implemented")
+}
+func (UnimplementedSlbServiceServer) GetBpfMapStats(context.Context, *Empty) (*BpfMapsStats, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBpfMapStats not implemented")
+}
+func (UnimplementedSlbServiceServer) GetControllerStats(context.Context, *Empty) (*ControllerStats, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetControllerStats not implemented")
+}
+func (UnimplementedSlbServiceServer) GetFlowDebugInfo(context.Context, *Flow) (*FlowDebugInfos, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFlowDebugInfo not implemented")
+}
+func (UnimplementedSlbServiceServer) GetMonitorStats(context.Context, *Empty) (*MonitorStats, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMonitorStats not implemented")
+}
+func (UnimplementedSlbServiceServer) CapturePackets(*CaptureRequest, SlbService_CapturePacketsServer) error {
+	return status.Errorf(codes.Unimplemented, "method CapturePackets not implemented")
+}
 func (UnimplementedSlbServiceServer) AddHealthcheckerDst(context.Context, *Healthcheck) (*Bool, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method AddHealthcheckerDst not implemented")
 }
@@ -414,6 +675,60 @@ func _SlbService_GetMac_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SlbService_AddNexthop_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Mac)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SlbServiceServer).AddNexthop(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/SlbService/addNexthop",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SlbServiceServer).AddNexthop(ctx, req.(*Mac))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SlbService_DelNexthop_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Mac)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SlbServiceServer).DelNexthop(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/SlbService/delNexthop",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SlbServiceServer).DelNexthop(ctx, req.(*Mac))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SlbService_GetNexthops_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SlbServiceServer).GetNexthops(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/SlbService/getNexthops",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SlbServiceServer).GetNexthops(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _SlbService_AddVip_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(VipMeta)
 	if err := dec(in); err != nil {
@@ -522,6 +837,42 @@ func _SlbService_GetVipFlags_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SlbService_ChangeHashFunction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VipHashFunction)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SlbServiceServer).ChangeHashFunction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/SlbService/changeHashFunction",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SlbServiceServer).ChangeHashFunction(ctx, req.(*VipHashFunction))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SlbService_GetVipInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Vip)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SlbServiceServer).GetVipInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/SlbService/getVipInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SlbServiceServer).GetVipInfo(ctx, req.(*Vip))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _SlbService_GetRealFlags_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Real)
 	if err := dec(in); err != nil {
@@ -594,6 +945,24 @@ func _SlbService_ModifyRealsForVip_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SlbService_ModifyRealsForVips_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ModifiedRealsForVips)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SlbServiceServer).ModifyRealsForVips(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/SlbService/modifyRealsForVips",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SlbServiceServer).ModifyRealsForVips(ctx, req.(*ModifiedRealsForVips))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _SlbService_GetRealsForVip_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Vip)
 	if err := dec(in); err != nil {
@@ -612,6 +981,42 @@ func _SlbService_GetRealsForVip_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SlbService_SimulateRealsChange_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ModifiedRealsForVip)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SlbServiceServer).SimulateRealsChange(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/SlbService/simulateRealsChange",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SlbServiceServer).SimulateRealsChange(ctx, req.(*ModifiedRealsForVip))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SlbService_GetHashRing_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HashRingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SlbServiceServer).GetHashRing(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/SlbService/getHashRing",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SlbServiceServer).GetHashRing(ctx, req.(*HashRingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _SlbService_ModifyQuicRealsMapping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ModifiedQuicReals)
 	if err := dec(in); err != nil {
@@ -702,6 +1107,60 @@ func _SlbService_GetLruMissStats_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SlbService_SetLruMissStatsVip_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Vip)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SlbServiceServer).SetLruMissStatsVip(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/SlbService/setLruMissStatsVip",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SlbServiceServer).SetLruMissStatsVip(ctx, req.(*Vip))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SlbService_ClearLruMissStatsVip_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SlbServiceServer).ClearLruMissStatsVip(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/SlbService/clearLruMissStatsVip",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SlbServiceServer).ClearLruMissStatsVip(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SlbService_GetLruMissStatsForVip_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SlbServiceServer).GetLruMissStatsForVip(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/SlbService/getLruMissStatsForVip",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SlbServiceServer).GetLruMissStatsForVip(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _SlbService_GetLruFallbackStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Empty)
 	if err := dec(in); err != nil {
@@ -738,6 +1197,117 @@ func _SlbService_GetIcmpTooBigStats_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SlbService_GetLruMapsStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SlbServiceServer).GetLruMapsStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/SlbService/getLruMapsStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SlbServiceServer).GetLruMapsStats(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SlbService_GetBpfMapStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SlbServiceServer).GetBpfMapStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/SlbService/getBpfMapStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SlbServiceServer).GetBpfMapStats(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SlbService_GetControllerStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SlbServiceServer).GetControllerStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/SlbService/getControllerStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SlbServiceServer).GetControllerStats(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SlbService_GetFlowDebugInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Flow)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SlbServiceServer).GetFlowDebugInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/SlbService/getFlowDebugInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SlbServiceServer).GetFlowDebugInfo(ctx, req.(*Flow))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SlbService_GetMonitorStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SlbServiceServer).GetMonitorStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/SlbService/getMonitorStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SlbServiceServer).GetMonitorStats(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SlbService_CapturePackets_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(CaptureRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SlbServiceServer).CapturePackets(m, &slbServiceCapturePacketsServer{stream})
+}
+
+type SlbService_CapturePacketsServer interface {
+	Send(*CapturedPacket) error
+	grpc.ServerStream
+}
+
+type slbServiceCapturePacketsServer struct {
+	grpc.ServerStream
+}
+
+func (x *slbServiceCapturePacketsServer) Send(m *CapturedPacket) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 func _SlbService_AddHealthcheckerDst_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Healthcheck)
 	if err := dec(in); err != nil {
@@ -807,6 +1377,18 @@ var SlbService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "getMac",
 			Handler:    _SlbService_GetMac_Handler,
 		},
+		{
+			MethodName: "addNexthop",
+			Handler:    _SlbService_AddNexthop_Handler,
+		},
+		{
+			MethodName: "delNexthop",
+			Handler:    _SlbService_DelNexthop_Handler,
+		},
+		{
+			MethodName: "getNexthops",
+			Handler:    _SlbService_GetNexthops_Handler,
+		},
 		{
 			MethodName: "addVip",
 			Handler:    _SlbService_AddVip_Handler,
@@ -831,6 +1413,14 @@ var SlbService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "getVipFlags",
 			Handler:    _SlbService_GetVipFlags_Handler,
 		},
+		{
+			MethodName: "changeHashFunction",
+			Handler:    _SlbService_ChangeHashFunction_Handler,
+		},
+		{
+			MethodName: "getVipInfo",
+			Handler:    _SlbService_GetVipInfo_Handler,
+		},
 		{
 			MethodName: "getRealFlags",
 			Handler:    _SlbService_GetRealFlags_Handler,
@@ -847,10 +1437,22 @@ var SlbService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "modifyRealsForVip",
 			Handler:    _SlbService_ModifyRealsForVip_Handler,
 		},
+		{
+			MethodName: "modifyRealsForVips",
+			Handler:    _SlbService_ModifyRealsForVips_Handler,
+		},
 		{
 			MethodName: "getRealsForVip",
 			Handler:    _SlbService_GetRealsForVip_Handler,
 		},
+		{
+			MethodName: "simulateRealsChange",
+			Handler:    _SlbService_SimulateRealsChange_Handler,
+		},
+		{
+			MethodName: "getHashRing",
+			Handler:    _SlbService_GetHashRing_Handler,
+		},
 		{
 			MethodName: "modifyQuicRealsMapping",
 			Handler:    _SlbService_ModifyQuicRealsMapping_Handler,
@@ -871,6 +1473,18 @@ var SlbService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "getLruMissStats",
 			Handler:    _SlbService_GetLruMissStats_Handler,
 		},
+		{
+			MethodName: "setLruMissStatsVip",
+			Handler:    _SlbService_SetLruMissStatsVip_Handler,
+		},
+		{
+			MethodName: "clearLruMissStatsVip",
+			Handler:    _SlbService_ClearLruMissStatsVip_Handler,
+		},
+		{
+			MethodName: "getLruMissStatsForVip",
+			Handler:    _SlbService_GetLruMissStatsForVip_Handler,
+		},
 		{
 			MethodName: "getLruFallbackStats",
 			Handler:    _SlbService_GetLruFallbackStats_Handler,
@@ -879,6 +1493,26 @@ var SlbService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "getIcmpTooBigStats",
 			Handler:    _SlbService_GetIcmpTooBigStats_Handler,
 		},
+		{
+			MethodName: "getLruMapsStats",
+			Handler:    _SlbService_GetLruMapsStats_Handler,
+		},
+		{
+			MethodName: "getBpfMapStats",
+			Handler:    _SlbService_GetBpfMapStats_Handler,
+		},
+		{
+			MethodName: "getControllerStats",
+			Handler:    _SlbService_GetControllerStats_Handler,
+		},
+		{
+			MethodName: "getFlowDebugInfo",
+			Handler:    _SlbService_GetFlowDebugInfo_Handler,
+		},
+		{
+			MethodName: "getMonitorStats",
+			Handler:    _SlbService_GetMonitorStats_Handler,
+		},
 		{
 			MethodName: "addHealthcheckerDst",
 			Handler:    _SlbService_AddHealthcheckerDst_Handler,
@@ -892,6 +1526,12 @@ var SlbService_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _SlbService_GetHealthcheckersDst_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "capturePackets",
+			Handler:       _SlbService_CapturePackets_Handler,
+			ServerStreams: true,
+		},
+	},
 	Metadata: "pkg/pb/l4slb.proto",
 }
@@ -5,11 +5,15 @@ import (
 	"github.com/cybwan/l4slb/pkg/ch"
 	"golang.org/x/exp/slices"
 	"net"
+	"runtime"
+	"sync"
+	"time"
 
 	"github.com/cilium/ebpf"
 
 	"github.com/cybwan/l4slb/pkg/bpf"
 	"github.com/cybwan/l4slb/pkg/bpf/adapter"
+	"github.com/cybwan/l4slb/pkg/bpf/progs/balancer"
 )
 
 // position of elements inside control vector
@@ -20,6 +24,7 @@ const (
 	kMainIntfPos
 	kHcIntfPos
 	kIntrospectionGkPos
+	kNexthopsCntPos
 )
 
 const (
@@ -39,6 +44,9 @@ const (
 	kQuicIcmpOffset
 	kIcmpPtbV6Offset
 	kIcmpPtbV4Offset
+
+	// number of global counters in stats map
+	kStatsCountersNum
 )
 
 // LRU map related constants
@@ -66,6 +74,7 @@ const (
 	kRecirculationIndex uint32 = 0
 	kHcSrcMacPos        uint32 = 0
 	kHcDstMacPos        uint32 = 1
+	kMaxNexthops        int    = 16
 
 	kEmptyString            = ""
 	kFlowDebugParentMapName = "flow_debug_maps"
@@ -81,6 +90,13 @@ func NewFlomeshLb(config *FlomeshLbConfig) *FlomeshLb {
 		reals:      make(map[IPAddress]*RealMeta),
 		numToReals: make(map[uint32]IPAddress),
 		hckeys:     make(map[VipKey]uint32),
+		rings:      newRingAllocator(config.getChRingsSize()),
+
+		forwardingCores: config.forwardingCores,
+		numaNodes:       config.numaNodes,
+
+		bpfMaps: adapter.NewRegistry(adapter.BalancerProg),
+		hcMaps:  adapter.NewRegistry(adapter.HealthcheckingProg),
 	}
 	slb.ctlValues = make([]bpf.CtlValue, kCtlMapSize)
 	for i := uint32(0); i < slb.config.maxVips; i++ {
@@ -104,6 +120,72 @@ func NewFlomeshLb(config *FlomeshLbConfig) *FlomeshLb {
 	return &slb
 }
 
+// BalancerMaps returns registry which maps of the balancer are loaded into
+func (lb *FlomeshLb) BalancerMaps() *adapter.Registry {
+	return lb.bpfMaps
+}
+
+// LoadBalancer loads the balancer program with sizes of lb's config and registers its
+// maps into BalancerMaps. the program is owned by lb until CloseBalancer is called
+func (lb *FlomeshLb) LoadBalancer() (*balancer.Program, error) {
+	if lb.balancerProg != nil {
+		return nil, fmt.Errorf("balancer is already loaded")
+	}
+	prog, err := balancer.Load(lb.GetBalancerConfig(), lb.bpfMaps)
+	if err != nil {
+		return nil, err
+	}
+	lb.balancerProg = prog
+	return prog, nil
+}
+
+// CloseBalancer unloads the balancer loaded by LoadBalancer and forgets its maps
+func (lb *FlomeshLb) CloseBalancer() {
+	if lb.balancerProg == nil {
+		return
+	}
+	lb.balancerProg.Close()
+	lb.balancerProg = nil
+	lb.bpfMaps.Reset()
+}
+
+// HealthcheckingMaps returns registry which maps of the healthchecking program are loaded into
+func (lb *FlomeshLb) HealthcheckingMaps() *adapter.Registry {
+	return lb.hcMaps
+}
+
+// GetBalancerConfig returns sizes of balancer's maps which agree with lb's config
+func (lb *FlomeshLb) GetBalancerConfig() *balancer.Config {
+	return &balancer.Config{
+		MaxVips:       lb.config.maxVips,
+		MaxReals:      lb.config.maxReals,
+		ChRingSize:    lb.config.chRingSize,
+		ChRingsSize:   lb.config.chRingsSize,
+		LruSize:       lb.getPerCpuLruSize(),
+		GlobalLruSize: lb.config.globalLruSize,
+		MaxLpmSrcSize: lb.config.maxLpmSrcSize,
+		MaxDecapDst:   lb.config.maxDecapDst,
+	}
+}
+
+// getPerCpuLruSize returns size of connection table of each forwarding core.
+// LruSize is split between forwarding cores or, if they are not specified, all cpus
+func (lb *FlomeshLb) getPerCpuLruSize() uint32 {
+	cores := len(lb.config.forwardingCores)
+	if cores == 0 {
+		if nrCpus, err := adapter.GetPossibleCpus(); err == nil && nrCpus > 0 {
+			cores = nrCpus
+		} else {
+			cores = 1
+		}
+	}
+	perCpuLruSize := lb.config.LruSize / uint64(cores)
+	if perCpuLruSize > uint64(^uint32(0)) {
+		perCpuLruSize = uint64(^uint32(0))
+	}
+	return uint32(perCpuLruSize)
+}
+
 func (lb *FlomeshLb) validateAddress(addr string, allowNetAddr bool) AddressType {
 	if net.ParseIP(addr) == nil {
 		if allowNetAddr && (lb.features.srcRouting || lb.config.testing) {
@@ -111,7 +193,7 @@ func (lb *FlomeshLb) validateAddress(addr string, allowNetAddr bool) AddressType
 				return NETWORK
 			}
 		}
-		lb.lbStats.addrValidationFailed++
+		lb.lbStats.AddrValidationFailed++
 		log.Error().Msgf("Invalid address: %s", addr)
 		return INVALID
 	}
@@ -123,12 +205,14 @@ func (lb *FlomeshLb) ChangeMac(newMac []uint8) bool {
 	if len(newMac) != kMacBytes {
 		return false
 	}
+	lb.ctlLock.Lock()
+	defer lb.ctlLock.Unlock()
 	lb.ctlValues[kMacAddrPos].SetMac(newMac)
-	if !lb.config.testing {
+	if lb.writesBpfMaps() {
 		if !lb.config.disableForwarding {
 			key := kMacAddrPos
-			if err := adapter.BpfUpdateMap(adapter.CtlArray, &key, &lb.ctlValues[kMacAddrPos], ebpf.UpdateAny); err != nil {
-				lb.lbStats.bpfFailedCalls++
+			if err := lb.ctlArray().Update(key, lb.ctlValues[kMacAddrPos], ebpf.UpdateAny); err != nil {
+				lb.lbStats.BpfFailedCalls++
 				log.Error().Msgf("can't add new mac address, error: %v", err)
 				return false
 			}
@@ -136,8 +220,10 @@ func (lb *FlomeshLb) ChangeMac(newMac []uint8) bool {
 
 		if lb.features.directHealthchecking {
 			key := kHcDstMacPos
-			if err := adapter.BpfUpdateMap(adapter.HcPcktMacs, &key, &lb.ctlValues[kMacAddrPos], ebpf.UpdateAny); err != nil {
-				lb.lbStats.bpfFailedCalls++
+			hcMac := new(bpf.HcMac)
+			hcMac.SetMac(newMac)
+			if err := lb.hcPcktMacs().Update(key, *hcMac, ebpf.UpdateAny); err != nil {
+				lb.lbStats.BpfFailedCalls++
 				log.Error().Msgf("can't add new mac address for direct healthchecks, error: %v", err)
 				return false
 			}
@@ -147,7 +233,11 @@ func (lb *FlomeshLb) ChangeMac(newMac []uint8) bool {
 }
 
 func (lb *FlomeshLb) GetMac() []uint8 {
-	return lb.ctlValues[kMacAddrPos].GetMac()
+	lb.ctlLock.Lock()
+	defer lb.ctlLock.Unlock()
+	mac := make([]uint8, kMacBytes)
+	copy(mac, lb.ctlValues[kMacAddrPos].GetMac())
+	return mac
 }
 
 func (lb *FlomeshLb) GetIndexOfNetworkInterfaces() map[int]uint32 {
@@ -164,6 +254,20 @@ func (lb *FlomeshLb) GetIndexOfNetworkInterfaces() map[int]uint32 {
 }
 
 func (lb *FlomeshLb) AddVip(vip *VipKey, flags uint32) bool {
+	return lb.AddVipWithRingSize(vip, flags, 0)
+}
+
+// AddVipWithRingSize adds vip whose ring has ringSize positions, ch_ring_size if 0.
+// smaller rings let ch_rings hold more vips with few reals, larger ones spread
+// traffic more evenly between many reals
+func (lb *FlomeshLb) AddVipWithRingSize(vip *VipKey, flags uint32, ringSize uint32) bool {
+	return lb.AddVipWithHashFunction(vip, flags, ringSize, lb.config.hashFunction)
+}
+
+// AddVipWithHashFunction adds vip whose ring has ringSize positions and is generated
+// by hfunc instead of hash_function of the balancer
+func (lb *FlomeshLb) AddVipWithHashFunction(vip *VipKey, flags uint32, ringSize uint32,
+	hfunc ch.HashFunction) bool {
 	if lb.config.disableForwarding {
 		log.Info().Msg("Ignoring addVip call on non-forwarding instance")
 		return false
@@ -171,6 +275,14 @@ func (lb *FlomeshLb) AddVip(vip *VipKey, flags uint32) bool {
 
 	log.Info().Msgf("adding new vip: %s:%d:%d", vip.Address, vip.Port, vip.Proto)
 
+	if ringSize == 0 {
+		ringSize = lb.config.chRingSize
+	}
+	if !isPrime(ringSize) {
+		log.Error().Msgf("ring size of vip must be a prime number, got %d", ringSize)
+		return false
+	}
+
 	if lb.vipNums.Len() == 0 {
 		log.Error().Msg("exhausted vip's space")
 		return false
@@ -182,15 +294,29 @@ func (lb *FlomeshLb) AddVip(vip *VipKey, flags uint32) bool {
 	}
 
 	vipNum := lb.vipNums.PopFront().(uint32)
-	lb.vips[*vip] = NewVip(vipNum, flags, lb.config.chRingSize, lb.config.hashFunction)
+	ringOffset, err := lb.allocateRing(vipNum, ringSize)
+	if err != nil {
+		lb.vipNums.PushFront(vipNum)
+		log.Error().Msgf("can't add vip %s: %v", vip.Address, err)
+		return false
+	}
+	entry := NewVip(vipNum, flags, ringOffset, ringSize, hfunc)
+	lb.vips[*vip] = entry
 
-	if !lb.config.testing {
+	if lb.writesBpfMaps() {
 		meta := new(bpf.VipMeta)
 		meta.VipNum = vipNum
 		meta.Flags = flags
-		return lb.updateVipMap(ADD, vip, meta)
+		if !lb.updateVipRingsMap(entry) || !lb.updateVipMap(ADD, vip, meta) {
+			// the vip is not added, its number and ring are left for the next one
+			delete(lb.vips, *vip)
+			lb.releaseRing(entry)
+			lb.vipNums.PushFront(vipNum)
+			return false
+		}
 	}
 
+	lb.saveVips()
 	return true
 }
 
@@ -209,15 +335,17 @@ func (lb *FlomeshLb) AddHcKey(hcKey *VipKey) bool {
 	}
 	hcKeyNum := lb.hcKeyNums.PopFront().(uint32)
 	lb.hckeys[*hcKey] = hcKeyNum
-	if !lb.config.testing {
+	if lb.writesBpfMaps() {
 		return lb.updateHcKeyMap(ADD, hcKey, hcKeyNum)
 	}
 	return true
 }
 
+// ChangeHashFunctionForVip regenerates ring of the vip by hfunc, which is then used for all
+// changes of the vip's reals
 func (lb *FlomeshLb) ChangeHashFunctionForVip(vip *VipKey, hfunc ch.HashFunction) bool {
 	if lb.config.disableForwarding {
-		log.Error().Msg("Ignoring addVip call on non-forwarding instance")
+		log.Error().Msg("Ignoring changeHashFunctionForVip call on non-forwarding instance")
 		return false
 	}
 	if net.ParseIP(vip.Address) == nil {
@@ -230,33 +358,68 @@ func (lb *FlomeshLb) ChangeHashFunctionForVip(vip *VipKey, hfunc ch.HashFunction
 		return false
 	}
 
+	if reals := entry.numReals(); hfunc == ch.Rendezvous && reals > ch.RendezvousMaxEndpoints {
+		log.Error().Msgf("vip has %d reals, %s supports at most %d", reals, hfunc, ch.RendezvousMaxEndpoints)
+		return false
+	}
+
 	entry.SetHashFunction(hfunc)
-	positions := entry.recalculateHashRing()
-	lb.programHashRing(positions, entry.GetNum())
+	positions := lb.generateHashRing(entry.recalculateHashRing)
+	lb.programHashRing(positions, entry)
+	lb.saveVips()
 	return true
 }
 
-func (lb *FlomeshLb) programHashRing(chPositions []RealPos, vipNum uint32) {
+func (lb *FlomeshLb) programHashRing(chPositions []RealPos, vip *Vip) {
 	if len(chPositions) == 0 {
 		return
 	}
-	if !lb.config.testing {
+	if lb.writesBpfMaps() {
 		updateSize := len(chPositions)
-		keys := make([]uint32, updateSize)
-		values := make([]uint32, updateSize)
+		if cap(lb.ringKeys) < updateSize {
+			lb.ringKeys = make([]uint32, updateSize)
+			lb.ringValues = make([]uint32, updateSize)
+		}
+		keys := lb.ringKeys[:updateSize]
+		values := lb.ringValues[:updateSize]
 
 		for i := 0; i < updateSize; i++ {
-			keys[i] = vipNum*lb.config.chRingSize + chPositions[i].pos
+			keys[i] = vip.ringOffset + chPositions[i].pos
 			values[i] = chPositions[i].real
 		}
 
-		if err := adapter.BpfUpdateMapBatch(adapter.ChRings, keys, values, updateSize); err != nil {
-			lb.lbStats.bpfFailedCalls++
+		if err := lb.chRings().BatchUpdate(keys, values); err != nil {
+			lb.lbStats.BpfFailedCalls++
 			log.Error().Msgf("can't update ch ring, error: %v", err)
+			return
+		}
+		lb.lbStats.RingBatchUpdates++
+		lb.lbStats.RingPositionsWritten += uint64(updateSize)
+		if uint64(updateSize) > lb.lbStats.MaxRingBatchUpdateSize {
+			lb.lbStats.MaxRingBatchUpdateSize = uint64(updateSize)
 		}
 	}
 }
 
+// generateHashRing runs generation of vip's ch ring and accounts it in controller's stats
+func (lb *FlomeshLb) generateHashRing(generate func() []RealPos) []RealPos {
+	start := time.Now()
+	positions := generate()
+	lb.lbStats.RingGenerationTime += time.Since(start)
+	lb.lbStats.RingRecomputations++
+	return positions
+}
+
+// GetFlomeshLbStats returns counters of the controller
+func (lb *FlomeshLb) GetFlomeshLbStats() FlomeshLbStats {
+	stats := lb.lbStats
+	stats.FreeVipNums = uint32(lb.vipNums.Len())
+	stats.FreeRealNums = uint32(lb.realNums.Len())
+	stats.FreeHcKeyNums = uint32(lb.hcKeyNums.Len())
+	stats.FreeRingPositions = lb.freeRingPositions()
+	return stats
+}
+
 func (lb *FlomeshLb) DelVip(vip *VipKey) bool {
 	if lb.config.disableForwarding {
 		log.Info().Msg("Ignoring delVip call on non-forwarding instance")
@@ -271,17 +434,23 @@ func (lb *FlomeshLb) DelVip(vip *VipKey) bool {
 		return false
 	}
 
-	for rnum, _ := range entry.reals {
+	for _, rnum := range entry.getReals() {
 		realAddr := lb.numToReals[rnum]
 		lb.decreaseRefCountForReal(realAddr)
 	}
 
 	lb.vipNums.PushBack(entry.num)
+	lb.releaseRing(entry)
 
-	if !lb.config.testing {
+	if tracked, isSome := lb.lruMissStatsVip.Get(); isSome && tracked.Equals(vip) {
+		lb.ClearLruMissStatsVip()
+	}
+
+	if lb.writesBpfMaps() {
 		lb.updateVipMap(DEL, vip, nil)
 	}
 	delete(lb.vips, *vip)
+	lb.saveVips()
 	return true
 }
 
@@ -302,7 +471,7 @@ func (lb *FlomeshLb) DelHcKey(hcKey *VipKey) bool {
 	lb.hcKeyNums.PushBack(entry)
 	delete(lb.hckeys, *hcKey)
 
-	if !lb.config.testing {
+	if lb.writesBpfMaps() {
 		lb.updateHcKeyMap(DEL, hcKey, 0)
 	}
 
@@ -333,6 +502,23 @@ func (lb *FlomeshLb) GetVipFlags(vip *VipKey) (uint32, error) {
 	return entry.GetFlags(), nil
 }
 
+// GetVipInfo returns flags, hash function, ring size and number of reals of the vip
+func (lb *FlomeshLb) GetVipInfo(vip *VipKey) (*VipInfo, error) {
+	if lb.config.disableForwarding {
+		return nil, fmt.Errorf("getVipInfo called on non-forwarding instance")
+	}
+	entry, exists := lb.vips[*vip]
+	if !exists {
+		return nil, fmt.Errorf("trying to get info of non-existing vip: %s", vip.Address)
+	}
+	return &VipInfo{
+		Flags:        entry.GetFlags(),
+		HashFunction: entry.GetHashFunction(),
+		RingSize:     entry.GetChRingSize(),
+		Reals:        uint32(entry.numReals()),
+	}, nil
+}
+
 func (lb *FlomeshLb) ModifyVip(vip *VipKey, flag uint32, set bool) bool {
 	log.Info().Msgf("modifying vip: %s:%d:%d", vip.Address, vip.Port, vip.Proto)
 	entry, exists := lb.vips[*vip]
@@ -345,12 +531,15 @@ func (lb *FlomeshLb) ModifyVip(vip *VipKey, flag uint32, set bool) bool {
 	} else {
 		entry.UnsetFlags(flag)
 	}
-	if !lb.config.testing {
+	if lb.writesBpfMaps() {
 		meta := new(bpf.VipMeta)
 		meta.VipNum = entry.GetNum()
 		meta.Flags = entry.GetFlags()
-		return lb.updateVipMap(ADD, vip, meta)
+		if !lb.updateVipMap(ADD, vip, meta) {
+			return false
+		}
 	}
+	lb.saveVips()
 	return true
 }
 
@@ -401,9 +590,10 @@ func (lb *FlomeshLb) ModifyReal(real string, flags uint8, set bool) bool {
 		entry.flags &= ^flags
 	}
 	lb.reals[raddr].flags = entry.flags
-	if !lb.config.testing {
+	if lb.writesBpfMaps() {
 		lb.updateRealsMap(raddr, entry.num, entry.flags)
 	}
+	lb.saveVips()
 	return true
 }
 
@@ -412,17 +602,106 @@ func (lb *FlomeshLb) ModifyRealsForVip(action ModifyAction, reals []NewReal, vip
 		log.Error().Msg("modifyRealsForVip called on non-forwarding instance")
 		return false
 	}
-	ureal := UpdateReal{}
-	ureal.action = action
-
-	ureals := make([]UpdateReal, 0)
-
 	entry, exists := lb.vips[*vip]
 	if !exists {
 		log.Info().Msgf("trying to modify reals for non existing vip:: %s", vip.Address)
 		return false
 	}
-	curReals := entry.getReals()
+	ureals, _ := lb.prepareRealsUpdate(action, reals, vip, entry.getReals())
+	chPositions := lb.generateHashRing(func() []RealPos {
+		return entry.batchRealsUpdate(ureals)
+	})
+	lb.programHashRing(chPositions, entry)
+	lb.saveVips()
+	return true
+}
+
+// VipRealsChange is change of reals of a vip, as ModifyRealsForVip applies it
+type VipRealsChange struct {
+	Vip    VipKey
+	Action ModifyAction
+	Reals  []NewReal
+}
+
+// ModifyRealsForVips applies changes of reals of several vips. rings of the vips are generated
+// in parallel and then programmed one by one. nothing is changed if any of the vips doesn't exist
+func (lb *FlomeshLb) ModifyRealsForVips(changes []VipRealsChange) bool {
+	if lb.config.disableForwarding {
+		log.Error().Msg("modifyRealsForVips called on non-forwarding instance")
+		return false
+	}
+	for i := range changes {
+		if _, exists := lb.vips[changes[i].Vip]; !exists {
+			log.Info().Msgf("trying to modify reals for non existing vip:: %s", changes[i].Vip.Address)
+			return false
+		}
+	}
+
+	// changes of the same vip are applied together, so every vip is generated by one goroutine
+	type vipUpdate struct {
+		entry     *Vip
+		curReals  []uint32
+		ureals    []UpdateReal
+		positions []RealPos
+		took      time.Duration
+	}
+	updates := make([]*vipUpdate, 0, len(changes))
+	byVip := make(map[VipKey]*vipUpdate, len(changes))
+	for i := range changes {
+		change := &changes[i]
+		update, exists := byVip[change.Vip]
+		if !exists {
+			entry := lb.vips[change.Vip]
+			update = &vipUpdate{entry: entry, curReals: entry.getReals()}
+			byVip[change.Vip] = update
+			updates = append(updates, update)
+		}
+		var ureals []UpdateReal
+		ureals, update.curReals = lb.prepareRealsUpdate(change.Action, change.Reals, &change.Vip, update.curReals)
+		update.ureals = append(update.ureals, ureals...)
+	}
+
+	jobs := make(chan *vipUpdate)
+	var wg sync.WaitGroup
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(updates) {
+		workers = len(updates)
+	}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for update := range jobs {
+				start := time.Now()
+				update.positions = update.entry.batchRealsUpdate(update.ureals)
+				update.took = time.Since(start)
+			}
+		}()
+	}
+	for _, update := range updates {
+		jobs <- update
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, update := range updates {
+		lb.lbStats.RingGenerationTime += update.took
+		lb.lbStats.RingRecomputations++
+		lb.programHashRing(update.positions, update.entry)
+	}
+	lb.saveVips()
+	return true
+}
+
+// prepareRealsUpdate validates reals, allocates numbers of new ones and accounts their
+// references. returns updates of the vip's reals and numbers of its reals after them,
+// curReals are numbers of the vip's reals before them
+func (lb *FlomeshLb) prepareRealsUpdate(
+	action ModifyAction, reals []NewReal, vip *VipKey, curReals []uint32) ([]UpdateReal, []uint32) {
+	ureal := UpdateReal{}
+	ureal.action = action
+
+	ureals := make([]UpdateReal, 0)
 
 	for _, r := range reals {
 		if net.ParseIP(r.Address) == nil {
@@ -446,8 +725,15 @@ func (lb *FlomeshLb) ModifyRealsForVip(action ModifyAction, reals []NewReal, vip
 			}
 			ureal.updatedReal.Num = rentry.num
 			lb.decreaseRefCountForReal(raddr)
+			i := slices.Index(curReals, rentry.num)
+			curReals = slices.Delete(curReals, i, i+1)
 		} else {
 			rentry, found := lb.reals[raddr]
+			if (!found || !slices.Contains(curReals, rentry.num)) && lb.realsLimitReached(vip, curReals) {
+				log.Error().Msgf("vip %s:%d:%d has %d reals, which is the most its hash function supports",
+					vip.Address, vip.Port, vip.Proto, len(curReals))
+				continue
+			}
 			if found {
 				if !slices.Contains(curReals, rentry.num) {
 					// increment ref count if it's a new real for this vip
@@ -462,16 +748,22 @@ func (lb *FlomeshLb) ModifyRealsForVip(action ModifyAction, reals []NewReal, vip
 					continue
 				}
 				ureal.updatedReal.Num = rnum
+				curReals = append(curReals, rnum)
 			}
 			ureal.updatedReal.Weight = r.Weight
 			ureal.updatedReal.Hash = raddr.hash()
+			ureal.updatedReal.Key = r.Address
 		}
 		ureals = append(ureals, ureal)
 	}
-	chPositions := entry.batchRealsUpdate(ureals)
-	vipNum := entry.num
-	lb.programHashRing(chPositions, vipNum)
-	return true
+	return ureals, curReals
+}
+
+// realsLimitReached returns true if no more reals could be added to vip, which has curReals.
+// ring generation of rendezvous hashing takes too long for many reals
+func (lb *FlomeshLb) realsLimitReached(vip *VipKey, curReals []uint32) bool {
+	entry := lb.vips[*vip]
+	return entry.GetHashFunction() == ch.Rendezvous && len(curReals) >= ch.RendezvousMaxEndpoints
 }
 
 func (lb *FlomeshLb) GetRealsForVip(vip *VipKey) ([]NewReal, error) {
@@ -516,16 +808,16 @@ func (lb *FlomeshLb) GetIndexForReal(real string) int32 {
 func (lb *FlomeshLb) updateVipMap(action ModifyAction, vip *VipKey, meta *bpf.VipMeta) bool {
 	vipDef := lb.vipKeyToVipDefinition(vip)
 	if action == ADD {
-		if err := adapter.BpfUpdateMap(adapter.VipMap, vipDef, meta, ebpf.UpdateAny); err != nil {
+		if err := lb.vipMap().Update(*vipDef, *meta, ebpf.UpdateAny); err != nil {
 			log.Error().Msgf("can't add new element into vip_map, error:%v", err)
-			lb.lbStats.bpfFailedCalls++
+			lb.lbStats.BpfFailedCalls++
 			return false
 		}
 		return true
 	} else {
-		if err := adapter.BpfMapDeleteElement(adapter.VipMap, vipDef); err != nil {
+		if err := lb.vipMap().Delete(*vipDef); err != nil {
 			log.Error().Msgf("can't delete element from vip_map, error:%v", err)
-			lb.lbStats.bpfFailedCalls++
+			lb.lbStats.BpfFailedCalls++
 			return false
 		}
 		return true
@@ -535,16 +827,16 @@ func (lb *FlomeshLb) updateVipMap(action ModifyAction, vip *VipKey, meta *bpf.Vi
 func (lb *FlomeshLb) updateHcKeyMap(action ModifyAction, hcKey *VipKey, hcKeyId uint32) bool {
 	vipDef := lb.vipKeyToVipDefinition(hcKey)
 	if action == ADD {
-		if err := adapter.BpfUpdateMap(adapter.HcKeyMap, vipDef, &hcKeyId, ebpf.UpdateAny); err != nil {
+		if err := lb.hcKeyMap().Update(*vipDef, hcKeyId, ebpf.UpdateAny); err != nil {
 			log.Error().Msgf("can't add new element into hc_key_map, error:%v", err)
-			lb.lbStats.bpfFailedCalls++
+			lb.lbStats.BpfFailedCalls++
 			return false
 		}
 		return true
 	} else {
-		if err := adapter.BpfMapDeleteElement(adapter.HcKeyMap, vipDef); err != nil {
+		if err := lb.hcKeyMap().Delete(*vipDef); err != nil {
 			log.Error().Msgf("can't delete element from hc_key_map, error:%v", err)
-			lb.lbStats.bpfFailedCalls++
+			lb.lbStats.BpfFailedCalls++
 			return false
 		}
 		return true
@@ -560,11 +852,11 @@ func (lb *FlomeshLb) updateRealsMap(real IPAddress, num uint32, flags uint8) boo
 	realAddr := new(BeAddr)
 	realAddr.SetAddr(addr)
 	flags &= ^V6DADDR // to keep IPv4/IPv6 specific flag
-	realAddr.SetFlags(flags)
+	realAddr.SetFlags(realAddr.flags | flags)
 
-	if err := adapter.BpfUpdateMap(adapter.Reals, &num, realAddr, ebpf.UpdateAny); err != nil {
+	if err := lb.realsMap().Update(num, *realAddr, ebpf.UpdateAny); err != nil {
 		log.Error().Msgf("can't add new real, error:%v", err)
-		lb.lbStats.bpfFailedCalls++
+		lb.lbStats.BpfFailedCalls++
 		return false
 	}
 	return true
@@ -576,7 +868,7 @@ func (lb *FlomeshLb) vipKeyToVipDefinition(vipKey *VipKey) *bpf.VipDefinition {
 	if ip4Addr := vipAddr.To4(); ip4Addr != nil {
 		vipDef.SetVip4(ip4Addr)
 	} else if ip6Addr := vipAddr.To16(); ip6Addr != nil {
-		vipDef.SetVip6(ip4Addr)
+		vipDef.SetVip6(ip6Addr)
 	}
 	vipDef.SetPort(vipKey.Port)
 	vipDef.SetProto(vipKey.Proto)
@@ -621,7 +913,7 @@ func (lb *FlomeshLb) increaseRefCountForReal(real IPAddress, flags uint8) uint32
 	rmeta.flags = flags
 	lb.reals[real] = rmeta
 
-	if !lb.config.testing {
+	if lb.writesBpfMaps() {
 		lb.updateRealsMap(real, rnum, flags)
 	}
 
@@ -704,23 +996,11 @@ func (lb *FlomeshLb) getLbStats(position uint32, name adapter.BpfMapName) bpf.Lb
 	if lb.config.disableForwarding {
 		return bpf.LbStats{}
 	}
-	nrCpus, err := adapter.GetPossibleCpus()
-	if err != nil {
-		return bpf.LbStats{}
-	}
-	if nrCpus < 1 {
-		return bpf.LbStats{}
-	}
-	stats := make([]bpf.LbStats, nrCpus)
 	sumStat := bpf.LbStats{}
-	if !lb.config.testing {
-		if err := adapter.BpfMapLookupElement(name, &position, stats); err == nil {
-			for _, stat := range stats {
-				sumStat.V1 += stat.V1
-				sumStat.V2 += stat.V1
-			}
-		} else {
-			lb.lbStats.bpfFailedCalls++
+	if lb.writesBpfMaps() {
+		var err error
+		if sumStat, err = lb.statsArray(name).LookupSum(position, bpf.AddLbStats); err != nil {
+			lb.lbStats.BpfFailedCalls++
 		}
 	}
 	return sumStat
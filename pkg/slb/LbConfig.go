@@ -2,7 +2,6 @@ package slb
 
 import "github.com/cybwan/l4slb/pkg/ch"
 
-// TODO benne
 type FlomeshLbMonitorConfig struct {
 	nCpus      uint32
 	pages      uint32
@@ -12,6 +11,10 @@ type FlomeshLbMonitorConfig struct {
 	snapLen    uint32
 	storage    PcapStorageFormat
 	bufferSize uint32
+	// directory of pcap files for FILE storage
+	path string
+	// events which are read, all if empty
+	events []string
 }
 
 type FlomeshLbConfig struct {
@@ -30,6 +33,7 @@ type FlomeshLbConfig struct {
 	maxVips                uint32
 	maxReals               uint32
 	chRingSize             uint32
+	chRingsSize            uint32
 	testing                bool
 	LruSize                uint64
 	forwardingCores        []int32
@@ -39,6 +43,7 @@ type FlomeshLbConfig struct {
 	hcInterface            string
 	xdpAttachFlags         uint32
 	monitorConfig          FlomeshLbMonitorConfig
+	introspection          bool
 	memlockUnlimited       bool
 	LbSrcV4                string
 	LbSrcV6                string
@@ -47,6 +52,8 @@ type FlomeshLbConfig struct {
 	flowDebug              bool
 	globalLruSize          uint32
 	useRootMap             bool
+	// file vips are saved to and restored from, see LbVipConfig.go
+	vipConfigPath string
 }
 
 func NewFlomeshLbConfig() *FlomeshLbConfig {
@@ -71,5 +78,29 @@ func NewFlomeshLbConfig() *FlomeshLbConfig {
 		hashFunction:       ch.Maglev,
 		globalLruSize:      kDefaultGlobalLruSize,
 		useRootMap:         true,
+		monitorConfig: FlomeshLbMonitorConfig{
+			pages:      kDefaultNumOfPages,
+			queueSize:  kDefaultMonitorQueueSize,
+			pcktLimit:  kDefaultMonitorPcktLimit,
+			snapLen:    kDefaultMonitorSnapLen,
+			storage:    IOBUF,
+			bufferSize: kDefaultMonitorBufSize,
+			path:       kDefaultMonitorPath,
+		},
 	}
 }
+
+// MainInterface returns name of the interface the balancer is attached to
+func (c *FlomeshLbConfig) MainInterface() string {
+	return c.mainInterface
+}
+
+// XdpAttachFlags returns XDP_FLAGS_* the balancer is attached with
+func (c *FlomeshLbConfig) XdpAttachFlags() uint32 {
+	return c.xdpAttachFlags
+}
+
+// RootMapPos returns position of the balancer in root map
+func (c *FlomeshLbConfig) RootMapPos() uint32 {
+	return c.rootMapPos
+}
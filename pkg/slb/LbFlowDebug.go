@@ -0,0 +1,171 @@
+package slb
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/cilium/ebpf"
+
+	"github.com/cybwan/l4slb/pkg/bpf"
+	"github.com/cybwan/l4slb/pkg/bpf/adapter"
+)
+
+// Flow is 5 tuple of client's packet, as it is seen by the balancer
+type Flow struct {
+	Src     string
+	Dst     string
+	SrcPort uint16
+	DstPort uint16
+	Proto   uint8
+}
+
+// FlowDebugInfo is what cpu knows about the flow. only cpus which know something are reported
+type FlowDebugInfo struct {
+	Cpu int32
+
+	// connection table of the cpu
+	InLru bool
+	Real  string
+	Atime uint64
+
+	// global connection table of the cpu
+	InGlobalLru bool
+	GlobalReal  string
+
+	// outer addresses of gue packet, recorded when the flow has been decapsulated on this host
+	HasRoute bool
+	L4Hop    string
+	ThisHop  string
+}
+
+// initFlowDebugMaps creates maps of flow debug for cpus which have connection tables.
+// they are sized and placed the same way as connection tables
+func (lb *FlomeshLb) initFlowDebugMaps() bool {
+	if !lb.config.flowDebug {
+		return true
+	}
+	if !lb.bpfMaps.Has(adapter.FlowDebugMaps) {
+		// nothing would ever be recorded, so the option is refused instead of being ignored
+		log.Error().Msgf("flow_debug is enabled, but balancer has been built without %s. "+
+			"build it with BPF_FEATURES=\"INLINE_DECAP_GUE RECORD_FLOW_INFO\"", kFlowDebugParentMapName)
+		return false
+	}
+	for _, lru := range lb.lruMaps {
+		debugMap := cpuLruMap{cpu: lru.cpu, numaNode: lru.numaNode, maxEntries: lru.maxEntries,
+			fallback: lru.fallback}
+		if !lb.addCpuLruMap(adapter.FlowDebugMaps, &debugMap) {
+			return false
+		}
+		lb.flowDebugMaps = append(lb.flowDebugMaps, debugMap)
+	}
+	lb.features.flowDebug = true
+	return true
+}
+
+// GetFlowDebugInfo looks the flow up in connection tables and flow debug maps of all cpus
+func (lb *FlomeshLb) GetFlowDebugInfo(flow *Flow) ([]FlowDebugInfo, error) {
+	key, err := lb.flowToFlowKey(flow)
+	if err != nil {
+		return nil, err
+	}
+	infos := make(map[int32]*FlowDebugInfo)
+	var cpus []int32
+	getInfo := func(cpu int32) *FlowDebugInfo {
+		info, exists := infos[cpu]
+		if !exists {
+			info = &FlowDebugInfo{Cpu: cpu}
+			infos[cpu] = info
+			cpus = append(cpus, cpu)
+		}
+		return info
+	}
+
+	for _, lru := range lb.lruMaps {
+		var realPos bpf.RealPosLru
+		found, err := lb.lookupFlow(lru.bpfMap, key, &realPos)
+		if err != nil {
+			return nil, fmt.Errorf("can't lookup connection table of cpu %d: %w", lru.cpu, err)
+		}
+		if found {
+			info := getInfo(lru.cpu)
+			info.InLru = true
+			info.Real = lb.getRealByPos(realPos.Pos)
+			info.Atime = realPos.Atime
+		}
+	}
+	for _, glru := range lb.globalLruMaps {
+		var realPos uint32
+		found, err := lb.lookupFlow(glru.bpfMap, key, &realPos)
+		if err != nil {
+			return nil, fmt.Errorf("can't lookup global connection table of cpu %d: %w", glru.cpu, err)
+		}
+		if found {
+			info := getInfo(glru.cpu)
+			info.InGlobalLru = true
+			info.GlobalReal = lb.getRealByPos(realPos)
+		}
+	}
+	for _, debugMap := range lb.flowDebugMaps {
+		var route bpf.FlowDebugInfo
+		found, err := lb.lookupFlow(debugMap.bpfMap, key, &route)
+		if err != nil {
+			return nil, fmt.Errorf("can't lookup flow debug map of cpu %d: %w", debugMap.cpu, err)
+		}
+		if found {
+			info := getInfo(debugMap.cpu)
+			info.HasRoute = true
+			info.L4Hop = route.GetL4Hop().String()
+			info.ThisHop = route.GetThisHop().String()
+		}
+	}
+
+	result := make([]FlowDebugInfo, 0, len(cpus))
+	for _, cpu := range cpus {
+		result = append(result, *infos[cpu])
+	}
+	return result, nil
+}
+
+func (lb *FlomeshLb) lookupFlow(bpfMap *ebpf.Map, key *bpf.FlowKey, value interface{}) (bool, error) {
+	err := bpfMap.Lookup(key, value)
+	if errors.Is(err, ebpf.ErrKeyNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		lb.lbStats.BpfFailedCalls++
+		return false, err
+	}
+	return true, nil
+}
+
+// getRealByPos returns address of real by its position in reals map. position is
+// printed if real has been deleted since the flow has been recorded
+func (lb *FlomeshLb) getRealByPos(pos uint32) string {
+	if real, exists := lb.numToReals[pos]; exists {
+		return string(real)
+	}
+	return fmt.Sprintf("unknown real #%d", pos)
+}
+
+func (lb *FlomeshLb) flowToFlowKey(flow *Flow) (*bpf.FlowKey, error) {
+	src := net.ParseIP(flow.Src)
+	if src == nil {
+		lb.lbStats.AddrValidationFailed++
+		return nil, fmt.Errorf("invalid src address: %s", flow.Src)
+	}
+	dst := net.ParseIP(flow.Dst)
+	if dst == nil {
+		lb.lbStats.AddrValidationFailed++
+		return nil, fmt.Errorf("invalid dst address: %s", flow.Dst)
+	}
+	if (src.To4() == nil) != (dst.To4() == nil) {
+		return nil, fmt.Errorf("src %s and dst %s are of different families", flow.Src, flow.Dst)
+	}
+	key := new(bpf.FlowKey)
+	key.SetSrc(src)
+	key.SetDst(dst)
+	key.SetPorts(flow.SrcPort, flow.DstPort)
+	key.SetProto(flow.Proto)
+	return key, nil
+}
@@ -0,0 +1,108 @@
+package slb
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cilium/ebpf"
+
+	"github.com/cybwan/l4slb/pkg/ch"
+)
+
+// HashRing is ring of the vip as the controller has generated it and, if it has been
+// read back, as it is in ch_rings
+type HashRing struct {
+	RingOffset   uint32
+	HashFunction ch.HashFunction
+	// number of the real at each position, -1 if no real has been placed there yet
+	Positions []int
+	// addresses of reals of Positions and KernelPositions by their numbers
+	Addresses map[uint32]string
+	// positions read from ch_rings, nil if they have not been read
+	KernelPositions []uint32
+	// positions whose real in ch_rings differs from the generated one. positions
+	// without real in Positions are not checked, they have never been written
+	Mismatched []uint32
+}
+
+// GetHashRing returns copy of the vip's ring. if readKernel is set, the ring is also read
+// back from ch_rings and compared with the generated one, so positions the data plane
+// disagrees on (e.g. after failed updates of the map) could be found
+func (lb *FlomeshLb) GetHashRing(vip *VipKey, readKernel bool) (*HashRing, error) {
+	if lb.config.disableForwarding {
+		return nil, fmt.Errorf("getHashRing called on non-forwarding instance")
+	}
+	entry, exists := lb.vips[*vip]
+	if !exists {
+		return nil, fmt.Errorf("trying to get ring of non-existing vip: %s", vip.Address)
+	}
+
+	positions, hfunc := entry.getChRing()
+	ring := &HashRing{
+		RingOffset:   entry.ringOffset,
+		HashFunction: hfunc,
+		Positions:    positions,
+		Addresses:    make(map[uint32]string),
+	}
+	addAddress := func(num uint32) {
+		if _, added := ring.Addresses[num]; !added {
+			if address, known := lb.numToReals[num]; known {
+				ring.Addresses[num] = string(address)
+			}
+		}
+	}
+	for _, num := range ring.Positions {
+		if num >= 0 {
+			addAddress(uint32(num))
+		}
+	}
+	if !readKernel {
+		return ring, nil
+	}
+
+	if !lb.writesBpfMaps() {
+		return nil, fmt.Errorf("ch_rings is not loaded")
+	}
+	// ch_rings is an array, so positions of the ring are the entries which follow
+	// the one before its offset
+	var prevKey *uint32
+	if ring.RingOffset > 0 {
+		prev := ring.RingOffset - 1
+		prevKey = &prev
+	}
+	size := len(ring.Positions)
+	_, nums, err := lb.chRings().BatchLookupAfter(prevKey, size)
+	if errors.Is(err, ebpf.ErrNotSupported) {
+		nums, err = lb.lookupRingPositions(ring.RingOffset, size)
+	}
+	if err == nil && len(nums) != size {
+		err = fmt.Errorf("%d of %d positions are read", len(nums), size)
+	}
+	if err != nil {
+		lb.lbStats.BpfFailedCalls++
+		return nil, fmt.Errorf("can't read ring of vip %s: %w", vip.Address, err)
+	}
+	ring.KernelPositions = nums
+	for pos, num := range ring.KernelPositions {
+		if ring.Positions[pos] >= 0 && uint32(ring.Positions[pos]) != num {
+			ring.Mismatched = append(ring.Mismatched, uint32(pos))
+			addAddress(num)
+		}
+	}
+	return ring, nil
+}
+
+// lookupRingPositions reads size positions of ch_rings starting at offset one by one,
+// for kernels without batch api
+func (lb *FlomeshLb) lookupRingPositions(offset uint32, size int) ([]uint32, error) {
+	chRings := lb.chRings()
+	nums := make([]uint32, size)
+	for pos := range nums {
+		num, err := chRings.Lookup(offset + uint32(pos))
+		if err != nil {
+			return nil, fmt.Errorf("can't read position %d: %w", pos, err)
+		}
+		nums[pos] = num
+	}
+	return nums, nil
+}
@@ -0,0 +1,180 @@
+package slb
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/cybwan/l4slb/pkg/bpf/adapter"
+)
+
+func TestGetHashRing(t *testing.T) {
+	lb := newFakeMapsLb(t)
+	vip := testVip(1)
+	reals := testReals(2)
+	addTestVip(t, lb, vip, reals)
+
+	ring, err := lb.GetHashRing(&vip, true)
+	if err != nil {
+		t.Fatalf("can't get ring: %v", err)
+	}
+	if len(ring.Positions) != kTestChRingSize || len(ring.KernelPositions) != kTestChRingSize || len(ring.Mismatched) != 0 {
+		t.Fatalf("ring %+v, expected %d positions read back without mismatches", ring, kTestChRingSize)
+	}
+	for _, real := range reals {
+		if ring.Addresses[uint32(lb.GetIndexForReal(real.Address))] != real.Address {
+			t.Errorf("addresses of the ring %v, expected %s", ring.Addresses, real.Address)
+		}
+	}
+
+	// position which has been changed behind the controller
+	offset := lb.vips[vip].GetRingOffset()
+	other := uint32(ring.Positions[0]+1) % 2
+	if err = lb.chRings().Update(offset, other, 0); err != nil {
+		t.Fatalf("can't change ch_rings: %v", err)
+	}
+	if ring, err = lb.GetHashRing(&vip, true); err != nil || len(ring.Mismatched) != 1 || ring.Mismatched[0] != 0 {
+		t.Errorf("mismatched positions %v (%v), expected [0]", ring.Mismatched, err)
+	}
+
+	// returned ring is a copy
+	ring.Positions[1] = 100
+	if lb.vips[vip].chRing[1] == 100 {
+		t.Error("ring of the vip has been changed through returned one")
+	}
+	missing := testVip(2)
+	if _, err = lb.GetHashRing(&missing, false); err == nil {
+		t.Error("ring of non-existing vip, expected error")
+	}
+}
+
+func TestGetHashRingReadsBatches(t *testing.T) {
+	lb := newFakeMapsLb(t)
+	var counter *callCounter
+	replaceFakeMap(t, lb, adapter.ChRings, func(fake *adapter.FakeMap) adapter.Backend {
+		counter = &callCounter{FakeMap: fake}
+		return counter
+	})
+	// ring of the second vip doesn't start at the first position of ch_rings
+	first, second := testVip(1), testVip(2)
+	addTestVip(t, lb, first, testReals(1))
+	if !lb.AddVipWithRingSize(&second, 0, 521) {
+		t.Fatal("can't add vip")
+	}
+	if !lb.ModifyRealsForVip(ADD, []NewReal{{Address: "10.0.0.2", Weight: 1}}, &second) {
+		t.Fatal("can't add real")
+	}
+
+	ring, err := lb.GetHashRing(&second, true)
+	if err != nil {
+		t.Fatalf("can't get ring: %v", err)
+	}
+	if ring.RingOffset != kTestChRingSize || len(ring.KernelPositions) != 521 || len(ring.Mismatched) != 0 {
+		t.Fatalf("ring at %d of %d positions with mismatched %v, expected one at %d of 521 positions",
+			ring.RingOffset, len(ring.KernelPositions), ring.Mismatched, kTestChRingSize)
+	}
+	// positions of the first vip are not read as the second one's
+	num := uint32(lb.GetIndexForReal("10.0.0.2"))
+	for pos, real := range ring.KernelPositions {
+		if real != num {
+			t.Fatalf("position %d of the ring has real %d, expected %d", pos, real, num)
+		}
+	}
+	if counter.batchLookups != 3 || counter.lookups != 0 {
+		t.Errorf("ring of 521 positions is read by %d batch lookups and %d lookups, expected 3 batch lookups",
+			counter.batchLookups, counter.lookups)
+	}
+}
+
+// TestConcurrentRingReads changes weights of reals while rings are read and simulated,
+// it is meant to be run with -race
+func TestConcurrentRingReads(t *testing.T) {
+	lb := newTestLb(t)
+	vips := []VipKey{testVip(1), testVip(2)}
+	reals := testReals(4)
+	for _, vip := range vips {
+		addTestVip(t, lb, vip, reals)
+	}
+
+	const kIterations = 200
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < kIterations; i++ {
+			changed := append([]NewReal(nil), reals...)
+			changed[i%len(changed)].Weight = uint32(i%7 + 1)
+			changes := make([]VipRealsChange, 0, len(vips))
+			for _, vip := range vips {
+				changes = append(changes, VipRealsChange{Vip: vip, Action: ADD, Reals: changed})
+			}
+			if !lb.ModifyRealsForVips(changes) {
+				t.Error("can't change weights of reals")
+				return
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < kIterations; i++ {
+			vip := vips[i%len(vips)]
+			if _, err := lb.SimulateRealsChange(DEL, reals[:1], &vip); err != nil {
+				t.Errorf("can't simulate change: %v", err)
+				return
+			}
+			if _, err := lb.GetHashRing(&vip, false); err != nil {
+				t.Errorf("can't get ring: %v", err)
+				return
+			}
+			if _, err := lb.GetRealsForVip(&vip); err != nil {
+				t.Errorf("can't get reals: %v", err)
+				return
+			}
+		}
+	}()
+	wg.Wait()
+}
+
+func BenchmarkModifyRealsForVip(b *testing.B) {
+	config := NewFlomeshLbConfig()
+	config.testing = true
+	config.enableHc = false
+	lb := NewFlomeshLb(config)
+	vip := testVip(1)
+	reals := testReals(100)
+	if !lb.AddVip(&vip, 0) || !lb.ModifyRealsForVip(ADD, reals, &vip) {
+		b.Fatal("can't add vip")
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// weight of one of the reals changes every time
+		real := reals[i%len(reals) : i%len(reals)+1]
+		real[0].Weight = uint32(i%5 + 1)
+		lb.ModifyRealsForVip(ADD, real, &vip)
+	}
+}
+
+func BenchmarkModifyRealsForVips(b *testing.B) {
+	config := NewFlomeshLbConfig()
+	config.testing = true
+	config.enableHc = false
+	lb := NewFlomeshLb(config)
+	reals := testReals(20)
+	vips := make([]VipKey, 16)
+	for i := range vips {
+		vips[i] = testVip(i + 1)
+		if !lb.AddVip(&vips[i], 0) || !lb.ModifyRealsForVip(ADD, reals, &vips[i]) {
+			b.Fatal("can't add vip")
+		}
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		changes := make([]VipRealsChange, 0, len(vips))
+		for _, vip := range vips {
+			changed := NewReal{Address: reals[i%len(reals)].Address, Weight: uint32(i%5 + 1)}
+			changes = append(changes, VipRealsChange{Vip: vip, Action: ADD, Reals: []NewReal{changed}})
+		}
+		lb.ModifyRealsForVips(changes)
+	}
+}
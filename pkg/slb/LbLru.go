@@ -0,0 +1,208 @@
+package slb
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/cilium/ebpf"
+
+	"github.com/cybwan/l4slb/pkg/bpf/adapter"
+)
+
+// LruMapStats describes occupancy of the connection table of one cpu
+type LruMapStats struct {
+	Cpu      int32
+	NumaNode int32
+	// true if cpu is not a forwarding core and uses small fallback table
+	Fallback       bool
+	MaxEntries     uint32
+	CurrentEntries uint32
+}
+
+// cpuLruMap is a connection table which has been inserted into map-in-map at cpu's position
+type cpuLruMap struct {
+	cpu        int32
+	numaNode   int32
+	fallback   bool
+	maxEntries uint32
+	bpfMap     *ebpf.Map
+}
+
+// InitLruMaps creates connection tables of forwarding cores, sized LruSize / number of cores
+// and allocated on their numa nodes, and small fallback tables for all other cpus. if forwarding
+// cores are not specified all cpus are considered forwarding ones.
+// global LRUs are created for forwarding cores, other cpus use fallback_glru.
+// if flow debug is enabled, its maps are created alongside connection tables.
+func (lb *FlomeshLb) InitLruMaps() bool {
+	if lb.config.testing || lb.config.disableForwarding {
+		return true
+	}
+	nrCpus, err := adapter.GetPossibleCpus()
+	if err != nil {
+		log.Error().Msgf("can't get number of possible cpus, error: %v", err)
+		return false
+	}
+	if nrCpus > int(kMaxForwardingCores) {
+		log.Warn().Msgf("only first %d of %d cpus get connection tables", kMaxForwardingCores, nrCpus)
+		nrCpus = int(kMaxForwardingCores)
+	}
+
+	numaNodes := make(map[int32]int32)
+	forwardingCores := lb.forwardingCores
+	if len(forwardingCores) == 0 {
+		for cpu := int32(0); cpu < int32(nrCpus); cpu++ {
+			forwardingCores = append(forwardingCores, cpu)
+		}
+	}
+	for i, core := range forwardingCores {
+		numaNodes[core] = int32(kNoNuma)
+		if len(lb.numaNodes) > 0 {
+			numaNodes[core] = lb.numaNodes[i]
+		}
+	}
+
+	perCpuLruSize := lb.getPerCpuLruSize()
+	for cpu := int32(0); cpu < int32(nrCpus); cpu++ {
+		lru := cpuLruMap{cpu: cpu, numaNode: int32(kNoNuma), maxEntries: uint32(kFallbackLruSize), fallback: true}
+		if numaNode, forwarding := numaNodes[cpu]; forwarding {
+			lru.numaNode = numaNode
+			lru.maxEntries = perCpuLruSize
+			lru.fallback = false
+		}
+		if !lb.addCpuLruMap(adapter.LruMapping, &lru) {
+			return false
+		}
+		lb.lruMaps = append(lb.lruMaps, lru)
+
+		if lru.fallback || !lb.bpfMaps.Has(adapter.GlobalLruMaps) {
+			continue
+		}
+		glru := cpuLruMap{cpu: cpu, numaNode: lru.numaNode, maxEntries: lb.config.globalLruSize}
+		if !lb.addCpuLruMap(adapter.GlobalLruMaps, &glru) {
+			return false
+		}
+		lb.globalLruMaps = append(lb.globalLruMaps, glru)
+	}
+	log.Info().Msgf("created connection tables for %d cpus, %d entries per forwarding core",
+		nrCpus, perCpuLruSize)
+	return lb.initFlowDebugMaps()
+}
+
+func (lb *FlomeshLb) addCpuLruMap(outer adapter.BpfMapName, lru *cpuLruMap) bool {
+	if lb.balancerProg == nil {
+		log.Error().Msgf("can't create %s map for cpu %d, balancer is not loaded", outer, lru.cpu)
+		return false
+	}
+	bpfMap, err := lb.balancerProg.NewInnerMap(outer, lru.maxEntries, int(lru.numaNode))
+	if err != nil {
+		lb.lbStats.BpfFailedCalls++
+		log.Error().Msgf("can't create %s map for cpu %d, error: %v", outer, lru.cpu, err)
+		return false
+	}
+	key := uint32(lru.cpu)
+	if err = lb.bpfMaps.Map(outer).Update(&key, bpfMap, ebpf.UpdateAny); err != nil {
+		lb.lbStats.BpfFailedCalls++
+		log.Error().Msgf("can't add map of cpu %d into %s, error: %v", lru.cpu, outer, err)
+		bpfMap.Close()
+		return false
+	}
+	lru.bpfMap = bpfMap
+	return true
+}
+
+// GetLruMapsStats returns occupancy of connection tables of all cpus
+func (lb *FlomeshLb) GetLruMapsStats() []LruMapStats {
+	return lb.getCpuLruMapsStats(lb.lruMaps)
+}
+
+// GetGlobalLruMapsStats returns occupancy of global connection tables of forwarding cores
+func (lb *FlomeshLb) GetGlobalLruMapsStats() []LruMapStats {
+	return lb.getCpuLruMapsStats(lb.globalLruMaps)
+}
+
+func (lb *FlomeshLb) getCpuLruMapsStats(lruMaps []cpuLruMap) []LruMapStats {
+	stats := make([]LruMapStats, 0, len(lruMaps))
+	for _, lru := range lruMaps {
+		entries, err := countMapEntries(lru.bpfMap, lru.maxEntries)
+		if err != nil {
+			lb.lbStats.BpfFailedCalls++
+			log.Error().Msgf("can't count entries of connection table of cpu %d, error: %v", lru.cpu, err)
+		}
+		stats = append(stats, LruMapStats{
+			Cpu:            lru.cpu,
+			NumaNode:       lru.numaNode,
+			Fallback:       lru.fallback,
+			MaxEntries:     lru.maxEntries,
+			CurrentEntries: entries,
+		})
+	}
+	return stats
+}
+
+// kCountEntriesBatchSize is number of entries read by one batch lookup while counting entries
+const kCountEntriesBatchSize = 256
+
+// countableMap is a map whose entries could be counted, e.g. *ebpf.Map or adapter.Backend
+type countableMap interface {
+	Type() ebpf.MapType
+	KeySize() uint32
+	ValueSize() uint32
+	NextKey(key, nextKeyOut interface{}) error
+	BatchLookup(prevKey, nextKeyOut, keysOut, valuesOut interface{}, opts *ebpf.BatchOptions) (int, error)
+}
+
+// countMapEntries counts entries of hash and lru maps by batch lookups, which take one
+// syscall per kCountEntriesBatchSize entries. other maps, or kernels without batch api,
+// are walked key by key
+func countMapEntries(bpfMap countableMap, maxEntries uint32) (uint32, error) {
+	if bpfMap.Type() != ebpf.Hash && bpfMap.Type() != ebpf.LRUHash {
+		return walkMapKeys(bpfMap, maxEntries)
+	}
+	keyType := reflect.ArrayOf(int(bpfMap.KeySize()), reflect.TypeOf(byte(0)))
+	valueType := reflect.ArrayOf(int(bpfMap.ValueSize()), reflect.TypeOf(byte(0)))
+	keys := reflect.MakeSlice(reflect.SliceOf(keyType), kCountEntriesBatchSize, kCountEntriesBatchSize).Interface()
+	values := reflect.MakeSlice(reflect.SliceOf(valueType), kCountEntriesBatchSize, kCountEntriesBatchSize).Interface()
+
+	var entries uint32
+	var prevKey interface{}
+	for {
+		var nextKey []byte
+		count, err := bpfMap.BatchLookup(prevKey, &nextKey, keys, values, nil)
+		entries += uint32(count)
+		if errors.Is(err, ebpf.ErrKeyNotExist) {
+			return entries, nil
+		}
+		if errors.Is(err, ebpf.ErrNotSupported) && prevKey == nil {
+			return walkMapKeys(bpfMap, maxEntries)
+		}
+		if err != nil {
+			return entries, err
+		}
+		prevKey = nextKey
+	}
+}
+
+// walkMapKeys counts entries by walking keys of the map; values are not read, so it works for
+// per cpu maps too. walk restarts if current key is evicted meanwhile, so it is bounded by maxEntries
+func walkMapKeys(bpfMap countableMap, maxEntries uint32) (uint32, error) {
+	var entries uint32
+	var key []byte
+	for entries < maxEntries {
+		var nextKey []byte
+		var err error
+		if key == nil {
+			err = bpfMap.NextKey(nil, &nextKey)
+		} else {
+			err = bpfMap.NextKey(key, &nextKey)
+		}
+		if errors.Is(err, ebpf.ErrKeyNotExist) {
+			return entries, nil
+		}
+		if err != nil {
+			return entries, err
+		}
+		entries++
+		key = nextKey
+	}
+	return entries, nil
+}
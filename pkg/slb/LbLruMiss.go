@@ -0,0 +1,126 @@
+package slb
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/cilium/ebpf"
+	"go.eth-p.dev/goptional"
+
+	"github.com/cybwan/l4slb/pkg/bpf"
+)
+
+// RealLruMiss is number of lru misses of the tracked vip, after which packets
+// have been sent to the real
+type RealLruMiss struct {
+	Real   string
+	Misses uint64
+}
+
+// SetLruMissStatsVip selects the vip whose lru misses are counted per real.
+// counters of the previously tracked vip are reset
+func (lb *FlomeshLb) SetLruMissStatsVip(vip *VipKey) bool {
+	if lb.config.disableForwarding {
+		log.Error().Msg("setLruMissStatsVip called on non-forwarding instance")
+		return false
+	}
+	if _, exists := lb.vips[*vip]; !exists {
+		log.Error().Msgf("can't track lru misses of non-existing vip %s:%d:%d",
+			vip.Address, vip.Port, vip.Proto)
+		return false
+	}
+	log.Info().Msgf("tracking lru misses of vip: %s:%d:%d", vip.Address, vip.Port, vip.Proto)
+	if lb.writesBpfMaps() {
+		if !lb.resetLruMissStats() {
+			return false
+		}
+		if !lb.updateLruMissStatsVip(lb.vipKeyToVipDefinition(vip)) {
+			return false
+		}
+	}
+	lb.lruMissStatsVip = goptional.Some(*vip)
+	return true
+}
+
+// ClearLruMissStatsVip stops tracking of lru misses and resets counters
+func (lb *FlomeshLb) ClearLruMissStatsVip() bool {
+	if lb.lruMissStatsVip.IsNone() {
+		return true
+	}
+	if lb.writesBpfMaps() {
+		// zero vip_definition never matches, as vip's protocol is always set
+		if !lb.updateLruMissStatsVip(new(bpf.VipDefinition)) {
+			return false
+		}
+		if !lb.resetLruMissStats() {
+			return false
+		}
+	}
+	lb.lruMissStatsVip = goptional.None[VipKey]()
+	return true
+}
+
+// GetLruMissStatsVip returns the vip whose lru misses are tracked, if any
+func (lb *FlomeshLb) GetLruMissStatsVip() (VipKey, bool) {
+	return lb.lruMissStatsVip.Get()
+}
+
+// GetLruMissStatsForVip returns lru misses of the tracked vip per real, most missed first.
+// all reals of the vip are reported, as well as other reals which have been missed,
+// e.g. they have been removed from the vip since tracking has been started
+func (lb *FlomeshLb) GetLruMissStatsForVip() ([]RealLruMiss, error) {
+	vip, tracked := lb.lruMissStatsVip.Get()
+	if !tracked {
+		return nil, fmt.Errorf("lru misses are not tracked for any vip")
+	}
+	vipReals := make(map[uint32]bool)
+	if entry, exists := lb.vips[vip]; exists {
+		for _, rnum := range entry.getReals() {
+			vipReals[rnum] = true
+		}
+	}
+	var stats []RealLruMiss
+	for rnum, real := range lb.numToReals {
+		misses := uint64(0)
+		if lb.writesBpfMaps() {
+			values, err := lb.lruMissStats().Lookup(rnum)
+			if err != nil {
+				lb.lbStats.BpfFailedCalls++
+				return nil, fmt.Errorf("can't read lru misses of real %s: %w", real, err)
+			}
+			for _, value := range values {
+				misses += uint64(value)
+			}
+		}
+		if misses > 0 || vipReals[rnum] {
+			stats = append(stats, RealLruMiss{Real: string(real), Misses: misses})
+		}
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Misses != stats[j].Misses {
+			return stats[i].Misses > stats[j].Misses
+		}
+		return stats[i].Real < stats[j].Real
+	})
+	return stats, nil
+}
+
+func (lb *FlomeshLb) updateLruMissStatsVip(vipDef *bpf.VipDefinition) bool {
+	key := uint32(0)
+	if err := lb.lruMissStatsVipMap().Update(key, *vipDef, ebpf.UpdateAny); err != nil {
+		lb.lbStats.BpfFailedCalls++
+		log.Error().Msgf("can't update lru_miss_stats_vip, error: %v", err)
+		return false
+	}
+	return true
+}
+
+// resetLruMissStats zeroes per real counters on all cpus
+func (lb *FlomeshLb) resetLruMissStats() bool {
+	if err := lb.lruMissStats().ResetAll(lb.config.maxReals); err != nil {
+		lb.lbStats.BpfFailedCalls++
+		log.Error().Msgf("can't reset lru_miss_stats, error: %v", err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,139 @@
+package slb
+
+import (
+	"os"
+	"testing"
+
+	"github.com/cilium/ebpf"
+
+	"github.com/cybwan/l4slb/pkg/bpf/adapter"
+)
+
+// callCounter counts calls which would be syscalls for maps in the kernel
+type callCounter struct {
+	*adapter.FakeMap
+	batchLookups int
+	nextKeys     int
+	lookups      int
+}
+
+func (c *callCounter) Lookup(key, valueOut interface{}) error {
+	c.lookups++
+	return c.FakeMap.Lookup(key, valueOut)
+}
+
+func (c *callCounter) NextKey(key, nextKeyOut interface{}) error {
+	c.nextKeys++
+	return c.FakeMap.NextKey(key, nextKeyOut)
+}
+
+func (c *callCounter) BatchLookup(prevKey, nextKeyOut, keysOut, valuesOut interface{},
+	opts *ebpf.BatchOptions) (int, error) {
+	c.batchLookups++
+	return c.FakeMap.BatchLookup(prevKey, nextKeyOut, keysOut, valuesOut, opts)
+}
+
+func newFilledFakeMap(t *testing.T, mapType ebpf.MapType, maxEntries, entries uint32) *callCounter {
+	t.Helper()
+	fake, err := adapter.NewFakeMap(&ebpf.MapSpec{
+		Name: "test", Type: mapType, KeySize: 4, ValueSize: 8, MaxEntries: maxEntries,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nrCpus, err := adapter.GetPossibleCpus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key := uint32(0); key < entries; key++ {
+		var value interface{} = uint64(key)
+		if mapType == ebpf.PerCPUHash {
+			value = make([]uint64, nrCpus)
+		}
+		if err = fake.Update(key, value, ebpf.UpdateAny); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return &callCounter{FakeMap: fake}
+}
+
+func TestCountMapEntriesInBatches(t *testing.T) {
+	for _, entries := range []uint32{0, 1, kCountEntriesBatchSize, 1000} {
+		counter := newFilledFakeMap(t, ebpf.LRUHash, 1024, entries)
+		count, err := countMapEntries(counter, counter.MaxEntries())
+		if err != nil {
+			t.Fatalf("can't count %d entries: %v", entries, err)
+		}
+		if count != entries {
+			t.Errorf("counted %d entries, expected %d", count, entries)
+		}
+		expected := int((entries + kCountEntriesBatchSize - 1) / kCountEntriesBatchSize)
+		if expected == 0 {
+			expected = 1
+		}
+		if counter.batchLookups != expected || counter.nextKeys != 0 {
+			t.Errorf("%d entries have been counted by %d batch lookups and %d key lookups, expected %d batch lookups",
+				entries, counter.batchLookups, counter.nextKeys, expected)
+		}
+	}
+}
+
+func TestCountMapEntriesWithoutBatches(t *testing.T) {
+	// per cpu maps can't be read by batches, keys are walked instead
+	counter := newFilledFakeMap(t, ebpf.PerCPUHash, 64, 10)
+	count, err := countMapEntries(counter, counter.MaxEntries())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 10 || counter.nextKeys != 11 {
+		t.Errorf("counted %d entries by %d key lookups, expected 10 entries by 11 lookups", count, counter.nextKeys)
+	}
+
+	// walk is bounded by max entries
+	counter = newFilledFakeMap(t, ebpf.PerCPUHash, 64, 10)
+	if count, _ = walkMapKeys(counter, 5); count != 5 {
+		t.Errorf("counted %d entries, expected walk to stop at 5", count)
+	}
+}
+
+func TestCountKernelMapEntries(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("creating bpf maps requires root")
+	}
+	for _, mapType := range []ebpf.MapType{ebpf.Hash, ebpf.LRUHash} {
+		bpfMap, err := ebpf.NewMap(&ebpf.MapSpec{
+			Type: mapType, KeySize: 4, ValueSize: 8, MaxEntries: 2048,
+		})
+		if err != nil {
+			t.Skipf("can't create %s map: %v", mapType, err)
+		}
+		defer bpfMap.Close()
+		const entries = 1000
+		for key := uint32(0); key < entries; key++ {
+			if err = bpfMap.Put(key, uint64(key)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		count, err := countMapEntries(bpfMap, bpfMap.MaxEntries())
+		if err != nil {
+			t.Fatalf("can't count entries of %s map: %v", mapType, err)
+		}
+		if count != entries {
+			t.Errorf("counted %d entries of %s map, expected %d", count, mapType, entries)
+		}
+	}
+}
+
+func TestFlowDebugNeedsItsMap(t *testing.T) {
+	lb := newFakeMapsLb(t)
+	if lb.bpfMaps.Has(adapter.FlowDebugMaps) {
+		t.Skip("balancer has been built with flow debug")
+	}
+	if !lb.initFlowDebugMaps() {
+		t.Error("flow debug which is not enabled has failed")
+	}
+	lb.config.flowDebug = true
+	if lb.initFlowDebugMaps() || lb.features.flowDebug {
+		t.Error("flow debug is enabled without flow_debug_maps")
+	}
+}
@@ -0,0 +1,66 @@
+package slb
+
+import (
+	"fmt"
+
+	"github.com/cilium/ebpf"
+
+	"github.com/cybwan/l4slb/pkg/bpf/adapter"
+)
+
+// GetBpfMapStats returns occupancy of the map of the balancer or healthchecking program. entries of hash and lru maps are counted;
+// for reals and ch_rings they are taken from the balancer's state, as these arrays
+// are filled up by it. other arrays are always full
+func (lb *FlomeshLb) GetBpfMapStats(name adapter.BpfMapName) (FlomeshLbBpfMapStats, error) {
+	bpfMap := lb.bpfMaps.Map(name).Backend()
+	if bpfMap == nil {
+		bpfMap = lb.hcMaps.Map(name).Backend()
+	}
+	if bpfMap == nil {
+		return FlomeshLbBpfMapStats{}, fmt.Errorf("map %s is not loaded", name)
+	}
+	stats := FlomeshLbBpfMapStats{MaxEntries: bpfMap.MaxEntries()}
+	switch {
+	case name == adapter.Reals:
+		stats.CurrentEntries = uint32(len(lb.numToReals))
+	case name == adapter.ChRings:
+		for _, vip := range lb.vips {
+			stats.CurrentEntries += vip.GetChRingSize()
+		}
+	case isArrayMap(bpfMap.Type()):
+		stats.CurrentEntries = stats.MaxEntries
+	default:
+		entries, err := countMapEntries(bpfMap, bpfMap.MaxEntries())
+		if err != nil {
+			lb.lbStats.BpfFailedCalls++
+			return stats, fmt.Errorf("can't count entries of map %s: %w", name, err)
+		}
+		stats.CurrentEntries = entries
+	}
+	return stats, nil
+}
+
+// GetAllBpfMapStats returns occupancy of all loaded maps. maps whose entries
+// can't be counted are skipped
+func (lb *FlomeshLb) GetAllBpfMapStats() map[adapter.BpfMapName]FlomeshLbBpfMapStats {
+	allStats := make(map[adapter.BpfMapName]FlomeshLbBpfMapStats)
+	names := append(lb.bpfMaps.Names(), lb.hcMaps.Names()...)
+	for _, name := range names {
+		stats, err := lb.GetBpfMapStats(name)
+		if err != nil {
+			log.Error().Msgf("can't get stats of map %s, error: %v", name, err)
+			continue
+		}
+		allStats[name] = stats
+	}
+	return allStats
+}
+
+func isArrayMap(mapType ebpf.MapType) bool {
+	switch mapType {
+	case ebpf.Array, ebpf.PerCPUArray, ebpf.ProgramArray, ebpf.PerfEventArray,
+		ebpf.ArrayOfMaps, ebpf.CGroupArray, ebpf.DevMap, ebpf.CPUMap, ebpf.XSKMap:
+		return true
+	}
+	return false
+}
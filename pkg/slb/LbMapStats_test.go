@@ -0,0 +1,43 @@
+package slb
+
+import (
+	"testing"
+
+	"github.com/cybwan/l4slb/pkg/bpf/adapter"
+)
+
+func TestGetBpfMapStats(t *testing.T) {
+	lb := newFakeMapsLb(t)
+	addTestVip(t, lb, testVip(1), testReals(3))
+	addTestVip(t, lb, testVip(2), testReals(5))
+
+	tests := []struct {
+		name    adapter.BpfMapName
+		max     uint32
+		current uint32
+	}{
+		// counted hash map
+		{adapter.VipMap, kTestMaxVips, 2},
+		// filled up by the balancer
+		{adapter.Reals, kTestMaxReals, 5},
+		{adapter.ChRings, kTestMaxVips * kTestChRingSize, 2 * kTestChRingSize},
+		// other arrays are always full
+		{adapter.Stats, kTestMaxVips * 2, kTestMaxVips * 2},
+	}
+	for _, test := range tests {
+		stats, err := lb.GetBpfMapStats(test.name)
+		if err != nil {
+			t.Fatalf("can't get stats of %s: %v", test.name, err)
+		}
+		if stats.MaxEntries != test.max || stats.CurrentEntries != test.current {
+			t.Errorf("%s has %d entries of %d, expected %d of %d", test.name,
+				stats.CurrentEntries, stats.MaxEntries, test.current, test.max)
+		}
+	}
+	if _, err := lb.GetBpfMapStats("NoSuchMap"); err == nil {
+		t.Error("stats of unknown map, expected error")
+	}
+	if all := lb.GetAllBpfMapStats(); all[adapter.VipMap].CurrentEntries != 2 || len(all) != len(lb.bpfMaps.Names()) {
+		t.Errorf("stats of all maps %v", all)
+	}
+}
@@ -0,0 +1,93 @@
+package slb
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/cybwan/l4slb/pkg/bpf"
+	"github.com/cybwan/l4slb/pkg/bpf/adapter"
+	"github.com/cybwan/l4slb/pkg/bpf/progs/balancer"
+	"github.com/cybwan/l4slb/pkg/bpf/progs/healthchecking/kern"
+)
+
+// BeAddr is written into reals, so it must be laid out as struct real_definition
+const (
+	_ = uintptr(unsafe.Sizeof(BeAddr{}) - unsafe.Sizeof(bpf.RealDefinition{}))
+	_ = uintptr(unsafe.Sizeof(bpf.RealDefinition{}) - unsafe.Sizeof(BeAddr{}))
+)
+
+// UseFakeMaps registers in-memory fakes of maps of the balancer and, if healthchecking is enabled,
+// of the healthchecking program. it is only allowed in testing mode: map updates, which are
+// otherwise skipped in it, are done into fakes, so their contents could be checked
+func (lb *FlomeshLb) UseFakeMaps() error {
+	if !lb.config.testing {
+		return fmt.Errorf("fake maps could only be used in testing mode")
+	}
+	if err := balancer.LoadFake(lb.GetBalancerConfig(), lb.bpfMaps); err != nil {
+		return err
+	}
+	if lb.config.enableHc {
+		if err := kern.LoadFake(lb.hcMaps); err != nil {
+			return err
+		}
+	}
+	lb.fakeMaps = true
+	return nil
+}
+
+// writesBpfMaps returns true if maps are updated, i.e. the programs have been loaded or
+// fake maps are used
+func (lb *FlomeshLb) writesBpfMaps() bool {
+	return !lb.config.testing || lb.fakeMaps
+}
+
+// typed views of maps of the balancer, keys and values are as in balancer_maps.h.
+// maps-in-maps (lru_mapping, global_lru_maps, flow_debug_maps) and event_pipe
+// are used through untyped handles
+
+func (lb *FlomeshLb) ctlArray() adapter.Map[uint32, bpf.CtlValue] {
+	return adapter.NewMap[uint32, bpf.CtlValue](lb.bpfMaps.Map(adapter.CtlArray))
+}
+
+func (lb *FlomeshLb) vipMap() adapter.Map[bpf.VipDefinition, bpf.VipMeta] {
+	return adapter.NewMap[bpf.VipDefinition, bpf.VipMeta](lb.bpfMaps.Map(adapter.VipMap))
+}
+
+func (lb *FlomeshLb) realsMap() adapter.Map[uint32, BeAddr] {
+	return adapter.NewMap[uint32, BeAddr](lb.bpfMaps.Map(adapter.Reals))
+}
+
+func (lb *FlomeshLb) chRings() adapter.Map[uint32, uint32] {
+	return adapter.NewMap[uint32, uint32](lb.bpfMaps.Map(adapter.ChRings))
+}
+
+func (lb *FlomeshLb) vipRings() adapter.Map[uint32, bpf.VipRing] {
+	return adapter.NewMap[uint32, bpf.VipRing](lb.bpfMaps.Map(adapter.VipRings))
+}
+
+// statsArray returns per cpu array of counters: stats, reals_stats or nexthop_stats
+func (lb *FlomeshLb) statsArray(name adapter.BpfMapName) adapter.PerCPUArray[bpf.LbStats] {
+	return adapter.NewPerCPUArray[bpf.LbStats](lb.bpfMaps.Map(name))
+}
+
+func (lb *FlomeshLb) lruMissStats() adapter.PerCPUArray[uint32] {
+	return adapter.NewPerCPUArray[uint32](lb.bpfMaps.Map(adapter.LruMissStats))
+}
+
+func (lb *FlomeshLb) lruMissStatsVipMap() adapter.Map[uint32, bpf.VipDefinition] {
+	return adapter.NewMap[uint32, bpf.VipDefinition](lb.bpfMaps.Map(adapter.LruMissStatsVip))
+}
+
+func (lb *FlomeshLb) nexthopMacs() adapter.Map[uint32, bpf.CtlValue] {
+	return adapter.NewMap[uint32, bpf.CtlValue](lb.bpfMaps.Map(adapter.NexthopMacs))
+}
+
+// maps of the healthchecking program
+
+func (lb *FlomeshLb) hcKeyMap() adapter.Map[bpf.VipDefinition, uint32] {
+	return adapter.NewMap[bpf.VipDefinition, uint32](lb.hcMaps.Map(adapter.HcKeyMap))
+}
+
+func (lb *FlomeshLb) hcPcktMacs() adapter.Map[uint32, bpf.HcMac] {
+	return adapter.NewMap[uint32, bpf.HcMac](lb.hcMaps.Map(adapter.HcPcktMacs))
+}
@@ -0,0 +1,99 @@
+package slb
+
+import (
+	"net"
+	"testing"
+)
+
+// checkRing verifies that every position of vip's ring in ch_rings holds one of expected reals
+func checkRing(t *testing.T, lb *FlomeshLb, vip *Vip, expected []string) map[uint32]int {
+	t.Helper()
+	nums := make(map[uint32]bool)
+	for _, addr := range expected {
+		num := lb.GetIndexForReal(addr)
+		if num < 0 {
+			t.Fatalf("real %s has no index", addr)
+		}
+		nums[uint32(num)] = true
+	}
+	share := make(map[uint32]int)
+	for pos := vip.GetRingOffset(); pos < vip.GetRingOffset()+vip.GetChRingSize(); pos++ {
+		realNum, err := lb.chRings().Lookup(pos)
+		if err != nil {
+			t.Fatalf("can't lookup position %d of ch_rings: %v", pos, err)
+		}
+		if !nums[realNum] {
+			t.Errorf("position %d of ch_rings points to real %d, expected one of %v", pos, realNum, nums)
+		}
+		share[realNum]++
+	}
+	return share
+}
+
+func TestVipProgramming(t *testing.T) {
+	lb := newFakeMapsLb(t)
+	key := testVip(1)
+	reals := testReals(3)
+	// maglev places weight positions of each real per pass, so with small weights
+	// every real gets a share of the small test ring
+	for i := range reals {
+		reals[i].Weight = 1
+	}
+	addTestVip(t, lb, key, reals)
+	vip := lb.vips[key]
+
+	meta, err := lb.vipMap().Lookup(*lb.vipKeyToVipDefinition(&key))
+	if err != nil {
+		t.Fatalf("vip %v is not in vip_map: %v", key, err)
+	}
+	if meta.VipNum != vip.GetNum() {
+		t.Errorf("vip_map has num %d for vip %v, expected %d", meta.VipNum, key, vip.GetNum())
+	}
+	ring, err := lb.vipRings().Lookup(vip.GetNum())
+	if err != nil || ring.Offset != vip.GetRingOffset() || ring.Size != vip.GetChRingSize() {
+		t.Errorf("vip_rings has %+v (%v), expected offset %d and size %d",
+			ring, err, vip.GetRingOffset(), vip.GetChRingSize())
+	}
+
+	for _, real := range reals {
+		var expected BeAddr
+		expected.SetAddr(net.ParseIP(real.Address))
+		addr, err := lb.realsMap().Lookup(uint32(lb.GetIndexForReal(real.Address)))
+		if err != nil || addr != expected {
+			t.Errorf("reals has %+v (%v) for %s, expected %+v", addr, err, real.Address, expected)
+		}
+	}
+	share := checkRing(t, lb, vip, []string{reals[0].Address, reals[1].Address, reals[2].Address})
+	if len(share) != len(reals) {
+		t.Errorf("ring of the vip has reals %v, expected all %d of them", share, len(reals))
+	}
+
+	// deleted real is gone from the ring
+	if !lb.ModifyRealsForVip(DEL, reals[:1], &key) {
+		t.Fatalf("can't delete real %s", reals[0].Address)
+	}
+	checkRing(t, lb, vip, []string{reals[1].Address, reals[2].Address})
+
+	if !lb.DelVip(&key) {
+		t.Fatalf("can't delete vip %v", key)
+	}
+	if _, err := lb.vipMap().Lookup(*lb.vipKeyToVipDefinition(&key)); err == nil {
+		t.Errorf("vip %v is still in vip_map after deletion", key)
+	}
+}
+
+func TestVipRingsDoNotOverlap(t *testing.T) {
+	lb := newFakeMapsLb(t)
+	first, second := testVip(1), testVip(2)
+	addTestVip(t, lb, first, testReals(2))
+	addTestVip(t, lb, second, []NewReal{{Address: "10.0.1.1", Weight: 10}})
+
+	checkRing(t, lb, lb.vips[first], []string{"10.0.0.1", "10.0.0.2"})
+	checkRing(t, lb, lb.vips[second], []string{"10.0.1.1"})
+
+	// changes of one vip don't touch ring of the other
+	if !lb.ModifyRealsForVip(ADD, []NewReal{{Address: "10.0.1.2", Weight: 10}}, &second) {
+		t.Fatal("can't add real to the second vip")
+	}
+	checkRing(t, lb, lb.vips[first], []string{"10.0.0.1", "10.0.0.2"})
+}
@@ -0,0 +1,157 @@
+package slb
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/cilium/ebpf"
+
+	"github.com/cybwan/l4slb/pkg/bpf/adapter"
+	"github.com/cybwan/l4slb/pkg/bpf/monitor"
+)
+
+// kMonitorSubscriberQueueSize is number of packets pending for each subscriber
+const kMonitorSubscriberQueueSize = 1024
+
+// StartMonitor starts reading of introspection events if introspection is enabled
+// in config, and opens the gate in the balancer, so it begins to submit events
+func (lb *FlomeshLb) StartMonitor() bool {
+	if !lb.config.introspection || lb.config.testing || lb.config.disableForwarding {
+		return true
+	}
+	if lb.introspectionStarted_ {
+		return true
+	}
+	if !lb.bpfMaps.Has(adapter.EventPipe) {
+		// no event would ever be read, so the option is refused instead of being ignored
+		log.Error().Msg("introspection is enabled, but balancer has been built without event_pipe. " +
+			"build it with BPF_FEATURES=FLOMESHLB_INTROSPECTION")
+		return false
+	}
+	config, err := lb.getMonitorConfig()
+	if err != nil {
+		log.Error().Msgf("invalid monitor config, error: %v", err)
+		return false
+	}
+	mon, err := monitor.New(*config)
+	if err != nil {
+		log.Error().Msgf("can't create monitor, error: %v", err)
+		return false
+	}
+	mon.Start()
+	if !lb.setIntrospectionGk(1) {
+		mon.Stop()
+		return false
+	}
+	lb.monitor = mon
+	lb.introspectionStarted_ = true
+	lb.features.introspection = true
+	return true
+}
+
+// StopMonitor closes the gate in the balancer and stops reading of events
+func (lb *FlomeshLb) StopMonitor() bool {
+	if !lb.introspectionStarted_ {
+		return true
+	}
+	success := lb.setIntrospectionGk(0)
+	lb.monitor.Stop()
+	lb.monitor = nil
+	lb.introspectionStarted_ = false
+	lb.features.introspection = false
+	return success
+}
+
+// UseMonitor makes the balancer subscribe capture clients to mon, which is started
+// by the caller, instead of the monitor of its own event pipe, e.g. to mon which reads
+// samples made up by tests. the gate in the balancer is not opened
+func (lb *FlomeshLb) UseMonitor(mon *monitor.Monitor) error {
+	if lb.monitor != nil {
+		return fmt.Errorf("monitor is already started")
+	}
+	lb.monitor = mon
+	return nil
+}
+
+// GetMonitorStats returns stats of the monitor; they are zero if it is not started
+func (lb *FlomeshLb) GetMonitorStats() FlomeshLbMonitorStats {
+	if lb.monitor == nil {
+		return FlomeshLbMonitorStats{}
+	}
+	stats := lb.monitor.GetStats()
+	return FlomeshLbMonitorStats{
+		Limit:      stats.Limit,
+		Amount:     stats.Amount,
+		BufferFull: stats.BufferFull,
+	}
+}
+
+// SubscribeMonitor subscribes to packets of events (all if empty), sent to vip (any if nil).
+// packets which are kept in memory for IOBUF storage are returned as well
+func (lb *FlomeshLb) SubscribeMonitor(events []monitor.EventId, vip *VipKey) (*monitor.Subscription, []*monitor.Packet, error) {
+	if lb.monitor == nil {
+		return nil, nil, fmt.Errorf("introspection is not enabled")
+	}
+	var filter *monitor.Filter
+	if vip != nil {
+		dst := net.ParseIP(vip.Address)
+		if dst == nil {
+			lb.lbStats.AddrValidationFailed++
+			return nil, nil, fmt.Errorf("invalid vip address: %s", vip.Address)
+		}
+		filter = &monitor.Filter{Dst: dst, Port: vip.Port, Proto: vip.Proto}
+	}
+	return lb.monitor.Subscribe(events, filter, kMonitorSubscriberQueueSize)
+}
+
+// UnsubscribeMonitor cancels subscription and returns number of packets dropped for it
+func (lb *FlomeshLb) UnsubscribeMonitor(sub *monitor.Subscription) uint32 {
+	if lb.monitor == nil {
+		return 0
+	}
+	return lb.monitor.Unsubscribe(sub)
+}
+
+func (lb *FlomeshLb) getMonitorConfig() (*monitor.Config, error) {
+	mc := &lb.config.monitorConfig
+	config := &monitor.Config{
+		EventPipe:  lb.bpfMaps.Map(adapter.EventPipe).Map(),
+		Pages:      mc.pages,
+		QueueSize:  mc.queueSize,
+		PcktLimit:  mc.pcktLimit,
+		SnapLen:    mc.snapLen,
+		BufferSize: mc.bufferSize,
+		Path:       mc.path,
+	}
+	switch mc.storage {
+	case FILE:
+		config.Storage = monitor.File
+	case IOBUF:
+		config.Storage = monitor.Buffer
+	case PIPE:
+		config.Storage = monitor.Pipe
+	default:
+		return nil, fmt.Errorf("unsupported storage format: %d", mc.storage)
+	}
+	for _, name := range mc.events {
+		event, err := monitor.ParseEventId(name)
+		if err != nil {
+			return nil, err
+		}
+		config.Events = append(config.Events, event)
+	}
+	return config, nil
+}
+
+func (lb *FlomeshLb) setIntrospectionGk(value uint64) bool {
+	lb.ctlLock.Lock()
+	defer lb.ctlLock.Unlock()
+	key := kIntrospectionGkPos
+	lb.ctlValues[kIntrospectionGkPos].SetValue(value)
+	if err := lb.ctlArray().Update(key, lb.ctlValues[kIntrospectionGkPos], ebpf.UpdateAny); err != nil {
+		lb.lbStats.BpfFailedCalls++
+		log.Error().Msgf("can't update introspection gatekeeper, error: %v", err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,22 @@
+package slb
+
+import (
+	"testing"
+
+	"github.com/cybwan/l4slb/pkg/bpf/adapter"
+)
+
+func TestMonitorNeedsEventPipe(t *testing.T) {
+	lb := newFakeMapsLb(t)
+	if lb.bpfMaps.Has(adapter.EventPipe) {
+		t.Skip("balancer has been built with introspection")
+	}
+	lb.config.testing = false
+	if !lb.StartMonitor() {
+		t.Error("monitor which is not enabled has failed")
+	}
+	lb.config.introspection = true
+	if lb.StartMonitor() || lb.monitor != nil || lb.features.introspection {
+		t.Error("monitor is started without event_pipe")
+	}
+}
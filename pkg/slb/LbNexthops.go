@@ -0,0 +1,213 @@
+package slb
+
+import (
+	"bytes"
+	"net"
+
+	"github.com/cilium/ebpf"
+
+	"github.com/cybwan/l4slb/pkg/bpf"
+	"github.com/cybwan/l4slb/pkg/bpf/adapter"
+)
+
+// NexthopsSupported returns true if loaded balancer is able to spread traffic
+// between multiple next hops
+func (lb *FlomeshLb) NexthopsSupported() bool {
+	return lb.config.testing || lb.bpfMaps.Has(adapter.NexthopMacs)
+}
+
+// AddNexthop adds mac of the next hop (e.g. one of ToRs behind ECMP default route).
+// it is kept along with next hops set with SetNexthops until it is deleted
+func (lb *FlomeshLb) AddNexthop(mac []uint8) bool {
+	if len(mac) != kMacBytes {
+		log.Error().Msgf("invalid next hop's mac: %v", net.HardwareAddr(mac))
+		return false
+	}
+	lb.ctlLock.Lock()
+	defer lb.ctlLock.Unlock()
+	if macPos(lb.configuredNexthops, mac) >= 0 {
+		log.Info().Msgf("next hop %v already exists", net.HardwareAddr(mac))
+		return true
+	}
+	configured := make([][]uint8, len(lb.configuredNexthops), len(lb.configuredNexthops)+1)
+	copy(configured, lb.configuredNexthops)
+	configured = append(configured, copyMac(mac))
+	return lb.applyNexthops(lb.learnedNexthops, configured)
+}
+
+// DelNexthop removes mac of the next hop. its flows are rehashed to remaining next hops.
+// learned next hop stays removed until SetNexthops is called with it again
+func (lb *FlomeshLb) DelNexthop(mac []uint8) bool {
+	lb.ctlLock.Lock()
+	defer lb.ctlLock.Unlock()
+	if lb.nexthopPos(mac) < 0 {
+		log.Info().Msgf("next hop %v does not exist", net.HardwareAddr(mac))
+		return true
+	}
+	return lb.applyNexthops(removeMac(lb.learnedNexthops, mac), removeMac(lb.configuredNexthops, mac))
+}
+
+// SetNexthops replaces learned next hops (i.e. ones of the default route reported by
+// gateway tracker) with macs. next hops added with AddNexthop are kept. empty set
+// of all next hops makes balancer to send everything to default's mac
+func (lb *FlomeshLb) SetNexthops(macs [][]uint8) bool {
+	learned := make([][]uint8, 0, len(macs))
+	for _, mac := range macs {
+		if len(mac) != kMacBytes {
+			log.Error().Msgf("invalid next hop's mac: %v", net.HardwareAddr(mac))
+			return false
+		}
+		if macPos(learned, mac) < 0 {
+			learned = append(learned, copyMac(mac))
+		}
+	}
+	lb.ctlLock.Lock()
+	defer lb.ctlLock.Unlock()
+	return lb.applyNexthops(learned, lb.configuredNexthops)
+}
+
+// GetNexthops returns macs of next hops. index of the mac could be used to
+// get its stats with GetNexthopStats
+func (lb *FlomeshLb) GetNexthops() [][]uint8 {
+	lb.ctlLock.Lock()
+	defer lb.ctlLock.Unlock()
+	nexthops := make([][]uint8, 0, len(lb.nexthops))
+	for _, mac := range lb.nexthops {
+		nexthops = append(nexthops, copyMac(mac))
+	}
+	return nexthops
+}
+
+// GetNexthopStats returns number of packets (v1) and bytes (v2) sent through the next hop
+func (lb *FlomeshLb) GetNexthopStats(pos uint32) bpf.LbStats {
+	sumStat := bpf.LbStats{}
+	for _, stat := range lb.getNexthopCpuStats(pos) {
+		sumStat.V1 += stat.V1
+		sumStat.V2 += stat.V2
+	}
+	return sumStat
+}
+
+func (lb *FlomeshLb) nexthopPos(mac []uint8) int {
+	return macPos(lb.nexthops, mac)
+}
+
+// applyNexthops programs union of learned and configured next hops and
+// remembers both sets on success. ctlLock must be held
+func (lb *FlomeshLb) applyNexthops(learned, configured [][]uint8) bool {
+	nexthops := make([][]uint8, 0, len(learned)+len(configured))
+	nexthops = append(nexthops, learned...)
+	for _, mac := range configured {
+		if macPos(nexthops, mac) < 0 {
+			nexthops = append(nexthops, mac)
+		}
+	}
+	if len(nexthops) > kMaxNexthops {
+		log.Error().Msgf("can't program %d next hops, max number of next hops is %d", len(nexthops), kMaxNexthops)
+		return false
+	}
+	if !lb.programNexthops(nexthops) {
+		return false
+	}
+	lb.learnedNexthops = learned
+	lb.configuredNexthops = configured
+	return true
+}
+
+func (lb *FlomeshLb) getNexthopCpuStats(pos uint32) []bpf.LbStats {
+	if !lb.writesBpfMaps() || lb.config.disableForwarding || !lb.bpfMaps.Has(adapter.NexthopStats) {
+		return nil
+	}
+	stats, err := lb.statsArray(adapter.NexthopStats).Lookup(pos)
+	if err != nil {
+		lb.lbStats.BpfFailedCalls++
+		return nil
+	}
+	return stats
+}
+
+// programNexthops writes nexthops into the balancer. ctlLock must be held.
+// counters of each next hop are moved along with it, so they are
+// not mixed up when positions of next hops change
+func (lb *FlomeshLb) programNexthops(nexthops [][]uint8) bool {
+	if lb.writesBpfMaps() && !lb.config.disableForwarding {
+		if !lb.bpfMaps.Has(adapter.NexthopMacs) {
+			log.Error().Msg("balancer has been built without next hops support")
+			return false
+		}
+		nrCpus, err := adapter.GetPossibleCpus()
+		if err != nil {
+			log.Error().Msgf("can't get number of possible cpus, error: %v", err)
+			return false
+		}
+		oldStats := make([][]bpf.LbStats, len(lb.nexthops))
+		for pos := range lb.nexthops {
+			oldStats[pos] = lb.getNexthopCpuStats(uint32(pos))
+		}
+
+		// while next hops are rewritten default's mac is used
+		if !lb.updateNexthopsCnt(0) {
+			return false
+		}
+		for pos, mac := range nexthops {
+			key := uint32(pos)
+			ctl := bpf.CtlValue{}
+			ctl.SetMac(mac)
+			if err = lb.nexthopMacs().Update(key, ctl, ebpf.UpdateAny); err != nil {
+				lb.lbStats.BpfFailedCalls++
+				log.Error().Msgf("can't add next hop %v, error: %v", net.HardwareAddr(mac), err)
+				return false
+			}
+			stats := make([]bpf.LbStats, nrCpus)
+			if oldPos := lb.nexthopPos(mac); oldPos >= 0 && len(oldStats[oldPos]) == nrCpus {
+				copy(stats, oldStats[oldPos])
+			}
+			if err = lb.statsArray(adapter.NexthopStats).Update(key, stats); err != nil {
+				lb.lbStats.BpfFailedCalls++
+				log.Error().Msgf("can't update stats of next hop %v, error: %v", net.HardwareAddr(mac), err)
+			}
+		}
+		if !lb.updateNexthopsCnt(uint64(len(nexthops))) {
+			return false
+		}
+	}
+	lb.nexthops = nexthops
+	log.Info().Msgf("programmed %d next hops", len(nexthops))
+	return true
+}
+
+func (lb *FlomeshLb) updateNexthopsCnt(cnt uint64) bool {
+	key := kNexthopsCntPos
+	lb.ctlValues[kNexthopsCntPos].SetValue(cnt)
+	if err := lb.ctlArray().Update(key, lb.ctlValues[kNexthopsCntPos], ebpf.UpdateAny); err != nil {
+		lb.lbStats.BpfFailedCalls++
+		log.Error().Msgf("can't update number of next hops, error: %v", err)
+		return false
+	}
+	return true
+}
+
+func macPos(macs [][]uint8, mac []uint8) int {
+	for pos, m := range macs {
+		if bytes.Equal(m, mac) {
+			return pos
+		}
+	}
+	return -1
+}
+
+func removeMac(macs [][]uint8, mac []uint8) [][]uint8 {
+	pos := macPos(macs, mac)
+	if pos < 0 {
+		return macs
+	}
+	removed := make([][]uint8, 0, len(macs)-1)
+	removed = append(removed, macs[:pos]...)
+	return append(removed, macs[pos+1:]...)
+}
+
+func copyMac(mac []uint8) []uint8 {
+	macCopy := make([]uint8, kMacBytes)
+	copy(macCopy, mac)
+	return macCopy
+}
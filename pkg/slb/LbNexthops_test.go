@@ -0,0 +1,92 @@
+package slb
+
+import (
+	"net"
+	"testing"
+)
+
+func testMac(i byte) []uint8 {
+	return []uint8{0x02, 0, 0, 0, 0, i}
+}
+
+func checkNexthops(t *testing.T, lb *FlomeshLb, expected ...[]uint8) {
+	t.Helper()
+	nexthops := lb.GetNexthops()
+	if len(nexthops) != len(expected) {
+		t.Fatalf("next hops %v, expected %v", nexthops, expected)
+	}
+	for i := range expected {
+		if net.HardwareAddr(nexthops[i]).String() != net.HardwareAddr(expected[i]).String() {
+			t.Fatalf("next hops %v, expected %v", nexthops, expected)
+		}
+	}
+}
+
+func TestSetNexthopsKeepsConfiguredNexthops(t *testing.T) {
+	lb := newTestLb(t)
+	if !lb.AddNexthop(testMac(1)) {
+		t.Fatal("can't add next hop")
+	}
+	if !lb.SetNexthops([][]uint8{testMac(2), testMac(3)}) {
+		t.Fatal("can't set next hops")
+	}
+	checkNexthops(t, lb, testMac(2), testMac(3), testMac(1))
+
+	// gateway tracker reports other routers
+	if !lb.SetNexthops([][]uint8{testMac(4)}) {
+		t.Fatal("can't set next hops")
+	}
+	checkNexthops(t, lb, testMac(4), testMac(1))
+
+	// default route is gone
+	if !lb.SetNexthops(nil) {
+		t.Fatal("can't set next hops")
+	}
+	checkNexthops(t, lb, testMac(1))
+}
+
+func TestNexthopBothLearnedAndConfigured(t *testing.T) {
+	lb := newTestLb(t)
+	lb.SetNexthops([][]uint8{testMac(1), testMac(2)})
+	lb.AddNexthop(testMac(2))
+	checkNexthops(t, lb, testMac(1), testMac(2))
+
+	// still configured
+	lb.SetNexthops([][]uint8{testMac(1)})
+	checkNexthops(t, lb, testMac(1), testMac(2))
+
+	// deleted next hop is removed from both sets
+	if !lb.DelNexthop(testMac(2)) || !lb.DelNexthop(testMac(1)) {
+		t.Fatal("can't delete next hop")
+	}
+	checkNexthops(t, lb)
+	lb.SetNexthops([][]uint8{testMac(1)})
+	checkNexthops(t, lb, testMac(1))
+}
+
+func TestNexthopsLimit(t *testing.T) {
+	lb := newTestLb(t)
+	learned := make([][]uint8, 0, kMaxNexthops)
+	for i := 0; i < kMaxNexthops; i++ {
+		learned = append(learned, testMac(byte(i)))
+	}
+	if !lb.SetNexthops(learned) {
+		t.Fatal("can't set max number of next hops")
+	}
+	extra := testMac(byte(kMaxNexthops))
+	if lb.AddNexthop(extra) {
+		t.Error("next hop has been added above the limit")
+	}
+	if !lb.SetNexthops(learned[1:]) || !lb.AddNexthop(extra) {
+		t.Fatal("can't add next hop after learned one is gone")
+	}
+	// new learned next hop doesn't fit, previous set is kept
+	if lb.SetNexthops(learned) {
+		t.Error("next hops have been set above the limit")
+	}
+	checkNexthops(t, lb, append(learned[1:], extra)...)
+
+	if lb.SetNexthops([][]uint8{{0x02}}) || lb.AddNexthop([]uint8{0x02}) {
+		t.Error("invalid mac has been accepted")
+	}
+}
@@ -0,0 +1,416 @@
+package slb
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/cybwan/l4slb/pkg/bpf/monitor"
+	"github.com/cybwan/l4slb/pkg/ch"
+)
+
+// xdp attach flags, see XDP_FLAGS_* in linux/if_link.h
+const (
+	kXdpFlagsUpdateIfNoExist uint32 = 1 << 0
+	kXdpFlagsSkbMode         uint32 = 1 << 1
+	kXdpFlagsDrvMode         uint32 = 1 << 2
+	kXdpFlagsHwMode          uint32 = 1 << 3
+	kXdpFlagsModes                  = kXdpFlagsSkbMode | kXdpFlagsDrvMode | kXdpFlagsHwMode
+	kXdpFlagsMask                   = kXdpFlagsUpdateIfNoExist | kXdpFlagsModes
+
+	// number of slots in root_array of xdp root program
+	kRootArraySize uint32 = 3
+)
+
+// FlomeshLbOptions is the exported form of FlomeshLbConfig. it could be
+// loaded from YAML config file and overridden by command line flags.
+// Build validates options and creates FlomeshLbConfig from them.
+type FlomeshLbOptions struct {
+	MainInterface      string   `yaml:"main_interface"`
+	V4TunInterface     string   `yaml:"v4_tun_interface"`
+	V6TunInterface     string   `yaml:"v6_tun_interface"`
+	HcInterface        string   `yaml:"hc_interface"`
+	RootMapPath        string   `yaml:"root_map_path"`
+	RootMapPos         uint32   `yaml:"root_map_pos"`
+	EnableHc           bool     `yaml:"enable_hc"`
+	TunnelBasedHCEncap bool     `yaml:"tunnel_based_hc_encap"`
+	DisableForwarding  bool     `yaml:"disable_forwarding"`
+	MaxVips            uint32   `yaml:"max_vips"`
+	MaxReals           uint32   `yaml:"max_reals"`
+	ChRingSize         uint32   `yaml:"ch_ring_size"`
+	ChRingsSize        uint32   `yaml:"ch_rings_size"`
+	HashFunction       string   `yaml:"hash_function"`
+	LruSize            uint64   `yaml:"lru_size"`
+	GlobalLruSize      uint32   `yaml:"global_lru_size"`
+	MaxLpmSrcSize      uint32   `yaml:"max_lpm_src_size"`
+	MaxDecapDst        uint32   `yaml:"max_decap_dst"`
+	ForwardingCores    []int32  `yaml:"forwarding_cores"`
+	NumaNodes          []int32  `yaml:"numa_nodes"`
+	XdpAttachFlags     uint32   `yaml:"xdp_attach_flags"`
+	LbSrcV4            string   `yaml:"lb_src_v4"`
+	LbSrcV6            string   `yaml:"lb_src_v6"`
+	FlowDebug          bool     `yaml:"flow_debug"`
+	Introspection      bool     `yaml:"introspection"`
+	MonitorPages       uint32   `yaml:"monitor_pages"`
+	MonitorQueueSize   uint32   `yaml:"monitor_queue_size"`
+	MonitorPcktLimit   uint32   `yaml:"monitor_pckt_limit"`
+	MonitorSnapLen     uint32   `yaml:"monitor_snap_len"`
+	MonitorStorage     string   `yaml:"monitor_storage"`
+	MonitorBufferSize  uint32   `yaml:"monitor_buffer_size"`
+	MonitorPath        string   `yaml:"monitor_path"`
+	MonitorEvents      []string `yaml:"monitor_events"`
+	VipConfigPath      string   `yaml:"vip_config_path"`
+}
+
+// DefaultFlomeshLbOptions returns options with the same defaults as NewFlomeshLbConfig
+func DefaultFlomeshLbOptions() *FlomeshLbOptions {
+	config := NewFlomeshLbConfig()
+	return &FlomeshLbOptions{
+		MainInterface:      config.mainInterface,
+		V4TunInterface:     config.v4TunInterface,
+		V6TunInterface:     config.v6TunInterface,
+		HcInterface:        config.hcInterface,
+		RootMapPath:        config.rootMapPath,
+		RootMapPos:         config.rootMapPos,
+		EnableHc:           config.enableHc,
+		TunnelBasedHCEncap: config.tunnelBasedHCEncap,
+		DisableForwarding:  config.disableForwarding,
+		MaxVips:            config.maxVips,
+		MaxReals:           config.maxReals,
+		ChRingSize:         config.chRingSize,
+		ChRingsSize:        config.chRingsSize,
+		HashFunction:       config.hashFunction.String(),
+		LruSize:            config.LruSize,
+		GlobalLruSize:      config.globalLruSize,
+		MaxLpmSrcSize:      config.maxLpmSrcSize,
+		MaxDecapDst:        config.maxDecapDst,
+		XdpAttachFlags:     config.xdpAttachFlags,
+		LbSrcV4:            config.LbSrcV4,
+		LbSrcV6:            config.LbSrcV6,
+		FlowDebug:          config.flowDebug,
+		Introspection:      config.introspection,
+		MonitorPages:       config.monitorConfig.pages,
+		MonitorQueueSize:   config.monitorConfig.queueSize,
+		MonitorPcktLimit:   config.monitorConfig.pcktLimit,
+		MonitorSnapLen:     config.monitorConfig.snapLen,
+		MonitorStorage:     config.monitorConfig.storage.String(),
+		MonitorBufferSize:  config.monitorConfig.bufferSize,
+		MonitorPath:        config.monitorConfig.path,
+		MonitorEvents:      config.monitorConfig.events,
+		VipConfigPath:      config.vipConfigPath,
+	}
+}
+
+// LoadFlomeshLbOptions reads options from YAML file. options which are not
+// present in the file keep their default values
+func LoadFlomeshLbOptions(path string) (*FlomeshLbOptions, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't open config file: %w", err)
+	}
+	defer f.Close()
+
+	opts := DefaultFlomeshLbOptions()
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+	if err = decoder.Decode(opts); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("can't parse config file %s: %w", path, err)
+	}
+	return opts, nil
+}
+
+// BindFlags registers a command line flag for every option, with current
+// values of the options as defaults
+func (o *FlomeshLbOptions) BindFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.V4TunInterface, "v4_tun_interface", o.V4TunInterface,
+		"Name of ipip interface for healthchecks")
+	fs.StringVar(&o.V6TunInterface, "v6_tun_interface", o.V6TunInterface,
+		"Name of ip6ip6 interface for healthchecks")
+	fs.StringVar(&o.HcInterface, "hc_interface", o.HcInterface,
+		"Interface to attach healthchecking bpf program to")
+	fs.StringVar(&o.RootMapPath, "root_map_path", o.RootMapPath,
+		"Path to pinned root map of the shared xdp root program")
+	fs.Var((*uint32Value)(&o.RootMapPos), "root_map_pos",
+		"Position of the balancer in root map")
+	fs.BoolVar(&o.EnableHc, "enable_hc", o.EnableHc, "Enable healthchecking bpf program")
+	fs.BoolVar(&o.TunnelBasedHCEncap, "tunnel_based_hc_encap", o.TunnelBasedHCEncap,
+		"Use tunnel interfaces to encapsulate healthchecks")
+	fs.BoolVar(&o.DisableForwarding, "disable_forwarding", o.DisableForwarding,
+		"Do not load forwarding plane, healthchecks only")
+	fs.Var((*uint32Value)(&o.MaxVips), "max_vips", "Max number of vips")
+	fs.Var((*uint32Value)(&o.MaxReals), "max_reals", "Max number of reals")
+	fs.Var((*uint32Value)(&o.ChRingSize), "ch_ring_size",
+		"Size of consistent hash ring of the vip. must be a prime number")
+	fs.Var((*uint32Value)(&o.ChRingsSize), "ch_rings_size",
+		"Number of positions of rings of all vips, max_vips * ch_ring_size if 0. "+
+			"smaller one fits more vips only if they are added with smaller rings")
+	fs.StringVar(&o.HashFunction, "hash_function", o.HashFunction,
+		"Consistent hash function. Possible values: maglev, maglev_v2, rendezvous, ketama, jump. "+
+			"rendezvous takes at most "+strconv.Itoa(ch.RendezvousMaxEndpoints)+" reals per vip")
+	fs.Uint64Var(&o.LruSize, "lru_size", o.LruSize, "Size of connection table (summary for all cpus)")
+	fs.Var((*uint32Value)(&o.GlobalLruSize), "global_lru_size",
+		"Size of global connection table per cpu")
+	fs.Var((*uint32Value)(&o.MaxLpmSrcSize), "max_lpm_src_size",
+		"Max number of prefixes for source based routing")
+	fs.Var((*uint32Value)(&o.MaxDecapDst), "max_decap_dst",
+		"Max number of destinations for inline decapsulation")
+	fs.Var((*int32ListValue)(&o.ForwardingCores), "forwarding_cores",
+		"Comma separated list of cpus which are handling NIC's irqs")
+	fs.Var((*int32ListValue)(&o.NumaNodes), "numa_nodes",
+		"Comma separated list of numa nodes of forwarding_cores")
+	fs.Var((*uint32Value)(&o.XdpAttachFlags), "xdp_attach_flags",
+		"XDP_FLAGS_* to attach xdp program with (e.g. 2 - skb mode, 4 - native mode)")
+	fs.StringVar(&o.LbSrcV4, "lb_src_v4", o.LbSrcV4, "Source address of ipv4 encapsulated packets")
+	fs.StringVar(&o.LbSrcV6, "lb_src_v6", o.LbSrcV6, "Source address of ipv6 encapsulated packets")
+	fs.BoolVar(&o.FlowDebug, "flow_debug", o.FlowDebug,
+		"Record flows' debug info. balancer must be built with INLINE_DECAP_GUE and RECORD_FLOW_INFO, "+
+			"see BPF_FEATURES of Makefile")
+	fs.BoolVar(&o.Introspection, "introspection", o.Introspection,
+		"Read introspection events of the balancer and capture packets which have triggered them. "+
+			"balancer must be built with FLOMESHLB_INTROSPECTION, see BPF_FEATURES of Makefile")
+	fs.Var((*uint32Value)(&o.MonitorPages), "monitor_pages", "Size of per cpu buffer of events, in pages")
+	fs.Var((*uint32Value)(&o.MonitorQueueSize), "monitor_queue_size",
+		"Max number of events waiting to be written")
+	fs.Var((*uint32Value)(&o.MonitorPcktLimit), "monitor_pckt_limit",
+		"Max number of packets written into storage, 0 - unlimited")
+	fs.Var((*uint32Value)(&o.MonitorSnapLen), "monitor_snap_len", "Max number of bytes of packet to capture")
+	fs.StringVar(&o.MonitorStorage, "monitor_storage", o.MonitorStorage,
+		"Where captured packets are written. Possible values: file, iobuf, pipe")
+	fs.Var((*uint32Value)(&o.MonitorBufferSize), "monitor_buffer_size",
+		"Max number of bytes of packets kept in memory per event for iobuf storage")
+	fs.StringVar(&o.MonitorPath, "monitor_path", o.MonitorPath, "Directory of pcap files for file storage")
+	fs.Var((*stringListValue)(&o.MonitorEvents), "monitor_events",
+		"Comma separated list of events to capture, all if empty. "+
+			"Possible values: tcp_nonsyn_lrumiss, packet_toobig, quic_packet_drop_no_real")
+	fs.StringVar(&o.VipConfigPath, "vip_config_path", o.VipConfigPath,
+		"YAML file vips and their reals are saved to on every change and restored from on start. "+
+			"vips are not saved if empty")
+}
+
+// ApplyFlags sets options which have been explicitly set in fs, so command
+// line takes precedence over the config file
+func (o *FlomeshLbOptions) ApplyFlags(fs *flag.FlagSet) error {
+	bound := flag.NewFlagSet("options", flag.ContinueOnError)
+	o.BindFlags(bound)
+	var err error
+	fs.Visit(func(f *flag.Flag) {
+		if err == nil && bound.Lookup(f.Name) != nil {
+			if setErr := bound.Set(f.Name, f.Value.String()); setErr != nil {
+				err = fmt.Errorf("invalid value %q for flag -%s: %w", f.Value.String(), f.Name, setErr)
+			}
+		}
+	})
+	return err
+}
+
+// Validate checks that options are consistent
+func (o *FlomeshLbOptions) Validate() error {
+	if o.MaxVips < kStatsCountersNum {
+		// global counters are stored in stats map right after per vip ones,
+		// at max_vips + offset, and stats map has max_vips * 2 entries
+		return fmt.Errorf("max_vips must be at least %d, got %d", kStatsCountersNum, o.MaxVips)
+	}
+	if o.MaxReals == 0 {
+		return fmt.Errorf("max_reals must be greater than 0")
+	}
+	if !isPrime(o.ChRingSize) {
+		return fmt.Errorf("ch_ring_size must be a prime number, got %d", o.ChRingSize)
+	}
+	if uint64(o.MaxVips)*uint64(o.ChRingSize) > uint64(^uint32(0)) {
+		return fmt.Errorf("max_vips * ch_ring_size (%d * %d) does not fit into uint32", o.MaxVips, o.ChRingSize)
+	}
+	if o.ChRingsSize != 0 && o.ChRingsSize < o.ChRingSize {
+		return fmt.Errorf("ch_rings_size must fit at least one ring of ch_ring_size %d, got %d",
+			o.ChRingSize, o.ChRingsSize)
+	}
+	if _, err := ch.ParseHashFunction(o.HashFunction); err != nil {
+		return fmt.Errorf("hash_function: %w", err)
+	}
+	if o.LruSize == 0 {
+		return fmt.Errorf("lru_size must be greater than 0")
+	}
+	if o.GlobalLruSize == 0 {
+		return fmt.Errorf("global_lru_size must be greater than 0")
+	}
+	if len(o.ForwardingCores) > int(kMaxForwardingCores) {
+		return fmt.Errorf("forwarding_cores has %d cpus, max is %d", len(o.ForwardingCores), kMaxForwardingCores)
+	}
+	cores := make(map[int32]bool)
+	for _, core := range o.ForwardingCores {
+		if core < 0 || core >= kMaxForwardingCores {
+			return fmt.Errorf("forwarding_cores: invalid cpu %d", core)
+		}
+		if cores[core] {
+			return fmt.Errorf("forwarding_cores: cpu %d is listed more than once", core)
+		}
+		cores[core] = true
+	}
+	if len(o.NumaNodes) > 0 && len(o.NumaNodes) != len(o.ForwardingCores) {
+		return fmt.Errorf("numa_nodes length (%d) must match forwarding_cores length (%d)",
+			len(o.NumaNodes), len(o.ForwardingCores))
+	}
+	for _, node := range o.NumaNodes {
+		if node < 0 {
+			return fmt.Errorf("numa_nodes: invalid numa node %d", node)
+		}
+	}
+	if o.XdpAttachFlags&^kXdpFlagsMask != 0 {
+		return fmt.Errorf("xdp_attach_flags: unknown flags %#x", o.XdpAttachFlags&^kXdpFlagsMask)
+	}
+	if modes := o.XdpAttachFlags & kXdpFlagsModes; modes&(modes-1) != 0 {
+		return fmt.Errorf("xdp_attach_flags: only one of skb, native and hw modes could be set")
+	}
+	if o.RootMapPos >= kRootArraySize {
+		return fmt.Errorf("root_map_pos must be less than %d, got %d", kRootArraySize, o.RootMapPos)
+	}
+	if o.LbSrcV4 != kAddressNotSpecified {
+		if ip := net.ParseIP(o.LbSrcV4); ip == nil || ip.To4() == nil {
+			return fmt.Errorf("lb_src_v4: invalid ipv4 address %q", o.LbSrcV4)
+		}
+	}
+	if o.Introspection {
+		if o.MonitorPages == 0 || o.MonitorPages&(o.MonitorPages-1) != 0 {
+			return fmt.Errorf("monitor_pages must be a power of 2, got %d", o.MonitorPages)
+		}
+		if o.MonitorQueueSize == 0 {
+			return fmt.Errorf("monitor_queue_size must be greater than 0")
+		}
+	}
+	if _, err := ParsePcapStorageFormat(o.MonitorStorage); err != nil {
+		return fmt.Errorf("monitor_storage: %w", err)
+	}
+	for _, event := range o.MonitorEvents {
+		if _, err := monitor.ParseEventId(event); err != nil {
+			return fmt.Errorf("monitor_events: %w", err)
+		}
+	}
+	if o.LbSrcV6 != kAddressNotSpecified {
+		if ip := net.ParseIP(o.LbSrcV6); ip == nil || ip.To4() != nil {
+			return fmt.Errorf("lb_src_v6: invalid ipv6 address %q", o.LbSrcV6)
+		}
+	}
+	return nil
+}
+
+// Build validates options and creates FlomeshLbConfig from them
+func (o *FlomeshLbOptions) Build() (*FlomeshLbConfig, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	hfunc, _ := ch.ParseHashFunction(o.HashFunction)
+	config := NewFlomeshLbConfig()
+	config.mainInterface = o.MainInterface
+	config.v4TunInterface = o.V4TunInterface
+	config.v6TunInterface = o.V6TunInterface
+	config.hcInterface = o.HcInterface
+	config.rootMapPath = o.RootMapPath
+	config.rootMapPos = o.RootMapPos
+	config.enableHc = o.EnableHc
+	config.tunnelBasedHCEncap = o.TunnelBasedHCEncap
+	config.disableForwarding = o.DisableForwarding
+	config.maxVips = o.MaxVips
+	config.maxReals = o.MaxReals
+	config.chRingSize = o.ChRingSize
+	config.chRingsSize = o.ChRingsSize
+	config.hashFunction = hfunc
+	config.LruSize = o.LruSize
+	config.globalLruSize = o.GlobalLruSize
+	config.maxLpmSrcSize = o.MaxLpmSrcSize
+	config.maxDecapDst = o.MaxDecapDst
+	config.forwardingCores = append([]int32(nil), o.ForwardingCores...)
+	config.numaNodes = append([]int32(nil), o.NumaNodes...)
+	config.xdpAttachFlags = o.XdpAttachFlags
+	config.LbSrcV4 = o.LbSrcV4
+	config.LbSrcV6 = o.LbSrcV6
+	config.flowDebug = o.FlowDebug
+	config.introspection = o.Introspection
+	config.monitorConfig.pages = o.MonitorPages
+	config.monitorConfig.queueSize = o.MonitorQueueSize
+	config.monitorConfig.pcktLimit = o.MonitorPcktLimit
+	config.monitorConfig.snapLen = o.MonitorSnapLen
+	config.monitorConfig.storage, _ = ParsePcapStorageFormat(o.MonitorStorage)
+	config.monitorConfig.bufferSize = o.MonitorBufferSize
+	config.monitorConfig.path = o.MonitorPath
+	config.monitorConfig.events = append([]string(nil), o.MonitorEvents...)
+	config.vipConfigPath = o.VipConfigPath
+	return config, nil
+}
+
+func isPrime(n uint32) bool {
+	if n < 2 {
+		return false
+	}
+	for i := uint64(2); i*i <= uint64(n); i++ {
+		if uint64(n)%i == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// uint32Value implements flag.Value for uint32 options
+type uint32Value uint32
+
+func (v *uint32Value) String() string {
+	return strconv.FormatUint(uint64(*v), 10)
+}
+
+func (v *uint32Value) Set(s string) error {
+	n, err := strconv.ParseUint(s, 0, 32)
+	if err != nil {
+		return err
+	}
+	*v = uint32Value(n)
+	return nil
+}
+
+// int32ListValue implements flag.Value for comma separated list of int32
+type int32ListValue []int32
+
+func (v *int32ListValue) String() string {
+	items := make([]string, len(*v))
+	for i, n := range *v {
+		items[i] = strconv.FormatInt(int64(n), 10)
+	}
+	return strings.Join(items, ",")
+}
+
+func (v *int32ListValue) Set(s string) error {
+	list := int32ListValue{}
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(item, 10, 32)
+		if err != nil {
+			return err
+		}
+		list = append(list, int32(n))
+	}
+	*v = list
+	return nil
+}
+
+// stringListValue implements flag.Value for comma separated list of strings
+type stringListValue []string
+
+func (v *stringListValue) String() string {
+	return strings.Join(*v, ",")
+}
+
+func (v *stringListValue) Set(s string) error {
+	list := stringListValue{}
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	*v = list
+	return nil
+}
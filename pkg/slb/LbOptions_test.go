@@ -0,0 +1,200 @@
+package slb
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cybwan/l4slb/pkg/ch"
+)
+
+func TestDefaultOptionsAreValid(t *testing.T) {
+	opts := DefaultFlomeshLbOptions()
+	if err := opts.Validate(); err != nil {
+		t.Fatalf("default options are invalid: %v", err)
+	}
+	config, err := opts.Build()
+	if err != nil {
+		t.Fatalf("can't build config from default options: %v", err)
+	}
+	defaults := NewFlomeshLbConfig()
+	config.forwardingCores, config.numaNodes, config.monitorConfig.events = nil, nil, nil
+	if !reflect.DeepEqual(config, defaults) {
+		t.Errorf("config built from default options\n%+v\ndiffers from default config\n%+v", config, defaults)
+	}
+}
+
+func TestValidateOptions(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(o *FlomeshLbOptions)
+		// substring of the error, empty if options are valid
+		err string
+	}{
+		{"min max_vips", func(o *FlomeshLbOptions) { o.MaxVips = kStatsCountersNum }, ""},
+		{"too few vips", func(o *FlomeshLbOptions) { o.MaxVips = kStatsCountersNum - 1 }, "max_vips"},
+		{"no reals", func(o *FlomeshLbOptions) { o.MaxReals = 0 }, "max_reals"},
+		{"prime ring size", func(o *FlomeshLbOptions) { o.ChRingSize = 1021 }, ""},
+		{"ring size is not prime", func(o *FlomeshLbOptions) { o.ChRingSize = 65536 }, "ch_ring_size"},
+		{"zero ring size", func(o *FlomeshLbOptions) { o.ChRingSize = 0 }, "ch_ring_size"},
+		{"rings overflow", func(o *FlomeshLbOptions) { o.MaxVips = 1 << 16 }, "does not fit"},
+		{"smaller rings size", func(o *FlomeshLbOptions) { o.ChRingsSize = 1 << 20 }, ""},
+		{"rings size below ring size", func(o *FlomeshLbOptions) { o.ChRingsSize = 100 }, "ch_rings_size"},
+		{"rendezvous", func(o *FlomeshLbOptions) { o.HashFunction = "rendezvous" }, ""},
+		{"unknown hash function", func(o *FlomeshLbOptions) { o.HashFunction = "crc32" }, "hash_function"},
+		{"no lru", func(o *FlomeshLbOptions) { o.LruSize = 0 }, "lru_size"},
+		{"no global lru", func(o *FlomeshLbOptions) { o.GlobalLruSize = 0 }, "global_lru_size"},
+		{"forwarding cores", func(o *FlomeshLbOptions) {
+			o.ForwardingCores = []int32{0, 1}
+			o.NumaNodes = []int32{0, 0}
+		}, ""},
+		{"negative core", func(o *FlomeshLbOptions) { o.ForwardingCores = []int32{-1} }, "invalid cpu"},
+		{"core above max", func(o *FlomeshLbOptions) { o.ForwardingCores = []int32{kMaxForwardingCores} }, "invalid cpu"},
+		{"duplicate core", func(o *FlomeshLbOptions) { o.ForwardingCores = []int32{1, 1} }, "more than once"},
+		{"numa nodes mismatch", func(o *FlomeshLbOptions) {
+			o.ForwardingCores = []int32{0, 1}
+			o.NumaNodes = []int32{0}
+		}, "numa_nodes length"},
+		{"negative numa node", func(o *FlomeshLbOptions) {
+			o.ForwardingCores = []int32{0}
+			o.NumaNodes = []int32{-1}
+		}, "invalid numa node"},
+		{"native mode", func(o *FlomeshLbOptions) { o.XdpAttachFlags = kXdpFlagsDrvMode }, ""},
+		{"unknown xdp flag", func(o *FlomeshLbOptions) { o.XdpAttachFlags = 1 << 4 }, "unknown flags"},
+		{"several xdp modes", func(o *FlomeshLbOptions) {
+			o.XdpAttachFlags = kXdpFlagsSkbMode | kXdpFlagsDrvMode
+		}, "only one of"},
+		{"root map pos", func(o *FlomeshLbOptions) { o.RootMapPos = kRootArraySize }, "root_map_pos"},
+		{"lb src v4", func(o *FlomeshLbOptions) { o.LbSrcV4 = "10.0.0.1" }, ""},
+		{"lb src v4 is v6", func(o *FlomeshLbOptions) { o.LbSrcV4 = "fc00::1" }, "lb_src_v4"},
+		{"lb src v6", func(o *FlomeshLbOptions) { o.LbSrcV6 = "fc00::1" }, ""},
+		{"lb src v6 is v4", func(o *FlomeshLbOptions) { o.LbSrcV6 = "10.0.0.1" }, "lb_src_v6"},
+		{"monitor pages", func(o *FlomeshLbOptions) {
+			o.Introspection = true
+			o.MonitorPages = 3
+		}, "monitor_pages"},
+		{"monitor queue", func(o *FlomeshLbOptions) {
+			o.Introspection = true
+			o.MonitorQueueSize = 0
+		}, "monitor_queue_size"},
+		{"monitor storage", func(o *FlomeshLbOptions) { o.MonitorStorage = "disk" }, "monitor_storage"},
+		{"monitor events", func(o *FlomeshLbOptions) { o.MonitorEvents = []string{"nope"} }, "monitor_events"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := DefaultFlomeshLbOptions()
+			test.modify(opts)
+			err := opts.Validate()
+			if test.err == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("error %v, expected one about %s", err, test.err)
+			}
+			if _, buildErr := opts.Build(); buildErr == nil {
+				t.Error("invalid options have been built")
+			}
+		})
+	}
+}
+
+func TestLoadOptions(t *testing.T) {
+	tests := []struct {
+		name  string
+		yaml  string
+		check func(o *FlomeshLbOptions) bool
+		err   string
+	}{
+		{
+			name:  "empty file keeps defaults",
+			yaml:  "",
+			check: func(o *FlomeshLbOptions) bool { return reflect.DeepEqual(o, DefaultFlomeshLbOptions()) },
+		},
+		{
+			name: "sizes",
+			yaml: "max_vips: 128\nmax_reals: 1024\nch_ring_size: 1021\nch_rings_size: 4096\n",
+			check: func(o *FlomeshLbOptions) bool {
+				d := DefaultFlomeshLbOptions()
+				return o.MaxVips == 128 && o.MaxReals == 1024 && o.ChRingSize == 1021 &&
+					o.ChRingsSize == 4096 && o.LruSize == d.LruSize && o.HashFunction == d.HashFunction
+			},
+		},
+		{
+			name: "lists",
+			yaml: "forwarding_cores: [0, 2]\nnuma_nodes: [0, 1]\nmonitor_events: [tcp_nonsyn_lrumiss]\n",
+			check: func(o *FlomeshLbOptions) bool {
+				return reflect.DeepEqual(o.ForwardingCores, []int32{0, 2}) &&
+					reflect.DeepEqual(o.NumaNodes, []int32{0, 1}) &&
+					reflect.DeepEqual(o.MonitorEvents, []string{"tcp_nonsyn_lrumiss"})
+			},
+		},
+		{name: "unknown option", yaml: "max_vip: 128\n", err: "max_vip"},
+		{name: "negative size", yaml: "max_reals: -1\n", err: "`-1` into uint32"},
+		{name: "size overflow", yaml: "ch_ring_size: 4294967296\n", err: "`4294967296` into uint32"},
+		{name: "not a number", yaml: "lru_size: many\n", err: "`many` into uint64"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "slb.yaml")
+			if err := os.WriteFile(path, []byte(test.yaml), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			opts, err := LoadFlomeshLbOptions(path)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Errorf("error %v, expected one about %s", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("can't load options: %v", err)
+			}
+			if !test.check(opts) {
+				t.Errorf("unexpected options %+v", opts)
+			}
+		})
+	}
+
+	if _, err := LoadFlomeshLbOptions(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("missing config file has been loaded")
+	}
+}
+
+func TestApplyFlags(t *testing.T) {
+	opts := DefaultFlomeshLbOptions()
+	opts.MaxVips = 128
+	opts.MaxReals = 1024
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	DefaultFlomeshLbOptions().BindFlags(fs)
+	if err := fs.Parse([]string{"-max_reals", "2048", "-hash_function", "rendezvous"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := opts.ApplyFlags(fs); err != nil {
+		t.Fatalf("can't apply flags: %v", err)
+	}
+	// flags which are not set keep values from config file
+	if opts.MaxVips != 128 || opts.MaxReals != 2048 || opts.HashFunction != "rendezvous" {
+		t.Errorf("unexpected options after flags are applied: %+v", opts)
+	}
+	config, err := opts.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.maxReals != 2048 || config.hashFunction != ch.Rendezvous {
+		t.Errorf("unexpected config %+v", config)
+	}
+
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(new(strings.Builder))
+	DefaultFlomeshLbOptions().BindFlags(fs)
+	if err := fs.Parse([]string{"-max_vips", "-5"}); err == nil {
+		t.Error("negative max_vips flag has been parsed")
+	}
+}
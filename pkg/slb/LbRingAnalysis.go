@@ -0,0 +1,90 @@
+package slb
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/cybwan/l4slb/pkg/ch"
+)
+
+// RealRingShare is part of vip's ring owned by a real
+type RealRingShare struct {
+	Address string
+	ch.EndpointShare
+}
+
+// RingSimulation is outcome of a change of vip's reals, as ModifyRealsForVip would apply it
+type RingSimulation struct {
+	RingSize         uint32
+	ChangedPositions uint32
+	ChangedFraction  float64
+	Before           []RealRingShare
+	ImbalanceBefore  float64
+	After            []RealRingShare
+	ImbalanceAfter   float64
+}
+
+// SimulateRealsChange generates ring of the vip with reals modified by action, by the vip's
+// hash function and ring size, and compares it with the current one. nothing is changed,
+// so the effect of new weights or reals could be seen before it is applied
+func (lb *FlomeshLb) SimulateRealsChange(action ModifyAction, reals []NewReal, vip *VipKey) (*RingSimulation, error) {
+	if lb.config.disableForwarding {
+		return nil, fmt.Errorf("simulateRealsChange called on non-forwarding instance")
+	}
+	entry, exists := lb.vips[*vip]
+	if !exists {
+		return nil, fmt.Errorf("trying to simulate change of reals for non-existing vip: %s", vip.Address)
+	}
+
+	// reals which are not known yet get numbers after valid ones, they only label endpoints
+	addresses := make(map[uint32]string)
+	nextNum := lb.config.maxReals
+	ureals := make([]UpdateReal, 0, len(reals))
+	for _, r := range reals {
+		if net.ParseIP(r.Address) == nil {
+			return nil, fmt.Errorf("invalid real's address: %s", r.Address)
+		}
+		raddr := IPAddress(r.Address)
+		ureal := UpdateReal{action: action}
+		if rentry, found := lb.reals[raddr]; found {
+			ureal.updatedReal.Num = rentry.num
+		} else if action == DEL {
+			return nil, fmt.Errorf("trying to delete non-existing real: %s", r.Address)
+		} else {
+			ureal.updatedReal.Num = nextNum
+			addresses[nextNum] = r.Address
+			nextNum++
+		}
+		ureal.updatedReal.Weight = r.Weight
+		ureal.updatedReal.Hash = raddr.hash()
+		ureal.updatedReal.Key = r.Address
+		ureals = append(ureals, ureal)
+	}
+
+	sim := entry.simulateRealsUpdate(ureals)
+	if sim == nil {
+		// calculateHashRing keeps the ring as is when no reals are left
+		return nil, fmt.Errorf("vip %s would be left without reals with non zero weight", vip.Address)
+	}
+
+	realShares := func(shares []ch.EndpointShare) []RealRingShare {
+		result := make([]RealRingShare, 0, len(shares))
+		for _, share := range shares {
+			address, isNew := addresses[share.Num]
+			if !isNew {
+				address = string(lb.numToReals[share.Num])
+			}
+			result = append(result, RealRingShare{Address: address, EndpointShare: share})
+		}
+		return result
+	}
+	return &RingSimulation{
+		RingSize:         sim.RingSize,
+		ChangedPositions: sim.ChangedPositions,
+		ChangedFraction:  sim.ChangedFraction(),
+		Before:           realShares(sim.Before.Shares),
+		ImbalanceBefore:  sim.Before.Imbalance,
+		After:            realShares(sim.After.Shares),
+		ImbalanceAfter:   sim.After.Imbalance,
+	}, nil
+}
@@ -0,0 +1,144 @@
+package slb
+
+import (
+	"testing"
+
+	"github.com/cybwan/l4slb/pkg/ch"
+)
+
+func TestSimulateRealsChange(t *testing.T) {
+	lb := newTestLb(t)
+	vip := testVip(1)
+	addTestVip(t, lb, vip, testReals(4))
+	entry := lb.vips[vip]
+	before := append([]int(nil), entry.chRing...)
+
+	added := []NewReal{{Address: "10.0.0.100", Weight: 10}}
+	sim, err := lb.SimulateRealsChange(ADD, added, &vip)
+	if err != nil {
+		t.Fatalf("can't simulate change: %v", err)
+	}
+	// nothing is changed by the simulation
+	if ch.ChangedPositions(before, entry.chRing) != 0 || len(entry.reals) != 4 || lb.GetIndexForReal("10.0.0.100") >= 0 {
+		t.Fatal("simulation has changed the vip")
+	}
+	if sim.RingSize != kTestChRingSize || len(sim.Before) != 4 || len(sim.After) != 5 {
+		t.Fatalf("simulation %+v, expected ring of %d positions and 4 reals before and 5 after",
+			sim, kTestChRingSize)
+	}
+	newReal := sim.After[len(sim.After)-1]
+	if newReal.Address != "10.0.0.100" || newReal.Positions == 0 {
+		t.Errorf("share of the new real %+v", newReal)
+	}
+
+	// the change applied gives the simulated ring
+	if !lb.ModifyRealsForVip(ADD, added, &vip) {
+		t.Fatal("can't add the real")
+	}
+	if changed := ch.ChangedPositions(before, entry.chRing); changed != sim.ChangedPositions {
+		t.Errorf("%d positions have changed, simulation has changed %d", changed, sim.ChangedPositions)
+	}
+	if fraction := float64(sim.ChangedPositions) / kTestChRingSize; sim.ChangedFraction != fraction {
+		t.Errorf("changed fraction %f, expected %f", sim.ChangedFraction, fraction)
+	}
+	for _, share := range sim.After {
+		if num := lb.GetIndexForReal(share.Address); share.Address != "10.0.0.100" && uint32(num) != share.Num {
+			t.Errorf("share of %s is labeled with %d, expected %d", share.Address, share.Num, num)
+		}
+	}
+}
+
+// TestSimulateRealsChangeOfLiveRing compares proposed ring with the programmed one, which
+// may differ from the ring generated for the current reals
+func TestSimulateRealsChangeOfLiveRing(t *testing.T) {
+	lb := newTestLb(t)
+	vip := testVip(1)
+	addTestVip(t, lb, vip, testReals(4))
+	entry := lb.vips[vip]
+	first := lb.GetIndexForReal("10.0.0.1")
+	for i := range entry.chRing {
+		entry.chRing[i] = int(first)
+	}
+	live := append([]int(nil), entry.chRing...)
+
+	added := []NewReal{{Address: "10.0.0.100", Weight: 10}}
+	sim, err := lb.SimulateRealsChange(ADD, added, &vip)
+	if err != nil {
+		t.Fatalf("can't simulate change: %v", err)
+	}
+	if !lb.ModifyRealsForVip(ADD, added, &vip) {
+		t.Fatal("can't add the real")
+	}
+	if changed := ch.ChangedPositions(live, entry.chRing); changed != sim.ChangedPositions {
+		t.Errorf("%d positions of the live ring have changed, simulation has changed %d",
+			changed, sim.ChangedPositions)
+	}
+	for _, share := range sim.Before {
+		expected := uint32(0)
+		if share.Address == "10.0.0.1" {
+			expected = kTestChRingSize
+		}
+		if share.Positions != expected {
+			t.Errorf("%s owns %d positions of the live ring, expected %d", share.Address,
+				share.Positions, expected)
+		}
+	}
+}
+
+func TestSimulateRealsChangeErrors(t *testing.T) {
+	lb := newTestLb(t)
+	vip := testVip(1)
+	reals := testReals(2)
+	addTestVip(t, lb, vip, reals)
+
+	for _, test := range []struct {
+		name   string
+		action ModifyAction
+		reals  []NewReal
+		vip    VipKey
+	}{
+		{"non-existing vip", ADD, reals, testVip(2)},
+		{"invalid address", ADD, []NewReal{{Address: "10.0.0", Weight: 1}}, vip},
+		{"deletion of unknown real", DEL, []NewReal{{Address: "10.0.0.100"}}, vip},
+		{"deletion of all reals", DEL, reals, vip},
+		{"zero weights", ADD, []NewReal{{Address: reals[0].Address}, {Address: reals[1].Address}}, vip},
+	} {
+		if _, err := lb.SimulateRealsChange(test.action, test.reals, &test.vip); err == nil {
+			t.Errorf("%s: expected error", test.name)
+		}
+	}
+}
+
+func TestProposedEndpoints(t *testing.T) {
+	vip := NewVip(0, 0, 0, kTestChRingSize, ch.Maglev)
+	vip.batchRealsUpdate([]UpdateReal{
+		{action: ADD, updatedReal: ch.Endpoint{Num: 1, Weight: 10, Hash: 300}},
+		{action: ADD, updatedReal: ch.Endpoint{Num: 2, Weight: 10, Hash: 100}},
+		{action: ADD, updatedReal: ch.Endpoint{Num: 3, Weight: 0, Hash: 200}},
+	})
+
+	current := vip.currentEndpoints()
+	if len(current) != 2 || current[0].Num != 2 || current[1].Num != 1 {
+		t.Errorf("current endpoints %+v, expected reals 2 and 1 sorted by hash, without drained real 3", current)
+	}
+
+	proposed := vip.proposedEndpoints([]UpdateReal{
+		{action: DEL, updatedReal: ch.Endpoint{Num: 1}},
+		{action: ADD, updatedReal: ch.Endpoint{Num: 3, Weight: 5, Hash: 200}},
+		{action: ADD, updatedReal: ch.Endpoint{Num: 4, Weight: 1, Hash: 50}},
+	})
+	expected := []ch.Endpoint{{Num: 4, Weight: 1, Hash: 50}, {Num: 2, Weight: 10, Hash: 100}, {Num: 3, Weight: 5, Hash: 200}}
+	if len(proposed) != len(expected) {
+		t.Fatalf("proposed endpoints %+v, expected %+v", proposed, expected)
+	}
+	for i := range expected {
+		if proposed[i] != expected[i] {
+			t.Errorf("proposed endpoints %+v, expected %+v", proposed, expected)
+			break
+		}
+	}
+	// the vip is not changed
+	if len(vip.reals) != 3 || vip.reals[1].weight != 10 || vip.reals[3].weight != 0 {
+		t.Errorf("reals of the vip have been changed by proposal")
+	}
+}
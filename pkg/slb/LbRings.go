@@ -0,0 +1,151 @@
+package slb
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/cilium/ebpf"
+	"golang.org/x/exp/slices"
+
+	"github.com/cybwan/l4slb/pkg/bpf"
+	"github.com/cybwan/l4slb/pkg/bpf/adapter"
+)
+
+// rings of vips are placed into ch_rings at offsets written into vip_rings, so vips
+// could have rings of different sizes and ch_rings could be smaller than
+// max_vips * ch_ring_size. balancers built without vip_rings look ring of a vip up
+// at ch_ring_size * vip's num, then all rings have ch_ring_size positions
+
+// ringExtent is a range of positions of ch_rings
+type ringExtent struct {
+	offset uint32
+	size   uint32
+}
+
+// ringAllocator places rings of vips into ch_rings. free ranges are sorted by offset
+// and merged with their neighbours when a ring is released. a ring is placed into
+// the first free range it fits in
+type ringAllocator struct {
+	free []ringExtent
+}
+
+func newRingAllocator(size uint32) ringAllocator {
+	return ringAllocator{free: []ringExtent{{offset: 0, size: size}}}
+}
+
+// allocate returns offset of a ring of size positions, false if there is no free range for it
+func (a *ringAllocator) allocate(size uint32) (uint32, bool) {
+	for i := range a.free {
+		extent := &a.free[i]
+		if extent.size < size {
+			continue
+		}
+		offset := extent.offset
+		extent.offset += size
+		extent.size -= size
+		if extent.size == 0 {
+			a.free = slices.Delete(a.free, i, i+1)
+		}
+		return offset, true
+	}
+	return 0, false
+}
+
+// release returns positions of the ring at offset into free ranges
+func (a *ringAllocator) release(offset, size uint32) {
+	i := sort.Search(len(a.free), func(i int) bool {
+		return a.free[i].offset > offset
+	})
+	mergesPrev := i > 0 && a.free[i-1].offset+a.free[i-1].size == offset
+	mergesNext := i < len(a.free) && offset+size == a.free[i].offset
+	switch {
+	case mergesPrev && mergesNext:
+		a.free[i-1].size += size + a.free[i].size
+		a.free = slices.Delete(a.free, i, i+1)
+	case mergesPrev:
+		a.free[i-1].size += size
+	case mergesNext:
+		a.free[i].offset = offset
+		a.free[i].size += size
+	default:
+		a.free = slices.Insert(a.free, i, ringExtent{offset: offset, size: size})
+	}
+}
+
+// freePositions returns number of positions which are not used by rings of vips
+func (a *ringAllocator) freePositions() uint32 {
+	var free uint32
+	for _, extent := range a.free {
+		free += extent.size
+	}
+	return free
+}
+
+// getChRingsSize returns number of positions of ch_rings
+func (c *FlomeshLbConfig) getChRingsSize() uint32 {
+	if c.chRingsSize != 0 {
+		return c.chRingsSize
+	}
+	return c.maxVips * c.chRingSize
+}
+
+// PerVipRingsSupported returns true if loaded balancer looks rings of vips up by vip_rings,
+// so vips could be added with rings of sizes other than ch_ring_size
+func (lb *FlomeshLb) PerVipRingsSupported() bool {
+	return lb.config.testing || lb.bpfMaps.Has(adapter.VipRings)
+}
+
+// allocateRing returns offset in ch_rings of ring of ringSize positions of vip with vipNum
+func (lb *FlomeshLb) allocateRing(vipNum, ringSize uint32) (uint32, error) {
+	if !lb.PerVipRingsSupported() {
+		if ringSize != lb.config.chRingSize {
+			return 0, fmt.Errorf("balancer has been built without per vip rings, only ring size %d is supported",
+				lb.config.chRingSize)
+		}
+		offset := vipNum * lb.config.chRingSize
+		if uint64(offset)+uint64(ringSize) > uint64(lb.config.getChRingsSize()) {
+			return 0, fmt.Errorf("ring of vip %d does not fit into ch_rings of %d positions",
+				vipNum, lb.config.getChRingsSize())
+		}
+		return offset, nil
+	}
+	offset, ok := lb.rings.allocate(ringSize)
+	if !ok {
+		return 0, fmt.Errorf("no free range of %d positions in ch_rings, %d positions are free",
+			ringSize, lb.rings.freePositions())
+	}
+	return offset, nil
+}
+
+// releaseRing frees positions of ring of the deleted vip
+func (lb *FlomeshLb) releaseRing(vip *Vip) {
+	if lb.PerVipRingsSupported() {
+		lb.rings.release(vip.ringOffset, vip.chRingSize)
+	}
+}
+
+// freeRingPositions returns number of positions of ch_rings which are not used by rings of vips
+func (lb *FlomeshLb) freeRingPositions() uint32 {
+	if lb.PerVipRingsSupported() {
+		return lb.rings.freePositions()
+	}
+	var used uint32
+	for _, vip := range lb.vips {
+		used += vip.chRingSize
+	}
+	return lb.config.getChRingsSize() - used
+}
+
+// updateVipRingsMap writes offset and size of vip's ring into vip_rings
+func (lb *FlomeshLb) updateVipRingsMap(vip *Vip) bool {
+	if !lb.writesBpfMaps() || !lb.bpfMaps.Has(adapter.VipRings) {
+		return true
+	}
+	ring := bpf.VipRing{Offset: vip.ringOffset, Size: vip.chRingSize}
+	if err := lb.vipRings().Update(vip.num, ring, ebpf.UpdateAny); err != nil {
+		lb.lbStats.BpfFailedCalls++
+		log.Error().Msgf("can't update ring of vip %d, error: %v", vip.num, err)
+		return false
+	}
+	return true
+}
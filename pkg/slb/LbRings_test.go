@@ -0,0 +1,131 @@
+package slb
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/cilium/ebpf"
+
+	"github.com/cybwan/l4slb/pkg/bpf/adapter"
+)
+
+func TestRingAllocator(t *testing.T) {
+	rings := newRingAllocator(100)
+	for i, size := range []uint32{10, 20, 30} {
+		offset, ok := rings.allocate(size)
+		if expected := []uint32{0, 10, 30}[i]; !ok || offset != expected {
+			t.Fatalf("ring of %d positions is at %d (%v), expected %d", size, offset, ok, expected)
+		}
+	}
+	if free := rings.freePositions(); free != 40 {
+		t.Errorf("%d free positions, expected 40", free)
+	}
+	if _, ok := rings.allocate(41); ok {
+		t.Error("ring larger than free positions has been allocated")
+	}
+	if offset, ok := rings.allocate(40); !ok || offset != 60 || len(rings.free) != 0 {
+		t.Errorf("ring of the rest is at %d (%v), free ranges %v", offset, ok, rings.free)
+	}
+	if _, ok := rings.allocate(1); ok {
+		t.Error("ring has been allocated in full ch_rings")
+	}
+
+	// released ranges are merged with neighbours
+	rings.release(10, 20)
+	rings.release(60, 40)
+	if expected := []ringExtent{{10, 20}, {60, 40}}; !reflect.DeepEqual(rings.free, expected) {
+		t.Fatalf("free ranges %v, expected %v", rings.free, expected)
+	}
+	rings.release(0, 10)
+	if expected := []ringExtent{{0, 30}, {60, 40}}; !reflect.DeepEqual(rings.free, expected) {
+		t.Fatalf("free ranges %v after release of preceding ring, expected %v", rings.free, expected)
+	}
+	rings.release(30, 30)
+	if expected := []ringExtent{{0, 100}}; !reflect.DeepEqual(rings.free, expected) {
+		t.Fatalf("free ranges %v after release of ring between them, expected %v", rings.free, expected)
+	}
+}
+
+func TestRingAllocatorFragmentation(t *testing.T) {
+	rings := newRingAllocator(50)
+	offsets := make([]uint32, 5)
+	for i := range offsets {
+		offsets[i], _ = rings.allocate(10)
+	}
+	// every second ring is released, 30 positions are free in ranges of 10
+	for _, i := range []int{0, 2, 4} {
+		rings.release(offsets[i], 10)
+	}
+	if free := rings.freePositions(); free != 30 || len(rings.free) != 3 {
+		t.Fatalf("%d positions are free in %v, expected 30 in 3 ranges", free, rings.free)
+	}
+	if _, ok := rings.allocate(20); ok {
+		t.Error("ring of 20 positions has been allocated in ranges of 10")
+	}
+	// the first range a ring fits in is used
+	if offset, ok := rings.allocate(5); !ok || offset != 0 {
+		t.Errorf("ring of 5 positions is at %d (%v), expected 0", offset, ok)
+	}
+	// neighbours of released ring make a range large enough
+	rings.release(offsets[3], 10)
+	if offset, ok := rings.allocate(20); !ok || offset != 20 {
+		t.Errorf("ring of 20 positions is at %d (%v), expected 20", offset, ok)
+	}
+}
+
+// failingMap fails all updates of the map
+type failingMap struct {
+	*adapter.FakeMap
+}
+
+func (f failingMap) Update(key, value interface{}, flags ebpf.MapUpdateFlags) error {
+	return errors.New("update failed")
+}
+
+// replaceFakeMap registers backend made by wrap of fake map name instead of it
+func replaceFakeMap(t *testing.T, lb *FlomeshLb, name adapter.BpfMapName,
+	wrap func(*adapter.FakeMap) adapter.Backend) {
+	t.Helper()
+	backends := make(map[adapter.BpfMapName]adapter.Backend)
+	for _, registered := range lb.bpfMaps.Names() {
+		backends[registered] = lb.bpfMaps.Map(registered).Backend()
+	}
+	lb.bpfMaps.Reset()
+	for registered, backend := range backends {
+		if registered == name {
+			backend = wrap(backend.(*adapter.FakeMap))
+		}
+		if err := lb.bpfMaps.RegisterBackend(registered, backend); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestAddVipRollback(t *testing.T) {
+	for _, name := range []adapter.BpfMapName{adapter.VipRings, adapter.VipMap} {
+		lb := newFakeMapsLb(t)
+		replaceFakeMap(t, lb, name, func(fake *adapter.FakeMap) adapter.Backend {
+			return failingMap{fake}
+		})
+		freeVipNums, freePositions := lb.vipNums.Len(), lb.freeRingPositions()
+
+		vip := testVip(1)
+		if lb.AddVip(&vip, 0) {
+			t.Fatalf("vip has been added with failing %s", name)
+		}
+		if _, exists := lb.vips[vip]; exists {
+			t.Errorf("failed vip is kept after failure of %s", name)
+		}
+		if lb.vipNums.Len() != freeVipNums || lb.freeRingPositions() != freePositions {
+			t.Errorf("%d free vip nums and %d ring positions after failure of %s, expected %d and %d",
+				lb.vipNums.Len(), lb.freeRingPositions(), name, freeVipNums, freePositions)
+		}
+		// the number is given to the next vip
+		next := lb.vipNums.PopFront().(uint32)
+		lb.vipNums.PushFront(next)
+		if next != 0 {
+			t.Errorf("next vip would get num %d after failure of %s, expected 0", next, name)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package slb
 
 import (
 	"sort"
+	"sync"
 
 	"github.com/cybwan/l4slb/pkg/ch"
 )
@@ -10,21 +11,34 @@ type Vip struct {
 	num   uint32
 	flags uint32
 
+	// ring is at ringOffset in ch_rings
+	ringOffset uint32
 	chRingSize uint32
 	chRing     []int
-	chash      ch.ConsistentHash
+	// function chash has been made of
+	hashFunction ch.HashFunction
+	chash        ch.ConsistentHash
+	// buffers of ring generation: the next ring, swapped with chRing, and its delta
+	nextChRing []int
+	delta      []RealPos
 
 	reals map[uint32]*VipRealMeta
+
+	// guards ring, its generation and reals: rings are generated by goroutines of
+	// ModifyRealsForVips, while they could be read by GetHashRing or SimulateRealsChange
+	lock sync.Mutex
 }
 
-func NewVip(num, flags, ringSize uint32, hfunc ch.HashFunction) *Vip {
+func NewVip(num, flags, ringOffset, ringSize uint32, hfunc ch.HashFunction) *Vip {
 	vip := Vip{
-		num:        num,
-		flags:      flags,
-		chRingSize: ringSize,
-		chRing:     make([]int, ringSize),
-		chash:      ch.Make(hfunc),
-		reals:      make(map[uint32]*VipRealMeta),
+		num:          num,
+		flags:        flags,
+		ringOffset:   ringOffset,
+		chRingSize:   ringSize,
+		chRing:       make([]int, ringSize),
+		hashFunction: hfunc,
+		chash:        ch.Make(hfunc),
+		reals:        make(map[uint32]*VipRealMeta),
 	}
 	for i := uint32(0); i < ringSize; i++ {
 		vip.chRing[i] = -1
@@ -44,6 +58,10 @@ func (v *Vip) GetChRingSize() uint32 {
 	return v.chRingSize
 }
 
+func (v *Vip) GetRingOffset() uint32 {
+	return v.ringOffset
+}
+
 func (v *Vip) SetFlags(flags uint32) {
 	v.flags |= flags
 }
@@ -56,37 +74,75 @@ func (v *Vip) UnsetFlags(flags uint32) {
 	v.flags &= ^flags
 }
 
+func (v *Vip) GetHashFunction() ch.HashFunction {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	return v.hashFunction
+}
+
 func (v *Vip) SetHashFunction(hfunc ch.HashFunction) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	v.hashFunction = hfunc
 	v.chash = ch.Make(hfunc)
 }
 
+// getChRing returns copy of the ring and hash function it has been generated by
+func (v *Vip) getChRing() ([]int, ch.HashFunction) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	return append([]int(nil), v.chRing...), v.hashFunction
+}
+
+// calculateHashRing generates ring of endpoints and returns positions which have changed.
+// returned slice is reused by the next calculation of the vip's ring. the vip must be locked
 func (v *Vip) calculateHashRing(endpoints []ch.Endpoint) []RealPos {
-	delta := make([]RealPos, 0)
+	v.delta = v.delta[:0]
 	if len(endpoints) > 0 {
-		newChRing := v.chash.GenerateHashRing(endpoints, v.chRingSize)
+		if uint32(len(v.nextChRing)) != v.chRingSize {
+			v.nextChRing = make([]int, v.chRingSize)
+		}
+		v.chash.GenerateHashRingInto(v.nextChRing, endpoints)
 		// compare new and old ch rings. send back only delta between em.
 		for i := uint32(0); i < v.chRingSize; i++ {
-			if newChRing[i] != v.chRing[i] {
+			if v.nextChRing[i] != v.chRing[i] {
 				newPos := RealPos{
 					pos:  i,
-					real: uint32(newChRing[i]),
+					real: uint32(v.nextChRing[i]),
 				}
-				delta = append(delta, newPos)
-				v.chRing[i] = newChRing[i]
+				v.delta = append(v.delta, newPos)
 			}
 		}
+		v.chRing, v.nextChRing = v.nextChRing, v.chRing
 	}
-	return delta
+	return v.delta
 }
 
 func (v *Vip) batchRealsUpdate(ureals []UpdateReal) []RealPos {
+	v.lock.Lock()
+	defer v.lock.Unlock()
 	endpoints := v.getEndpoints(ureals)
 	return v.calculateHashRing(endpoints)
 }
 
+// recalculateHashRing generates the ring again from reals with non zero weight,
+// as batchRealsUpdate does, so drained reals do not get positions back
 func (v *Vip) recalculateHashRing() []RealPos {
-	reals := v.getRealsAndWeight()
-	return v.calculateHashRing(reals)
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	return v.calculateHashRing(v.currentEndpoints())
+}
+
+// simulateRealsUpdate generates ring of reals after ureals and compares it with the programmed one,
+// nil if no reals with non zero weight would be left. the vip is not changed
+func (v *Vip) simulateRealsUpdate(ureals []UpdateReal) *ch.RingSimulation {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	proposed := v.proposedEndpoints(ureals)
+	if len(proposed) == 0 {
+		return nil
+	}
+	return ch.SimulateFrom(v.chash, v.chRing, v.currentEndpoints(), proposed)
 }
 
 func (v *Vip) addReal(real ch.Endpoint) []RealPos {
@@ -110,6 +166,8 @@ func (v *Vip) delReal(realNum uint32) []RealPos {
 }
 
 func (v *Vip) getReals() []uint32 {
+	v.lock.Lock()
+	defer v.lock.Unlock()
 	realNums := make([]uint32, 0)
 	for k := range v.reals {
 		realNums = append(realNums, k)
@@ -117,7 +175,16 @@ func (v *Vip) getReals() []uint32 {
 	return realNums
 }
 
+// numReals returns number of reals of the vip, including ones with zero weight
+func (v *Vip) numReals() int {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	return len(v.reals)
+}
+
 func (v *Vip) getRealsAndWeight() []ch.Endpoint {
+	v.lock.Lock()
+	defer v.lock.Unlock()
 	endpoints := make(ch.EndpointSlice, len(v.reals))
 	i := 0
 	for n, r := range v.reals {
@@ -125,6 +192,7 @@ func (v *Vip) getRealsAndWeight() []ch.Endpoint {
 			Num:    n,
 			Weight: r.weight,
 			Hash:   r.hash,
+			Key:    r.key,
 		}
 		endpoints[i] = endpoint
 		i++
@@ -150,6 +218,7 @@ func (v *Vip) getEndpoints(ureals []UpdateReal) []ch.Endpoint {
 			if curWeight != ureal.updatedReal.Weight {
 				realMeta.weight = ureal.updatedReal.Weight
 				realMeta.hash = ureal.updatedReal.Hash
+				realMeta.key = ureal.updatedReal.Key
 				realsChanged = true
 			}
 		}
@@ -161,6 +230,7 @@ func (v *Vip) getEndpoints(ureals []UpdateReal) []ch.Endpoint {
 					Num:    n,
 					Weight: r.weight,
 					Hash:   r.hash,
+					Key:    r.key,
 				}
 				endpoints = append(endpoints, endpoint)
 			}
@@ -169,3 +239,41 @@ func (v *Vip) getEndpoints(ureals []UpdateReal) []ch.Endpoint {
 	}
 	return endpoints
 }
+
+// currentEndpoints returns endpoints the ring of the vip is generated from
+func (v *Vip) currentEndpoints() []ch.Endpoint {
+	return v.proposedEndpoints(nil)
+}
+
+// proposedEndpoints returns endpoints the ring of the vip would be generated from
+// after ureals are applied by batchRealsUpdate. the vip is not changed, it must be locked
+func (v *Vip) proposedEndpoints(ureals []UpdateReal) []ch.Endpoint {
+	reals := make(map[uint32]VipRealMeta, len(v.reals))
+	for n, r := range v.reals {
+		reals[n] = *r
+	}
+	for _, ureal := range ureals {
+		if ureal.action == DEL {
+			delete(reals, ureal.updatedReal.Num)
+		} else {
+			reals[ureal.updatedReal.Num] = VipRealMeta{
+				weight: ureal.updatedReal.Weight,
+				hash:   ureal.updatedReal.Hash,
+				key:    ureal.updatedReal.Key,
+			}
+		}
+	}
+	endpoints := make(ch.EndpointSlice, 0, len(reals))
+	for n, r := range reals {
+		if r.weight != 0 {
+			endpoints = append(endpoints, ch.Endpoint{
+				Num:    n,
+				Weight: r.weight,
+				Hash:   r.hash,
+				Key:    r.key,
+			})
+		}
+	}
+	sort.Sort(endpoints)
+	return endpoints
+}
@@ -0,0 +1,202 @@
+package slb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/cybwan/l4slb/pkg/ch"
+)
+
+// VipConfig is configuration of the vip as it is saved to vip_config_path:
+// the vip, its flags, hash function, ring size and reals
+type VipConfig struct {
+	Address      string       `yaml:"address"`
+	Port         uint16       `yaml:"port"`
+	Proto        uint8        `yaml:"proto"`
+	Flags        uint32       `yaml:"flags"`
+	HashFunction string       `yaml:"hash_function"`
+	RingSize     uint32       `yaml:"ring_size"`
+	Reals        []RealConfig `yaml:"reals"`
+}
+
+// RealConfig is configuration of the vip's real
+type RealConfig struct {
+	Address string `yaml:"address"`
+	Weight  uint32 `yaml:"weight"`
+	Flags   uint8  `yaml:"flags"`
+}
+
+// GetVipConfigs returns configuration of all vips, sorted by address, port and proto
+func (lb *FlomeshLb) GetVipConfigs() []VipConfig {
+	configs := make([]VipConfig, 0, len(lb.vips))
+	for key, entry := range lb.vips {
+		config := VipConfig{
+			Address:      key.Address,
+			Port:         key.Port,
+			Proto:        key.Proto,
+			Flags:        entry.GetFlags(),
+			HashFunction: entry.GetHashFunction().String(),
+			RingSize:     entry.GetChRingSize(),
+		}
+		for _, realId := range entry.getRealsAndWeight() {
+			raddr := lb.numToReals[realId.Num]
+			config.Reals = append(config.Reals, RealConfig{
+				Address: string(raddr),
+				Weight:  realId.Weight,
+				Flags:   lb.reals[raddr].flags,
+			})
+		}
+		sort.Slice(config.Reals, func(i, j int) bool {
+			return config.Reals[i].Address < config.Reals[j].Address
+		})
+		configs = append(configs, config)
+	}
+	sort.Slice(configs, func(i, j int) bool {
+		a, b := &configs[i], &configs[j]
+		if a.Address != b.Address {
+			return a.Address < b.Address
+		}
+		if a.Port != b.Port {
+			return a.Port < b.Port
+		}
+		return a.Proto < b.Proto
+	})
+	return configs
+}
+
+// RestoreVips adds vips saved to vip_config_path, with their flags, hash functions,
+// ring sizes and reals. nothing is restored if the file doesn't exist yet
+func (lb *FlomeshLb) RestoreVips() error {
+	if lb.config.vipConfigPath == "" {
+		return nil
+	}
+	configs, err := LoadVipConfigs(lb.config.vipConfigPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	// the file is not rewritten by vips being restored, so it still has all of them
+	// if one of the vips can't be restored
+	lb.restoringVips = true
+	defer func() { lb.restoringVips = false }()
+	for i := range configs {
+		if err = lb.restoreVip(&configs[i]); err != nil {
+			return fmt.Errorf("can't restore vips from %s: %w", lb.config.vipConfigPath, err)
+		}
+	}
+	log.Info().Msgf("restored %d vips from %s", len(configs), lb.config.vipConfigPath)
+	return nil
+}
+
+func (lb *FlomeshLb) restoreVip(config *VipConfig) error {
+	vip := VipKey{Address: config.Address, Port: config.Port, Proto: config.Proto}
+	hfunc, err := ch.ParseHashFunction(config.HashFunction)
+	if err != nil {
+		return fmt.Errorf("vip %s:%d:%d: %w", vip.Address, vip.Port, vip.Proto, err)
+	}
+	if !lb.AddVipWithHashFunction(&vip, config.Flags, config.RingSize, hfunc) {
+		return fmt.Errorf("can't add vip %s:%d:%d", vip.Address, vip.Port, vip.Proto)
+	}
+	reals := make([]NewReal, len(config.Reals))
+	for i, real := range config.Reals {
+		reals[i] = NewReal{Address: real.Address, Weight: real.Weight, Flags: real.Flags}
+	}
+	if len(reals) > 0 && !lb.ModifyRealsForVip(ADD, reals, &vip) {
+		return fmt.Errorf("can't add reals of vip %s:%d:%d", vip.Address, vip.Port, vip.Proto)
+	}
+	// invalid reals and ones which don't fit are skipped by ModifyRealsForVip
+	if restored := lb.vips[vip].numReals(); restored != len(reals) {
+		return fmt.Errorf("%d of %d reals of vip %s:%d:%d are added", restored, len(reals),
+			vip.Address, vip.Port, vip.Proto)
+	}
+	return nil
+}
+
+// saveVips writes configuration of all vips to vip_config_path after they have been
+// changed. the file is not replaced if configuration is the same as the one last written,
+// e.g. after reals which have been already there are added. a failure is logged only,
+// as the change is already applied
+func (lb *FlomeshLb) saveVips() {
+	if lb.config.vipConfigPath == "" || lb.restoringVips {
+		return
+	}
+	data, err := encodeVipConfigs(lb.GetVipConfigs())
+	if err == nil && bytes.Equal(data, lb.savedVips) {
+		return
+	}
+	if err == nil {
+		err = writeVipConfigs(lb.config.vipConfigPath, data)
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("can't save vips")
+		return
+	}
+	lb.savedVips = data
+}
+
+// LoadVipConfigs reads configuration of vips from YAML file
+func LoadVipConfigs(path string) ([]VipConfig, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't open vip config file: %w", err)
+	}
+	defer f.Close()
+
+	var configs []VipConfig
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+	if err = decoder.Decode(&configs); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("can't parse vip config file %s: %w", path, err)
+	}
+	return configs, nil
+}
+
+// SaveVipConfigs writes configuration of vips to YAML file. the file is replaced
+// by a complete new one, so it is never left written in part
+func SaveVipConfigs(path string, configs []VipConfig) error {
+	data, err := encodeVipConfigs(configs)
+	if err != nil {
+		return err
+	}
+	return writeVipConfigs(path, data)
+}
+
+func encodeVipConfigs(configs []VipConfig) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(configs); err != nil {
+		return nil, fmt.Errorf("can't encode vip config: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func writeVipConfigs(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("can't create vip config file: %w", err)
+	}
+	defer os.Remove(f.Name())
+	if _, err = f.Write(data); err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("can't write vip config file: %w", err)
+	}
+	if err = os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("can't replace vip config file: %w", err)
+	}
+	return nil
+}
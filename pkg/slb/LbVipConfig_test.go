@@ -0,0 +1,190 @@
+package slb
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cybwan/l4slb/pkg/ch"
+)
+
+// newPersistentLb creates balancer in testing mode which saves its vips to path
+func newPersistentLb(t *testing.T, path string) *FlomeshLb {
+	t.Helper()
+	lb := newTestLb(t)
+	lb.config.vipConfigPath = path
+	return lb
+}
+
+func loadVipConfigs(t *testing.T, path string) []VipConfig {
+	t.Helper()
+	configs, err := LoadVipConfigs(path)
+	if err != nil {
+		t.Fatalf("can't load saved vips: %v", err)
+	}
+	return configs
+}
+
+func TestAddVipWithHashFunction(t *testing.T) {
+	lb := newTestLb(t)
+	vip := testVip(1)
+	if !lb.AddVipWithHashFunction(&vip, 0, 7, ch.Ketama) {
+		t.Fatal("can't add vip")
+	}
+	if !lb.ModifyRealsForVip(ADD, testReals(3), &vip) {
+		t.Fatal("can't add reals")
+	}
+	info, err := lb.GetVipInfo(&vip)
+	if err != nil || info.HashFunction != ch.Ketama || info.RingSize != 7 {
+		t.Fatalf("vip info %+v (%v), expected ketama ring of 7 positions", info, err)
+	}
+	entry := lb.vips[vip]
+	ring := ch.Make(ch.Ketama).GenerateHashRing(entry.currentEndpoints(), 7)
+	if ch.ChangedPositions(ring, entry.chRing) != 0 {
+		t.Errorf("ring %v of the vip, ketama generates %v", entry.chRing, ring)
+	}
+}
+
+func TestSaveAndRestoreVips(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vips.yaml")
+	lb := newPersistentLb(t, path)
+
+	first, second, third := testVip(1), testVip(2), testVip(3)
+	if !lb.AddVipWithHashFunction(&first, 4, 7, ch.MaglevV2) {
+		t.Fatal("can't add vip")
+	}
+	if !lb.ModifyRealsForVip(ADD, testReals(3), &first) {
+		t.Fatal("can't add reals")
+	}
+	addTestVip(t, lb, second, testReals(5))
+	addTestVip(t, lb, third, testReals(2))
+	if !lb.ChangeHashFunctionForVip(&second, ch.Jump) || !lb.ModifyReal("10.0.0.2", 2, true) ||
+		!lb.DelVip(&third) {
+		t.Fatal("can't change vips")
+	}
+
+	// the file is rewritten by every change
+	saved := loadVipConfigs(t, path)
+	if !reflect.DeepEqual(saved, lb.GetVipConfigs()) {
+		t.Fatalf("saved vips %+v, expected %+v", saved, lb.GetVipConfigs())
+	}
+	if len(saved) != 2 {
+		t.Fatalf("%d vips are saved, expected 2", len(saved))
+	}
+	if saved[0].HashFunction != "maglev_v2" || saved[0].RingSize != 7 || saved[0].Flags != 4 ||
+		saved[1].HashFunction != "jump" || saved[1].RingSize != kTestChRingSize {
+		t.Errorf("saved vips %+v, expected maglev_v2 vip with ring of 7 and flags 4 and jump one", saved)
+	}
+	if real := saved[1].Reals[1]; real.Address != "10.0.0.2" || real.Weight != 10 || real.Flags != 2 {
+		t.Errorf("saved real %+v, expected 10.0.0.2 of weight 10 and flags 2", real)
+	}
+
+	restored := newPersistentLb(t, path)
+	if err := restored.RestoreVips(); err != nil {
+		t.Fatalf("can't restore vips: %v", err)
+	}
+	if !reflect.DeepEqual(restored.GetVipConfigs(), saved) {
+		t.Errorf("restored vips %+v, expected %+v", restored.GetVipConfigs(), saved)
+	}
+	for _, vip := range []VipKey{first, second} {
+		ring, _ := lb.vips[vip].getChRing()
+		if restoredRing, _ := restored.vips[vip].getChRing(); ch.ChangedPositions(ring, restoredRing) != 0 {
+			t.Errorf("restored ring of %v is %v, expected %v", vip, restoredRing, ring)
+		}
+	}
+	// restoration doesn't rewrite the file
+	if !reflect.DeepEqual(loadVipConfigs(t, path), saved) {
+		t.Error("file has been changed by restoration")
+	}
+}
+
+func TestRestoreVipsFailure(t *testing.T) {
+	dir := t.TempDir()
+	lb := newPersistentLb(t, filepath.Join(dir, "missing.yaml"))
+	if err := lb.RestoreVips(); err != nil || len(lb.vips) != 0 {
+		t.Errorf("%d vips are restored from missing file (%v)", len(lb.vips), err)
+	}
+
+	for _, test := range []struct {
+		name string
+		yaml string
+		err  string
+	}{
+		{"unknown field", "- address: 10.200.1.1\n  weight: 1\n", "weight"},
+		{"unknown hash function", "- address: 10.200.1.1\n  port: 80\n  proto: 6\n  hash_function: crc32\n",
+			"crc32"},
+		{"ring size", "- address: 10.200.1.1\n  port: 80\n  proto: 6\n  hash_function: maglev\n  ring_size: 8\n",
+			"can't add vip"},
+		{"invalid real", "- address: 10.200.1.1\n  port: 80\n  proto: 6\n  hash_function: maglev\n" +
+			"  reals:\n  - address: 10.0.0.1\n    weight: 1\n  - address: 10.0.0\n    weight: 1\n", "1 of 2 reals"},
+	} {
+		path := filepath.Join(dir, "vips.yaml")
+		if err := os.WriteFile(path, []byte(test.yaml), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		lb := newPersistentLb(t, path)
+		err := lb.RestoreVips()
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: error %v, expected one about %s", test.name, err, test.err)
+		}
+		// vips which are restored before the failure don't replace the file
+		if content, _ := os.ReadFile(path); string(content) != test.yaml {
+			t.Errorf("%s: file has been rewritten to %q", test.name, content)
+		}
+	}
+}
+
+func TestSaveVipsSkipsUnchanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vips.yaml")
+	lb := newPersistentLb(t, path)
+	vip := testVip(1)
+	addTestVip(t, lb, vip, testReals(3))
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("vips are not saved: %v", err)
+	}
+	// reals which are already there and flags which are already set don't change configuration
+	if !lb.ModifyRealsForVip(ADD, testReals(3), &vip) || !lb.ModifyVip(&vip, 0, true) {
+		t.Fatal("can't modify vip")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("unchanged vips have been written (%v)", err)
+	}
+	if !lb.ModifyRealsForVip(ADD, testReals(4), &vip) {
+		t.Fatal("can't add real")
+	}
+	if saved := loadVipConfigs(t, path); len(saved) != 1 || len(saved[0].Reals) != 4 {
+		t.Errorf("saved vips %+v, expected vip with 4 reals", saved)
+	}
+}
+
+func TestFailedRestoreKeepsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vips.yaml")
+	lb := newPersistentLb(t, path)
+	addTestVip(t, lb, testVip(1), testReals(2))
+	addTestVip(t, lb, testVip(2), testReals(3))
+	saved, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("vips are not saved: %v", err)
+	}
+
+	// the second vip can't be restored, as there is room for one vip only
+	config := NewFlomeshLbConfig()
+	config.testing = true
+	config.enableHc = false
+	config.maxVips = 1
+	config.chRingSize = kTestChRingSize
+	config.vipConfigPath = path
+	restored := NewFlomeshLb(config)
+	if err = restored.RestoreVips(); err == nil {
+		t.Fatal("vips are restored without room for the second one")
+	}
+	if len(restored.vips) != 1 {
+		t.Errorf("%d vips are restored, expected the first one", len(restored.vips))
+	}
+	if content, _ := os.ReadFile(path); !bytes.Equal(content, saved) {
+		t.Errorf("file with vips has been rewritten to %q by failed restoration", content)
+	}
+}
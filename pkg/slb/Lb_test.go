@@ -0,0 +1,179 @@
+package slb
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/cybwan/l4slb/pkg/bpf/adapter"
+	"github.com/cybwan/l4slb/pkg/ch"
+)
+
+const (
+	kTestMaxVips    = 64
+	kTestMaxReals   = 128
+	kTestChRingSize = 13
+)
+
+// newTestLb creates balancer in testing mode with small maps, so rings
+// of vips could be checked position by position
+func newTestLb(t *testing.T) *FlomeshLb {
+	t.Helper()
+	config := NewFlomeshLbConfig()
+	config.testing = true
+	config.enableHc = false
+	config.maxVips = kTestMaxVips
+	config.maxReals = kTestMaxReals
+	config.chRingSize = kTestChRingSize
+	return NewFlomeshLb(config)
+}
+
+// newFakeMapsLb creates balancer in testing mode whose maps are in-memory fakes
+func newFakeMapsLb(t *testing.T) *FlomeshLb {
+	t.Helper()
+	lb := newTestLb(t)
+	if err := lb.UseFakeMaps(); err != nil {
+		t.Fatalf("can't use fake maps: %v", err)
+	}
+	return lb
+}
+
+func testVip(n int) VipKey {
+	return VipKey{Address: fmt.Sprintf("10.200.1.%d", n), Port: 80, Proto: unix.IPPROTO_TCP}
+}
+
+func testReals(n int) []NewReal {
+	reals := make([]NewReal, 0, n)
+	for i := 1; i <= n; i++ {
+		reals = append(reals, NewReal{Address: fmt.Sprintf("10.0.0.%d", i), Weight: 10})
+	}
+	return reals
+}
+
+// addTestVip adds vip with reals, failing the test if they can't be added
+func addTestVip(t *testing.T, lb *FlomeshLb, vip VipKey, reals []NewReal) {
+	t.Helper()
+	if !lb.AddVip(&vip, 0) {
+		t.Fatalf("can't add vip %v", vip)
+	}
+	if !lb.ModifyRealsForVip(ADD, reals, &vip) {
+		t.Fatalf("can't add reals to vip %v", vip)
+	}
+}
+
+func TestBalancersSideBySide(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("loading bpf programs requires root")
+	}
+	newLb := func(ringSize uint32) *FlomeshLb {
+		config := NewFlomeshLbConfig()
+		config.enableHc = false
+		config.maxVips = kTestMaxVips
+		config.maxReals = kTestMaxReals
+		config.chRingSize = ringSize
+		config.LruSize = 1024
+		config.globalLruSize = 64
+		lb := NewFlomeshLb(config)
+		if _, err := lb.LoadBalancer(); err != nil {
+			t.Skipf("can't load balancer: %v", err)
+		}
+		t.Cleanup(lb.CloseBalancer)
+		if !lb.InitLruMaps() {
+			t.Fatal("can't create connection tables")
+		}
+		return lb
+	}
+	first := newLb(kTestChRingSize)
+	second := newLb(17)
+	if _, err := first.LoadBalancer(); err == nil {
+		t.Error("balancer has been loaded twice by one instance")
+	}
+	if first.balancerProg.Prog().FD() == second.balancerProg.Prog().FD() {
+		t.Fatal("instances share the balancer program")
+	}
+
+	addTestVip(t, first, testVip(1), testReals(2))
+	addTestVip(t, second, testVip(1), testReals(3))
+	addTestVip(t, second, testVip(2), testReals(3))
+	for _, test := range []struct {
+		lb      *FlomeshLb
+		vips    uint32
+		ringLen uint32
+	}{{first, 1, kTestMaxVips * kTestChRingSize}, {second, 2, kTestMaxVips * 17}} {
+		vipMap, err := test.lb.GetBpfMapStats(adapter.VipMap)
+		if err != nil || vipMap.CurrentEntries != test.vips {
+			t.Errorf("vip_map has %d entries (%v), expected %d", vipMap.CurrentEntries, err, test.vips)
+		}
+		if chRings, _ := test.lb.GetBpfMapStats(adapter.ChRings); chRings.MaxEntries != test.ringLen {
+			t.Errorf("ch_rings has %d positions, expected %d", chRings.MaxEntries, test.ringLen)
+		}
+	}
+
+	// the other instance keeps working when one is unloaded
+	first.CloseBalancer()
+	addTestVip(t, second, testVip(3), testReals(1))
+	if lru := second.GetLruMapsStats(); len(lru) == 0 || lru[0].MaxEntries == 0 {
+		t.Errorf("connection tables of the remaining instance %v", lru)
+	}
+}
+
+func TestRendezvousRealsLimit(t *testing.T) {
+	config := NewFlomeshLbConfig()
+	config.testing = true
+	config.enableHc = false
+	config.maxVips = kTestMaxVips
+	config.maxReals = 2 * ch.RendezvousMaxEndpoints
+	config.chRingSize = kTestChRingSize
+	lb := NewFlomeshLb(config)
+
+	vip := testVip(1)
+	reals := make([]NewReal, 0, ch.RendezvousMaxEndpoints+1)
+	for i := 0; i <= ch.RendezvousMaxEndpoints; i++ {
+		reals = append(reals, NewReal{Address: fmt.Sprintf("10.0.%d.%d", i/256, i%256), Weight: 10})
+	}
+	addTestVip(t, lb, vip, reals)
+	if !lb.ChangeHashFunctionForVip(&vip, ch.Maglev) {
+		t.Fatal("can't change hash function of the vip to maglev")
+	}
+	if lb.ChangeHashFunctionForVip(&vip, ch.Rendezvous) {
+		t.Errorf("vip with %d reals has been switched to rendezvous", len(reals))
+	}
+
+	other := testVip(2)
+	if !lb.AddVip(&other, 0) || !lb.ChangeHashFunctionForVip(&other, ch.Rendezvous) {
+		t.Fatal("can't add rendezvous vip")
+	}
+	lb.ModifyRealsForVip(ADD, reals, &other)
+	if n := len(lb.vips[other].getReals()); n != ch.RendezvousMaxEndpoints {
+		t.Errorf("rendezvous vip has %d reals, expected %d", n, ch.RendezvousMaxEndpoints)
+	}
+	// the real which hasn't been added is referenced only by the first vip
+	if last := lb.reals[IPAddress(reals[len(reals)-1].Address)]; last.refCount != 1 {
+		t.Errorf("real which is over the limit has %d references, expected 1", last.refCount)
+	}
+}
+
+func TestKetamaVipHashesRealAddresses(t *testing.T) {
+	lb := newTestLb(t)
+	vip := testVip(1)
+	reals := testReals(3)
+	addTestVip(t, lb, vip, reals)
+	if !lb.ChangeHashFunctionForVip(&vip, ch.Ketama) {
+		t.Fatal("can't change hash function of the vip to ketama")
+	}
+
+	// proxies name servers by address only, whatever numbers reals have got
+	endpoints := make([]ch.Endpoint, len(reals))
+	for i, real := range reals {
+		endpoints[i] = ch.Endpoint{Num: uint32(lb.GetIndexForReal(real.Address)), Weight: real.Weight, Key: real.Address}
+	}
+	expected := ch.Make(ch.Ketama).GenerateHashRing(endpoints, kTestChRingSize)
+	ring := lb.vips[vip].chRing
+	for pos := range expected {
+		if ring[pos] != expected[pos] {
+			t.Fatalf("ring of the vip %v, expected %v", ring, expected)
+		}
+	}
+}
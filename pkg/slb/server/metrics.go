@@ -0,0 +1,123 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/cybwan/l4slb/pkg/bpf/adapter"
+	"github.com/cybwan/l4slb/pkg/slb"
+)
+
+const (
+	kMetricsNamespace = "l4slb"
+)
+
+// GetMetricsHandler returns an HTTP handler which serves stats of the balancer
+// in prometheus text exposition format
+func (s *Server) GetMetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var buf bytes.Buffer
+		s.writeControllerMetrics(&buf)
+		s.writeBpfMapMetrics(&buf)
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			log.Error().Err(err).Msg("Error writing metrics")
+		}
+	})
+}
+
+func (s *Server) writeControllerMetrics(buf *bytes.Buffer) {
+	stats := s.lb.GetFlomeshLbStats()
+	counters := []struct {
+		name  string
+		help  string
+		value uint64
+	}{
+		{"bpf_failed_calls_total", "Number of failed bpf syscalls", stats.BpfFailedCalls},
+		{"addr_validation_failed_total", "Number of invalid addresses passed to the controller",
+			stats.AddrValidationFailed},
+		{"ring_recomputations_total", "Number of generations of ch rings", stats.RingRecomputations},
+		{"ring_generation_microseconds_total", "Time spent generating ch rings",
+			uint64(stats.RingGenerationTime.Microseconds())},
+		{"ring_batch_updates_total", "Number of batch updates of ch_rings", stats.RingBatchUpdates},
+		{"ring_positions_written_total", "Number of ch_rings positions written", stats.RingPositionsWritten},
+	}
+	for _, counter := range counters {
+		writeMetricHeaderOfType(buf, counter.name, counter.help, "counter")
+		fmt.Fprintf(buf, "%s_%s %d\n", kMetricsNamespace, counter.name, counter.value)
+	}
+	gauges := []struct {
+		name  string
+		help  string
+		value uint64
+	}{
+		{"max_ring_batch_update_size", "Size of the largest batch update of ch_rings",
+			stats.MaxRingBatchUpdateSize},
+		{"free_vip_nums", "Number of vips which still could be added", uint64(stats.FreeVipNums)},
+		{"free_real_nums", "Number of reals which still could be added", uint64(stats.FreeRealNums)},
+		{"free_hc_key_nums", "Number of hc keys which still could be added", uint64(stats.FreeHcKeyNums)},
+		{"free_ring_positions", "Number of positions of ch_rings not used by rings of vips",
+			uint64(stats.FreeRingPositions)},
+	}
+	for _, gauge := range gauges {
+		writeMetricHeader(buf, gauge.name, gauge.help)
+		fmt.Fprintf(buf, "%s_%s %d\n", kMetricsNamespace, gauge.name, gauge.value)
+	}
+}
+
+func (s *Server) writeBpfMapMetrics(buf *bytes.Buffer) {
+	allStats := s.lb.GetAllBpfMapStats()
+	names := make([]adapter.BpfMapName, 0, len(allStats))
+	for name := range allStats {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+
+	writeMetricHeader(buf, "bpf_map_max_entries", "Max number of entries of bpf map")
+	for _, name := range names {
+		stats := allStats[name]
+		writeMetric(buf, "bpf_map_max_entries", fmt.Sprintf("map=%q", name), uint64(stats.MaxEntries))
+	}
+	writeMetricHeader(buf, "bpf_map_current_entries", "Current number of entries of bpf map")
+	for _, name := range names {
+		stats := allStats[name]
+		writeMetric(buf, "bpf_map_current_entries", fmt.Sprintf("map=%q", name), uint64(stats.CurrentEntries))
+	}
+
+	lruStats := []struct {
+		name  string
+		stats []slb.LruMapStats
+	}{
+		{"lru", s.lb.GetLruMapsStats()},
+		{"global_lru", s.lb.GetGlobalLruMapsStats()},
+	}
+	writeMetricHeader(buf, "lru_map_max_entries", "Max number of entries of connection table of cpu")
+	for _, lru := range lruStats {
+		for _, stats := range lru.stats {
+			writeMetric(buf, "lru_map_max_entries", fmt.Sprintf("table=%q,cpu=\"%d\"", lru.name, stats.Cpu),
+				uint64(stats.MaxEntries))
+		}
+	}
+	writeMetricHeader(buf, "lru_map_current_entries", "Current number of entries of connection table of cpu")
+	for _, lru := range lruStats {
+		for _, stats := range lru.stats {
+			writeMetric(buf, "lru_map_current_entries", fmt.Sprintf("table=%q,cpu=\"%d\"", lru.name, stats.Cpu),
+				uint64(stats.CurrentEntries))
+		}
+	}
+}
+
+func writeMetricHeader(buf *bytes.Buffer, name string, help string) {
+	writeMetricHeaderOfType(buf, name, help, "gauge")
+}
+
+func writeMetricHeaderOfType(buf *bytes.Buffer, name string, help string, metricType string) {
+	fmt.Fprintf(buf, "# HELP %s_%s %s\n", kMetricsNamespace, name, help)
+	fmt.Fprintf(buf, "# TYPE %s_%s %s\n", kMetricsNamespace, name, metricType)
+}
+
+func writeMetric(buf *bytes.Buffer, name string, labels string, value uint64) {
+	fmt.Fprintf(buf, "%s_%s{%s} %d\n", kMetricsNamespace, name, labels, value)
+}
@@ -5,10 +5,15 @@ import (
 	"fmt"
 	"github.com/cybwan/l4slb/pkg/helpers"
 	"net"
+	"sort"
 
 	"github.com/cilium/ebpf/rlimit"
 
+	"github.com/cybwan/l4slb/pkg/bpf/adapter"
+	"github.com/cybwan/l4slb/pkg/bpf/monitor"
 	"github.com/cybwan/l4slb/pkg/bpf/progs/root"
+	"github.com/cybwan/l4slb/pkg/ch"
+	"github.com/cybwan/l4slb/pkg/gateway"
 	"github.com/cybwan/l4slb/pkg/pb"
 	"github.com/cybwan/l4slb/pkg/slb"
 )
@@ -22,28 +27,53 @@ const (
 type Server struct {
 	pb.UnimplementedSlbServiceServer
 
-	lb *slb.FlomeshLb
+	config *slb.FlomeshLbConfig
+	lb     *slb.FlomeshLb
 }
 
 // NewL4SlbControlServer creates a new L4Slb Control Service server
-func NewL4SlbControlServer() *Server {
-	server := Server{}
-	server.lb = slb.NewFlomeshLb(slb.NewFlomeshLbConfig())
+func NewL4SlbControlServer(config *slb.FlomeshLbConfig) *Server {
+	server := Server{config: config}
+	server.lb = slb.NewFlomeshLb(config)
 	return &server
 }
 
 // Start starts the L4Slb Control server
-func (s *Server) Start(ctx context.Context, cancel context.CancelFunc, dev string, port int) (func(), error) {
+func (s *Server) Start(ctx context.Context, cancel context.CancelFunc, port int) (func(), error) {
 	// Allow the current process to lock memory for eBPF resources.
 	if err := rlimit.RemoveMemlock(); err != nil {
 		log.Fatal().Err(err)
 	}
 
-	if err := root.Load(); err != nil {
+	rootProg, err := root.Load(adapter.NewRegistry(adapter.RootProg))
+	if err != nil {
 		log.Fatal().Err(err)
 	}
 
-	release := root.Attach(dev)
+	balancerProg, err := s.lb.LoadBalancer()
+	if err != nil {
+		log.Fatal().Msgf("loading balancer objects: %s", err)
+	}
+
+	detach := rootProg.Attach(s.config.MainInterface(), s.config.XdpAttachFlags(), s.config.RootMapPos(),
+		balancerProg.Prog())
+	release := func() {
+		s.lb.StopMonitor()
+		detach()
+		s.lb.CloseBalancer()
+	}
+
+	if !s.lb.InitLruMaps() {
+		return release, fmt.Errorf("error starting L4Slb Control Server: can't create connection tables")
+	}
+
+	if err = s.lb.RestoreVips(); err != nil {
+		return release, fmt.Errorf("error starting L4Slb Control Server: %w", err)
+	}
+
+	if !s.lb.StartMonitor() {
+		return release, fmt.Errorf("error starting L4Slb Control Server: can't start monitor")
+	}
 
 	grpcServer, lis, err := NewGrpc(ServerType, port)
 	if err != nil {
@@ -60,6 +90,30 @@ func (s *Server) Start(ctx context.Context, cancel context.CancelFunc, dev strin
 	return release, nil
 }
 
+// TrackDefaultGwMac follows the default gateway of dev and programs its mac address
+// into the balancer every time it changes. if the balancer supports multiple next hops,
+// macs of all next hops of ECMP default route are programmed as well
+func (s *Server) TrackDefaultGwMac(ctx context.Context, dev string) (*gateway.Tracker, error) {
+	tracker := gateway.NewTracker(dev, func(mac net.HardwareAddr) bool {
+		return s.lb.ChangeMac(mac)
+	})
+	if s.lb.NexthopsSupported() {
+		tracker.OnNexthopsChange(func(macs []net.HardwareAddr) bool {
+			nexthops := make([][]uint8, 0, len(macs))
+			for _, mac := range macs {
+				nexthops = append(nexthops, mac)
+			}
+			return s.lb.SetNexthops(nexthops)
+		})
+	} else {
+		log.Warn().Msg("balancer has been built without next hops support, only default's mac is tracked")
+	}
+	if err := tracker.Start(ctx); err != nil {
+		return nil, fmt.Errorf("error tracking default gateway of %s: %w", dev, err)
+	}
+	return tracker, nil
+}
+
 func (s *Server) ChangeMac(ctx context.Context, mac *pb.Mac) (*pb.Bool, error) {
 	response := new(pb.Bool)
 	macBytes, err := helpers.ConvertMacToUint(mac.Mac)
@@ -81,9 +135,54 @@ func (s *Server) GetMac(ctx context.Context, empty *pb.Empty) (*pb.Mac, error) {
 	return response, nil
 }
 
+func (s *Server) AddNexthop(ctx context.Context, mac *pb.Mac) (*pb.Bool, error) {
+	response := new(pb.Bool)
+	macBytes, err := helpers.ConvertMacToUint(mac.Mac)
+	if err != nil {
+		log.Error().Err(err)
+		response.Success = false
+		return response, nil
+	}
+	response.Success = s.lb.AddNexthop(macBytes)
+	return response, nil
+}
+
+func (s *Server) DelNexthop(ctx context.Context, mac *pb.Mac) (*pb.Bool, error) {
+	response := new(pb.Bool)
+	macBytes, err := helpers.ConvertMacToUint(mac.Mac)
+	if err != nil {
+		log.Error().Err(err)
+		response.Success = false
+		return response, nil
+	}
+	response.Success = s.lb.DelNexthop(macBytes)
+	return response, nil
+}
+
+func (s *Server) GetNexthops(ctx context.Context, empty *pb.Empty) (*pb.Nexthops, error) {
+	response := new(pb.Nexthops)
+	for pos, macBytes := range s.lb.GetNexthops() {
+		stats := s.lb.GetNexthopStats(uint32(pos))
+		response.Nexthops = append(response.Nexthops, &pb.Nexthop{
+			Mac:   net.HardwareAddr(macBytes).String(),
+			Stats: &pb.Stats{V1: stats.V1, V2: stats.V2},
+		})
+	}
+	return response, nil
+}
+
 func (s *Server) AddVip(ctx context.Context, meta *pb.VipMeta) (*pb.Bool, error) {
 	vk := translateVipObject(meta.GetVip())
-	success := s.lb.AddVip(vk, uint32(meta.Flags))
+	var success bool
+	if meta.HashFunction != "" {
+		hfunc, err := ch.ParseHashFunction(meta.HashFunction)
+		if err != nil {
+			return nil, err
+		}
+		success = s.lb.AddVipWithHashFunction(vk, uint32(meta.Flags), meta.RingSize, hfunc)
+	} else {
+		success = s.lb.AddVipWithRingSize(vk, uint32(meta.Flags), meta.RingSize)
+	}
 	response := new(pb.Bool)
 	response.Success = success
 	return response, nil
@@ -117,6 +216,30 @@ func (s *Server) GetVipFlags(ctx context.Context, vip *pb.Vip) (*pb.Flags, error
 	panic("implement me")
 }
 
+func (s *Server) ChangeHashFunction(ctx context.Context, vipHash *pb.VipHashFunction) (*pb.Bool, error) {
+	hfunc, err := ch.ParseHashFunction(vipHash.HashFunction)
+	if err != nil {
+		return nil, err
+	}
+	success := s.lb.ChangeHashFunctionForVip(translateVipObject(vipHash.GetVip()), hfunc)
+	response := new(pb.Bool)
+	response.Success = success
+	return response, nil
+}
+
+func (s *Server) GetVipInfo(ctx context.Context, vip *pb.Vip) (*pb.VipInfo, error) {
+	info, err := s.lb.GetVipInfo(translateVipObject(vip))
+	if err != nil {
+		return nil, err
+	}
+	return &pb.VipInfo{
+		Flags:        uint64(info.Flags),
+		HashFunction: info.HashFunction.String(),
+		RingSize:     info.RingSize,
+		Reals:        info.Reals,
+	}, nil
+}
+
 func (s *Server) GetRealFlags(ctx context.Context, r *pb.Real) (*pb.Flags, error) {
 	//TODO implement me
 	panic("implement me")
@@ -137,28 +260,67 @@ func (s *Server) DelRealForVip(ctx context.Context, vip *pb.RealForVip) (*pb.Boo
 }
 
 func (s *Server) ModifyRealsForVip(ctx context.Context, vip *pb.ModifiedRealsForVip) (*pb.Bool, error) {
-	var action slb.ModifyAction
-	nreals := make([]slb.NewReal, 0)
-
-	switch vip.Action {
-	case pb.Action_ADD:
-		action = slb.ADD
-	case pb.Action_DEL:
-		action = slb.DEL
-	default:
-		break
-	}
+	action, nreals := translateModifiedReals(vip)
 	vk := translateVipObject(vip.GetVip())
-	for _, r := range vip.GetReal().GetReals() {
-		nr := translateRealObject(r)
-		nreals = append(nreals, *nr)
-	}
 	success := s.lb.ModifyRealsForVip(action, nreals, vk)
 	response := new(pb.Bool)
 	response.Success = success
 	return response, nil
 }
 
+func (s *Server) ModifyRealsForVips(ctx context.Context, vips *pb.ModifiedRealsForVips) (*pb.Bool, error) {
+	changes := make([]slb.VipRealsChange, 0, len(vips.GetVips()))
+	for _, vip := range vips.GetVips() {
+		action, nreals := translateModifiedReals(vip)
+		changes = append(changes, slb.VipRealsChange{
+			Vip:    *translateVipObject(vip.GetVip()),
+			Action: action,
+			Reals:  nreals,
+		})
+	}
+	response := new(pb.Bool)
+	response.Success = s.lb.ModifyRealsForVips(changes)
+	return response, nil
+}
+
+func (s *Server) SimulateRealsChange(ctx context.Context, vip *pb.ModifiedRealsForVip) (*pb.RingSimulation, error) {
+	action, nreals := translateModifiedReals(vip)
+	sim, err := s.lb.SimulateRealsChange(action, nreals, translateVipObject(vip.GetVip()))
+	if err != nil {
+		return nil, err
+	}
+	return &pb.RingSimulation{
+		RingSize:         sim.RingSize,
+		ChangedPositions: sim.ChangedPositions,
+		ChangedFraction:  sim.ChangedFraction,
+		Before:           translateRealRingShares(sim.Before),
+		ImbalanceBefore:  sim.ImbalanceBefore,
+		After:            translateRealRingShares(sim.After),
+		ImbalanceAfter:   sim.ImbalanceAfter,
+	}, nil
+}
+
+func (s *Server) GetHashRing(ctx context.Context, request *pb.HashRingRequest) (*pb.HashRing, error) {
+	ring, err := s.lb.GetHashRing(translateVipObject(request.GetVip()), request.ReadKernel)
+	if err != nil {
+		return nil, err
+	}
+	positions := make([]int32, len(ring.Positions))
+	for pos, num := range ring.Positions {
+		positions[pos] = int32(num)
+	}
+	return &pb.HashRing{
+		RingSize:        uint32(len(ring.Positions)),
+		RingOffset:      ring.RingOffset,
+		HashFunction:    ring.HashFunction.String(),
+		Positions:       positions,
+		Addresses:       ring.Addresses,
+		KernelRead:      ring.KernelPositions != nil,
+		KernelPositions: ring.KernelPositions,
+		Mismatched:      ring.Mismatched,
+	}, nil
+}
+
 func (s *Server) GetRealsForVip(ctx context.Context, vip *pb.Vip) (*pb.Reals, error) {
 	//TODO implement me
 	panic("implement me")
@@ -189,6 +351,46 @@ func (s *Server) GetLruMissStats(ctx context.Context, empty *pb.Empty) (*pb.Stat
 	panic("implement me")
 }
 
+func (s *Server) SetLruMissStatsVip(ctx context.Context, vip *pb.Vip) (*pb.Bool, error) {
+	vk := translateVipObject(vip)
+	success := s.lb.SetLruMissStatsVip(vk)
+	response := new(pb.Bool)
+	response.Success = success
+	return response, nil
+}
+
+func (s *Server) ClearLruMissStatsVip(ctx context.Context, empty *pb.Empty) (*pb.Bool, error) {
+	success := s.lb.ClearLruMissStatsVip()
+	response := new(pb.Bool)
+	response.Success = success
+	return response, nil
+}
+
+func (s *Server) GetLruMissStatsForVip(ctx context.Context, empty *pb.Empty) (*pb.LruMissStatsForVip, error) {
+	response := new(pb.LruMissStatsForVip)
+	vip, tracked := s.lb.GetLruMissStatsVip()
+	if !tracked {
+		return response, nil
+	}
+	stats, err := s.lb.GetLruMissStatsForVip()
+	if err != nil {
+		return nil, err
+	}
+	response.Tracked = true
+	response.Vip = &pb.Vip{
+		Address:  vip.Address,
+		Port:     int32(vip.Port),
+		Protocol: int32(vip.Proto),
+	}
+	for _, stat := range stats {
+		response.Reals = append(response.Reals, &pb.RealLruMiss{
+			Address: stat.Real,
+			Misses:  stat.Misses,
+		})
+	}
+	return response, nil
+}
+
 func (s *Server) GetLruFallbackStats(ctx context.Context, empty *pb.Empty) (*pb.Stats, error) {
 	//TODO implement me
 	panic("implement me")
@@ -199,6 +401,120 @@ func (s *Server) GetIcmpTooBigStats(ctx context.Context, empty *pb.Empty) (*pb.S
 	panic("implement me")
 }
 
+func (s *Server) GetLruMapsStats(ctx context.Context, empty *pb.Empty) (*pb.LruMapsStats, error) {
+	response := new(pb.LruMapsStats)
+	response.Lru = translateLruMapsStats(s.lb.GetLruMapsStats())
+	response.GlobalLru = translateLruMapsStats(s.lb.GetGlobalLruMapsStats())
+	return response, nil
+}
+
+func (s *Server) GetBpfMapStats(ctx context.Context, empty *pb.Empty) (*pb.BpfMapsStats, error) {
+	response := new(pb.BpfMapsStats)
+	for name, stats := range s.lb.GetAllBpfMapStats() {
+		response.Maps = append(response.Maps, &pb.BpfMapStats{
+			Name:           string(name),
+			MaxEntries:     stats.MaxEntries,
+			CurrentEntries: stats.CurrentEntries,
+		})
+	}
+	sort.Slice(response.Maps, func(i, j int) bool {
+		return response.Maps[i].Name < response.Maps[j].Name
+	})
+	return response, nil
+}
+
+func (s *Server) GetControllerStats(ctx context.Context, empty *pb.Empty) (*pb.ControllerStats, error) {
+	stats := s.lb.GetFlomeshLbStats()
+	return &pb.ControllerStats{
+		BpfFailedCalls:         stats.BpfFailedCalls,
+		AddrValidationFailed:   stats.AddrValidationFailed,
+		RingRecomputations:     stats.RingRecomputations,
+		RingGenerationTimeUs:   uint64(stats.RingGenerationTime.Microseconds()),
+		RingBatchUpdates:       stats.RingBatchUpdates,
+		RingPositionsWritten:   stats.RingPositionsWritten,
+		MaxRingBatchUpdateSize: stats.MaxRingBatchUpdateSize,
+		FreeVipNums:            stats.FreeVipNums,
+		FreeRealNums:           stats.FreeRealNums,
+		FreeHcKeyNums:          stats.FreeHcKeyNums,
+		FreeRingPositions:      stats.FreeRingPositions,
+	}, nil
+}
+
+func (s *Server) GetFlowDebugInfo(ctx context.Context, flow *pb.Flow) (*pb.FlowDebugInfos, error) {
+	infos, err := s.lb.GetFlowDebugInfo(translateFlowObject(flow))
+	if err != nil {
+		return nil, err
+	}
+	response := new(pb.FlowDebugInfos)
+	response.FlowDebug = s.lb.HasFeature(slb.FlowDebug)
+	for _, info := range infos {
+		response.Infos = append(response.Infos, &pb.FlowDebugInfo{
+			Cpu:         info.Cpu,
+			InLru:       info.InLru,
+			Real:        info.Real,
+			Atime:       info.Atime,
+			InGlobalLru: info.InGlobalLru,
+			GlobalReal:  info.GlobalReal,
+			HasRoute:    info.HasRoute,
+			L4Hop:       info.L4Hop,
+			ThisHop:     info.ThisHop,
+		})
+	}
+	return response, nil
+}
+
+func (s *Server) GetMonitorStats(ctx context.Context, empty *pb.Empty) (*pb.MonitorStats, error) {
+	stats := s.lb.GetMonitorStats()
+	response := new(pb.MonitorStats)
+	response.Enabled = s.lb.HasFeature(slb.Introspection)
+	response.Limit = stats.Limit
+	response.Amount = stats.Amount
+	response.BufferFull = stats.BufferFull
+	return response, nil
+}
+
+func (s *Server) CapturePackets(request *pb.CaptureRequest, stream pb.SlbService_CapturePacketsServer) error {
+	var events []monitor.EventId
+	for _, name := range request.Events {
+		event, err := monitor.ParseEventId(name)
+		if err != nil {
+			return err
+		}
+		events = append(events, event)
+	}
+	var vip *slb.VipKey
+	if request.GetVip().GetAddress() != "" {
+		vip = translateVipObject(request.GetVip())
+	}
+	sub, backlog, err := s.lb.SubscribeMonitor(events, vip)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if dropped := s.lb.UnsubscribeMonitor(sub); dropped > 0 {
+			log.Warn().Msgf("%d packets have been dropped for slow capture client", dropped)
+		}
+	}()
+	for _, pkt := range backlog {
+		if err = stream.Send(translatePacket(pkt)); err != nil {
+			return err
+		}
+	}
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case pkt, ok := <-sub.Packets():
+			if !ok {
+				return nil
+			}
+			if err = stream.Send(translatePacket(pkt)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
 func (s *Server) AddHealthcheckerDst(ctx context.Context, healthcheck *pb.Healthcheck) (*pb.Bool, error) {
 	//TODO implement me
 	panic("implement me")
@@ -235,9 +551,75 @@ func translateRealObject(real *pb.Real) *slb.NewReal {
 	return nr
 }
 
+func translateModifiedReals(vip *pb.ModifiedRealsForVip) (slb.ModifyAction, []slb.NewReal) {
+	var action slb.ModifyAction
+	nreals := make([]slb.NewReal, 0)
+
+	switch vip.Action {
+	case pb.Action_ADD:
+		action = slb.ADD
+	case pb.Action_DEL:
+		action = slb.DEL
+	default:
+		break
+	}
+	for _, r := range vip.GetReal().GetReals() {
+		nr := translateRealObject(r)
+		nreals = append(nreals, *nr)
+	}
+	return action, nreals
+}
+
 func translateQuicRealObject(real *pb.QuicReal) *slb.QuicReal {
 	qr := new(slb.QuicReal)
 	qr.Address = real.GetAddress()
 	qr.Id = uint32(real.GetId())
 	return qr
 }
+
+func translateFlowObject(flow *pb.Flow) *slb.Flow {
+	return &slb.Flow{
+		Src:     flow.Src,
+		Dst:     flow.Dst,
+		SrcPort: uint16(flow.SrcPort),
+		DstPort: uint16(flow.DstPort),
+		Proto:   uint8(flow.Protocol),
+	}
+}
+
+func translatePacket(pkt *monitor.Packet) *pb.CapturedPacket {
+	return &pb.CapturedPacket{
+		Event:     pkt.Event.String(),
+		Timestamp: pkt.Timestamp.UnixNano(),
+		PktSize:   pkt.PktSize,
+		Data:      pkt.Data,
+	}
+}
+
+func translateLruMapsStats(stats []slb.LruMapStats) []*pb.LruMapStats {
+	lruStats := make([]*pb.LruMapStats, 0, len(stats))
+	for _, stat := range stats {
+		lruStats = append(lruStats, &pb.LruMapStats{
+			Cpu:            stat.Cpu,
+			NumaNode:       stat.NumaNode,
+			Fallback:       stat.Fallback,
+			MaxEntries:     stat.MaxEntries,
+			CurrentEntries: stat.CurrentEntries,
+		})
+	}
+	return lruStats
+}
+
+func translateRealRingShares(shares []slb.RealRingShare) []*pb.RealRingShare {
+	result := make([]*pb.RealRingShare, 0, len(shares))
+	for _, share := range shares {
+		result = append(result, &pb.RealRingShare{
+			Address:    share.Address,
+			Weight:     share.Weight,
+			Positions:  share.Positions,
+			Share:      share.Share,
+			IdealShare: share.IdealShare,
+		})
+	}
+	return result
+}
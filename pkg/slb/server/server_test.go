@@ -0,0 +1,232 @@
+package server
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cilium/ebpf/perf"
+	"google.golang.org/grpc"
+
+	"github.com/cybwan/l4slb/pkg/bpf/monitor"
+	"github.com/cybwan/l4slb/pkg/pb"
+	"github.com/cybwan/l4slb/pkg/slb"
+)
+
+const kTestTimeout = 5 * time.Second
+
+// fakeReader returns samples which have been put into it, as if the balancer had
+// submitted them into event pipe
+type fakeReader struct {
+	samples chan []byte
+	once    sync.Once
+}
+
+func (r *fakeReader) Read() (perf.Record, error) {
+	sample, ok := <-r.samples
+	if !ok {
+		return perf.Record{}, perf.ErrClosed
+	}
+	return perf.Record{RawSample: sample}, nil
+}
+
+func (r *fakeReader) Close() error {
+	r.once.Do(func() { close(r.samples) })
+	return nil
+}
+
+// submit puts sample of packet of pktSize bytes sent to dst:port into the reader.
+// 58 bytes of it are captured: headers and 20 bytes of payload
+func (r *fakeReader) submit(event monitor.EventId, dst string, port uint16, pktSize uint32) {
+	frame := make([]byte, 12)
+	frame = binary.BigEndian.AppendUint16(frame, 0x0800)
+	ip := make([]byte, 20)
+	ip[0], ip[9] = 0x45, 6
+	copy(ip[16:], net.ParseIP(dst).To4())
+	frame = append(frame, ip...)
+	frame = binary.BigEndian.AppendUint16(frame, 31337)
+	frame = binary.BigEndian.AppendUint16(frame, port)
+	frame = append(frame, make([]byte, 20)...)
+
+	sample := binary.LittleEndian.AppendUint32(nil, uint32(event))
+	sample = binary.LittleEndian.AppendUint32(sample, pktSize)
+	sample = binary.LittleEndian.AppendUint32(sample, uint32(len(frame)))
+	r.samples <- append(sample, frame...)
+}
+
+// fakeStream is the server side of capture stream, cancelled by cancel
+type fakeStream struct {
+	grpc.ServerStream
+	ctx     context.Context
+	cancel  context.CancelFunc
+	packets chan *pb.CapturedPacket
+}
+
+func newFakeStream() *fakeStream {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &fakeStream{ctx: ctx, cancel: cancel, packets: make(chan *pb.CapturedPacket, 16)}
+}
+
+func (s *fakeStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeStream) Send(pkt *pb.CapturedPacket) error {
+	s.packets <- pkt
+	return nil
+}
+
+func (s *fakeStream) next(t *testing.T) *pb.CapturedPacket {
+	t.Helper()
+	select {
+	case pkt := <-s.packets:
+		return pkt
+	case <-time.After(kTestTimeout):
+		t.Fatal("no packet has been sent")
+		return nil
+	}
+}
+
+// newCaptureServer creates server whose balancer hands out packets of monitor of the reader
+func newCaptureServer(t *testing.T, config monitor.Config) (*Server, *monitor.Monitor, *fakeReader) {
+	t.Helper()
+	reader := &fakeReader{samples: make(chan []byte, 16)}
+	config.Reader = reader
+	config.Pages, config.QueueSize = 1, 16
+	mon, err := monitor.New(config)
+	if err != nil {
+		t.Fatalf("can't create monitor: %v", err)
+	}
+	mon.Start()
+	t.Cleanup(mon.Stop)
+	s := &Server{config: slb.NewFlomeshLbConfig()}
+	s.lb = slb.NewFlomeshLb(s.config)
+	if err = s.lb.UseMonitor(mon); err != nil {
+		t.Fatal(err)
+	}
+	return s, mon, reader
+}
+
+// capture runs CapturePackets and returns channel of its result
+func capture(s *Server, request *pb.CaptureRequest, stream *fakeStream) <-chan error {
+	result := make(chan error, 1)
+	go func() { result <- s.CapturePackets(request, stream) }()
+	return result
+}
+
+func waitCapture(t *testing.T, result <-chan error) error {
+	t.Helper()
+	select {
+	case err := <-result:
+		return err
+	case <-time.After(kTestTimeout):
+		t.Fatal("capture has not returned")
+		return nil
+	}
+}
+
+// waitStored waits until the monitor has stored count packets
+func waitStored(t *testing.T, mon *monitor.Monitor, count uint32) {
+	t.Helper()
+	deadline := time.Now().Add(kTestTimeout)
+	for mon.GetStats().Amount < count {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d of %d packets are stored", mon.GetStats().Amount, count)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCapturePackets(t *testing.T) {
+	s, mon, reader := newCaptureServer(t, monitor.Config{
+		Storage:    monitor.Buffer,
+		BufferSize: 4096,
+		// headers are kept, so packets could be matched against the vip
+		SnapLen:   40,
+		PcktLimit: 2,
+		Events:    monitor.AllEvents(),
+	})
+	// packets stored before capture are sent first, if they match the request
+	reader.submit(monitor.PacketTooBig, "10.200.1.1", 80, 1001)
+	reader.submit(monitor.PacketTooBig, "10.200.1.2", 80, 1002)
+	waitStored(t, mon, 2)
+
+	stream := newFakeStream()
+	result := capture(s, &pb.CaptureRequest{
+		Events: []string{"packet_toobig"},
+		Vip:    &pb.Vip{Address: "10.200.1.1", Port: 80, Protocol: 6},
+	}, stream)
+	pkt := stream.next(t)
+	if pkt.PktSize != 1001 || pkt.Event != "packet_toobig" || len(pkt.Data) != 40 {
+		t.Errorf("stored packet %s of %d bytes (%d captured), expected packet_toobig of 1001 bytes "+
+			"truncated to snap length 40", pkt.Event, pkt.PktSize, len(pkt.Data))
+	}
+
+	// packets of other events and vips are not sent
+	reader.submit(monitor.TcpNonSynLruMiss, "10.200.1.1", 80, 1003)
+	reader.submit(monitor.PacketTooBig, "10.200.1.1", 81, 1004)
+	reader.submit(monitor.PacketTooBig, "10.200.1.3", 80, 1005)
+	// packets are sent after storage has reached the limit
+	reader.submit(monitor.PacketTooBig, "10.200.1.1", 80, 1006)
+	if pkt = stream.next(t); pkt.PktSize != 1006 || len(pkt.Data) != 40 {
+		t.Errorf("packet of %d bytes (%d captured) is sent, expected one of 1006 bytes truncated to 40",
+			pkt.PktSize, len(pkt.Data))
+	}
+	if stats := mon.GetStats(); stats.Amount != 2 {
+		t.Errorf("%d packets are stored, expected limit of 2", stats.Amount)
+	}
+
+	// client cancels the capture
+	stream.cancel()
+	if err := waitCapture(t, result); err != nil {
+		t.Errorf("capture cancelled by client has failed: %v", err)
+	}
+	select {
+	case pkt = <-stream.packets:
+		t.Errorf("packet of %d bytes is sent, expected none", pkt.PktSize)
+	default:
+	}
+}
+
+func TestCaptureStopsWithMonitor(t *testing.T) {
+	s, mon, reader := newCaptureServer(t, monitor.Config{Storage: monitor.Buffer, BufferSize: 4096,
+		Events: monitor.AllEvents()})
+	reader.submit(monitor.PacketTooBig, "10.200.1.1", 80, 1001)
+	waitStored(t, mon, 1)
+	stream := newFakeStream()
+	defer stream.cancel()
+	result := capture(s, &pb.CaptureRequest{}, stream)
+	// the stored packet is sent once the capture has subscribed
+	stream.next(t)
+	mon.Stop()
+	if err := waitCapture(t, result); err != nil {
+		t.Errorf("capture of stopped monitor has failed: %v", err)
+	}
+}
+
+func TestCapturePacketsErrors(t *testing.T) {
+	s, _, _ := newCaptureServer(t, monitor.Config{Storage: monitor.Pipe})
+	noMonitor := &Server{config: slb.NewFlomeshLbConfig()}
+	noMonitor.lb = slb.NewFlomeshLb(noMonitor.config)
+	for _, test := range []struct {
+		name    string
+		server  *Server
+		request *pb.CaptureRequest
+		err     string
+	}{
+		{"unknown event", s, &pb.CaptureRequest{Events: []string{"packet_toosmall"}}, "unknown event"},
+		{"invalid vip", s, &pb.CaptureRequest{Vip: &pb.Vip{Address: "10.200.1", Port: 80}}, "invalid vip"},
+		{"no monitor", noMonitor, &pb.CaptureRequest{}, "introspection is not enabled"},
+	} {
+		stream := newFakeStream()
+		err := waitCapture(t, capture(test.server, test.request, stream))
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: error %v, expected one about %s", test.name, err, test.err)
+		}
+		stream.cancel()
+	}
+}
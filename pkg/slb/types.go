@@ -1,13 +1,19 @@
 package slb
 
 import (
+	"fmt"
 	"github.com/cybwan/l4slb/pkg/bpf"
+	"github.com/cybwan/l4slb/pkg/bpf/adapter"
+	"github.com/cybwan/l4slb/pkg/bpf/monitor"
+	"github.com/cybwan/l4slb/pkg/bpf/progs/balancer"
 	"github.com/cybwan/l4slb/pkg/ch"
 	"github.com/cybwan/l4slb/pkg/logger"
 	"github.com/cybwan/l4slb/pkg/stack"
 	"go.eth-p.dev/goptional"
 	"hash/fnv"
 	"net"
+	"sync"
+	"time"
 )
 
 const (
@@ -22,6 +28,7 @@ const (
 	kDefaultMonitorQueueSize uint32 = 4096
 	kDefaultMonitorPcktLimit uint32 = 0
 	kDefaultMonitorSnapLen   uint32 = 128
+	kDefaultMonitorBufSize   uint32 = 1 << 20
 	kDefaultGlobalLruSize    uint32 = 100000
 	kNoFlags                 uint32 = 0
 	kDefaultLruSize          uint64 = 8000000
@@ -29,6 +36,7 @@ const (
 	kNoExternalMap       = ""
 	kDefaultHcInterface  = ""
 	kAddressNotSpecified = ""
+	kDefaultMonitorPath  = "/tmp/l4slb_pcap"
 )
 
 // RealMeta meta info for real
@@ -66,6 +74,28 @@ const (
 	PIPE
 )
 
+var (
+	pcapStorageFormatNames = map[PcapStorageFormat]string{
+		FILE:  "file",
+		IOBUF: "iobuf",
+		PIPE:  "pipe",
+	}
+)
+
+func (f PcapStorageFormat) String() string {
+	return pcapStorageFormatNames[f]
+}
+
+// ParsePcapStorageFormat returns storage format by its name: file, iobuf or pipe
+func ParsePcapStorageFormat(name string) (PcapStorageFormat, error) {
+	for format, formatName := range pcapStorageFormatNames {
+		if formatName == name {
+			return format, nil
+		}
+	}
+	return FILE, fmt.Errorf("unsupported storage format: %q", name)
+}
+
 type ModifyAction int8
 
 type AddressType int
@@ -107,10 +137,21 @@ func (v *VipKey) Equals(o *VipKey) bool {
 	return v.Address == o.Address && v.Port == o.Port && v.Proto == o.Proto
 }
 
+// VipInfo is configuration of the vip: its flags, hash function and size of its ring
+type VipInfo struct {
+	Flags        uint32
+	HashFunction ch.HashFunction
+	RingSize     uint32
+	// number of reals of the vip, including ones with zero weight
+	Reals uint32
+}
+
 // VipRealMeta is used by Vip class to store real's related metadata such as real's weight and hash
 type VipRealMeta struct {
 	weight uint32
 	hash   uint64
+	// address of the real, as hash functions which follow proxies name it
+	key string
 }
 
 // RealPos show on which position real w/ specified opaque id should be located on ch ring.
@@ -163,9 +204,26 @@ type FlomeshLb struct {
 	realNums  stack.Stack
 	hcKeyNums stack.Stack
 
+	//free positions of ch_rings, rings of vips are placed into
+	rings ringAllocator
+
 	//vector of control elements (such as default's mac; ifindexes etc)
 	ctlValues []bpf.CtlValue
 
+	//guards ctlValues and nexthops, default's mac could be changed by gateway tracker
+	ctlLock sync.Mutex
+
+	//macs of next hops, traffic is spread between them by flow's hash.
+	//position in the slice is the position in nexthop_macs map.
+	//it is the union of learned and configured next hops
+	nexthops [][]uint8
+
+	//next hops of the default route set with SetNexthops (by gateway tracker)
+	learnedNexthops [][]uint8
+
+	//next hops added with AddNexthop, they are kept when learned ones change
+	configuredNexthops [][]uint8
+
 	//dict of so_mark to real mapping; for healthchecking
 	hcReals map[uint32]IPAddress
 
@@ -184,6 +242,11 @@ type FlomeshLb struct {
 
 	vips map[VipKey]*Vip
 
+	//vips are not saved to vip_config_path while they are restored from it
+	restoringVips bool
+	//content last written to vip_config_path, it is not written again if vips are unchanged
+	savedVips []byte
+
 	lruMissStatsVip goptional.Optional[VipKey]
 
 	//Maps an HcKey to its id
@@ -217,12 +280,37 @@ type FlomeshLb struct {
 	 */
 	numaNodes []int32
 
+	//connection tables of cpus, inserted into lru_mapping
+	lruMaps []cpuLruMap
+
+	//global connection tables of forwarding cores, inserted into global_lru_maps
+	globalLruMaps []cpuLruMap
+
+	//maps of flow debug of cpus, inserted into flow_debug_maps
+	flowDebugMaps []cpuLruMap
+
 	//userspace library stats
 	lbStats FlomeshLbStats
 
+	//keys and values of batch updates of ch_rings, reused by programHashRing
+	ringKeys   []uint32
+	ringValues []uint32
+
+	// maps of loaded balancer and healthchecking programs
+	bpfMaps *adapter.Registry
+	hcMaps  *adapter.Registry
+	// maps are in-memory fakes, which are written even in testing mode
+	fakeMaps bool
+
+	//balancer loaded by LoadBalancer, nil until it is loaded
+	balancerProg *balancer.Program
+
 	//flag which indicates that introspection routines already started
 	introspectionStarted_ bool
 
+	//reader of introspection events, set if introspection routines are started
+	monitor *monitor.Monitor
+
 	//flag which indicates that bpf program was reloaded
 	progsReloaded bool
 
@@ -231,19 +319,41 @@ type FlomeshLb struct {
 }
 
 type FlomeshLbMonitorStats struct {
-	limit      uint32
-	amount     uint32
-	bufferFull uint32
+	// max number of packets written into storage, 0 - unlimited
+	Limit uint32
+	// number of packets written into storage
+	Amount uint32
+	// number of packets lost because perf buffer or monitor's queue were full
+	BufferFull uint32
 }
 
+// FlomeshLbBpfMapStats describes occupancy of bpf map
 type FlomeshLbBpfMapStats struct {
-	maxEntries     uint32
-	currentEntries uint32
+	MaxEntries     uint32
+	CurrentEntries uint32
 }
 
+// FlomeshLbStats are counters of the controller
 type FlomeshLbStats struct {
-	bpfFailedCalls       uint64
-	addrValidationFailed uint64
+	BpfFailedCalls       uint64
+	AddrValidationFailed uint64
+
+	// number of generations of vips' ch rings
+	RingRecomputations uint64
+	// time spent generating ch rings
+	RingGenerationTime time.Duration
+	// number of batch updates of ch_rings and positions written by them
+	RingBatchUpdates     uint64
+	RingPositionsWritten uint64
+	// size of the largest batch update of ch_rings
+	MaxRingBatchUpdateSize uint64
+
+	// number of free vip, real and hc key numbers and of free positions
+	// of ch_rings; filled by GetFlomeshLbStats
+	FreeVipNums       uint32
+	FreeRealNums      uint32
+	FreeHcKeyNums     uint32
+	FreeRingPositions uint32
 }
 
 type HealthCheckProgStats struct {
@@ -0,0 +1,99 @@
+package tester
+
+import (
+	"encoding/binary"
+	"net"
+)
+
+// packets as they are sent by the balancer. sources of outer headers are derived
+// from source address and port of the flow as in pckt_encap.h, which stores
+// them as host order words. tester, as the balancer, runs on little endian hosts
+
+// EncapV4 returns pkt encapsulated into ipip towards ipv4 real, as the balancer sends it
+// to gateway gwMac
+func EncapV4(pkt []byte, gwMac net.HardwareAddr, real net.IP) []byte {
+	inner, flow := parseFlow(pkt)
+	src := net.IPv4(kIpipV4Prefix0, kIpipV4Prefix1, flow.src[len(flow.src)-2]^flow.sport[1],
+		flow.src[len(flow.src)-1]^flow.sport[0])
+	eth := ethHeader(Eth{Src: flow.dstMac, Dst: gwMac}, kEthPIPv4)
+	outer := IP{Src: src, Dst: real, Tos: flow.tos}
+	return append(eth, ipv4Header(outer, ProtoIPIP, len(inner), inner)...)
+}
+
+// EncapV6 returns pkt encapsulated into ip(6)ip6 towards ipv6 real, as the balancer sends it
+// to gateway gwMac
+func EncapV6(pkt []byte, gwMac net.HardwareAddr, real net.IP) []byte {
+	inner, flow := parseFlow(pkt)
+	// 0100::/64, IPIP_V6_PREFIX* of balancer_consts.h
+	src := make(net.IP, net.IPv6len)
+	src[0] = 1
+	suffix := flow.src[len(flow.src)-4:]
+	copy(src[12:], []byte{suffix[0] ^ flow.sport[0], suffix[1] ^ flow.sport[1], suffix[2], suffix[3]})
+	proto := ProtoIPv6
+	if flow.v4 {
+		proto = ProtoIPIP
+	}
+	eth := ethHeader(Eth{Src: flow.dstMac, Dst: gwMac}, kEthPIPv6)
+	outer := IP{Src: src, Dst: real, Tos: flow.tos}
+	return append(eth, ipv6Header(outer, proto, len(inner), inner)...)
+}
+
+// EchoReply returns reply to icmp or icmpv6 echo request, as the balancer sends it back
+func EchoReply(pkt []byte) []byte {
+	reply := append([]byte(nil), pkt...)
+	copy(reply[0:6], pkt[6:12])
+	copy(reply[6:12], pkt[0:6])
+	ip := reply[kEthHdrLen:]
+	if binary.BigEndian.Uint16(pkt[12:]) == kEthPIPv4 {
+		ihl := int(ip[0]&0x0f) * 4
+		copy(ip[12:16], pkt[kEthHdrLen+16:kEthHdrLen+20])
+		copy(ip[16:20], pkt[kEthHdrLen+12:kEthHdrLen+16])
+		ip[8] = kDefaultTtl
+		binary.BigEndian.PutUint16(ip[10:], 0)
+		binary.BigEndian.PutUint16(ip[10:], checksum(nil, ip[:ihl]))
+		icmp := ip[ihl:]
+		icmp[0] = IcmpEchoReply
+		binary.BigEndian.PutUint16(icmp[2:], 0)
+		binary.BigEndian.PutUint16(icmp[2:], checksum(nil, icmp))
+		return reply
+	}
+	copy(ip[8:24], pkt[kEthHdrLen+24:kEthHdrLen+40])
+	copy(ip[24:40], pkt[kEthHdrLen+8:kEthHdrLen+24])
+	ip[7] = kDefaultTtl
+	icmp := ip[kIPv6HdrLen:]
+	icmp[0] = IcmpV6EchoReply
+	binary.BigEndian.PutUint16(icmp[2:], 0)
+	pseudo := pseudoHeaderV6(net.IP(ip[8:24]), net.IP(ip[24:40]), ProtoIcmpV6, len(icmp))
+	binary.BigEndian.PutUint16(icmp[2:], checksum(pseudo, icmp))
+	return reply
+}
+
+type flowFields struct {
+	v4     bool
+	dstMac net.HardwareAddr
+	src    net.IP
+	// source port in network order
+	sport []byte
+	tos   uint8
+}
+
+// parseFlow returns ip packet of the frame and fields which go into outer headers
+func parseFlow(pkt []byte) ([]byte, flowFields) {
+	inner := pkt[kEthHdrLen:]
+	flow := flowFields{dstMac: net.HardwareAddr(pkt[0:6])}
+	if binary.BigEndian.Uint16(pkt[12:]) == kEthPIPv4 {
+		ihl := int(inner[0]&0x0f) * 4
+		flow.v4 = true
+		flow.tos = inner[1]
+		flow.src = net.IP(inner[12:16])
+		flow.sport = inner[ihl : ihl+2]
+		// ipv4 header could be followed by padding of ethernet frame
+		inner = inner[:binary.BigEndian.Uint16(inner[2:])]
+	} else {
+		flow.tos = inner[0]<<4 | inner[1]>>4
+		flow.src = net.IP(inner[8:24])
+		flow.sport = inner[kIPv6HdrLen : kIPv6HdrLen+2]
+		inner = inner[:kIPv6HdrLen+int(binary.BigEndian.Uint16(inner[4:]))]
+	}
+	return inner, flow
+}
@@ -0,0 +1,221 @@
+package tester
+
+import (
+	"encoding/binary"
+	"net"
+	"time"
+
+	"github.com/cybwan/l4slb/pkg/slb"
+)
+
+// flags of vips, see F_* of balancer_consts.h
+const (
+	VipHashNoSrcPort uint32 = 1 << 0
+	VipLruBypass     uint32 = 1 << 1
+	VipQuic          uint32 = 1 << 2
+	VipHashDPortOnly uint32 = 1 << 3
+)
+
+// Fixture is a packet passed through the balancer and expected result of the program
+type Fixture struct {
+	Description     string
+	Input           []byte
+	ExpectedVerdict XdpAction
+	// packet which the program is expected to leave in the buffer, not checked if nil
+	ExpectedOutput []byte
+	// time of the packet in pcap file it has been read from, if any
+	Timestamp time.Time
+}
+
+// VipSetup is a vip and its reals, each fixture's vip has a single real,
+// so packets are encapsulated towards known real whatever the hash of the flow is
+type VipSetup struct {
+	Key   slb.VipKey
+	Flags uint32
+	// size of the vip's ring, balancer's default if 0
+	RingSize uint32
+	Reals    []slb.NewReal
+}
+
+// Setup is configuration of the balancer fixtures are built for
+type Setup struct {
+	GatewayMac net.HardwareAddr
+	Vips       []VipSetup
+}
+
+var (
+	kClientMac  = mustParseMac("02:00:00:00:00:01")
+	kLbMac      = mustParseMac("02:00:00:00:00:02")
+	kGatewayMac = mustParseMac("02:00:00:00:00:03")
+
+	kClientV4 = net.ParseIP("192.168.1.42")
+	kClientV6 = net.ParseIP("fc00:2::42")
+
+	kVipTcpV4     = net.ParseIP("10.200.1.1")
+	kVipQuicV4    = net.ParseIP("10.200.1.2")
+	kVipAnyPortV4 = net.ParseIP("10.200.1.3")
+	kVipV6RealV4  = net.ParseIP("10.200.1.4")
+	kVipV6        = net.ParseIP("fc00:1::1")
+
+	kRealTcpV4     = net.ParseIP("10.0.0.1")
+	kRealUdpV4     = net.ParseIP("10.0.0.2")
+	kRealQuicV4    = net.ParseIP("10.0.0.3")
+	kRealAnyPortV4 = net.ParseIP("10.0.0.4")
+	kRealV6OfV4    = net.ParseIP("fc00::4")
+	kRealTcpV6     = net.ParseIP("fc00::1")
+	kRealUdpV6     = net.ParseIP("fc00::2")
+)
+
+// DefaultSetup returns vips and reals DefaultFixtures are built for
+func DefaultSetup() *Setup {
+	vip := func(addr net.IP, port uint16, proto uint8, flags uint32, real net.IP) VipSetup {
+		return VipSetup{
+			Key:   slb.VipKey{Address: addr.String(), Port: port, Proto: proto},
+			Flags: flags,
+			Reals: []slb.NewReal{{Address: real.String(), Weight: 1}},
+		}
+	}
+	return &Setup{
+		GatewayMac: kGatewayMac,
+		Vips: []VipSetup{
+			vip(kVipTcpV4, 80, ProtoTcp, 0, kRealTcpV4),
+			vip(kVipTcpV4, 80, ProtoUdp, 0, kRealUdpV4),
+			vip(kVipQuicV4, 443, ProtoUdp, VipQuic, kRealQuicV4),
+			vip(kVipAnyPortV4, 0, ProtoTcp, 0, kRealAnyPortV4),
+			vip(kVipV6RealV4, 80, ProtoTcp, 0, kRealV6OfV4),
+			vip(kVipV6, 80, ProtoTcp, 0, kRealTcpV6),
+			vip(kVipV6, 80, ProtoUdp, 0, kRealUdpV6),
+		},
+	}
+}
+
+// DefaultFixtures returns packets of all kinds the balancer handles: encapsulation towards
+// ipv4 and ipv6 reals, echo replies and packets which are passed to the kernel or dropped
+func DefaultFixtures() []Fixture {
+	toLb := Eth{Src: kClientMac, Dst: kLbMac}
+	payload := []byte("katran-like test payload")
+	v4 := func(dst net.IP) IP {
+		return IP{Src: kClientV4, Dst: dst}
+	}
+	v6 := func(dst net.IP) IP {
+		return IP{Src: kClientV6, Dst: dst}
+	}
+	syn := func(dport uint16) *Tcp {
+		return &Tcp{SrcPort: 31337, DstPort: dport, Seq: 1, Flags: TcpSyn}
+	}
+	ack := func(dport uint16) *Tcp {
+		return &Tcp{SrcPort: 31337, DstPort: dport, Seq: 2, Ack: 1, Flags: TcpAck | TcpPsh}
+	}
+	udp := func(dport uint16) *Udp {
+		return &Udp{SrcPort: 31337, DstPort: dport}
+	}
+
+	var fixtures []Fixture
+	encap := func(description string, pkt []byte, real net.IP) {
+		fixture := Fixture{Description: description, Input: pkt, ExpectedVerdict: XdpTx}
+		if real.To4() != nil {
+			fixture.ExpectedOutput = EncapV4(pkt, kGatewayMac, real)
+		} else {
+			fixture.ExpectedOutput = EncapV6(pkt, kGatewayMac, real)
+		}
+		fixtures = append(fixtures, fixture)
+	}
+	verdict := func(description string, pkt []byte, action XdpAction, output []byte) {
+		fixtures = append(fixtures, Fixture{
+			Description:     description,
+			Input:           pkt,
+			ExpectedVerdict: action,
+			ExpectedOutput:  output,
+		})
+	}
+
+	encap("ipv4 tcp syn to vip, ipv4 real: ipip",
+		Build(toLb, v4(kVipTcpV4), syn(80), nil), kRealTcpV4)
+	encap("ipv4 tcp data of known flow: ipip to the same real",
+		Build(toLb, v4(kVipTcpV4), ack(80), payload), kRealTcpV4)
+	encap("ipv4 udp to vip: ipip",
+		Build(toLb, v4(kVipTcpV4), udp(80), payload), kRealUdpV4)
+	tos := v4(kVipTcpV4)
+	tos.Tos = 0x8c
+	encap("ipv4 tcp with tos: tos is copied into ipip header",
+		Build(toLb, tos, &Tcp{SrcPort: 1024, DstPort: 80, Flags: TcpSyn}, nil), kRealTcpV4)
+	encap("ipv4 tcp to any port of vip without port",
+		Build(toLb, v4(kVipAnyPortV4), syn(8080), nil), kRealAnyPortV4)
+	encap("ipv4 tcp to vip, ipv6 real: ipip in ipv6",
+		Build(toLb, v4(kVipV6RealV4), syn(80), nil), kRealV6OfV4)
+	encap("ipv4 quic initial to quic vip: ch",
+		Build(toLb, v4(kVipQuicV4), udp(443), quicInitial()), kRealQuicV4)
+	encap("ipv4 quic short header of unknown server id: ch",
+		Build(toLb, v4(kVipQuicV4), udp(443), quicShortHeader(0x4242)), kRealQuicV4)
+	encap("ipv6 tcp syn to vip: ip6ip6",
+		Build(toLb, v6(kVipV6), syn(80), nil), kRealTcpV6)
+	encap("ipv6 tcp data of known flow: ip6ip6 to the same real",
+		Build(toLb, v6(kVipV6), ack(80), payload), kRealTcpV6)
+	encap("ipv6 udp to vip: ip6ip6",
+		Build(toLb, v6(kVipV6), udp(80), payload), kRealUdpV6)
+	tclass := v6(kVipV6)
+	tclass.Tos = 0xb8
+	encap("ipv6 tcp with traffic class: it is copied into ip6ip6 header",
+		Build(toLb, tclass, &Tcp{SrcPort: 1024, DstPort: 80, Flags: TcpSyn}, nil), kRealTcpV6)
+
+	echo := Build(toLb, v4(kVipTcpV4), &Icmp{Type: IcmpEchoRequest, Id: 1, Seq: 1}, payload)
+	verdict("ipv4 icmp echo request: echo reply", echo, XdpTx, EchoReply(echo))
+	echo6 := Build(toLb, v6(kVipV6), &Icmp{Type: IcmpV6EchoRequest, Id: 1, Seq: 1}, payload)
+	verdict("ipv6 icmp echo request: echo reply", echo6, XdpTx, EchoReply(echo6))
+
+	pass := func(description string, pkt []byte) {
+		verdict(description, pkt, XdpPass, pkt)
+	}
+	pass("ipv4 tcp to address which is not a vip: pass",
+		Build(toLb, v4(net.ParseIP("10.200.2.1")), syn(80), nil))
+	pass("ipv4 tcp to port of vip which is not balanced: pass",
+		Build(toLb, v4(kVipTcpV4), syn(81), nil))
+	pass("ipv6 udp to address which is not a vip: pass",
+		Build(toLb, v6(net.ParseIP("fc00:1::2")), udp(80), payload))
+	pass("ipv4 gre to vip: pass",
+		BuildRaw(toLb, v4(kVipTcpV4), 47, make([]byte, 8)))
+	pass("arp: pass", arpRequest(toLb))
+
+	drop := func(description string, pkt []byte) {
+		verdict(description, pkt, XdpDrop, nil)
+	}
+	fragment := v4(kVipTcpV4)
+	fragment.MoreFrags = true
+	drop("ipv4 fragment: drop", Build(toLb, fragment, syn(80), payload))
+	options := v4(kVipTcpV4)
+	options.Options = []byte{1, 1, 1, 0}
+	drop("ipv4 with options: drop", Build(toLb, options, syn(80), nil))
+	drop("ipv6 fragment: drop", BuildRaw(toLb, v6(kVipV6), ProtoFragment, make([]byte, 16)))
+	truncated := Build(toLb, v4(kVipTcpV4), syn(80), nil)
+	drop("ipv4 truncated tcp header: drop", truncated[:kEthHdrLen+kIPv4HdrLen+10])
+	return fixtures
+}
+
+// quicInitial returns long header of client's initial packet, padded
+func quicInitial() []byte {
+	pkt := []byte{0xc0, 0xfa, 0xce, 0xb0, 0x02, 8}
+	pkt = append(pkt, 1, 2, 3, 4, 5, 6, 7, 8)
+	pkt = append(pkt, 0)
+	return append(pkt, make([]byte, 64)...)
+}
+
+// quicShortHeader returns short header packet with connection id of version 1 with serverId
+func quicShortHeader(serverId uint16) []byte {
+	pkt := []byte{0x40, 0x40 | uint8(serverId>>10), uint8(serverId >> 2), uint8(serverId << 6)}
+	pkt = append(pkt, 5, 6, 7, 8)
+	return append(pkt, make([]byte, 32)...)
+}
+
+func arpRequest(eth Eth) []byte {
+	pkt := ethHeader(Eth{Src: eth.Src, Dst: net.HardwareAddr{0xff, 0xff, 0xff, 0xff, 0xff, 0xff}}, 0x0806)
+	arp := make([]byte, 28)
+	binary.BigEndian.PutUint16(arp[0:], 1)
+	binary.BigEndian.PutUint16(arp[2:], kEthPIPv4)
+	arp[4] = 6
+	arp[5] = 4
+	binary.BigEndian.PutUint16(arp[6:], 1)
+	copy(arp[8:14], eth.Src)
+	copy(arp[14:18], kClientV4.To4())
+	copy(arp[24:28], kVipTcpV4.To4())
+	return append(pkt, arp...)
+}
@@ -0,0 +1,136 @@
+package tester
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cybwan/l4slb/pkg/slb"
+)
+
+var update = flag.Bool("update", false, "Rewrite golden files of testdata from built-in fixtures")
+
+// kGoldenFixtures keeps packets of DefaultFixtures as bytes, one block per fixture:
+// "# description", "verdict", "input" and, if the output is checked, "output" lines.
+// the file is a snapshot of the tester's own fixtures, outputs included: -update rewrites
+// it from DefaultFixtures, i.e. from encap.go, so it is no independent reference for the
+// balancer. a change of the tester shows up as a diff of the file to be reviewed, and
+// TestBalancerGolden checks that the balancer still produces the snapshotted outputs
+var kGoldenFixtures = filepath.Join("testdata", "fixtures.golden")
+
+func writeGolden(fixtures []Fixture) []byte {
+	var buf bytes.Buffer
+	for i := range fixtures {
+		fixture := &fixtures[i]
+		fmt.Fprintf(&buf, "# %s\n", fixture.Description)
+		fmt.Fprintf(&buf, "verdict %s\n", fixture.ExpectedVerdict)
+		fmt.Fprintf(&buf, "input %s\n", hex.EncodeToString(fixture.Input))
+		if fixture.ExpectedOutput != nil {
+			fmt.Fprintf(&buf, "output %s\n", hex.EncodeToString(fixture.ExpectedOutput))
+		}
+		buf.WriteString("\n")
+	}
+	return buf.Bytes()
+}
+
+func readGolden(t *testing.T) []Fixture {
+	t.Helper()
+	data, err := os.ReadFile(kGoldenFixtures)
+	if err != nil {
+		t.Fatalf("can't read golden fixtures: %v", err)
+	}
+	var fixtures []Fixture
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if text == "" {
+			continue
+		}
+		if strings.HasPrefix(text, "# ") {
+			fixtures = append(fixtures, Fixture{Description: text[2:]})
+			continue
+		}
+		key, value, _ := strings.Cut(text, " ")
+		if len(fixtures) == 0 {
+			t.Fatalf("line %d: %s of no fixture", line, key)
+		}
+		fixture := &fixtures[len(fixtures)-1]
+		switch key {
+		case "verdict":
+			fixture.ExpectedVerdict, err = ParseXdpAction(value)
+		case "input":
+			fixture.Input, err = hex.DecodeString(value)
+		case "output":
+			fixture.ExpectedOutput, err = hex.DecodeString(value)
+		default:
+			err = fmt.Errorf("unknown field %q", key)
+		}
+		if err != nil {
+			t.Fatalf("line %d: %v", line, err)
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		t.Fatalf("can't read golden fixtures: %v", err)
+	}
+	return fixtures
+}
+
+// TestDefaultFixturesMatchGolden catches changes of packet building and encapsulation
+// of the tester, which would otherwise change expectations together with inputs
+func TestDefaultFixturesMatchGolden(t *testing.T) {
+	fixtures := DefaultFixtures()
+	if *update {
+		if err := os.WriteFile(kGoldenFixtures, writeGolden(fixtures), 0644); err != nil {
+			t.Fatalf("can't write golden fixtures: %v", err)
+		}
+	}
+	golden := readGolden(t)
+	if len(golden) != len(fixtures) {
+		t.Fatalf("%d golden fixtures, %d built-in ones", len(golden), len(fixtures))
+	}
+	for i := range fixtures {
+		built, expected := &fixtures[i], &golden[i]
+		if built.Description != expected.Description || built.ExpectedVerdict != expected.ExpectedVerdict ||
+			!bytes.Equal(built.Input, expected.Input) || !bytes.Equal(built.ExpectedOutput, expected.ExpectedOutput) {
+			t.Errorf("fixture %d %q differs from golden one %q, run with -update if the change is intended",
+				i+1, built.Description, expected.Description)
+		}
+	}
+}
+
+// newPrivilegedTester loads the balancer as slbtester does, skipping the test without privileges
+func newPrivilegedTester(t *testing.T) *Tester {
+	t.Helper()
+	if os.Geteuid() != 0 {
+		t.Skip("loading bpf programs requires root")
+	}
+	opts := slb.DefaultFlomeshLbOptions()
+	opts.EnableHc = false
+	opts.LruSize = 1000
+	tester, err := NewTester(opts)
+	if err != nil {
+		t.Skipf("can't load balancer: %v", err)
+	}
+	t.Cleanup(tester.Close)
+	if err = tester.Program(DefaultSetup()); err != nil {
+		t.Fatalf("can't configure balancer: %v", err)
+	}
+	return tester
+}
+
+func TestBalancerGolden(t *testing.T) {
+	tester := newPrivilegedTester(t)
+	results := tester.Run(readGolden(t))
+	for i := range results {
+		if result := &results[i]; !result.Passed() {
+			t.Errorf("fixture %d %s:\n%s", i+1, result.Fixture.Description, result.Diff())
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package tester
+
+import (
+	"encoding/binary"
+	"net"
+)
+
+// protocols and header sizes of packets built by the tester
+const (
+	kEthHdrLen  = 14
+	kIPv4HdrLen = 20
+	kIPv6HdrLen = 40
+	kTcpHdrLen  = 20
+	kUdpHdrLen  = 8
+	kIcmpHdrLen = 8
+
+	kEthPIPv4 uint16 = 0x0800
+	kEthPIPv6 uint16 = 0x86dd
+
+	ProtoIcmp     uint8 = 1
+	ProtoIPIP     uint8 = 4
+	ProtoTcp      uint8 = 6
+	ProtoUdp      uint8 = 17
+	ProtoIPv6     uint8 = 41
+	ProtoFragment uint8 = 44
+	ProtoIcmpV6   uint8 = 58
+
+	// ttl of packets which are built by the balancer, DEFAULT_TTL of balancer_consts.h
+	kDefaultTtl uint8 = 64
+	// 172.16/16, source of ipip headers, IPIP_V4_PREFIX of balancer_consts.h
+	kIpipV4Prefix0 = 172
+	kIpipV4Prefix1 = 16
+)
+
+// tcp flags
+const (
+	TcpFin uint8 = 1 << 0
+	TcpSyn uint8 = 1 << 1
+	TcpRst uint8 = 1 << 2
+	TcpPsh uint8 = 1 << 3
+	TcpAck uint8 = 1 << 4
+)
+
+// icmp types
+const (
+	IcmpEchoReply     uint8 = 0
+	IcmpEchoRequest   uint8 = 8
+	IcmpV6EchoRequest uint8 = 128
+	IcmpV6EchoReply   uint8 = 129
+)
+
+// Eth is ethernet header of a packet
+type Eth struct {
+	Src net.HardwareAddr
+	Dst net.HardwareAddr
+}
+
+// IP is ipv4 or ipv6 header; version is taken from Src. Tos is traffic class of ipv6
+type IP struct {
+	Src net.IP
+	Dst net.IP
+	Tos uint8
+	// ttl or hop limit, kDefaultTtl if zero
+	Ttl uint8
+	// ipv4 only
+	Id        uint16
+	MoreFrags bool
+	// ipv4 header words after fixed header, ihl is 5 + len(Options)/4
+	Options []byte
+}
+
+func (ip *IP) isV4() bool {
+	return ip.Src.To4() != nil
+}
+
+// L4 is transport header of a packet
+type L4 interface {
+	proto(v4 bool) uint8
+	// marshal returns header followed by payload, with checksum calculated over pseudo header
+	marshal(payload []byte, pseudo []byte) []byte
+}
+
+// Tcp is tcp header
+type Tcp struct {
+	SrcPort uint16
+	DstPort uint16
+	Seq     uint32
+	Ack     uint32
+	Flags   uint8
+	Window  uint16
+}
+
+func (t *Tcp) proto(v4 bool) uint8 {
+	return ProtoTcp
+}
+
+func (t *Tcp) marshal(payload []byte, pseudo []byte) []byte {
+	hdr := make([]byte, kTcpHdrLen, kTcpHdrLen+len(payload))
+	binary.BigEndian.PutUint16(hdr[0:], t.SrcPort)
+	binary.BigEndian.PutUint16(hdr[2:], t.DstPort)
+	binary.BigEndian.PutUint32(hdr[4:], t.Seq)
+	binary.BigEndian.PutUint32(hdr[8:], t.Ack)
+	hdr[12] = (kTcpHdrLen / 4) << 4
+	hdr[13] = t.Flags
+	window := t.Window
+	if window == 0 {
+		window = 8192
+	}
+	binary.BigEndian.PutUint16(hdr[14:], window)
+	segment := append(hdr, payload...)
+	binary.BigEndian.PutUint16(segment[16:], checksum(pseudo, segment))
+	return segment
+}
+
+// Udp is udp header
+type Udp struct {
+	SrcPort uint16
+	DstPort uint16
+}
+
+func (u *Udp) proto(v4 bool) uint8 {
+	return ProtoUdp
+}
+
+func (u *Udp) marshal(payload []byte, pseudo []byte) []byte {
+	hdr := make([]byte, kUdpHdrLen, kUdpHdrLen+len(payload))
+	binary.BigEndian.PutUint16(hdr[0:], u.SrcPort)
+	binary.BigEndian.PutUint16(hdr[2:], u.DstPort)
+	binary.BigEndian.PutUint16(hdr[4:], uint16(kUdpHdrLen+len(payload)))
+	datagram := append(hdr, payload...)
+	sum := checksum(pseudo, datagram)
+	if sum == 0 {
+		sum = 0xffff
+	}
+	binary.BigEndian.PutUint16(datagram[6:], sum)
+	return datagram
+}
+
+// Icmp is echo request or reply of icmp or icmpv6, depending on version of ip header
+type Icmp struct {
+	Type uint8
+	Code uint8
+	Id   uint16
+	Seq  uint16
+}
+
+func (i *Icmp) proto(v4 bool) uint8 {
+	if v4 {
+		return ProtoIcmp
+	}
+	return ProtoIcmpV6
+}
+
+func (i *Icmp) marshal(payload []byte, pseudo []byte) []byte {
+	hdr := make([]byte, kIcmpHdrLen, kIcmpHdrLen+len(payload))
+	hdr[0] = i.Type
+	hdr[1] = i.Code
+	binary.BigEndian.PutUint16(hdr[4:], i.Id)
+	binary.BigEndian.PutUint16(hdr[6:], i.Seq)
+	message := append(hdr, payload...)
+	// icmpv4 checksum doesn't cover pseudo header
+	binary.BigEndian.PutUint16(message[2:], checksum(pseudo, message))
+	return message
+}
+
+// Build returns ethernet frame of ip packet with l4 header and payload
+func Build(eth Eth, ip IP, l4 L4, payload []byte) []byte {
+	v4 := ip.isV4()
+	proto := l4.proto(v4)
+	segmentLen := len(payload) + l4HdrLen(l4)
+	var pseudo []byte
+	if v4 {
+		if proto != ProtoIcmp {
+			pseudo = pseudoHeaderV4(ip.Src, ip.Dst, proto, segmentLen)
+		}
+	} else {
+		pseudo = pseudoHeaderV6(ip.Src, ip.Dst, proto, segmentLen)
+	}
+	return BuildRaw(eth, ip, proto, l4.marshal(payload, pseudo))
+}
+
+// BuildRaw returns ethernet frame of ip packet with payload of protocol proto, which is put as is
+func BuildRaw(eth Eth, ip IP, proto uint8, payload []byte) []byte {
+	if ip.isV4() {
+		return append(ethHeader(eth, kEthPIPv4), ipv4Header(ip, proto, len(payload), payload)...)
+	}
+	return append(ethHeader(eth, kEthPIPv6), ipv6Header(ip, proto, len(payload), payload)...)
+}
+
+func l4HdrLen(l4 L4) int {
+	switch l4.(type) {
+	case *Tcp:
+		return kTcpHdrLen
+	case *Udp:
+		return kUdpHdrLen
+	default:
+		return kIcmpHdrLen
+	}
+}
+
+func ethHeader(eth Eth, proto uint16) []byte {
+	hdr := make([]byte, kEthHdrLen)
+	copy(hdr[0:6], eth.Dst)
+	copy(hdr[6:12], eth.Src)
+	binary.BigEndian.PutUint16(hdr[12:], proto)
+	return hdr
+}
+
+// ipv4Header returns ipv4 header followed by payload
+func ipv4Header(ip IP, proto uint8, payloadLen int, payload []byte) []byte {
+	hdrLen := kIPv4HdrLen + len(ip.Options)
+	hdr := make([]byte, hdrLen, hdrLen+len(payload))
+	hdr[0] = 4<<4 | uint8(hdrLen/4)
+	hdr[1] = ip.Tos
+	binary.BigEndian.PutUint16(hdr[2:], uint16(hdrLen+payloadLen))
+	binary.BigEndian.PutUint16(hdr[4:], ip.Id)
+	if ip.MoreFrags {
+		hdr[6] = 0x20
+	}
+	hdr[8] = ttlOrDefault(ip.Ttl)
+	hdr[9] = proto
+	copy(hdr[12:16], ip.Src.To4())
+	copy(hdr[16:20], ip.Dst.To4())
+	copy(hdr[20:], ip.Options)
+	binary.BigEndian.PutUint16(hdr[10:], checksum(nil, hdr))
+	return append(hdr, payload...)
+}
+
+// ipv6Header returns ipv6 header followed by payload
+func ipv6Header(ip IP, proto uint8, payloadLen int, payload []byte) []byte {
+	hdr := make([]byte, kIPv6HdrLen, kIPv6HdrLen+len(payload))
+	hdr[0] = 6<<4 | ip.Tos>>4
+	hdr[1] = ip.Tos << 4
+	binary.BigEndian.PutUint16(hdr[4:], uint16(payloadLen))
+	hdr[6] = proto
+	hdr[7] = ttlOrDefault(ip.Ttl)
+	copy(hdr[8:24], ip.Src.To16())
+	copy(hdr[24:40], ip.Dst.To16())
+	return append(hdr, payload...)
+}
+
+func ttlOrDefault(ttl uint8) uint8 {
+	if ttl == 0 {
+		return kDefaultTtl
+	}
+	return ttl
+}
+
+func pseudoHeaderV4(src, dst net.IP, proto uint8, length int) []byte {
+	pseudo := make([]byte, 12)
+	copy(pseudo[0:4], src.To4())
+	copy(pseudo[4:8], dst.To4())
+	pseudo[9] = proto
+	binary.BigEndian.PutUint16(pseudo[10:], uint16(length))
+	return pseudo
+}
+
+func pseudoHeaderV6(src, dst net.IP, proto uint8, length int) []byte {
+	pseudo := make([]byte, 40)
+	copy(pseudo[0:16], src.To16())
+	copy(pseudo[16:32], dst.To16())
+	binary.BigEndian.PutUint32(pseudo[32:], uint32(length))
+	pseudo[39] = proto
+	return pseudo
+}
+
+// checksum returns internet checksum of pseudo header and data; checksum field of data must be zero
+func checksum(pseudo []byte, data []byte) uint16 {
+	var sum uint32
+	for _, buf := range [][]byte{pseudo, data} {
+		for i := 0; i+1 < len(buf); i += 2 {
+			sum += uint32(binary.BigEndian.Uint16(buf[i:]))
+		}
+		if len(buf)%2 == 1 {
+			sum += uint32(buf[len(buf)-1]) << 8
+		}
+	}
+	for sum > 0xffff {
+		sum = sum>>16 + sum&0xffff
+	}
+	return ^uint16(sum)
+}
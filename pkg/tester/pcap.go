@@ -0,0 +1,224 @@
+package tester
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cybwan/l4slb/pkg/bpf/monitor"
+)
+
+// fixtures are kept as three files: pcap of input packets, pcap of packets
+// the program is expected to leave in the buffer, and report with one line per
+// packet: number, verdict, length of output packet and summary of input packet.
+// expected pcap has a record per input packet, empty ones for packets whose output
+// is not checked, so records of all three files match by their numbers
+
+// ReadFixtures creates fixtures of packets of pcap file, without expectations.
+// they are set by SetExpected, or the fixtures are just replayed to see what the program does
+func ReadFixtures(input io.Reader) ([]Fixture, error) {
+	reader, err := monitor.NewPcapReader(input)
+	if err != nil {
+		return nil, err
+	}
+	var fixtures []Fixture
+	for {
+		pkt, err := reader.ReadPacket()
+		if err == io.EOF {
+			return fixtures, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("packet %d: %w", len(fixtures)+1, err)
+		}
+		if pkt.PktSize > uint32(len(pkt.Data)) {
+			log.Warn().Msgf("packet %d is truncated to %d of %d bytes", len(fixtures)+1, len(pkt.Data), pkt.PktSize)
+		}
+		fixtures = append(fixtures, Fixture{
+			Description: describe(pkt.Data),
+			Input:       pkt.Data,
+			Timestamp:   pkt.Timestamp,
+		})
+	}
+}
+
+// SetExpected sets expected outputs of fixtures from pcap file and verdicts from report
+func SetExpected(fixtures []Fixture, expected io.Reader, report io.Reader) error {
+	verdicts, err := readReport(report)
+	if err != nil {
+		return err
+	}
+	if len(verdicts) != len(fixtures) {
+		return fmt.Errorf("report has %d packets, expected %d", len(verdicts), len(fixtures))
+	}
+	reader, err := monitor.NewPcapReader(expected)
+	if err != nil {
+		return err
+	}
+	for i := range fixtures {
+		pkt, err := reader.ReadPacket()
+		if err == io.EOF {
+			return fmt.Errorf("expected pcap has %d packets, expected %d", i, len(fixtures))
+		}
+		if err != nil {
+			return fmt.Errorf("expected packet %d: %w", i+1, err)
+		}
+		fixtures[i].ExpectedVerdict = verdicts[i]
+		fixtures[i].ExpectedOutput = nil
+		if len(pkt.Data) > 0 {
+			fixtures[i].ExpectedOutput = pkt.Data
+		}
+	}
+	return nil
+}
+
+// WriteFixtures writes input packets, expected outputs and expected verdicts of fixtures
+func WriteFixtures(fixtures []Fixture, input io.Writer, expected io.Writer, report io.Writer) error {
+	inputs := monitor.NewPcapWriter(input, monitor.DefaultSnapLen)
+	outputs := monitor.NewPcapWriter(expected, monitor.DefaultSnapLen)
+	reportWriter := bufio.NewWriter(report)
+	writeReportHeader(reportWriter)
+	for i := range fixtures {
+		fixture := &fixtures[i]
+		ts := timestamp(fixture, i)
+		if err := inputs.WritePacket(&monitor.Packet{Timestamp: ts, Data: fixture.Input}); err != nil {
+			return err
+		}
+		if err := outputs.WritePacket(&monitor.Packet{Timestamp: ts, Data: fixture.ExpectedOutput}); err != nil {
+			return err
+		}
+		writeReportLine(reportWriter, i, fixture.ExpectedVerdict, fixture.ExpectedOutput, fixture.Description)
+	}
+	if err := inputs.WriteHeader(); err != nil {
+		return err
+	}
+	if err := outputs.WriteHeader(); err != nil {
+		return err
+	}
+	return reportWriter.Flush()
+}
+
+// WriteResults writes packets the program has left in the buffer and its verdicts.
+// outputs of dropped packets are written as empty records
+func WriteResults(results []Result, output io.Writer, report io.Writer) error {
+	outputs := monitor.NewPcapWriter(output, monitor.DefaultSnapLen)
+	reportWriter := bufio.NewWriter(report)
+	writeReportHeader(reportWriter)
+	for i := range results {
+		result := &results[i]
+		data := result.Output
+		if result.Err != nil || result.Verdict == XdpDrop || result.Verdict == XdpAborted {
+			data = nil
+		}
+		pkt := monitor.Packet{Timestamp: timestamp(result.Fixture, i), Data: data}
+		if err := outputs.WritePacket(&pkt); err != nil {
+			return err
+		}
+		description := result.Fixture.Description
+		if result.Err != nil {
+			description = fmt.Sprintf("%s (test run failed: %v)", description, result.Err)
+		}
+		writeReportLine(reportWriter, i, result.Verdict, data, description)
+	}
+	if err := outputs.WriteHeader(); err != nil {
+		return err
+	}
+	return reportWriter.Flush()
+}
+
+// timestamp returns time of the fixture's packet; generated packets are a second apart
+func timestamp(fixture *Fixture, i int) time.Time {
+	if fixture.Timestamp.IsZero() {
+		return time.Unix(int64(i), 0)
+	}
+	return fixture.Timestamp
+}
+
+func writeReportHeader(w io.Writer) {
+	fmt.Fprintf(w, "# packet\tverdict\toutput_len\tsummary\n")
+}
+
+func writeReportLine(w io.Writer, i int, verdict XdpAction, output []byte, description string) {
+	fmt.Fprintf(w, "%d\t%s\t%d\t%s\n", i+1, verdict, len(output), description)
+}
+
+// readReport returns verdicts of report, in order of packets
+func readReport(r io.Reader) ([]XdpAction, error) {
+	var verdicts []XdpAction
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.SplitN(text, "\t", 4)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("report line %d: expected packet number and verdict", line)
+		}
+		if num, err := strconv.Atoi(fields[0]); err != nil || num != len(verdicts)+1 {
+			return nil, fmt.Errorf("report line %d: expected packet %d, got %q", line, len(verdicts)+1, fields[0])
+		}
+		verdict, err := ParseXdpAction(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("report line %d: %w", line, err)
+		}
+		verdicts = append(verdicts, verdict)
+	}
+	return verdicts, scanner.Err()
+}
+
+// describe returns one line summary of ethernet frame, like "tcp 10.0.0.1:1234 > 10.0.0.2:80"
+func describe(pkt []byte) string {
+	if len(pkt) < kEthHdrLen {
+		return fmt.Sprintf("runt frame of %d bytes", len(pkt))
+	}
+	ethType := binary.BigEndian.Uint16(pkt[12:])
+	ip := pkt[kEthHdrLen:]
+	var src, dst net.IP
+	var proto uint8
+	var l4 []byte
+	switch {
+	case ethType == kEthPIPv4 && len(ip) >= kIPv4HdrLen:
+		ihl := int(ip[0]&0x0f) * 4
+		src, dst, proto = net.IP(ip[12:16]), net.IP(ip[16:20]), ip[9]
+		if binary.BigEndian.Uint16(ip[6:])&0x3fff != 0 {
+			return fmt.Sprintf("ipv4 fragment %s > %s proto %d", src, dst, proto)
+		}
+		if ihl <= len(ip) {
+			l4 = ip[ihl:]
+		}
+	case ethType == kEthPIPv6 && len(ip) >= kIPv6HdrLen:
+		src, dst, proto = net.IP(ip[8:24]), net.IP(ip[24:40]), ip[6]
+		l4 = ip[kIPv6HdrLen:]
+	default:
+		return fmt.Sprintf("ethertype %#04x, %d bytes", ethType, len(pkt))
+	}
+	switch proto {
+	case ProtoTcp, ProtoUdp:
+		name := "udp"
+		if proto == ProtoTcp {
+			name = "tcp"
+		}
+		if len(l4) < 4 {
+			return fmt.Sprintf("truncated %s %s > %s", name, src, dst)
+		}
+		return fmt.Sprintf("%s %s > %s", name,
+			net.JoinHostPort(src.String(), strconv.Itoa(int(binary.BigEndian.Uint16(l4[0:])))),
+			net.JoinHostPort(dst.String(), strconv.Itoa(int(binary.BigEndian.Uint16(l4[2:])))))
+	case ProtoIcmp, ProtoIcmpV6:
+		name := "icmp"
+		if proto == ProtoIcmpV6 {
+			name = "icmpv6"
+		}
+		if len(l4) < 2 {
+			return fmt.Sprintf("truncated %s %s > %s", name, src, dst)
+		}
+		return fmt.Sprintf("%s %s > %s type %d code %d", name, src, dst, l4[0], l4[1])
+	default:
+		return fmt.Sprintf("%s > %s proto %d", src, dst, proto)
+	}
+}
@@ -0,0 +1,112 @@
+package tester
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// kPcapFixtures is the directory with fixture files as slbtester -generate writes them
+var kPcapFixtures = filepath.Join("testdata", "default")
+
+func readTestFile(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(kPcapFixtures, name))
+	if err != nil {
+		t.Fatalf("can't read %s: %v", name, err)
+	}
+	return data
+}
+
+// readPcapFixtures loads fixtures of testdata as slbtester -pcap -expected_pcap does
+func readPcapFixtures(t *testing.T) []Fixture {
+	t.Helper()
+	fixtures, err := ReadFixtures(bytes.NewReader(readTestFile(t, "input.pcap")))
+	if err != nil {
+		t.Fatalf("can't read input packets: %v", err)
+	}
+	err = SetExpected(fixtures, bytes.NewReader(readTestFile(t, "expected.pcap")),
+		bytes.NewReader(readTestFile(t, "expected.txt")))
+	if err != nil {
+		t.Fatalf("can't read expectations: %v", err)
+	}
+	return fixtures
+}
+
+func TestWriteFixturesMatchesTestdata(t *testing.T) {
+	var input, expected, report bytes.Buffer
+	if err := WriteFixtures(DefaultFixtures(), &input, &expected, &report); err != nil {
+		t.Fatalf("can't write fixtures: %v", err)
+	}
+	files := map[string][]byte{
+		"input.pcap":    input.Bytes(),
+		"expected.pcap": expected.Bytes(),
+		"expected.txt":  report.Bytes(),
+	}
+	for name, data := range files {
+		if *update {
+			if err := os.WriteFile(filepath.Join(kPcapFixtures, name), data, 0644); err != nil {
+				t.Fatalf("can't write %s: %v", name, err)
+			}
+		}
+		if !bytes.Equal(data, readTestFile(t, name)) {
+			t.Errorf("%s differs from written fixtures, run with -update if the change is intended", name)
+		}
+	}
+}
+
+func TestReadFixtures(t *testing.T) {
+	fixtures := readPcapFixtures(t)
+	golden := readGolden(t)
+	if len(fixtures) != len(golden) {
+		t.Fatalf("read %d fixtures, expected %d", len(fixtures), len(golden))
+	}
+	for i := range fixtures {
+		read, expected := &fixtures[i], &golden[i]
+		if !bytes.Equal(read.Input, expected.Input) || read.ExpectedVerdict != expected.ExpectedVerdict ||
+			!bytes.Equal(read.ExpectedOutput, expected.ExpectedOutput) {
+			t.Errorf("packet %d %q differs from fixture %q", i+1, read.Description, expected.Description)
+		}
+	}
+	if fixtures[0].Description != "tcp 192.168.1.42:31337 > 10.200.1.1:80" {
+		t.Errorf("first packet is described as %q", fixtures[0].Description)
+	}
+}
+
+func TestSetExpectedMismatch(t *testing.T) {
+	fixtures := readPcapFixtures(t)
+	err := SetExpected(fixtures[:len(fixtures)-1], bytes.NewReader(readTestFile(t, "expected.pcap")),
+		bytes.NewReader(readTestFile(t, "expected.txt")))
+	if err == nil {
+		t.Error("expectations of more packets than there are, expected error")
+	}
+}
+
+func TestReplayPcap(t *testing.T) {
+	tester := newPrivilegedTester(t)
+	results := tester.Run(readPcapFixtures(t))
+	for i := range results {
+		if result := &results[i]; !result.Passed() {
+			t.Errorf("packet %d %s:\n%s", i+1, result.Fixture.Description, result.Diff())
+		}
+	}
+
+	// output of the replay is what the fixtures expect
+	var output, report bytes.Buffer
+	if err := WriteResults(results, &output, &report); err != nil {
+		t.Fatalf("can't write results: %v", err)
+	}
+	if !bytes.Equal(output.Bytes(), readTestFile(t, "expected.pcap")) {
+		t.Error("output packets differ from expected.pcap")
+	}
+	verdicts, err := readReport(&report)
+	if err != nil || len(verdicts) != len(results) {
+		t.Fatalf("report of %d verdicts (%v), expected %d", len(verdicts), err, len(results))
+	}
+	for i, verdict := range verdicts {
+		if verdict != results[i].Fixture.ExpectedVerdict {
+			t.Errorf("packet %d is reported with %s, expected %s", i+1, verdict, results[i].Fixture.ExpectedVerdict)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package tester
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/cybwan/l4slb/pkg/slb"
+)
+
+// setupFile is YAML representation of Setup, e.g.
+//
+//	gateway_mac: 02:00:00:00:00:03
+//	vips:
+//	  - address: 10.200.1.1
+//	    port: 80
+//	    proto: tcp
+//	    flags: 0
+//	    ring_size: 1021
+//	    reals:
+//	      - address: 10.0.0.1
+//	        weight: 1
+type setupFile struct {
+	GatewayMac string `yaml:"gateway_mac"`
+	Vips       []struct {
+		Address  string `yaml:"address"`
+		Port     uint16 `yaml:"port"`
+		Proto    string `yaml:"proto"`
+		Flags    uint32 `yaml:"flags"`
+		RingSize uint32 `yaml:"ring_size"`
+		Reals    []struct {
+			Address string `yaml:"address"`
+			Weight  uint32 `yaml:"weight"`
+			Flags   uint8  `yaml:"flags"`
+		} `yaml:"reals"`
+	} `yaml:"vips"`
+}
+
+// LoadSetup reads vips and reals the balancer is configured with, for replaying
+// captures of a balancer which serves other vips than DefaultSetup
+func LoadSetup(path string) (*Setup, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't open setup file: %w", err)
+	}
+	defer f.Close()
+
+	var file setupFile
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+	if err = decoder.Decode(&file); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("can't parse setup file %s: %w", path, err)
+	}
+
+	setup := &Setup{}
+	if setup.GatewayMac, err = net.ParseMAC(file.GatewayMac); err != nil {
+		return nil, fmt.Errorf("gateway_mac: %w", err)
+	}
+	for _, v := range file.Vips {
+		if net.ParseIP(v.Address) == nil {
+			return nil, fmt.Errorf("vip: invalid address %q", v.Address)
+		}
+		proto, err := parseProto(v.Proto)
+		if err != nil {
+			return nil, fmt.Errorf("vip %s: %w", v.Address, err)
+		}
+		vip := VipSetup{
+			Key:      slb.VipKey{Address: v.Address, Port: v.Port, Proto: proto},
+			Flags:    v.Flags,
+			RingSize: v.RingSize,
+		}
+		for _, r := range v.Reals {
+			if net.ParseIP(r.Address) == nil {
+				return nil, fmt.Errorf("vip %s: invalid real address %q", v.Address, r.Address)
+			}
+			vip.Reals = append(vip.Reals, slb.NewReal{Address: r.Address, Weight: r.Weight, Flags: r.Flags})
+		}
+		setup.Vips = append(setup.Vips, vip)
+	}
+	return setup, nil
+}
+
+func parseProto(proto string) (uint8, error) {
+	switch strings.ToLower(proto) {
+	case "tcp":
+		return ProtoTcp, nil
+	case "udp":
+		return ProtoUdp, nil
+	default:
+		return 0, fmt.Errorf("unsupported proto %q, expected tcp or udp", proto)
+	}
+}
@@ -0,0 +1,189 @@
+package tester
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/cilium/ebpf"
+
+	"github.com/cybwan/l4slb/pkg/logger"
+	"github.com/cybwan/l4slb/pkg/slb"
+)
+
+var (
+	log = logger.New("slb-tester")
+)
+
+// XdpAction is verdict of xdp program
+type XdpAction uint32
+
+const (
+	XdpAborted XdpAction = iota
+	XdpDrop
+	XdpPass
+	XdpTx
+	XdpRedirect
+)
+
+func (a XdpAction) String() string {
+	switch a {
+	case XdpAborted:
+		return "XDP_ABORTED"
+	case XdpDrop:
+		return "XDP_DROP"
+	case XdpPass:
+		return "XDP_PASS"
+	case XdpTx:
+		return "XDP_TX"
+	case XdpRedirect:
+		return "XDP_REDIRECT"
+	default:
+		return fmt.Sprintf("XDP_%d", uint32(a))
+	}
+}
+
+// ParseXdpAction returns action by its name, as it is printed by String
+func ParseXdpAction(s string) (XdpAction, error) {
+	for a := XdpAborted; a <= XdpRedirect; a++ {
+		if strings.EqualFold(s, a.String()) {
+			return a, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown xdp action %q", s)
+}
+
+// Tester runs packets through the balancer program by BPF_PROG_TEST_RUN. the program
+// is loaded into the kernel but not attached to any interface, and is configured by
+// FlomeshLb as slbd does, so it needs privileges to load bpf programs but no NIC
+type Tester struct {
+	lb   *slb.FlomeshLb
+	prog *ebpf.Program
+}
+
+// NewTester loads the balancer with opts and creates its connection tables
+func NewTester(opts *slb.FlomeshLbOptions) (*Tester, error) {
+	config, err := opts.Build()
+	if err != nil {
+		return nil, err
+	}
+	lb := slb.NewFlomeshLb(config)
+	prog, err := lb.LoadBalancer()
+	if err != nil {
+		return nil, fmt.Errorf("can't load balancer: %w", err)
+	}
+	if !lb.InitLruMaps() {
+		lb.CloseBalancer()
+		return nil, fmt.Errorf("can't create connection tables")
+	}
+	return &Tester{lb: lb, prog: prog.Prog()}, nil
+}
+
+// Close unloads the balancer
+func (t *Tester) Close() {
+	t.lb.CloseBalancer()
+}
+
+// Lb returns controller of the loaded balancer
+func (t *Tester) Lb() *slb.FlomeshLb {
+	return t.lb
+}
+
+// Program sets gateway's mac and adds vips and reals of setup
+func (t *Tester) Program(setup *Setup) error {
+	if !t.lb.ChangeMac(setup.GatewayMac) {
+		return fmt.Errorf("can't set gateway's mac %s", setup.GatewayMac)
+	}
+	for _, vip := range setup.Vips {
+		if !t.lb.AddVipWithRingSize(&vip.Key, vip.Flags, vip.RingSize) {
+			return fmt.Errorf("can't add vip %s:%d:%d", vip.Key.Address, vip.Key.Port, vip.Key.Proto)
+		}
+		for _, real := range vip.Reals {
+			real := real
+			if !t.lb.AddRealForVip(&real, &vip.Key) {
+				return fmt.Errorf("can't add real %s for vip %s:%d:%d",
+					real.Address, vip.Key.Address, vip.Key.Port, vip.Key.Proto)
+			}
+		}
+	}
+	return nil
+}
+
+// Result is outcome of a fixture
+type Result struct {
+	Fixture *Fixture
+	Verdict XdpAction
+	Output  []byte
+	Err     error
+}
+
+// Passed returns true if the program has returned expected verdict and, if it is set, expected packet
+func (r *Result) Passed() bool {
+	if r.Err != nil || r.Verdict != r.Fixture.ExpectedVerdict {
+		return false
+	}
+	return r.Fixture.ExpectedOutput == nil || bytes.Equal(r.Output, r.Fixture.ExpectedOutput)
+}
+
+// Diff describes mismatch of result and fixture, empty if the fixture has passed
+func (r *Result) Diff() string {
+	if r.Err != nil {
+		return fmt.Sprintf("test run failed: %v", r.Err)
+	}
+	var diff bytes.Buffer
+	if r.Verdict != r.Fixture.ExpectedVerdict {
+		fmt.Fprintf(&diff, "verdict %s, expected %s\n", r.Verdict, r.Fixture.ExpectedVerdict)
+	}
+	if r.Fixture.ExpectedOutput != nil && !bytes.Equal(r.Output, r.Fixture.ExpectedOutput) {
+		fmt.Fprintf(&diff, "output packet differs at byte %d\n", firstDiff(r.Output, r.Fixture.ExpectedOutput))
+		fmt.Fprintf(&diff, "got:\n%sexpected:\n%s", hex.Dump(r.Output), hex.Dump(r.Fixture.ExpectedOutput))
+	}
+	return diff.String()
+}
+
+func firstDiff(a, b []byte) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	if len(a) < len(b) {
+		return len(a)
+	}
+	return len(b)
+}
+
+// Run passes packet of every fixture through the balancer, in order. fixtures of one flow
+// depend on each other, as the first packet of the flow fills up connection table
+func (t *Tester) Run(fixtures []Fixture) []Result {
+	results := make([]Result, 0, len(fixtures))
+	for i := range fixtures {
+		results = append(results, t.RunFixture(&fixtures[i]))
+	}
+	return results
+}
+
+// RunFixture passes packet of the fixture through the balancer
+func (t *Tester) RunFixture(fixture *Fixture) Result {
+	result := Result{Fixture: fixture}
+	ret, out, err := t.prog.Test(fixture.Input)
+	result.Verdict = XdpAction(ret)
+	result.Output = out
+	result.Err = err
+	if !result.Passed() {
+		log.Error().Msgf("%s: failed\n%s", fixture.Description, result.Diff())
+	} else {
+		log.Debug().Msgf("%s: passed", fixture.Description)
+	}
+	return result
+}
+
+func mustParseMac(mac string) net.HardwareAddr {
+	addr, err := net.ParseMAC(mac)
+	if err != nil {
+		panic(err)
+	}
+	return addr
+}